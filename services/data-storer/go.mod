@@ -5,11 +5,13 @@ go 1.17
 require (
 	github.com/aws/aws-lambda-go v1.17.0
 	github.com/cshep4/go-log v1.0.0
+	github.com/cshep4/kripto/shared/go/events v0.0.0-00010101000000-000000000000
 	github.com/cshep4/lambda-go/idempotency v1.1.0
 	github.com/cshep4/lambda-go/lambda v1.2.0
 	github.com/cshep4/lambda-go/log/v2 v2.0.1
 	github.com/cshep4/lambda-go/mongodb v1.0.2
 	github.com/golang/mock v1.4.3
+	github.com/shopspring/decimal v1.3.1
 	github.com/stretchr/testify v1.7.0
 	go.mongodb.org/mongo-driver v1.3.3
 	go.uber.org/zap v1.19.0
@@ -33,3 +35,5 @@ require (
 	golang.org/x/tools v0.0.0-20200311090712-aafaee8bce8c // indirect
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
+
+replace github.com/cshep4/kripto/shared/go/events => ../../shared/go/events
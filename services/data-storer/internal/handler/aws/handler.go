@@ -10,12 +10,14 @@ import (
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/cshep4/go-log"
 	"github.com/cshep4/kripto/services/data-storer/internal/model"
+	schema "github.com/cshep4/kripto/shared/go/events"
 	"go.uber.org/zap"
 )
 
 type (
 	Servicer interface {
 		Get(ctx context.Context) ([]model.Rate, error)
+		GetTrades(ctx context.Context, productId string) ([]model.Trade, error)
 		StoreTrade(ctx context.Context, trade model.Trade) error
 		StoreRate(ctx context.Context, rate float64, dateTime time.Time) error
 	}
@@ -42,6 +44,7 @@ func (h *Handler) Functions() map[string]interface{} {
 	return map[string]interface{}{
 		"data-reader":      h.Get,
 		"data-reader-http": h.GetRates,
+		"trade-reader":     h.GetTrades,
 		"trade-writer":     h.StoreTrade,
 		"rate-writer":      h.StoreRate,
 	}
@@ -57,6 +60,23 @@ func (h *Handler) Get(ctx context.Context) ([]model.Rate, error) {
 	return rates, nil
 }
 
+func (h *Handler) GetTrades(ctx context.Context, req model.GetTradesRequest) ([]model.Trade, error) {
+	if req.ProductId == "" {
+		return nil, errors.New("no productId passed to function")
+	}
+
+	trades, err := h.Service.GetTrades(ctx, req.ProductId)
+	if err != nil {
+		log.Error(ctx, "error_getting_trades",
+			zap.String("productId", req.ProductId),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	return trades, nil
+}
+
 func (h *Handler) GetRates(ctx context.Context) (*events.APIGatewayProxyResponse, error) {
 	rates, err := h.Service.Get(ctx)
 	if err != nil {
@@ -88,20 +108,21 @@ func (h *Handler) StoreTrade(ctx context.Context, sqsEvent events.SQSEvent) erro
 	}
 
 	for _, msg := range sqsEvent.Records {
-		var req model.TradeRequest
-		err := json.Unmarshal([]byte(msg.Body), &req)
+		var event schema.TradeExecutedV1
+		err := schema.Unmarshal([]byte(msg.Body), &event)
 		if err != nil {
 			log.Error(ctx, "invalid_msg_body", zap.Error(err))
 			continue
 		}
 
+		req := model.TradeRequest(event)
 		trade, err := req.ToTrade()
 		if err != nil {
 			log.Error(ctx, "invalid_msg_body",
 				zap.String("id", req.Id),
 				zap.String("funds", req.Funds),
-				zap.String("btc", req.FilledSize),
-				zap.String("gbp", req.ExecutedValue),
+				zap.String("filledSize", req.FilledSize),
+				zap.String("executedValue", req.ExecutedValue),
 				zap.Error(err),
 			)
 			continue
@@ -112,8 +133,8 @@ func (h *Handler) StoreTrade(ctx context.Context, sqsEvent events.SQSEvent) erro
 			log.Error(ctx, "error_storing_trade",
 				zap.String("id", trade.Id),
 				zap.Time("createdAt", trade.CreatedAt),
-				zap.Float64("btc", trade.Value.BTC),
-				zap.Float64("gbp", trade.Value.GBP),
+				zap.Stringer("base", trade.Value.Base),
+				zap.Stringer("quote", trade.Value.Quote),
 				zap.Error(err),
 			)
 			return err
@@ -130,7 +151,7 @@ func (h *Handler) StoreRate(ctx context.Context, sqsEvent events.SQSEvent) error
 
 	for _, msg := range sqsEvent.Records {
 		var req model.StoreRateRequest
-		err := json.Unmarshal([]byte(msg.Body), &req)
+		err := schema.Unmarshal([]byte(msg.Body), &req)
 		if err != nil {
 			log.Error(ctx, "invalid_msg_body", zap.Error(err))
 			continue
@@ -9,6 +9,7 @@ import (
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -110,11 +111,11 @@ func TestHandler_StoreTrade(t *testing.T) {
 				Id:         "tradeId",
 				TradeType:  model.Buy,
 				ProductId:  "productId",
-				SpentFunds: float64(1),
-				Fees:       float64(2),
+				SpentFunds: decimal.RequireFromString("1"),
+				Fees:       decimal.RequireFromString("2"),
 				Value: model.Value{
-					BTC: float64(3),
-					GBP: float64(4),
+					Base:  decimal.RequireFromString("3"),
+					Quote: decimal.RequireFromString("4"),
 				},
 			}
 		)
@@ -127,6 +128,39 @@ func TestHandler_StoreTrade(t *testing.T) {
 		assert.True(t, errors.Is(err, testErr))
 	})
 
+	t.Run("stores orders without funds or fills", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			service = service_mocks.NewMockServicer(ctrl)
+			handler = aws.Handler{
+				Service: service,
+			}
+			ctx   = context.Background()
+			event = events.SQSEvent{
+				Records: []events.SQSMessage{
+					{Body: `{"id": "limit", "side": "buy", "type": "limit", "productId": "BTC-GBP", "settled": true, "price": "40000", "size": "0.01", "fillFees": "2", "filledSize": "0.01", "executedValue": "400"}`},
+					{Body: `{"id": "market", "side": "sell", "type": "market", "productId": "BTC-GBP", "settled": true, "size": "0.01", "fillFees": "2", "filledSize": "0.01", "executedValue": "400"}`},
+					{Body: `{"id": "open", "side": "buy", "type": "limit", "productId": "BTC-GBP", "status": "open", "price": "30000", "size": "0.01"}`},
+				},
+			}
+			filled = model.Value{
+				Base:  decimal.RequireFromString("0.01"),
+				Quote: decimal.RequireFromString("400"),
+			}
+		)
+
+		gomock.InOrder(
+			service.EXPECT().StoreTrade(ctx, model.Trade{Id: "limit", TradeType: model.Buy, ProductId: "BTC-GBP", Settled: true, SpentFunds: decimal.Zero, Fees: decimal.RequireFromString("2"), Value: filled}).Return(nil),
+			service.EXPECT().StoreTrade(ctx, model.Trade{Id: "market", TradeType: model.Sell, ProductId: "BTC-GBP", Settled: true, SpentFunds: decimal.Zero, Fees: decimal.RequireFromString("2"), Value: filled}).Return(nil),
+			service.EXPECT().StoreTrade(ctx, model.Trade{Id: "open", TradeType: model.Buy, ProductId: "BTC-GBP", SpentFunds: decimal.Zero, Fees: decimal.Zero, Value: model.Value{Base: decimal.Zero, Quote: decimal.Zero}}).Return(nil),
+		)
+
+		err := handler.StoreTrade(ctx, event)
+		require.NoError(t, err)
+	})
+
 	t.Run("returns nil if trade stored successfully", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -154,11 +188,11 @@ func TestHandler_StoreTrade(t *testing.T) {
 				Id:         "tradeId",
 				TradeType:  model.Buy,
 				ProductId:  "productId",
-				SpentFunds: float64(1),
-				Fees:       float64(2),
+				SpentFunds: decimal.RequireFromString("1"),
+				Fees:       decimal.RequireFromString("2"),
 				Value: model.Value{
-					BTC: float64(3),
-					GBP: float64(4),
+					Base:  decimal.RequireFromString("3"),
+					Quote: decimal.RequireFromString("4"),
 				},
 			}
 		)
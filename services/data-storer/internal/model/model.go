@@ -2,9 +2,11 @@ package model
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
+
+	"github.com/cshep4/kripto/shared/go/events"
+	"github.com/shopspring/decimal"
 )
 
 const (
@@ -13,10 +15,8 @@ const (
 )
 
 type (
-	StoreRateRequest struct {
-		Rate     float64   `json:"rate"`
-		DateTime time.Time `json:"dateTime"`
-	}
+	// StoreRateRequest is the RateUpdated event published by the rate-retriever.
+	StoreRateRequest = events.RateUpdatedV1
 
 	Rate struct {
 		Id       string    `json:"id"`
@@ -25,35 +25,54 @@ type (
 	}
 
 	Trade struct {
-		Id         string    `json:"id"`
-		TradeType  TradeType `json:"tradeType"`
-		ProductId  string    `json:"productId"`
-		Settled    bool      `json:"settled"`
-		CreatedAt  time.Time `json:"createdAt,string,omitempty"`
-		SpentFunds float64   `json:"funds,omitempty"`
-		Fees       float64   `json:"fillFees,omitempty"`
-		Value      Value     `json:"value"`
+		Id         string          `json:"id"`
+		TradeType  TradeType       `json:"tradeType"`
+		ProductId  string          `json:"productId"`
+		Settled    bool            `json:"settled"`
+		CreatedAt  time.Time       `json:"createdAt,string,omitempty"`
+		SpentFunds decimal.Decimal `json:"funds,omitempty"`
+		Fees       decimal.Decimal `json:"fillFees,omitempty"`
+		Value      Value           `json:"value"`
+		// EffectivePrice is the price in quote currency paid or received per unit
+		// of base currency including fees.
+		EffectivePrice decimal.Decimal `json:"effectivePrice"`
+		// FeeRate is the fees as a fraction of the executed value in quote currency.
+		FeeRate decimal.Decimal `json:"feeRate"`
+		// Net is the change in each balance, negative if the balance decreased.
+		Net   Value  `json:"net"`
+		Fills []Fill `json:"fills,omitempty"`
+		// DryRun is set if the trade was only simulated by the trader.
+		DryRun bool `json:"dryRun,omitempty"`
 	}
 
+	// Fill is a single execution of part of a trade.
+	Fill struct {
+		Id        string          `json:"id"`
+		Price     decimal.Decimal `json:"price"`
+		Size      decimal.Decimal `json:"size"`
+		Fee       decimal.Decimal `json:"fee"`
+		Liquidity string          `json:"liquidity"` // M for maker or T for taker.
+		CreatedAt time.Time       `json:"createdAt"`
+	}
+
+	// Value is an amount of each currency of the product, e.g. BTC and GBP for BTC-GBP.
 	Value struct {
-		GBP float64 `json:"gbp"`
-		BTC float64 `json:"btc"`
+		Base  decimal.Decimal `json:"base"`
+		Quote decimal.Decimal `json:"quote"`
 	}
 
 	TradeType string
 
-	TradeRequest struct {
-		Id            string    `json:"id"`
-		Side          TradeType `json:"side"`
-		ProductId     string    `json:"productId"`
-		Settled       bool      `json:"settled"`
-		CreatedAt     time.Time `json:"createdAt"`
-		Funds         string    `json:"funds"`         // Spent Funds in GBP.
-		FillFees      string    `json:"fillFees"`      // Fees in GBP.
-		FilledSize    string    `json:"filledSize"`    // Value in BTC.
-		ExecutedValue string    `json:"executedValue"` // Value in GBP.
+	GetTradesRequest struct {
+		ProductId string `json:"productId"`
 	}
 
+	// TradeRequest is the TradeExecuted event published by the trader, using the
+	// shared schema so the field names can't drift from what is published.
+	TradeRequest events.TradeExecutedV1
+
+	FillRequest = events.FillV1
+
 	InvalidPropertyError struct {
 		Parameter string
 		Err       string
@@ -74,34 +93,91 @@ func (t *TradeRequest) ToTrade() (Trade, error) {
 		return Trade{}, InvalidPropertyError{Parameter: "productId", Err: "value is empty"}
 	}
 
-	funds, err := strconv.ParseFloat(strings.TrimSpace(t.Funds), 64)
+	funds, err := parseAmount(t.Funds)
 	if err != nil {
 		return Trade{}, InvalidPropertyError{Parameter: "funds", Err: err.Error()}
 	}
-	fees, err := strconv.ParseFloat(strings.TrimSpace(t.FillFees), 64)
+	fees, err := parseAmount(t.FillFees)
 	if err != nil {
 		return Trade{}, InvalidPropertyError{Parameter: "fillFees", Err: err.Error()}
 	}
-	btc, err := strconv.ParseFloat(strings.TrimSpace(t.FilledSize), 64)
+	base, err := parseAmount(t.FilledSize)
 	if err != nil {
 		return Trade{}, InvalidPropertyError{Parameter: "filledSize", Err: err.Error()}
 	}
-	gbp, err := strconv.ParseFloat(strings.TrimSpace(t.ExecutedValue), 64)
+	quote, err := parseAmount(t.ExecutedValue)
 	if err != nil {
 		return Trade{}, InvalidPropertyError{Parameter: "executedValue", Err: err.Error()}
 	}
 
+	var fills []Fill
+	for i, f := range t.Fills {
+		fill, err := toFill(f)
+		if err != nil {
+			return Trade{}, InvalidPropertyError{Parameter: fmt.Sprintf("fills[%d].%s", i, err.Parameter), Err: err.Err}
+		}
+		fills = append(fills, fill)
+	}
+
 	return Trade{
 		Id:         t.Id,
-		TradeType:  t.Side,
+		TradeType:  TradeType(t.Side),
 		ProductId:  t.ProductId,
 		Settled:    t.Settled,
 		CreatedAt:  t.CreatedAt,
 		SpentFunds: funds,
 		Fees:       fees,
 		Value: Value{
-			GBP: gbp,
-			BTC: btc,
+			Base:  base,
+			Quote: quote,
+		},
+		EffectivePrice: t.EffectivePrice,
+		FeeRate:        t.FeeRate,
+		Net: Value{
+			Base:  t.NetBase,
+			Quote: t.NetQuote,
 		},
+		Fills:  fills,
+		DryRun: t.DryRun,
+	}, nil
+}
+
+// parseAmount parses an optional amount of the trade, which is zero if it is
+// empty. Orders placed by size have no funds, and orders which haven't been
+// filled yet have no fill amounts.
+func parseAmount(v string) (decimal.Decimal, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return decimal.Zero, nil
+	}
+
+	return decimal.NewFromString(v)
+}
+
+func toFill(f FillRequest) (Fill, *InvalidPropertyError) {
+	if f.Id == "" {
+		return Fill{}, &InvalidPropertyError{Parameter: "id", Err: "value is empty"}
+	}
+
+	price, err := decimal.NewFromString(strings.TrimSpace(f.Price))
+	if err != nil {
+		return Fill{}, &InvalidPropertyError{Parameter: "price", Err: err.Error()}
+	}
+	size, err := decimal.NewFromString(strings.TrimSpace(f.Size))
+	if err != nil {
+		return Fill{}, &InvalidPropertyError{Parameter: "size", Err: err.Error()}
+	}
+	fee, err := decimal.NewFromString(strings.TrimSpace(f.Fee))
+	if err != nil {
+		return Fill{}, &InvalidPropertyError{Parameter: "fee", Err: err.Error()}
+	}
+
+	return Fill{
+		Id:        f.Id,
+		Price:     price,
+		Size:      size,
+		Fee:       fee,
+		Liquidity: f.Liquidity,
+		CreatedAt: f.CreatedAt,
 	}, nil
 }
@@ -1,10 +1,13 @@
 package model_test
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/cshep4/kripto/services/data-storer/internal/model"
+	"github.com/cshep4/kripto/shared/go/events/eventstest"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -65,7 +68,7 @@ func TestTradeRequest_ToTrade(t *testing.T) {
 		ipErr, ok := err.(model.InvalidPropertyError)
 		assert.True(t, ok)
 		assert.Equal(t, "funds", ipErr.Parameter)
-		assert.Equal(t, "strconv.ParseFloat: parsing \"invalid\": invalid syntax", ipErr.Err)
+		assert.Equal(t, "can't convert invalid to decimal", ipErr.Err)
 	})
 
 	t.Run("return error if fillFees is invalid", func(t *testing.T) {
@@ -83,7 +86,7 @@ func TestTradeRequest_ToTrade(t *testing.T) {
 		ipErr, ok := err.(model.InvalidPropertyError)
 		assert.True(t, ok)
 		assert.Equal(t, "fillFees", ipErr.Parameter)
-		assert.Equal(t, "strconv.ParseFloat: parsing \"invalid\": invalid syntax", ipErr.Err)
+		assert.Equal(t, "can't convert invalid to decimal", ipErr.Err)
 	})
 
 	t.Run("return error if filledSize is invalid", func(t *testing.T) {
@@ -102,7 +105,7 @@ func TestTradeRequest_ToTrade(t *testing.T) {
 		ipErr, ok := err.(model.InvalidPropertyError)
 		assert.True(t, ok)
 		assert.Equal(t, "filledSize", ipErr.Parameter)
-		assert.Equal(t, "strconv.ParseFloat: parsing \"invalid\": invalid syntax", ipErr.Err)
+		assert.Equal(t, "can't convert invalid to decimal", ipErr.Err)
 	})
 
 	t.Run("return error if executedValue is invalid", func(t *testing.T) {
@@ -122,7 +125,7 @@ func TestTradeRequest_ToTrade(t *testing.T) {
 		ipErr, ok := err.(model.InvalidPropertyError)
 		assert.True(t, ok)
 		assert.Equal(t, "executedValue", ipErr.Parameter)
-		assert.Equal(t, "strconv.ParseFloat: parsing \"invalid\": invalid syntax", ipErr.Err)
+		assert.Equal(t, "can't convert invalid to decimal", ipErr.Err)
 	})
 
 	t.Run("returns trade", func(t *testing.T) {
@@ -143,13 +146,265 @@ func TestTradeRequest_ToTrade(t *testing.T) {
 		require.NoError(t, err)
 
 		assert.Equal(t, req.Id, trade.Id)
-		assert.Equal(t, req.Side, trade.TradeType)
+		assert.Equal(t, model.Buy, trade.TradeType)
 		assert.Equal(t, req.ProductId, trade.ProductId)
 		assert.Equal(t, req.Settled, trade.Settled)
 		assert.Equal(t, now, trade.CreatedAt)
-		assert.Equal(t, float64(1), trade.SpentFunds)
-		assert.Equal(t, float64(2), trade.Fees)
-		assert.Equal(t, float64(3), trade.Value.BTC)
-		assert.Equal(t, float64(4), trade.Value.GBP)
+		assert.Equal(t, "1", trade.SpentFunds.String())
+		assert.Equal(t, "2", trade.Fees.String())
+		assert.Equal(t, "3", trade.Value.Base.String())
+		assert.Equal(t, "4", trade.Value.Quote.String())
 	})
+
+	t.Run("returns trade without losing precision", func(t *testing.T) {
+		req := model.TradeRequest{
+			Id:            "id",
+			Side:          "buy",
+			ProductId:     "productId",
+			Funds:         "9.95024875",
+			FillFees:      "0.049751102976",
+			FilledSize:    "0.00125952",
+			ExecutedValue: "9.9502205952",
+		}
+
+		trade, err := req.ToTrade()
+		require.NoError(t, err)
+
+		assert.Equal(t, "9.95024875", trade.SpentFunds.String())
+		assert.Equal(t, "0.049751102976", trade.Fees.String())
+		assert.Equal(t, "0.00125952", trade.Value.Base.String())
+		assert.Equal(t, "9.9502205952", trade.Value.Quote.String())
+	})
+
+	t.Run("returns trade with fee-aware amounts from message", func(t *testing.T) {
+		var req model.TradeRequest
+		err := json.Unmarshal([]byte(`{
+			"id": "id",
+			"side": "buy",
+			"productId": "BTC-GBP",
+			"funds": "80.4",
+			"fillFees": "0.4",
+			"filledSize": "0.002",
+			"executedValue": "80",
+			"effectivePrice": "40200",
+			"feeRate": "0.005",
+			"netQuote": "-80.4",
+			"netBase": "0.002"
+		}`), &req)
+		require.NoError(t, err)
+
+		trade, err := req.ToTrade()
+		require.NoError(t, err)
+
+		assert.Equal(t, "40200", trade.EffectivePrice.String())
+		assert.Equal(t, "0.005", trade.FeeRate.String())
+		assert.Equal(t, "-80.4", trade.Net.Quote.String())
+		assert.Equal(t, "0.002", trade.Net.Base.String())
+	})
+
+	t.Run("returns limit order with no funds", func(t *testing.T) {
+		trade, err := (&model.TradeRequest{
+			Id:            "id",
+			Side:          "buy",
+			Type:          "limit",
+			ProductId:     "BTC-GBP",
+			Settled:       true,
+			Price:         "40000",
+			Size:          "0.01",
+			FillFees:      "2",
+			FilledSize:    "0.01",
+			ExecutedValue: "400",
+		}).ToTrade()
+		require.NoError(t, err)
+
+		assert.True(t, trade.SpentFunds.IsZero())
+		assert.Equal(t, "2", trade.Fees.String())
+		assert.Equal(t, "0.01", trade.Value.Base.String())
+		assert.Equal(t, "400", trade.Value.Quote.String())
+	})
+
+	t.Run("returns size-based market order with no funds", func(t *testing.T) {
+		trade, err := (&model.TradeRequest{
+			Id:            "id",
+			Side:          "sell",
+			Type:          "market",
+			ProductId:     "BTC-GBP",
+			Settled:       true,
+			Size:          "0.01",
+			FillFees:      "2",
+			FilledSize:    "0.01",
+			ExecutedValue: "400",
+		}).ToTrade()
+		require.NoError(t, err)
+
+		assert.Equal(t, model.Sell, trade.TradeType)
+		assert.True(t, trade.SpentFunds.IsZero())
+		assert.Equal(t, "0.01", trade.Value.Base.String())
+	})
+
+	t.Run("returns open order with no fills", func(t *testing.T) {
+		trade, err := (&model.TradeRequest{
+			Id:        "id",
+			Side:      "buy",
+			Type:      "limit",
+			ProductId: "BTC-GBP",
+			Status:    "open",
+			Price:     "30000",
+			Size:      "0.01",
+		}).ToTrade()
+		require.NoError(t, err)
+
+		assert.False(t, trade.Settled)
+		assert.True(t, trade.SpentFunds.IsZero())
+		assert.True(t, trade.Fees.IsZero())
+		assert.True(t, trade.Value.Base.IsZero())
+		assert.True(t, trade.Value.Quote.IsZero())
+		assert.Empty(t, trade.Fills)
+	})
+
+	t.Run("return error if fill is invalid", func(t *testing.T) {
+		trade, err := (&model.TradeRequest{
+			Id:            "id",
+			Side:          "buy",
+			ProductId:     "BTC-GBP",
+			Funds:         "10",
+			FillFees:      "0",
+			FilledSize:    "0.001",
+			ExecutedValue: "10",
+			Fills: []model.FillRequest{
+				{Id: "1", Price: "10000", Size: "0.001", Fee: "invalid"},
+			},
+		}).ToTrade()
+		require.Error(t, err)
+
+		assert.Empty(t, trade)
+
+		ipErr, ok := err.(model.InvalidPropertyError)
+		assert.True(t, ok)
+		assert.Equal(t, "fills[0].fee", ipErr.Parameter)
+	})
+
+	t.Run("returns trade with fills", func(t *testing.T) {
+		createdAt := time.Now()
+
+		trade, err := (&model.TradeRequest{
+			Id:            "id",
+			Side:          "buy",
+			ProductId:     "BTC-GBP",
+			Funds:         "10",
+			FillFees:      "0.015",
+			FilledSize:    "0.001",
+			ExecutedValue: "10",
+			Fills: []model.FillRequest{
+				{Id: "2", Price: "10000.1", Size: "0.0005", Fee: "0.015", Liquidity: "T", CreatedAt: createdAt},
+				{Id: "1", Price: "9999.9", Size: "0.0005", Fee: "0", Liquidity: "M", CreatedAt: createdAt},
+			},
+		}).ToTrade()
+		require.NoError(t, err)
+
+		require.Len(t, trade.Fills, 2)
+		assert.Equal(t, "2", trade.Fills[0].Id)
+		assert.Equal(t, "10000.1", trade.Fills[0].Price.String())
+		assert.Equal(t, "0.0005", trade.Fills[0].Size.String())
+		assert.Equal(t, "0.015", trade.Fills[0].Fee.String())
+		assert.Equal(t, "T", trade.Fills[0].Liquidity)
+		assert.Equal(t, createdAt, trade.Fills[0].CreatedAt)
+		assert.Equal(t, "M", trade.Fills[1].Liquidity)
+	})
+}
+
+// TestTradeRequest_Schema fails if the trade stored from the TradeExecuted event
+// published by the trader loses any of its fields.
+func TestTradeRequest_Schema(t *testing.T) {
+	t.Run("returns trade with every field of example event", func(t *testing.T) {
+		var req model.TradeRequest
+		err := json.Unmarshal([]byte(eventstest.TradeExecutedV1JSON), &req)
+		require.NoError(t, err)
+
+		trade, err := req.ToTrade()
+		require.NoError(t, err)
+
+		example := eventstest.TradeExecutedV1()
+		assert.Equal(t, model.Trade{
+			Id:             example.Id,
+			TradeType:      model.Buy,
+			ProductId:      example.ProductId,
+			Settled:        example.Settled,
+			CreatedAt:      example.CreatedAt,
+			SpentFunds:     decimal.RequireFromString(example.Funds),
+			Fees:           decimal.RequireFromString(example.FillFees),
+			EffectivePrice: example.EffectivePrice,
+			FeeRate:        example.FeeRate,
+			Value: model.Value{
+				Quote: decimal.RequireFromString(example.ExecutedValue),
+				Base:  decimal.RequireFromString(example.FilledSize),
+			},
+			Net: model.Value{
+				Quote: example.NetQuote,
+				Base:  example.NetBase,
+			},
+			Fills: []model.Fill{{
+				Id:        example.Fills[0].Id,
+				Price:     decimal.RequireFromString(example.Fills[0].Price),
+				Size:      decimal.RequireFromString(example.Fills[0].Size),
+				Fee:       decimal.RequireFromString(example.Fills[0].Fee),
+				Liquidity: example.Fills[0].Liquidity,
+				CreatedAt: example.Fills[0].CreatedAt,
+			}},
+			DryRun: example.DryRun,
+		}, trade)
+	})
+
+	t.Run("returns trade for every shape of order published", func(t *testing.T) {
+		for _, e := range eventstest.TradeExecutedV1Examples() {
+			var req model.TradeRequest
+			err := json.Unmarshal([]byte(e.JSON), &req)
+			require.NoError(t, err, e.Name)
+
+			trade, err := req.ToTrade()
+			require.NoError(t, err, e.Name)
+
+			var fills []model.Fill
+			for _, f := range e.Event.Fills {
+				fills = append(fills, model.Fill{
+					Id:        f.Id,
+					Price:     decimal.RequireFromString(f.Price),
+					Size:      decimal.RequireFromString(f.Size),
+					Fee:       decimal.RequireFromString(f.Fee),
+					Liquidity: f.Liquidity,
+					CreatedAt: f.CreatedAt,
+				})
+			}
+
+			assert.Equal(t, model.Trade{
+				Id:             e.Event.Id,
+				TradeType:      model.TradeType(e.Event.Side),
+				ProductId:      e.Event.ProductId,
+				Settled:        e.Event.Settled,
+				CreatedAt:      e.Event.CreatedAt,
+				SpentFunds:     amount(e.Event.Funds),
+				Fees:           amount(e.Event.FillFees),
+				EffectivePrice: e.Event.EffectivePrice,
+				FeeRate:        e.Event.FeeRate,
+				Value: model.Value{
+					Quote: amount(e.Event.ExecutedValue),
+					Base:  amount(e.Event.FilledSize),
+				},
+				Net: model.Value{
+					Quote: e.Event.NetQuote,
+					Base:  e.Event.NetBase,
+				},
+				Fills:  fills,
+				DryRun: e.Event.DryRun,
+			}, trade, e.Name)
+		}
+	})
+}
+
+// amount returns the optional amount of the event, which is zero if empty.
+func amount(v string) decimal.Decimal {
+	if v == "" {
+		return decimal.Zero
+	}
+	return decimal.RequireFromString(v)
 }
@@ -17,6 +17,7 @@ type (
 	TradeStore interface {
 		Store(ctx context.Context, trade model.Trade) error
 		GetPreviousWeeks(ctx context.Context) ([]model.Trade, error)
+		GetTrades(ctx context.Context, productId string) ([]model.Trade, error)
 	}
 
 	service struct {
@@ -74,3 +75,12 @@ func (s *service) StoreTrade(ctx context.Context, trade model.Trade) error {
 
 	return nil
 }
+
+func (s *service) GetTrades(ctx context.Context, productId string) ([]model.Trade, error) {
+	trades, err := s.tradeStore.GetTrades(ctx, productId)
+	if err != nil {
+		return nil, fmt.Errorf("get_trades: %w", err)
+	}
+
+	return trades, nil
+}
@@ -156,3 +156,52 @@ func TestService_StoreTrade(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestService_GetTrades(t *testing.T) {
+	t.Run("returns error if error getting trades", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			rateStore  = rate_mocks.NewMockRateStore(ctrl)
+			tradeStore = trade_mocks.NewMockTradeStore(ctrl)
+
+			ctx     = context.Background()
+			testErr = errors.New("error")
+		)
+
+		s, err := service.New(rateStore, tradeStore)
+		require.NoError(t, err)
+
+		tradeStore.EXPECT().GetTrades(ctx, "BTC-GBP").Return(nil, testErr)
+
+		trades, err := s.GetTrades(ctx, "BTC-GBP")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Nil(t, trades)
+	})
+
+	t.Run("returns trades", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			rateStore  = rate_mocks.NewMockRateStore(ctrl)
+			tradeStore = trade_mocks.NewMockTradeStore(ctrl)
+
+			ctx    = context.Background()
+			trades = []model.Trade{{Id: "id", ProductId: "BTC-GBP"}}
+		)
+
+		s, err := service.New(rateStore, tradeStore)
+		require.NoError(t, err)
+
+		tradeStore.EXPECT().GetTrades(ctx, "BTC-GBP").Return(trades, nil)
+
+		res, err := s.GetTrades(ctx, "BTC-GBP")
+		require.NoError(t, err)
+
+		assert.Equal(t, trades, res)
+	})
+}
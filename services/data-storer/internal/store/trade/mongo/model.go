@@ -2,26 +2,58 @@ package mongo
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
 	"github.com/cshep4/kripto/services/data-storer/internal/model"
 )
 
 type (
 	trade struct {
-		Id         string    `bson:"_id"`
-		TradeType  string    `bson:"tradeType"`
-		ProductId  string    `bson:"productId"`
-		Settled    bool      `bson:"settled"`
-		CreatedAt  time.Time `bson:"createdAt,string,omitempty"`
-		SpentFunds float64   `bson:"funds,omitempty"`
-		Fees       float64   `bson:"fillFees,omitempty"`
-		Value      value     `bson:"value"`
+		Id             string    `bson:"_id"`
+		TradeType      string    `bson:"tradeType"`
+		ProductId      string    `bson:"productId"`
+		Settled        bool      `bson:"settled"`
+		CreatedAt      time.Time `bson:"createdAt,string,omitempty"`
+		SpentFunds     amount    `bson:"funds,omitempty"`
+		Fees           amount    `bson:"fillFees,omitempty"`
+		Value          value     `bson:"value"`
+		EffectivePrice amount    `bson:"effectivePrice"`
+		FeeRate        amount    `bson:"feeRate"`
+		Net            value     `bson:"net"`
 	}
 
 	value struct {
-		GBP float64 `bson:"gbp"`
-		BTC float64 `bson:"btc"`
+		Base  amount `bson:"base"`
+		Quote amount `bson:"quote"`
+
+		// BTC and GBP hold the amounts of trades stored before they were named
+		// base and quote, so they are only read.
+		BTC *amount `bson:"btc,omitempty"`
+		GBP *amount `bson:"gbp,omitempty"`
+	}
+
+	// fill is stored in the fills collection, linked to the trade by TradeId.
+	fill struct {
+		TradeId   string    `bson:"tradeId"`
+		FillId    string    `bson:"fillId"`
+		Price     amount    `bson:"price"`
+		Size      amount    `bson:"size"`
+		Fee       amount    `bson:"fee"`
+		Liquidity string    `bson:"liquidity"`
+		CreatedAt time.Time `bson:"createdAt"`
+	}
+
+	// amount is an exact amount, stored as a Decimal128. Trades stored before
+	// amounts were exact hold them as doubles, so doubles and integers are read
+	// as well. An amount which isn't stored is zero.
+	amount struct {
+		decimal.Decimal
 	}
 )
 
@@ -36,27 +68,115 @@ func fromTrade(t model.Trade) (trade, error) {
 		ProductId:  t.ProductId,
 		Settled:    t.Settled,
 		CreatedAt:  t.CreatedAt,
-		SpentFunds: t.SpentFunds,
-		Fees:       t.Fees,
+		SpentFunds: amount{t.SpentFunds},
+		Fees:       amount{t.Fees},
 		Value: value{
-			GBP: t.Value.GBP,
-			BTC: t.Value.BTC,
+			Base:  amount{t.Value.Base},
+			Quote: amount{t.Value.Quote},
+		},
+		EffectivePrice: amount{t.EffectivePrice},
+		FeeRate:        amount{t.FeeRate},
+		Net: value{
+			Base:  amount{t.Net.Base},
+			Quote: amount{t.Net.Quote},
 		},
 	}, nil
 }
 
 func toTrade(t trade) model.Trade {
+	base, quote := t.Value.toValue()
+	netBase, netQuote := t.Net.toValue()
+
 	return model.Trade{
 		Id:         t.Id,
 		TradeType:  model.TradeType(t.TradeType),
 		ProductId:  t.ProductId,
 		Settled:    t.Settled,
 		CreatedAt:  t.CreatedAt,
-		SpentFunds: t.SpentFunds,
-		Fees:       t.Fees,
+		SpentFunds: t.SpentFunds.toDecimal(),
+		Fees:       t.Fees.toDecimal(),
 		Value: model.Value{
-			GBP: t.Value.GBP,
-			BTC: t.Value.BTC,
+			Base:  base,
+			Quote: quote,
 		},
+		EffectivePrice: t.EffectivePrice.toDecimal(),
+		FeeRate:        t.FeeRate.toDecimal(),
+		Net: model.Value{
+			Base:  netBase,
+			Quote: netQuote,
+		},
+	}
+}
+
+// toValue returns the base and quote amounts, reading them from the btc and gbp
+// fields if the trade was stored before they were renamed.
+func (v value) toValue() (decimal.Decimal, decimal.Decimal) {
+	base, quote := v.Base, v.Quote
+	if v.BTC != nil {
+		base = *v.BTC
+	}
+	if v.GBP != nil {
+		quote = *v.GBP
+	}
+
+	return base.toDecimal(), quote.toDecimal()
+}
+
+func fromFill(tradeId string, f model.Fill) (fill, error) {
+	if f.Id == "" {
+		return fill{}, errors.New("invalid_fill_id")
+	}
+
+	return fill{
+		TradeId:   tradeId,
+		FillId:    f.Id,
+		Price:     amount{f.Price},
+		Size:      amount{f.Size},
+		Fee:       amount{f.Fee},
+		Liquidity: f.Liquidity,
+		CreatedAt: f.CreatedAt,
+	}, nil
+}
+
+func (a amount) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	d, err := primitive.ParseDecimal128(a.toDecimal().String())
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid_amount (%s): %w", a.String(), err)
+	}
+
+	return bson.MarshalValue(d)
+}
+
+func (a *amount) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	v := bson.RawValue{Type: t, Value: data}
+
+	switch t {
+	case bsontype.Decimal128:
+		d, err := decimal.NewFromString(v.Decimal128().String())
+		if err != nil {
+			return fmt.Errorf("invalid_decimal128 (%s): %w", v.Decimal128(), err)
+		}
+		a.Decimal = d
+	case bsontype.Double:
+		a.Decimal = decimal.NewFromFloat(v.Double())
+	case bsontype.Int32:
+		a.Decimal = decimal.New(int64(v.Int32()), 0)
+	case bsontype.Int64:
+		a.Decimal = decimal.New(v.Int64(), 0)
+	case bsontype.Null, bsontype.Undefined:
+		a.Decimal = decimal.Zero
+	default:
+		return fmt.Errorf("unsupported_amount_type: %s", t)
+	}
+
+	return nil
+}
+
+// toDecimal returns the amount, zero if it isn't set.
+func (a amount) toDecimal() decimal.Decimal {
+	if a.Decimal == (decimal.Decimal{}) {
+		return decimal.Zero
 	}
+
+	return a.Decimal
 }
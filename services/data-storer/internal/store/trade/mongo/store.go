@@ -14,14 +14,18 @@ import (
 )
 
 const (
-	db         = "trade"
-	collection = "trade"
+	db               = "trade"
+	collection       = "trade"
+	fillsCollection  = "fills"
+	dryRunCollection = "dry-run"
 )
 
 type (
 	store struct {
 		client     *mongo.Client
 		collection *mongo.Collection
+		fills      *mongo.Collection
+		dryRuns    *mongo.Collection
 	}
 
 	// InvalidParameterError is returned when a required parameter passed to New is invalid.
@@ -42,6 +46,8 @@ func New(ctx context.Context, client *mongo.Client) (*store, error) {
 	s := &store{
 		client:     client,
 		collection: client.Database(db).Collection(collection),
+		fills:      client.Database(db).Collection(fillsCollection),
+		dryRuns:    client.Database(db).Collection(dryRunCollection),
 	}
 
 	if err := s.ping(ctx); err != nil {
@@ -73,23 +79,134 @@ func (s *store) ensureIndexes(ctx context.Context) error {
 		return err
 	}
 
+	_, err = s.fills.Indexes().
+		CreateOne(
+			ctx,
+			mongo.IndexModel{
+				Keys: bsonx.Doc{
+					{Key: "tradeId", Value: bsonx.Int64(1)},
+					{Key: "fillId", Value: bsonx.Int64(1)},
+				},
+				Options: options.Index().
+					SetName("tradeFillIdx").
+					SetUnique(true).
+					SetBackground(true),
+			},
+		)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// Store upserts the trade and its fills by ID, so a trade delivered more than
+// once is only stored once. Dry-run trades are stored in their own collection
+// without their fills, so they are never read back as real trades.
 func (s *store) Store(ctx context.Context, trade model.Trade) error {
 	t, err := fromTrade(trade)
 	if err != nil {
 		return fmt.Errorf("map_document: %w", err)
 	}
 
-	_, err = s.collection.InsertOne(ctx, t)
+	if trade.DryRun {
+		if err := upsert(ctx, s.dryRuns, t); err != nil {
+			return fmt.Errorf("upsert_dry_run: %w", err)
+		}
+		return nil
+	}
+
+	if err := s.storeFills(ctx, trade); err != nil {
+		return err
+	}
+
+	if err := upsert(ctx, s.collection, t); err != nil {
+		return fmt.Errorf("upsert: %w", err)
+	}
+
+	return nil
+}
+
+func upsert(ctx context.Context, collection *mongo.Collection, t trade) error {
+	_, err := collection.ReplaceOne(
+		ctx,
+		bson.D{
+			{Key: "_id", Value: t.Id},
+		},
+		t,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// storeFills upserts the fills of the trade, so they are not duplicated if the
+// trade is stored again after failing to insert.
+func (s *store) storeFills(ctx context.Context, trade model.Trade) error {
+	if len(trade.Fills) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, len(trade.Fills))
+	for i, f := range trade.Fills {
+		doc, err := fromFill(trade.Id, f)
+		if err != nil {
+			return fmt.Errorf("map_fill_document: %w", err)
+		}
+
+		models[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.D{
+				{Key: "tradeId", Value: doc.TradeId},
+				{Key: "fillId", Value: doc.FillId},
+			}).
+			SetReplacement(doc).
+			SetUpsert(true)
+	}
+
+	_, err := s.fills.BulkWrite(ctx, models)
 	if err != nil {
-		return fmt.Errorf("insert_one: %w", err)
+		return fmt.Errorf("bulk_write_fills: %w", err)
 	}
 
 	return nil
 }
 
+// GetTrades returns all trades of the product, oldest first.
+func (s *store) GetTrades(ctx context.Context, productId string) ([]model.Trade, error) {
+	cur, err := s.collection.Find(
+		ctx,
+		bson.D{
+			{Key: "productId", Value: productId},
+		},
+		&options.FindOptions{
+			Sort: bson.D{
+				bson.E{Key: "createdAt", Value: 1},
+			},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("find: %w", err)
+	}
+
+	var trades []model.Trade
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var t trade
+		err := cur.Decode(&t)
+		if err != nil {
+			return nil, fmt.Errorf("decode: %w", err)
+		}
+
+		trades = append(trades, toTrade(t))
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("cursor_err: %w", err)
+	}
+
+	return trades, nil
+}
+
 func (s *store) GetPreviousWeeks(ctx context.Context) ([]model.Trade, error) {
 	cur, err := s.collection.Find(
 		ctx,
@@ -5,9 +5,11 @@ package mongo_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/cshep4/kripto/services/data-storer/internal/model"
 	store "github.com/cshep4/kripto/services/data-storer/internal/store/trade/mongo"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
@@ -161,6 +163,277 @@ func TestStore_Store(t *testing.T) {
 
 		assert.Equal(t, tradeId, res["_id"])
 	})
+
+	t.Run("stores trade once if delivered again", func(t *testing.T) {
+		ctx := context.Background()
+
+		client := newClient(t, ctx)
+		store, err := store.New(ctx, client)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			err := client.
+				Database("trade").
+				Drop(ctx)
+			require.NoError(t, err)
+
+			err = store.Close(ctx)
+			require.NoError(t, err)
+		})
+
+		const tradeId = "🤝"
+		trade := model.Trade{
+			Id:        tradeId,
+			CreatedAt: time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC),
+			Fills: []model.Fill{
+				{Id: "1", Liquidity: "M"},
+			},
+		}
+
+		err = store.Store(ctx, trade)
+		require.NoError(t, err)
+
+		err = store.Store(ctx, trade)
+		require.NoError(t, err)
+
+		count, err := client.
+			Database("trade").
+			Collection("trade").
+			CountDocuments(ctx, bson.M{"_id": tradeId})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		count, err = client.
+			Database("trade").
+			Collection("fills").
+			CountDocuments(ctx, bson.M{"tradeId": tradeId})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("stores fills linked to trade", func(t *testing.T) {
+		ctx := context.Background()
+
+		client := newClient(t, ctx)
+		store, err := store.New(ctx, client)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			err := client.
+				Database("trade").
+				Drop(ctx)
+			require.NoError(t, err)
+
+			err = store.Close(ctx)
+			require.NoError(t, err)
+		})
+
+		const tradeId = "🤝"
+		trade := model.Trade{
+			Id: tradeId,
+			Fills: []model.Fill{
+				{Id: "1", Liquidity: "M"},
+				{Id: "2", Liquidity: "T"},
+			},
+		}
+
+		err = store.Store(ctx, trade)
+		require.NoError(t, err)
+
+		count, err := client.
+			Database("trade").
+			Collection("fills").
+			CountDocuments(
+				ctx,
+				bson.M{
+					"tradeId": tradeId,
+				},
+			)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(2), count)
+	})
+	t.Run("stores dry-run trade in dry-run collection", func(t *testing.T) {
+		ctx := context.Background()
+
+		client := newClient(t, ctx)
+		store, err := store.New(ctx, client)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			err := client.
+				Database("trade").
+				Drop(ctx)
+			require.NoError(t, err)
+
+			err = store.Close(ctx)
+			require.NoError(t, err)
+		})
+
+		const tradeId = "dry-run-🤝"
+		trade := model.Trade{
+			Id:     tradeId,
+			DryRun: true,
+			Fills: []model.Fill{
+				{Id: "1", Liquidity: "T"},
+			},
+		}
+
+		err = store.Store(ctx, trade)
+		require.NoError(t, err)
+
+		count, err := client.
+			Database("trade").
+			Collection("dry-run").
+			CountDocuments(ctx, bson.M{"_id": tradeId})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		count, err = client.
+			Database("trade").
+			Collection("trade").
+			CountDocuments(ctx, bson.M{"_id": tradeId})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+
+		count, err = client.
+			Database("trade").
+			Collection("fills").
+			CountDocuments(ctx, bson.M{"tradeId": tradeId})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+}
+
+func TestStore_GetTrades(t *testing.T) {
+	t.Run("returns trades of product oldest first", func(t *testing.T) {
+		ctx := context.Background()
+
+		client := newClient(t, ctx)
+		store, err := store.New(ctx, client)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			err := client.
+				Database("trade").
+				Drop(ctx)
+			require.NoError(t, err)
+
+			err = store.Close(ctx)
+			require.NoError(t, err)
+		})
+
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		for _, trade := range []model.Trade{
+			{Id: "2", ProductId: "BTC-GBP", CreatedAt: now},
+			{Id: "1", ProductId: "BTC-GBP", CreatedAt: now.Add(-time.Hour)},
+			{Id: "3", ProductId: "ETH-GBP", CreatedAt: now.Add(time.Hour)},
+		} {
+			err = store.Store(ctx, trade)
+			require.NoError(t, err)
+		}
+
+		trades, err := store.GetTrades(ctx, "BTC-GBP")
+		require.NoError(t, err)
+
+		require.Len(t, trades, 2)
+		assert.Equal(t, "1", trades[0].Id)
+		assert.Equal(t, "2", trades[1].Id)
+	})
+
+	t.Run("returns amounts of trades stored before they were named base and quote", func(t *testing.T) {
+		ctx := context.Background()
+
+		client := newClient(t, ctx)
+		store, err := store.New(ctx, client)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			err := client.
+				Database("trade").
+				Drop(ctx)
+			require.NoError(t, err)
+
+			err = store.Close(ctx)
+			require.NoError(t, err)
+		})
+
+		_, err = client.
+			Database("trade").
+			Collection("trade").
+			InsertOne(ctx, bson.M{
+				"_id":       "legacy",
+				"productId": "BTC-GBP",
+				"createdAt": time.Now().UTC(),
+				"funds":     402.0,
+				"fillFees":  2.0,
+				"value":     bson.M{"btc": 0.01, "gbp": 400.0},
+			})
+		require.NoError(t, err)
+
+		err = store.Store(ctx, model.Trade{
+			Id:        "current",
+			ProductId: "BTC-GBP",
+			CreatedAt: time.Now().UTC().Add(time.Hour),
+			Value:     model.Value{Base: decimal.RequireFromString("0.02"), Quote: decimal.RequireFromString("800")},
+			Net:       model.Value{Base: decimal.RequireFromString("-0.02"), Quote: decimal.RequireFromString("796")},
+		})
+		require.NoError(t, err)
+
+		trades, err := store.GetTrades(ctx, "BTC-GBP")
+		require.NoError(t, err)
+
+		require.Len(t, trades, 2)
+		assert.Equal(t, "402", trades[0].SpentFunds.String())
+		assert.Equal(t, "2", trades[0].Fees.String())
+		assert.Equal(t, "0.01", trades[0].Value.Base.String())
+		assert.Equal(t, "400", trades[0].Value.Quote.String())
+		assert.Equal(t, "0.02", trades[1].Value.Base.String())
+		assert.Equal(t, "800", trades[1].Value.Quote.String())
+		assert.Equal(t, "-0.02", trades[1].Net.Base.String())
+		assert.Equal(t, "796", trades[1].Net.Quote.String())
+	})
+
+	t.Run("returns amounts of trades stored as doubles", func(t *testing.T) {
+		ctx := context.Background()
+
+		client := newClient(t, ctx)
+		store, err := store.New(ctx, client)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			err := client.
+				Database("trade").
+				Drop(ctx)
+			require.NoError(t, err)
+
+			err = store.Close(ctx)
+			require.NoError(t, err)
+		})
+
+		_, err = client.
+			Database("trade").
+			Collection("trade").
+			InsertOne(ctx, bson.M{
+				"_id":       "double",
+				"productId": "BTC-GBP",
+				"createdAt": time.Now().UTC(),
+				"funds":     402.5,
+				"fillFees":  2.5,
+				"value":     bson.M{"base": 0.01, "quote": 400.0},
+			})
+		require.NoError(t, err)
+
+		trades, err := store.GetTrades(ctx, "BTC-GBP")
+		require.NoError(t, err)
+
+		require.Len(t, trades, 1)
+		assert.Equal(t, "402.5", trades[0].SpentFunds.String())
+		assert.Equal(t, "2.5", trades[0].Fees.String())
+		assert.Equal(t, "0.01", trades[0].Value.Base.String())
+		assert.Equal(t, "400", trades[0].Value.Quote.String())
+		assert.Equal(t, "0", trades[0].Net.Base.String())
+	})
 }
 
 func newClient(t *testing.T, ctx context.Context) *mongo.Client {
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Netflix/go-env"
+	awsconfig "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awslambda "github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/cshep4/kripto/services/dca/internal/handler/aws"
+	"github.com/cshep4/kripto/services/dca/internal/service"
+	plan "github.com/cshep4/kripto/services/dca/internal/store/plan/mongo"
+	"github.com/cshep4/kripto/services/dca/internal/trade"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/cshep4/kripto/shared/go/lambda"
+	"github.com/cshep4/kripto/shared/go/log"
+	"github.com/cshep4/kripto/shared/go/mongodb"
+)
+
+const (
+	logLevel     = "info"
+	serviceName  = "dca"
+	functionName = "save-plan"
+)
+
+type config struct {
+	Region            string `env:"REGION"`
+	TradeFunctionName string `env:"TRADE_FUNCTION_NAME"`
+}
+
+var (
+	cfg = lambda.FunctionConfig{
+		LogLevel:     logLevel,
+		ServiceName:  serviceName,
+		FunctionName: functionName,
+		Setup:        setup,
+		Initialised:  func() bool { return handler.Service != nil },
+	}
+
+	handler aws.Handler
+
+	runner = lambda.New(
+		handler.SavePlan,
+		lambda.WithPreExecute(log.Middleware(logLevel, serviceName, functionName)),
+		lambda.WithErrorResponse(apperror.Wrap),
+	)
+)
+
+func main() {
+	runner.Start(cfg)
+}
+
+func setup(ctx context.Context) error {
+	var c config
+	if _, err := env.UnmarshalFromEnviron(&c); err != nil {
+		return fmt.Errorf("unmarshal_environment_variables: %w", err)
+	}
+	if c.TradeFunctionName == "" {
+		return errors.New("missing_environment_variable: TRADE_FUNCTION_NAME")
+	}
+
+	sess, err := session.NewSession(&awsconfig.Config{
+		Region: &c.Region,
+	})
+	if err != nil {
+		return fmt.Errorf("new_session: %w", err)
+	}
+
+	trader, err := trade.New(awslambda.New(sess), c.TradeFunctionName)
+	if err != nil {
+		return fmt.Errorf("initialise_trader: %w", err)
+	}
+
+	mongoClient, err := mongodb.New(ctx)
+	if err != nil {
+		return fmt.Errorf("initialise_mongo_client: %w", err)
+	}
+
+	store, err := plan.New(ctx, mongoClient)
+	if err != nil {
+		return fmt.Errorf("initialise_plan_store: %w", err)
+	}
+
+	handler.Service, err = service.New(store, trader)
+	if err != nil {
+		return fmt.Errorf("initialise_service: %w", err)
+	}
+
+	return nil
+}
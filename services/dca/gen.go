@@ -0,0 +1,6 @@
+package dca
+
+//go:generate mockgen -destination=internal/mocks/service/servicer.gen.go -package=service_mocks github.com/cshep4/kripto/services/dca/internal/handler/aws Servicer
+//go:generate mockgen -destination=internal/mocks/plan/store.gen.go -package=plan_mocks github.com/cshep4/kripto/services/dca/internal/service PlanStore
+//go:generate mockgen -destination=internal/mocks/trade/trader.gen.go -package=trade_mocks github.com/cshep4/kripto/services/dca/internal/service Trader
+//go:generate mockgen -destination=internal/mocks/trade/invoker.gen.go -package=trade_mocks github.com/cshep4/kripto/services/dca/internal/trade Invoker
@@ -0,0 +1,140 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cshep4/kripto/services/dca/internal/model"
+	"github.com/cshep4/kripto/services/dca/internal/schedule"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/cshep4/kripto/shared/go/log"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	Servicer interface {
+		SavePlan(ctx context.Context, plan model.Plan) (model.Plan, error)
+		ListPlans(ctx context.Context) ([]model.Plan, error)
+		RunPlans(ctx context.Context) (int, error)
+	}
+
+	Handler struct {
+		Service Servicer
+	}
+
+	// BadRequestError is returned when a request parameter is invalid.
+	BadRequestError struct {
+		Parameter string
+		Err       string
+	}
+
+	// SavePlanRequest creates a plan, or updates the plan with the ID if it is set.
+	// Start defaults to now, End and MaxTotal are optional.
+	SavePlanRequest struct {
+		Id        string    `json:"id"`
+		ProductId string    `json:"productId"`
+		Amount    string    `json:"amount"`
+		Schedule  string    `json:"schedule"`
+		Start     time.Time `json:"start"`
+		End       time.Time `json:"end"`
+		MaxTotal  string    `json:"maxTotal"`
+	}
+)
+
+func (i BadRequestError) Error() string {
+	return fmt.Sprintf("bad request - param: %s, error: %s", i.Parameter, i.Err)
+}
+
+func (BadRequestError) ErrorCode() apperror.Code {
+	return apperror.Validation
+}
+
+func (h *Handler) SavePlan(ctx context.Context, req SavePlanRequest) (*model.Plan, error) {
+	plan, err := req.toPlan()
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := h.Service.SavePlan(ctx, plan)
+	if err != nil {
+		log.Error(ctx, "error_saving_plan",
+			log.ErrorParam(err),
+			log.SafeParam("planId", plan.Id),
+			log.SafeParam("productId", plan.ProductId),
+		)
+		return nil, fmt.Errorf("save_plan: %w", err)
+	}
+
+	return &saved, nil
+}
+
+func (r SavePlanRequest) toPlan() (model.Plan, error) {
+	plan := model.Plan{
+		Id:        strings.TrimSpace(r.Id),
+		ProductId: strings.ToUpper(strings.TrimSpace(r.ProductId)),
+		Schedule:  strings.TrimSpace(r.Schedule),
+		Start:     r.Start.UTC(),
+		End:       r.End.UTC(),
+	}
+
+	if parts := strings.Split(plan.ProductId, "-"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return model.Plan{}, BadRequestError{Parameter: "productId", Err: "invalid value - should be in the format BASE-QUOTE"}
+	}
+
+	switch amount, err := decimal.NewFromString(r.Amount); {
+	case r.Amount == "":
+		return model.Plan{}, BadRequestError{Parameter: "amount", Err: "empty"}
+	case err != nil:
+		return model.Plan{}, BadRequestError{Parameter: "amount", Err: "invalid value - should be numeric"}
+	case !amount.IsPositive():
+		return model.Plan{}, BadRequestError{Parameter: "amount", Err: "invalid value - should be positive"}
+	default:
+		plan.Amount = amount
+	}
+
+	if r.MaxTotal != "" {
+		maxTotal, err := decimal.NewFromString(r.MaxTotal)
+		if err != nil || maxTotal.IsNegative() {
+			return model.Plan{}, BadRequestError{Parameter: "maxTotal", Err: "invalid value - should be a positive number"}
+		}
+		plan.MaxTotal = maxTotal
+	}
+
+	if plan.Schedule == "" {
+		return model.Plan{}, BadRequestError{Parameter: "schedule", Err: "empty"}
+	}
+	if _, err := schedule.Parse(plan.Schedule); err != nil {
+		return model.Plan{}, BadRequestError{Parameter: "schedule", Err: err.Error()}
+	}
+
+	if !plan.End.IsZero() && !plan.End.After(plan.Start) {
+		return model.Plan{}, BadRequestError{Parameter: "end", Err: "invalid value - should be after start"}
+	}
+
+	return plan, nil
+}
+
+func (h *Handler) ListPlans(ctx context.Context) ([]model.Plan, error) {
+	plans, err := h.Service.ListPlans(ctx)
+	if err != nil {
+		log.Error(ctx, "error_listing_plans", log.ErrorParam(err))
+		return nil, fmt.Errorf("list_plans: %w", err)
+	}
+
+	return plans, nil
+}
+
+func (h *Handler) RunPlans(ctx context.Context) error {
+	bought, err := h.Service.RunPlans(ctx)
+	if bought > 0 {
+		log.Info(ctx, "plans_run", log.SafeParam("bought", bought))
+	}
+	if err != nil {
+		log.Error(ctx, "error_running_plans", log.ErrorParam(err))
+		return fmt.Errorf("run_plans: %w", err)
+	}
+
+	return nil
+}
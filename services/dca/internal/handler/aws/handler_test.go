@@ -0,0 +1,241 @@
+package aws_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/dca/internal/handler/aws"
+	"github.com/cshep4/kripto/services/dca/internal/mocks/service"
+	"github.com/cshep4/kripto/services/dca/internal/model"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_SavePlan(t *testing.T) {
+	valid := aws.SavePlanRequest{
+		ProductId: "BTC-GBP",
+		Amount:    "25",
+		Schedule:  "0 9 * * MON",
+	}
+
+	for _, tc := range []struct {
+		name      string
+		update    func(r *aws.SavePlanRequest)
+		parameter string
+		err       string
+	}{
+		{
+			name:      "returns error if productId is invalid",
+			update:    func(r *aws.SavePlanRequest) { r.ProductId = "BTC" },
+			parameter: "productId",
+			err:       "invalid value - should be in the format BASE-QUOTE",
+		},
+		{
+			name:      "returns error if amount is empty",
+			update:    func(r *aws.SavePlanRequest) { r.Amount = "" },
+			parameter: "amount",
+			err:       "empty",
+		},
+		{
+			name:      "returns error if amount is invalid",
+			update:    func(r *aws.SavePlanRequest) { r.Amount = "invalid" },
+			parameter: "amount",
+			err:       "invalid value - should be numeric",
+		},
+		{
+			name:      "returns error if amount is not positive",
+			update:    func(r *aws.SavePlanRequest) { r.Amount = "0" },
+			parameter: "amount",
+			err:       "invalid value - should be positive",
+		},
+		{
+			name:      "returns error if maxTotal is negative",
+			update:    func(r *aws.SavePlanRequest) { r.MaxTotal = "-1" },
+			parameter: "maxTotal",
+			err:       "invalid value - should be a positive number",
+		},
+		{
+			name:      "returns error if schedule is empty",
+			update:    func(r *aws.SavePlanRequest) { r.Schedule = "" },
+			parameter: "schedule",
+			err:       "empty",
+		},
+		{
+			name: "returns error if end is before start",
+			update: func(r *aws.SavePlanRequest) {
+				r.Start = time.Date(2020, 6, 2, 0, 0, 0, 0, time.UTC)
+				r.End = time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+			},
+			parameter: "end",
+			err:       "invalid value - should be after start",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := valid
+			tc.update(&req)
+
+			handler := aws.Handler{}
+
+			plan, err := handler.SavePlan(context.Background(), req)
+			require.Error(t, err)
+
+			assert.Nil(t, plan)
+
+			brErr, ok := err.(aws.BadRequestError)
+			assert.True(t, ok)
+			assert.Equal(t, tc.parameter, brErr.Parameter)
+			assert.Equal(t, tc.err, brErr.Err)
+			assert.Equal(t, apperror.Validation, apperror.CodeOf(err))
+		})
+	}
+
+	t.Run("returns error if schedule is invalid", func(t *testing.T) {
+		req := valid
+		req.Schedule = "every monday"
+
+		handler := aws.Handler{}
+
+		_, err := handler.SavePlan(context.Background(), req)
+		require.Error(t, err)
+
+		brErr, ok := err.(aws.BadRequestError)
+		assert.True(t, ok)
+		assert.Equal(t, "schedule", brErr.Parameter)
+	})
+
+	t.Run("returns error if error saving plan", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := errors.New("error")
+
+		servicer := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: servicer}
+
+		servicer.EXPECT().SavePlan(ctx, gomock.Any()).Return(model.Plan{}, testErr)
+
+		plan, err := handler.SavePlan(ctx, valid)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Nil(t, plan)
+	})
+
+	t.Run("returns saved plan", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		start := time.Date(2020, 6, 1, 9, 0, 0, 0, time.FixedZone("UTC+1", 60*60))
+		req := aws.SavePlanRequest{
+			Id:        "plan",
+			ProductId: " btc-gbp ",
+			Amount:    "25",
+			Schedule:  "@weekly",
+			Start:     start,
+			MaxTotal:  "100",
+		}
+		expected := model.Plan{
+			Id:        "plan",
+			ProductId: "BTC-GBP",
+			Amount:    decimal.New(25, 0),
+			Schedule:  "@weekly",
+			Start:     start.UTC(),
+			End:       time.Time{}.UTC(),
+			MaxTotal:  decimal.New(100, 0),
+		}
+		saved := expected
+		saved.CreatedAt = time.Now()
+
+		servicer := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: servicer}
+
+		servicer.EXPECT().SavePlan(ctx, expected).Return(saved, nil)
+
+		plan, err := handler.SavePlan(ctx, req)
+		require.NoError(t, err)
+
+		assert.Equal(t, &saved, plan)
+	})
+}
+
+func TestHandler_ListPlans(t *testing.T) {
+	t.Run("returns error if error listing plans", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := errors.New("error")
+
+		servicer := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: servicer}
+
+		servicer.EXPECT().ListPlans(ctx).Return(nil, testErr)
+
+		plans, err := handler.ListPlans(ctx)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Nil(t, plans)
+	})
+
+	t.Run("returns plans", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		plans := []model.Plan{{Id: "plan"}}
+
+		servicer := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: servicer}
+
+		servicer.EXPECT().ListPlans(ctx).Return(plans, nil)
+
+		res, err := handler.ListPlans(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, plans, res)
+	})
+}
+
+func TestHandler_RunPlans(t *testing.T) {
+	t.Run("returns error if error running plans", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := apperror.Parse("timeout: trade: context deadline exceeded")
+
+		servicer := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: servicer}
+
+		servicer.EXPECT().RunPlans(ctx).Return(1, testErr)
+
+		err := handler.RunPlans(ctx)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Equal(t, apperror.Timeout, apperror.CodeOf(err))
+	})
+
+	t.Run("runs plans", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		servicer := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: servicer}
+
+		servicer.EXPECT().RunPlans(ctx).Return(2, nil)
+
+		err := handler.RunPlans(ctx)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type (
+	// Plan is a recurring buy of Amount, in the quote currency of the product, on
+	// each run of its cron Schedule between Start and End. Plans with a MaxTotal
+	// stop once that much has been spent. End and MaxTotal are optional.
+	Plan struct {
+		Id        string          `json:"id"`
+		ProductId string          `json:"productId"`
+		Amount    decimal.Decimal `json:"amount"`
+		Schedule  string          `json:"schedule"`
+		Start     time.Time       `json:"start"`
+		End       time.Time       `json:"end"`
+		MaxTotal  decimal.Decimal `json:"maxTotal"`
+
+		// Updated each time the plan is run.
+		Spent       decimal.Decimal `json:"spent"`
+		LastPeriod  time.Time       `json:"lastPeriod"` // Scheduled time of the last period run.
+		LastTradeId string          `json:"lastTradeId,omitempty"`
+		CreatedAt   time.Time       `json:"createdAt"`
+	}
+
+	// Order is a buy placed by the trade function for a plan period. IdempotencyKey
+	// is the same for every attempt at the period.
+	Order struct {
+		IdempotencyKey string
+		ProductId      string
+		Amount         decimal.Decimal
+	}
+
+	// Trade is the trade placed for an order.
+	Trade struct {
+		Id    string
+		Spent decimal.Decimal // Quote currency spent including fees.
+	}
+)
+
+// Remaining returns how much more the plan can spend, or false if it has no maximum.
+func (p Plan) Remaining() (decimal.Decimal, bool) {
+	if !p.MaxTotal.IsPositive() {
+		return decimal.Zero, false
+	}
+
+	return p.MaxTotal.Sub(p.Spent), true
+}
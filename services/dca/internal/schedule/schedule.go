@@ -0,0 +1,84 @@
+// Package schedule works out which period of a cron schedule is due.
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// lookbacks are searched in turn for the latest period, so frequent schedules
+// aren't stepped through all the way from a start time long in the past.
+var lookbacks = []time.Duration{
+	time.Hour,
+	24 * time.Hour,
+	32 * 24 * time.Hour,
+	367 * 24 * time.Hour,
+}
+
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+type (
+	// Schedule is a standard five field cron schedule, e.g. "0 9 * * MON", or a
+	// descriptor such as "@daily". Times are in UTC.
+	Schedule struct {
+		spec cron.Schedule
+	}
+
+	// InvalidScheduleError is returned when a schedule can't be parsed.
+	InvalidScheduleError struct {
+		Spec string
+		Err  string
+	}
+)
+
+func (i InvalidScheduleError) Error() string {
+	return fmt.Sprintf("invalid schedule %q: %s", i.Spec, i.Err)
+}
+
+func Parse(spec string) (Schedule, error) {
+	s, err := parser.Parse(spec)
+	if err != nil {
+		return Schedule{}, InvalidScheduleError{Spec: spec, Err: err.Error()}
+	}
+
+	return Schedule{spec: s}, nil
+}
+
+// Next returns the first period after t.
+func (s Schedule) Next(t time.Time) time.Time {
+	return s.spec.Next(t.UTC())
+}
+
+// Latest returns the most recent period after from and no later than to, or
+// false if there is none.
+func (s Schedule) Latest(from, to time.Time) (time.Time, bool) {
+	if !to.After(from) {
+		return time.Time{}, false
+	}
+
+	for _, l := range lookbacks {
+		start := to.Add(-l)
+		if !start.After(from) {
+			break
+		}
+		if period, ok := s.latest(start, to); ok {
+			return period, true
+		}
+	}
+
+	return s.latest(from, to)
+}
+
+func (s Schedule) latest(from, to time.Time) (time.Time, bool) {
+	var (
+		latest time.Time
+		found  bool
+	)
+	for next := s.Next(from); !next.IsZero() && !next.After(to); next = s.Next(next) {
+		latest, found = next, true
+	}
+
+	return latest, found
+}
@@ -0,0 +1,107 @@
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/dca/internal/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("returns error if schedule is invalid", func(t *testing.T) {
+		_, err := schedule.Parse("every tuesday")
+		require.Error(t, err)
+
+		isErr, ok := err.(schedule.InvalidScheduleError)
+		assert.True(t, ok)
+		assert.Equal(t, "every tuesday", isErr.Spec)
+	})
+
+	t.Run("returns error if schedule has seconds", func(t *testing.T) {
+		_, err := schedule.Parse("0 0 9 * * MON")
+		require.Error(t, err)
+	})
+
+	t.Run("parses cron schedule", func(t *testing.T) {
+		_, err := schedule.Parse("0 9 * * MON")
+		require.NoError(t, err)
+	})
+
+	t.Run("parses descriptor", func(t *testing.T) {
+		_, err := schedule.Parse("@daily")
+		require.NoError(t, err)
+	})
+}
+
+func TestSchedule_Next(t *testing.T) {
+	s, err := schedule.Parse("0 9 * * MON")
+	require.NoError(t, err)
+
+	t.Run("returns next period in UTC", func(t *testing.T) {
+		loc := time.FixedZone("UTC+5", 5*60*60)
+		from := time.Date(2020, 6, 1, 12, 0, 0, 0, loc) // Monday 07:00 UTC.
+
+		assert.Equal(t, time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC), s.Next(from))
+	})
+}
+
+func TestSchedule_Latest(t *testing.T) {
+	t.Run("returns false if to is not after from", func(t *testing.T) {
+		s, err := schedule.Parse("@hourly")
+		require.NoError(t, err)
+
+		now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+		_, ok := s.Latest(now, now)
+		assert.False(t, ok)
+	})
+
+	t.Run("returns false if no period is due", func(t *testing.T) {
+		s, err := schedule.Parse("0 9 * * MON")
+		require.NoError(t, err)
+
+		from := time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)
+		to := time.Date(2020, 6, 7, 23, 59, 0, 0, time.UTC)
+
+		_, ok := s.Latest(from, to)
+		assert.False(t, ok)
+	})
+
+	t.Run("includes period at to but not at from", func(t *testing.T) {
+		s, err := schedule.Parse("@daily")
+		require.NoError(t, err)
+
+		from := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2020, 6, 2, 0, 0, 0, 0, time.UTC)
+
+		period, ok := s.Latest(from, to)
+		require.True(t, ok)
+		assert.Equal(t, to, period)
+	})
+
+	t.Run("returns latest of several due periods", func(t *testing.T) {
+		s, err := schedule.Parse("*/15 * * * *")
+		require.NoError(t, err)
+
+		from := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2020, 6, 1, 12, 40, 0, 0, time.UTC)
+
+		period, ok := s.Latest(from, to)
+		require.True(t, ok)
+		assert.Equal(t, time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC), period)
+	})
+
+	t.Run("returns latest period outside of lookbacks", func(t *testing.T) {
+		s, err := schedule.Parse("0 0 29 2 *")
+		require.NoError(t, err)
+
+		from := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+
+		period, ok := s.Latest(from, to)
+		require.True(t, ok)
+		assert.Equal(t, time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC), period)
+	})
+}
@@ -0,0 +1,14 @@
+package service
+
+import "time"
+
+type Option func(*service)
+
+// WithClock sets the function used to get the current time.
+func WithClock(now func() time.Time) Option {
+	return func(s *service) {
+		if now != nil {
+			s.now = now
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cshep4/kripto/services/dca/internal/model"
+	"github.com/cshep4/kripto/services/dca/internal/schedule"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/cshep4/kripto/shared/go/log"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/shopspring/decimal"
+)
+
+const periodFormat = "2006-01-02T15:04Z"
+
+type (
+	PlanStore interface {
+		Save(ctx context.Context, plan model.Plan) (model.Plan, error)
+		List(ctx context.Context) ([]model.Plan, error)
+		RecordPeriod(ctx context.Context, id string, period time.Time, trade model.Trade) error
+	}
+	Trader interface {
+		Buy(ctx context.Context, order model.Order) (model.Trade, error)
+	}
+
+	service struct {
+		store  PlanStore
+		trader Trader
+		now    func() time.Time
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func New(store PlanStore, trader Trader, opts ...Option) (*service, error) {
+	switch {
+	case store == nil:
+		return nil, InvalidParameterError{Parameter: "store"}
+	case trader == nil:
+		return nil, InvalidParameterError{Parameter: "trader"}
+	}
+
+	s := &service{
+		store:  store,
+		trader: trader,
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+// SavePlan creates the plan, or updates it if the ID is already in use. Plans
+// without an ID are given one, and plans without a start time start now.
+func (s *service) SavePlan(ctx context.Context, plan model.Plan) (model.Plan, error) {
+	if plan.Id == "" {
+		plan.Id = uuid.NewV4().String()
+	}
+	if plan.Start.IsZero() {
+		plan.Start = s.now().UTC()
+	}
+
+	saved, err := s.store.Save(ctx, plan)
+	if err != nil {
+		return model.Plan{}, fmt.Errorf("save: %w", err)
+	}
+
+	return saved, nil
+}
+
+func (s *service) ListPlans(ctx context.Context) ([]model.Plan, error) {
+	plans, err := s.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+
+	return plans, nil
+}
+
+// RunPlans buys for each plan which has a period due, returning the number of
+// buys placed. A plan which fails is left for the next run, and the error of
+// the first to fail is returned once every plan has been run.
+func (s *service) RunPlans(ctx context.Context) (int, error) {
+	plans, err := s.store.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list: %w", err)
+	}
+
+	var (
+		bought int
+		runErr error
+	)
+	for _, p := range plans {
+		ok, err := s.run(ctx, p)
+		if err != nil {
+			log.Error(ctx, "error_running_plan", log.ErrorParam(err), log.SafeParam("planId", p.Id))
+			if runErr == nil {
+				runErr = err
+			}
+			continue
+		}
+		if ok {
+			bought++
+		}
+	}
+
+	if runErr != nil {
+		return bought, fmt.Errorf("run_plans: %w", runErr)
+	}
+
+	return bought, nil
+}
+
+// run buys for the latest period of the plan due since the last period run.
+// Earlier periods which were missed are skipped rather than bought all at once.
+// Every attempt at a period uses the same idempotency key, so a period which is
+// retried, e.g. because recording it failed, returns the trade already placed.
+func (s *service) run(ctx context.Context, plan model.Plan) (bool, error) {
+	period, ok, err := duePeriod(plan, s.now())
+	if err != nil || !ok {
+		return false, err
+	}
+
+	amount := plan.Amount
+	if remaining, ok := plan.Remaining(); ok {
+		if !remaining.IsPositive() {
+			return false, nil
+		}
+		amount = decimal.Min(amount, remaining)
+	}
+
+	trade, err := s.trader.Buy(ctx, model.Order{
+		IdempotencyKey: IdempotencyKey(plan.Id, period),
+		ProductId:      plan.ProductId,
+		Amount:         amount,
+	})
+	switch {
+	case isRejected(err):
+		// The same request will be rejected again, so skip the period.
+		log.Warn(ctx, "plan_buy_rejected",
+			log.ErrorParam(err),
+			log.SafeParam("planId", plan.Id),
+			log.SafeParam("period", period),
+		)
+		trade = model.Trade{}
+	case err != nil:
+		return false, fmt.Errorf("buy: %w", err)
+	}
+
+	if err := s.store.RecordPeriod(ctx, plan.Id, period, trade); err != nil {
+		return false, fmt.Errorf("record_period: %w", err)
+	}
+
+	return trade.Id != "", nil
+}
+
+// duePeriod returns the latest period of the plan between its start, or the last
+// period run, and now, which must not be after the end of the plan.
+func duePeriod(plan model.Plan, now time.Time) (time.Time, bool, error) {
+	sched, err := schedule.Parse(plan.Schedule)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	// Periods are after from, so a period at the start of the plan is included.
+	from := plan.Start.Add(-time.Second)
+	if plan.LastPeriod.After(from) {
+		from = plan.LastPeriod
+	}
+	if !plan.End.IsZero() && plan.End.Before(now) {
+		now = plan.End
+	}
+
+	period, ok := sched.Latest(from, now)
+	return period, ok, nil
+}
+
+// isRejected returns true if the trade function rejected the buy for a reason
+// which retrying won't fix, e.g. a failed risk check.
+func isRejected(err error) bool {
+	switch apperror.CodeOf(err) {
+	case apperror.Validation, apperror.InsufficientFunds, apperror.ExchangeRejected:
+		return true
+	default:
+		return false
+	}
+}
+
+// IdempotencyKey returns the idempotency key for a period of the plan, which is
+// the same every time the period is run.
+func IdempotencyKey(planId string, period time.Time) string {
+	return fmt.Sprintf("dca-%s-%s", planId, period.UTC().Format(periodFormat))
+}
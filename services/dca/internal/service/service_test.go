@@ -0,0 +1,458 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/dca/internal/mocks/plan"
+	"github.com/cshep4/kripto/services/dca/internal/mocks/trade"
+	"github.com/cshep4/kripto/services/dca/internal/model"
+	"github.com/cshep4/kripto/services/dca/internal/service"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2020, 6, 3, 12, 0, 0, 0, time.UTC)
+
+func clock() time.Time { return now }
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if store is empty", func(t *testing.T) {
+		s, err := service.New(nil, nil)
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+
+		ipErr, ok := err.(service.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "store", ipErr.Parameter)
+	})
+
+	t.Run("returns error if trader is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s, err := service.New(plan_mocks.NewMockPlanStore(ctrl), nil)
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+
+		ipErr, ok := err.(service.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "trader", ipErr.Parameter)
+	})
+
+	t.Run("returns service", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s, err := service.New(plan_mocks.NewMockPlanStore(ctrl), trade_mocks.NewMockTrader(ctrl))
+		require.NoError(t, err)
+
+		assert.NotNil(t, s)
+	})
+}
+
+func TestService_SavePlan(t *testing.T) {
+	t.Run("returns error if error saving plan", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := errors.New("error")
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+
+		s, err := service.New(store, trade_mocks.NewMockTrader(ctrl))
+		require.NoError(t, err)
+
+		store.EXPECT().Save(ctx, gomock.Any()).Return(model.Plan{}, testErr)
+
+		_, err = s.SavePlan(ctx, model.Plan{Id: "plan"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("sets id and start of new plan", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+
+		s, err := service.New(store, trade_mocks.NewMockTrader(ctrl), service.WithClock(clock))
+		require.NoError(t, err)
+
+		store.EXPECT().Save(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, p model.Plan) (model.Plan, error) {
+			assert.NotEmpty(t, p.Id)
+			assert.Equal(t, now, p.Start)
+			return p, nil
+		})
+
+		res, err := s.SavePlan(ctx, model.Plan{ProductId: "BTC-GBP"})
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, res.Id)
+		assert.Equal(t, "BTC-GBP", res.ProductId)
+	})
+
+	t.Run("keeps id and start of existing plan", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		p := model.Plan{Id: "plan", Start: now.Add(-time.Hour)}
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+
+		s, err := service.New(store, trade_mocks.NewMockTrader(ctrl), service.WithClock(clock))
+		require.NoError(t, err)
+
+		store.EXPECT().Save(ctx, p).Return(p, nil)
+
+		res, err := s.SavePlan(ctx, p)
+		require.NoError(t, err)
+
+		assert.Equal(t, p, res)
+	})
+}
+
+func TestService_ListPlans(t *testing.T) {
+	t.Run("returns error if error listing plans", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := errors.New("error")
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+
+		s, err := service.New(store, trade_mocks.NewMockTrader(ctrl))
+		require.NoError(t, err)
+
+		store.EXPECT().List(ctx).Return(nil, testErr)
+
+		plans, err := s.ListPlans(ctx)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Nil(t, plans)
+	})
+
+	t.Run("returns plans", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		plans := []model.Plan{{Id: "1"}, {Id: "2"}}
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+
+		s, err := service.New(store, trade_mocks.NewMockTrader(ctrl))
+		require.NoError(t, err)
+
+		store.EXPECT().List(ctx).Return(plans, nil)
+
+		res, err := s.ListPlans(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, plans, res)
+	})
+}
+
+func TestService_RunPlans(t *testing.T) {
+	daily := model.Plan{
+		Id:        "plan",
+		ProductId: "BTC-GBP",
+		Amount:    decimal.New(25, 0),
+		Schedule:  "0 9 * * *",
+		Start:     time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	period := time.Date(2020, 6, 3, 9, 0, 0, 0, time.UTC)
+
+	t.Run("returns error if error listing plans", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := errors.New("error")
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+
+		s, err := service.New(store, trade_mocks.NewMockTrader(ctrl))
+		require.NoError(t, err)
+
+		store.EXPECT().List(ctx).Return(nil, testErr)
+
+		bought, err := s.RunPlans(ctx)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Equal(t, 0, bought)
+	})
+
+	t.Run("buys latest due period", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		trade := model.Trade{Id: "trade", Spent: decimal.New(25, 0)}
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+		trader := trade_mocks.NewMockTrader(ctrl)
+
+		s, err := service.New(store, trader, service.WithClock(clock))
+		require.NoError(t, err)
+
+		store.EXPECT().List(ctx).Return([]model.Plan{daily}, nil)
+		trader.EXPECT().Buy(ctx, model.Order{
+			IdempotencyKey: "dca-plan-2020-06-03T09:00Z",
+			ProductId:      "BTC-GBP",
+			Amount:         daily.Amount,
+		}).Return(trade, nil)
+		store.EXPECT().RecordPeriod(ctx, "plan", period, trade).Return(nil)
+
+		bought, err := s.RunPlans(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, bought)
+	})
+
+	t.Run("buys period at start of plan", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		p := daily
+		p.Start = period
+		trade := model.Trade{Id: "trade", Spent: decimal.New(25, 0)}
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+		trader := trade_mocks.NewMockTrader(ctrl)
+
+		s, err := service.New(store, trader, service.WithClock(clock))
+		require.NoError(t, err)
+
+		store.EXPECT().List(ctx).Return([]model.Plan{p}, nil)
+		trader.EXPECT().Buy(ctx, gomock.Any()).Return(trade, nil)
+		store.EXPECT().RecordPeriod(ctx, "plan", period, trade).Return(nil)
+
+		bought, err := s.RunPlans(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, bought)
+	})
+
+	t.Run("does nothing if period already run", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		p := daily
+		p.LastPeriod = period
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+
+		s, err := service.New(store, trade_mocks.NewMockTrader(ctrl), service.WithClock(clock))
+		require.NoError(t, err)
+
+		store.EXPECT().List(ctx).Return([]model.Plan{p}, nil)
+
+		bought, err := s.RunPlans(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, bought)
+	})
+
+	t.Run("does nothing if plan hasn't started", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		p := daily
+		p.Start = now.Add(time.Hour)
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+
+		s, err := service.New(store, trade_mocks.NewMockTrader(ctrl), service.WithClock(clock))
+		require.NoError(t, err)
+
+		store.EXPECT().List(ctx).Return([]model.Plan{p}, nil)
+
+		bought, err := s.RunPlans(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, bought)
+	})
+
+	t.Run("buys last period before end of plan", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		p := daily
+		p.End = time.Date(2020, 6, 2, 12, 0, 0, 0, time.UTC)
+		trade := model.Trade{Id: "trade", Spent: decimal.New(25, 0)}
+		last := time.Date(2020, 6, 2, 9, 0, 0, 0, time.UTC)
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+		trader := trade_mocks.NewMockTrader(ctrl)
+
+		s, err := service.New(store, trader, service.WithClock(clock))
+		require.NoError(t, err)
+
+		store.EXPECT().List(ctx).Return([]model.Plan{p}, nil)
+		trader.EXPECT().Buy(ctx, gomock.Any()).Return(trade, nil)
+		store.EXPECT().RecordPeriod(ctx, "plan", last, trade).Return(nil)
+
+		bought, err := s.RunPlans(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, bought)
+	})
+
+	t.Run("caps amount at remaining max total", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		p := daily
+		p.MaxTotal = decimal.New(60, 0)
+		p.Spent = decimal.New(50, 0)
+		trade := model.Trade{Id: "trade", Spent: decimal.New(10, 0)}
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+		trader := trade_mocks.NewMockTrader(ctrl)
+
+		s, err := service.New(store, trader, service.WithClock(clock))
+		require.NoError(t, err)
+
+		store.EXPECT().List(ctx).Return([]model.Plan{p}, nil)
+		trader.EXPECT().Buy(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, o model.Order) (model.Trade, error) {
+			assert.Equal(t, "10", o.Amount.String())
+			return trade, nil
+		})
+		store.EXPECT().RecordPeriod(ctx, "plan", period, trade).Return(nil)
+
+		bought, err := s.RunPlans(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, bought)
+	})
+
+	t.Run("does nothing if max total spent", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		p := daily
+		p.MaxTotal = decimal.New(50, 0)
+		p.Spent = decimal.New(50, 0)
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+
+		s, err := service.New(store, trade_mocks.NewMockTrader(ctrl), service.WithClock(clock))
+		require.NoError(t, err)
+
+		store.EXPECT().List(ctx).Return([]model.Plan{p}, nil)
+
+		bought, err := s.RunPlans(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, bought)
+	})
+
+	t.Run("skips period if buy is rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+		trader := trade_mocks.NewMockTrader(ctrl)
+
+		s, err := service.New(store, trader, service.WithClock(clock))
+		require.NoError(t, err)
+
+		store.EXPECT().List(ctx).Return([]model.Plan{daily}, nil)
+		trader.EXPECT().Buy(ctx, gomock.Any()).Return(model.Trade{}, apperror.Parse("insufficient_funds: trade: insufficient funds"))
+		store.EXPECT().RecordPeriod(ctx, "plan", period, model.Trade{}).Return(nil)
+
+		bought, err := s.RunPlans(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, bought)
+	})
+
+	t.Run("leaves period to retry and runs other plans if buy fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := apperror.Parse("exchange_unavailable: trade: unavailable")
+		other := daily
+		other.Id = "other"
+		trade := model.Trade{Id: "trade", Spent: decimal.New(25, 0)}
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+		trader := trade_mocks.NewMockTrader(ctrl)
+
+		s, err := service.New(store, trader, service.WithClock(clock))
+		require.NoError(t, err)
+
+		store.EXPECT().List(ctx).Return([]model.Plan{daily, other}, nil)
+		gomock.InOrder(
+			trader.EXPECT().Buy(ctx, gomock.Any()).Return(model.Trade{}, testErr),
+			trader.EXPECT().Buy(ctx, gomock.Any()).Return(trade, nil),
+		)
+		store.EXPECT().RecordPeriod(ctx, "other", period, trade).Return(nil)
+
+		bought, err := s.RunPlans(ctx)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Equal(t, apperror.ExchangeUnavailable, apperror.CodeOf(err))
+		assert.Equal(t, 1, bought)
+	})
+
+	t.Run("returns error if error recording period", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := errors.New("error")
+
+		store := plan_mocks.NewMockPlanStore(ctrl)
+		trader := trade_mocks.NewMockTrader(ctrl)
+
+		s, err := service.New(store, trader, service.WithClock(clock))
+		require.NoError(t, err)
+
+		store.EXPECT().List(ctx).Return([]model.Plan{daily}, nil)
+		trader.EXPECT().Buy(ctx, gomock.Any()).Return(model.Trade{Id: "trade"}, nil)
+		store.EXPECT().RecordPeriod(ctx, "plan", period, gomock.Any()).Return(testErr)
+
+		bought, err := s.RunPlans(ctx)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Equal(t, 0, bought)
+	})
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	t.Run("returns same key for period in any location", func(t *testing.T) {
+		period := time.Date(2020, 6, 3, 9, 0, 0, 0, time.UTC)
+		loc := time.FixedZone("UTC+1", 60*60)
+
+		assert.Equal(t, "dca-plan-2020-06-03T09:00Z", service.IdempotencyKey("plan", period))
+		assert.Equal(t, "dca-plan-2020-06-03T09:00Z", service.IdempotencyKey("plan", period.In(loc)))
+	})
+}
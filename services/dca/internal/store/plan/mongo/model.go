@@ -0,0 +1,110 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/cshep4/kripto/services/dca/internal/model"
+)
+
+type plan struct {
+	Id          string               `bson:"_id"`
+	ProductId   string               `bson:"productId"`
+	Amount      primitive.Decimal128 `bson:"amount"`
+	Schedule    string               `bson:"schedule"`
+	Start       time.Time            `bson:"start"`
+	End         time.Time            `bson:"end"`
+	MaxTotal    primitive.Decimal128 `bson:"maxTotal"`
+	Spent       primitive.Decimal128 `bson:"spent"`
+	LastPeriod  time.Time            `bson:"lastPeriod"`
+	LastTradeId string               `bson:"lastTradeId,omitempty"`
+	CreatedAt   time.Time            `bson:"createdAt"`
+}
+
+func fromPlan(p model.Plan) (plan, error) {
+	if p.Id == "" {
+		return plan{}, errors.New("invalid_plan_id")
+	}
+
+	amount, err := toDecimal128(p.Amount)
+	if err != nil {
+		return plan{}, fmt.Errorf("invalid_amount: %w", err)
+	}
+	maxTotal, err := toDecimal128(p.MaxTotal)
+	if err != nil {
+		return plan{}, fmt.Errorf("invalid_max_total: %w", err)
+	}
+	spent, err := toDecimal128(p.Spent)
+	if err != nil {
+		return plan{}, fmt.Errorf("invalid_spent: %w", err)
+	}
+
+	return plan{
+		Id:          p.Id,
+		ProductId:   p.ProductId,
+		Amount:      amount,
+		Schedule:    p.Schedule,
+		Start:       p.Start,
+		End:         p.End,
+		MaxTotal:    maxTotal,
+		Spent:       spent,
+		LastPeriod:  p.LastPeriod,
+		LastTradeId: p.LastTradeId,
+		CreatedAt:   p.CreatedAt,
+	}, nil
+}
+
+func toPlan(p plan) (model.Plan, error) {
+	amount, err := fromDecimal128(p.Amount)
+	if err != nil {
+		return model.Plan{}, fmt.Errorf("invalid_amount: %w", err)
+	}
+	maxTotal, err := fromDecimal128(p.MaxTotal)
+	if err != nil {
+		return model.Plan{}, fmt.Errorf("invalid_max_total: %w", err)
+	}
+	spent, err := fromDecimal128(p.Spent)
+	if err != nil {
+		return model.Plan{}, fmt.Errorf("invalid_spent: %w", err)
+	}
+
+	return model.Plan{
+		Id:          p.Id,
+		ProductId:   p.ProductId,
+		Amount:      amount,
+		Schedule:    p.Schedule,
+		Start:       toUTC(p.Start),
+		End:         toUTC(p.End),
+		MaxTotal:    maxTotal,
+		Spent:       spent,
+		LastPeriod:  toUTC(p.LastPeriod),
+		LastTradeId: p.LastTradeId,
+		CreatedAt:   toUTC(p.CreatedAt),
+	}, nil
+}
+
+// toUTC converts a stored time to UTC, leaving unset times as the zero time.
+func toUTC(t time.Time) time.Time {
+	if t.IsZero() || t.Year() <= 1 {
+		return time.Time{}
+	}
+
+	return t.UTC()
+}
+
+func toDecimal128(d decimal.Decimal) (primitive.Decimal128, error) {
+	return primitive.ParseDecimal128(d.String())
+}
+
+// fromDecimal128 converts a stored Decimal128, an unset value is treated as zero.
+func fromDecimal128(d primitive.Decimal128) (decimal.Decimal, error) {
+	if d == (primitive.Decimal128{}) {
+		return decimal.Zero, nil
+	}
+
+	return decimal.NewFromString(d.String())
+}
@@ -0,0 +1,183 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/cshep4/kripto/services/dca/internal/model"
+)
+
+const (
+	db         = "dca"
+	collection = "plan"
+)
+
+type (
+	store struct {
+		client     *mongo.Client
+		collection *mongo.Collection
+		now        func() time.Time
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+// ErrPlanNotFound is returned when a plan can't be found.
+var ErrPlanNotFound = errors.New("plan not found")
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func New(ctx context.Context, client *mongo.Client) (*store, error) {
+	if client == nil {
+		return nil, InvalidParameterError{Parameter: "client"}
+	}
+
+	s := &store{
+		client:     client,
+		collection: client.Database(db).Collection(collection),
+		now:        time.Now,
+	}
+
+	if err := s.ping(ctx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Save creates the plan, or updates its settings if it already exists. The
+// amount spent and the last period run are only set when the plan is created,
+// so changing a plan doesn't reset its progress. The saved plan is returned.
+func (s *store) Save(ctx context.Context, p model.Plan) (model.Plan, error) {
+	doc, err := fromPlan(p)
+	if err != nil {
+		return model.Plan{}, fmt.Errorf("map_document: %w", err)
+	}
+
+	res := s.collection.FindOneAndUpdate(
+		ctx,
+		bson.D{{Key: "_id", Value: doc.Id}},
+		bson.D{
+			{
+				Key: "$set",
+				Value: bson.D{
+					{Key: "productId", Value: doc.ProductId},
+					{Key: "amount", Value: doc.Amount},
+					{Key: "schedule", Value: doc.Schedule},
+					{Key: "start", Value: doc.Start},
+					{Key: "end", Value: doc.End},
+					{Key: "maxTotal", Value: doc.MaxTotal},
+				},
+			},
+			{
+				Key: "$setOnInsert",
+				Value: bson.D{
+					{Key: "spent", Value: doc.Spent},
+					{Key: "lastPeriod", Value: doc.LastPeriod},
+					{Key: "createdAt", Value: s.now()},
+				},
+			},
+		},
+		options.FindOneAndUpdate().
+			SetUpsert(true).
+			SetReturnDocument(options.After),
+	)
+
+	var saved plan
+	if err := res.Decode(&saved); err != nil {
+		return model.Plan{}, fmt.Errorf("find_one_and_update: %w", err)
+	}
+
+	return toPlan(saved)
+}
+
+// List returns all plans, oldest first.
+func (s *store) List(ctx context.Context) ([]model.Plan, error) {
+	cur, err := s.collection.Find(
+		ctx,
+		bson.D{},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("find: %w", err)
+	}
+
+	var docs []plan
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	plans := make([]model.Plan, len(docs))
+	for i, d := range docs {
+		if plans[i], err = toPlan(d); err != nil {
+			return nil, fmt.Errorf("map_document: %w", err)
+		}
+	}
+
+	return plans, nil
+}
+
+// RecordPeriod records the trade placed for the period and adds what it spent
+// to the plan. Periods are only recorded once, so recording a period again,
+// e.g. after a retry, or one older than the last period recorded does nothing.
+func (s *store) RecordPeriod(ctx context.Context, id string, period time.Time, trade model.Trade) error {
+	spent, err := toDecimal128(trade.Spent)
+	if err != nil {
+		return fmt.Errorf("invalid_spent: %w", err)
+	}
+
+	set := bson.D{{Key: "lastPeriod", Value: period}}
+	if trade.Id != "" {
+		set = append(set, bson.E{Key: "lastTradeId", Value: trade.Id})
+	}
+
+	res, err := s.collection.UpdateOne(
+		ctx,
+		bson.D{
+			{Key: "_id", Value: id},
+			{Key: "lastPeriod", Value: bson.D{{Key: "$lt", Value: period}}},
+		},
+		bson.D{
+			{Key: "$set", Value: set},
+			{Key: "$inc", Value: bson.D{{Key: "spent", Value: spent}}},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("update_one: %w", err)
+	}
+	if res.MatchedCount > 0 {
+		return nil
+	}
+
+	count, err := s.collection.CountDocuments(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return fmt.Errorf("count_documents: %w", err)
+	}
+	if count == 0 {
+		return ErrPlanNotFound
+	}
+
+	return nil
+}
+
+func (s *store) ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	return s.client.Ping(ctx, nil)
+}
+
+func (s *store) Close(ctx context.Context) error {
+	return s.client.Disconnect(ctx)
+}
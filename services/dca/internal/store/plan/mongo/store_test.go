@@ -0,0 +1,224 @@
+//+build integration
+
+package mongo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/dca/internal/model"
+	"github.com/cshep4/kripto/services/dca/internal/service"
+	store "github.com/cshep4/kripto/services/dca/internal/store/plan/mongo"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if mongo client is nil", func(t *testing.T) {
+		s, err := store.New(context.Background(), nil)
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+
+		ipErr, ok := err.(store.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "client", ipErr.Parameter)
+	})
+
+	t.Run("returns error if ping fails", func(t *testing.T) {
+		ctx := context.Background()
+
+		client := newClient(t, ctx)
+
+		err := client.Disconnect(ctx)
+		require.NoError(t, err)
+
+		s, err := store.New(ctx, client)
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+	})
+
+	t.Run("returns store", func(t *testing.T) {
+		ctx := context.Background()
+
+		s, err := store.New(ctx, newClient(t, ctx))
+		require.NoError(t, err)
+
+		assert.NotNil(t, s)
+
+		err = s.Close(ctx)
+		require.NoError(t, err)
+	})
+}
+
+func TestStore_Save(t *testing.T) {
+	ctx := context.Background()
+	s := newStore(t, ctx)
+
+	p := model.Plan{
+		Id:        "plan",
+		ProductId: "BTC-GBP",
+		Amount:    decimal.New(25, 0),
+		Schedule:  "0 9 * * MON",
+		Start:     time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC),
+		MaxTotal:  decimal.New(100, 0),
+	}
+
+	t.Run("creates plan", func(t *testing.T) {
+		saved, err := s.Save(ctx, p)
+		require.NoError(t, err)
+
+		assert.Equal(t, p.Id, saved.Id)
+		assert.Equal(t, p.ProductId, saved.ProductId)
+		assert.True(t, p.Amount.Equal(saved.Amount))
+		assert.Equal(t, p.Schedule, saved.Schedule)
+		assert.Equal(t, p.Start, saved.Start)
+		assert.True(t, saved.End.IsZero())
+		assert.True(t, p.MaxTotal.Equal(saved.MaxTotal))
+		assert.True(t, saved.Spent.IsZero())
+		assert.True(t, saved.LastPeriod.IsZero())
+		assert.False(t, saved.CreatedAt.IsZero())
+	})
+
+	t.Run("updates plan without resetting progress", func(t *testing.T) {
+		period := time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)
+		err := s.RecordPeriod(ctx, p.Id, period, model.Trade{Id: "trade", Spent: decimal.New(25, 0)})
+		require.NoError(t, err)
+
+		updated := p
+		updated.Amount = decimal.New(50, 0)
+
+		saved, err := s.Save(ctx, updated)
+		require.NoError(t, err)
+
+		assert.True(t, updated.Amount.Equal(saved.Amount))
+		assert.Equal(t, "25", saved.Spent.String())
+		assert.Equal(t, period, saved.LastPeriod)
+		assert.Equal(t, "trade", saved.LastTradeId)
+	})
+}
+
+func TestStore_List(t *testing.T) {
+	ctx := context.Background()
+	s := newStore(t, ctx)
+
+	t.Run("returns empty list if there are no plans", func(t *testing.T) {
+		plans, err := s.List(ctx)
+		require.NoError(t, err)
+
+		assert.Empty(t, plans)
+	})
+
+	t.Run("returns plans oldest first", func(t *testing.T) {
+		for _, id := range []string{"1", "2"} {
+			_, err := s.Save(ctx, model.Plan{Id: id, ProductId: "BTC-GBP", Schedule: "@daily"})
+			require.NoError(t, err)
+		}
+
+		plans, err := s.List(ctx)
+		require.NoError(t, err)
+
+		require.Len(t, plans, 2)
+		assert.Equal(t, "1", plans[0].Id)
+		assert.Equal(t, "2", plans[1].Id)
+	})
+}
+
+func TestStore_RecordPeriod(t *testing.T) {
+	ctx := context.Background()
+	s := newStore(t, ctx)
+
+	_, err := s.Save(ctx, model.Plan{Id: "plan", ProductId: "BTC-GBP", Schedule: "@daily"})
+	require.NoError(t, err)
+
+	period := time.Date(2020, 6, 2, 0, 0, 0, 0, time.UTC)
+	trade := model.Trade{Id: "trade", Spent: decimal.New(25, 0)}
+
+	t.Run("returns error if plan doesn't exist", func(t *testing.T) {
+		err := s.RecordPeriod(ctx, "invalid", period, trade)
+		require.Error(t, err)
+
+		assert.Equal(t, store.ErrPlanNotFound, err)
+	})
+
+	t.Run("records period and adds spent", func(t *testing.T) {
+		err := s.RecordPeriod(ctx, "plan", period, trade)
+		require.NoError(t, err)
+
+		plans, err := s.List(ctx)
+		require.NoError(t, err)
+
+		require.Len(t, plans, 1)
+		assert.Equal(t, period, plans[0].LastPeriod)
+		assert.Equal(t, "trade", plans[0].LastTradeId)
+		assert.Equal(t, "25", plans[0].Spent.String())
+	})
+
+	t.Run("does nothing if period already recorded", func(t *testing.T) {
+		err := s.RecordPeriod(ctx, "plan", period, trade)
+		require.NoError(t, err)
+
+		err = s.RecordPeriod(ctx, "plan", period.Add(-24*time.Hour), trade)
+		require.NoError(t, err)
+
+		plans, err := s.List(ctx)
+		require.NoError(t, err)
+
+		require.Len(t, plans, 1)
+		assert.Equal(t, period, plans[0].LastPeriod)
+		assert.Equal(t, "25", plans[0].Spent.String())
+	})
+
+	t.Run("records skipped period without trade", func(t *testing.T) {
+		next := period.Add(24 * time.Hour)
+
+		err := s.RecordPeriod(ctx, "plan", next, model.Trade{})
+		require.NoError(t, err)
+
+		plans, err := s.List(ctx)
+		require.NoError(t, err)
+
+		require.Len(t, plans, 1)
+		assert.Equal(t, next, plans[0].LastPeriod)
+		assert.Equal(t, "trade", plans[0].LastTradeId)
+		assert.Equal(t, "25", plans[0].Spent.String())
+	})
+}
+
+func newStore(t *testing.T, ctx context.Context) service.PlanStore {
+	t.Helper()
+
+	client := newClient(t, ctx)
+
+	t.Cleanup(func() {
+		err := client.
+			Database("dca").
+			Drop(ctx)
+		require.NoError(t, err)
+
+		err = client.Disconnect(ctx)
+		require.NoError(t, err)
+	})
+
+	s, err := store.New(ctx, client)
+	require.NoError(t, err)
+
+	return s
+}
+
+func newClient(t *testing.T, ctx context.Context) *mongo.Client {
+	t.Helper()
+
+	client, err := mongo.NewClient(options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+
+	err = client.Connect(ctx)
+	require.NoError(t, err)
+
+	return client
+}
@@ -0,0 +1,114 @@
+// Package trade places buys by invoking the trader trade function.
+package trade
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/cshep4/kripto/services/dca/internal/model"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/shopspring/decimal"
+)
+
+const buy = "buy"
+
+type (
+	Invoker interface {
+		InvokeWithContext(ctx context.Context, input *lambda.InvokeInput, opts ...request.Option) (*lambda.InvokeOutput, error)
+	}
+
+	trader struct {
+		invoker      Invoker
+		functionName string
+	}
+
+	// tradeRequest is the request accepted by the trade function.
+	tradeRequest struct {
+		IdempotencyKey string `json:"idempotencyKey"`
+		ProductId      string `json:"productId"`
+		TradeType      string `json:"tradeType"`
+		Amount         string `json:"amount"`
+	}
+
+	// tradeResponse is the trade returned by the trade function.
+	tradeResponse struct {
+		Id       string          `json:"id"`
+		NetQuote decimal.Decimal `json:"netQuote"`
+	}
+
+	// errorResponse is returned by Lambda when the function fails.
+	errorResponse struct {
+		ErrorMessage string `json:"errorMessage"`
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func New(invoker Invoker, functionName string) (*trader, error) {
+	switch {
+	case invoker == nil:
+		return nil, InvalidParameterError{Parameter: "invoker"}
+	case functionName == "":
+		return nil, InvalidParameterError{Parameter: "functionName"}
+	}
+
+	return &trader{
+		invoker:      invoker,
+		functionName: functionName,
+	}, nil
+}
+
+// Buy places a market buy for the order amount. Errors returned by the trade
+// function keep their code, so callers can tell whether to retry the order.
+func (t *trader) Buy(ctx context.Context, order model.Order) (model.Trade, error) {
+	payload, err := json.Marshal(tradeRequest{
+		IdempotencyKey: order.IdempotencyKey,
+		ProductId:      order.ProductId,
+		TradeType:      buy,
+		Amount:         order.Amount.String(),
+	})
+	if err != nil {
+		return model.Trade{}, fmt.Errorf("json_marshal: %w", err)
+	}
+
+	out, err := t.invoker.InvokeWithContext(ctx, &lambda.InvokeInput{
+		FunctionName: aws.String(t.functionName),
+		Payload:      payload,
+	})
+	if err != nil {
+		return model.Trade{}, fmt.Errorf("invoke: %w", err)
+	}
+	if out.FunctionError != nil {
+		var res errorResponse
+		if err := json.Unmarshal(out.Payload, &res); err != nil || res.ErrorMessage == "" {
+			return model.Trade{}, fmt.Errorf("function error - type: %s, payload: %s", aws.StringValue(out.FunctionError), out.Payload)
+		}
+		return model.Trade{}, apperror.Parse(res.ErrorMessage)
+	}
+
+	var res tradeResponse
+	if err := json.Unmarshal(out.Payload, &res); err != nil {
+		return model.Trade{}, fmt.Errorf("json_unmarshal: %w", err)
+	}
+
+	spent := res.NetQuote.Abs()
+	if spent.IsZero() {
+		spent = order.Amount
+	}
+
+	return model.Trade{
+		Id:    res.Id,
+		Spent: spent,
+	}, nil
+}
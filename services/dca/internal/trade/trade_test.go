@@ -0,0 +1,201 @@
+package trade_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/cshep4/kripto/services/dca/internal/mocks/trade"
+	"github.com/cshep4/kripto/services/dca/internal/model"
+	"github.com/cshep4/kripto/services/dca/internal/trade"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	functionName = "trade"
+	payload      = `{"idempotencyKey":"dca-plan-2020-06-01T09:00Z","productId":"BTC-GBP","tradeType":"buy","amount":"25"}`
+)
+
+var order = model.Order{
+	IdempotencyKey: "dca-plan-2020-06-01T09:00Z",
+	ProductId:      "BTC-GBP",
+	Amount:         decimal.New(25, 0),
+}
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if invoker is empty", func(t *testing.T) {
+		tr, err := trade.New(nil, functionName)
+		require.Error(t, err)
+
+		assert.Nil(t, tr)
+
+		ipErr, ok := err.(trade.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "invoker", ipErr.Parameter)
+	})
+
+	t.Run("returns error if functionName is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tr, err := trade.New(trade_mocks.NewMockInvoker(ctrl), "")
+		require.Error(t, err)
+
+		assert.Nil(t, tr)
+
+		ipErr, ok := err.(trade.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "functionName", ipErr.Parameter)
+	})
+
+	t.Run("returns trader", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tr, err := trade.New(trade_mocks.NewMockInvoker(ctrl), functionName)
+		require.NoError(t, err)
+
+		assert.NotNil(t, tr)
+	})
+}
+
+func TestTrader_Buy(t *testing.T) {
+	t.Run("returns error if error invoking function", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := errors.New("error")
+
+		invoker := trade_mocks.NewMockInvoker(ctrl)
+
+		tr, err := trade.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, &lambda.InvokeInput{
+			FunctionName: aws.String(functionName),
+			Payload:      []byte(payload),
+		}).Return(nil, testErr)
+
+		_, err = tr.Buy(ctx, order)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("returns error with code if function returns error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := trade_mocks.NewMockInvoker(ctrl)
+
+		tr, err := trade.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, gomock.Any()).Return(&lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			Payload:       []byte(`{"errorMessage":"insufficient_funds: trade: insufficient funds","errorType":"Error"}`),
+		}, nil)
+
+		_, err = tr.Buy(ctx, order)
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.InsufficientFunds, apperror.CodeOf(err))
+	})
+
+	t.Run("returns error if function error can't be read", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := trade_mocks.NewMockInvoker(ctrl)
+
+		tr, err := trade.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, gomock.Any()).Return(&lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			Payload:       []byte(`not json`),
+		}, nil)
+
+		_, err = tr.Buy(ctx, order)
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.Internal, apperror.CodeOf(err))
+	})
+
+	t.Run("returns error if response can't be read", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := trade_mocks.NewMockInvoker(ctrl)
+
+		tr, err := trade.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, gomock.Any()).Return(&lambda.InvokeOutput{
+			Payload: []byte(`not json`),
+		}, nil)
+
+		_, err = tr.Buy(ctx, order)
+		require.Error(t, err)
+	})
+
+	t.Run("returns trade with net quote spent", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := trade_mocks.NewMockInvoker(ctrl)
+
+		tr, err := trade.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, &lambda.InvokeInput{
+			FunctionName: aws.String(functionName),
+			Payload:      []byte(payload),
+		}).Return(&lambda.InvokeOutput{
+			Payload: []byte(`{"id":"trade-id","netQuote":"-24.9"}`),
+		}, nil)
+
+		res, err := tr.Buy(ctx, order)
+		require.NoError(t, err)
+
+		assert.Equal(t, "trade-id", res.Id)
+		assert.Equal(t, "24.9", res.Spent.String())
+	})
+
+	t.Run("returns trade with order amount spent if net quote is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := trade_mocks.NewMockInvoker(ctrl)
+
+		tr, err := trade.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, gomock.Any()).Return(&lambda.InvokeOutput{
+			Payload: []byte(`{"id":"trade-id"}`),
+		}, nil)
+
+		res, err := tr.Buy(ctx, order)
+		require.NoError(t, err)
+
+		assert.Equal(t, "trade-id", res.Id)
+		assert.True(t, order.Amount.Equal(res.Spent))
+	})
+}
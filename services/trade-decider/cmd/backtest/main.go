@@ -0,0 +1,214 @@
+// Command backtest replays stored rates through a strategy on a simulated
+// exchange and reports how it would have performed.
+//
+// Rates are read from a CSV or JSON export with -file, or otherwise from the
+// rate collection of the database at MONGO_URI.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/backtest"
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	rate "github.com/cshep4/kripto/services/trade-decider/internal/store/rate/mongo"
+	"github.com/cshep4/kripto/services/trade-decider/internal/strategy"
+	"github.com/cshep4/kripto/shared/go/mongodb"
+	"github.com/shopspring/decimal"
+)
+
+type config struct {
+	file     string
+	from     time.Time
+	to       time.Time
+	strategy string
+	feeRate  decimal.Decimal
+	balance  model.Balance
+	interval time.Duration
+	window   time.Duration
+	json     bool
+	trades   bool
+}
+
+func main() {
+	c, err := parseFlags()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := run(context.Background(), c, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parseFlags() (config, error) {
+	var (
+		c config
+
+		from    = flag.String("from", "", "Only use rates from this time, RFC 3339.")
+		to      = flag.String("to", "", "Only use rates before this time, RFC 3339.")
+		feeRate = flag.String("fee", "0.005", "Fee charged on each trade as a fraction of its value.")
+		base    = flag.String("base", "0", "Starting balance of the base currency, e.g. BTC.")
+		quote   = flag.String("quote", "1000", "Starting balance of the quote currency, e.g. GBP.")
+	)
+	flag.StringVar(&c.file, "file", "", "CSV or JSON export of rates to use instead of the database.")
+	flag.StringVar(&c.strategy, "strategy", strategy.EMACrossoverName, "Strategy to test.")
+	flag.DurationVar(&c.interval, "interval", 2*time.Minute, "How often the strategy decides whether to trade.")
+	flag.DurationVar(&c.window, "window", 30*24*time.Hour, "How far back the rates passed to the strategy go.")
+	flag.BoolVar(&c.json, "json", false, "Write the report as JSON.")
+	flag.BoolVar(&c.trades, "trades", false, "List each trade in the report.")
+	flag.Parse()
+
+	var err error
+	if c.from, err = parseTime(*from); err != nil {
+		return config{}, fmt.Errorf("invalid -from: %w", err)
+	}
+	if c.to, err = parseTime(*to); err != nil {
+		return config{}, fmt.Errorf("invalid -to: %w", err)
+	}
+	if c.feeRate, err = decimal.NewFromString(*feeRate); err != nil {
+		return config{}, fmt.Errorf("invalid -fee: %w", err)
+	}
+	if c.balance.Base, err = decimal.NewFromString(*base); err != nil {
+		return config{}, fmt.Errorf("invalid -base: %w", err)
+	}
+	if c.balance.Quote, err = decimal.NewFromString(*quote); err != nil {
+		return config{}, fmt.Errorf("invalid -quote: %w", err)
+	}
+
+	return c, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func run(ctx context.Context, c config, w io.Writer) error {
+	s, err := strategy.New(c.strategy)
+	if err != nil {
+		return err
+	}
+
+	rates, err := loadRates(ctx, c)
+	if err != nil {
+		return fmt.Errorf("load_rates: %w", err)
+	}
+
+	b, err := backtest.New(s,
+		backtest.WithFeeRate(c.feeRate),
+		backtest.WithBalance(c.balance),
+		backtest.WithInterval(c.interval),
+		backtest.WithWindow(c.window),
+	)
+	if err != nil {
+		return fmt.Errorf("initialise_backtester: %w", err)
+	}
+
+	report, err := b.Run(rates)
+	if err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+
+	if c.json {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	return writeReport(w, report, c.trades)
+}
+
+func loadRates(ctx context.Context, c config) ([]model.Rate, error) {
+	if c.file == "" {
+		client, err := mongodb.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("initialise_mongo_client: %w", err)
+		}
+
+		store, err := rate.New(ctx, client)
+		if err != nil {
+			return nil, fmt.Errorf("initialise_rate_store: %w", err)
+		}
+		defer store.Close(ctx)
+
+		return store.GetRates(ctx, c.from, c.to)
+	}
+
+	f, err := os.Open(c.file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rates []model.Rate
+	switch ext := strings.ToLower(filepath.Ext(c.file)); ext {
+	case ".csv":
+		rates, err = backtest.LoadCSV(f)
+	case ".json":
+		rates, err = backtest.LoadJSON(f)
+	default:
+		return nil, fmt.Errorf("unsupported file type %q, should be .csv or .json", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := rates[:0]
+	for _, r := range rates {
+		if (c.from.IsZero() || !r.DateTime.Before(c.from)) && (c.to.IsZero() || r.DateTime.Before(c.to)) {
+			filtered = append(filtered, r)
+		}
+	}
+
+	return filtered, nil
+}
+
+func writeReport(w io.Writer, r *backtest.Report, trades bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Period\t%s - %s\n", r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339))
+	fmt.Fprintf(tw, "Rates\t%d\n", r.Rates)
+	fmt.Fprintf(tw, "Trades\t%d (%d rejected)\n", len(r.Trades), r.Rejected)
+	fmt.Fprintf(tw, "Start balance\t%s base, %s quote\n", r.StartBalance.Base, r.StartBalance.Quote)
+	fmt.Fprintf(tw, "End balance\t%s base, %s quote\n", r.EndBalance.Base.StringFixed(8), r.EndBalance.Quote.StringFixed(2))
+	fmt.Fprintf(tw, "Start value\t%s\n", r.StartValue.StringFixed(2))
+	fmt.Fprintf(tw, "End value\t%s\n", r.EndValue.StringFixed(2))
+	fmt.Fprintf(tw, "Fees\t%s\n", r.Fees.StringFixed(2))
+	fmt.Fprintf(tw, "P&L\t%s (%.2f%%, holding %.2f%%)\n", r.PnL.StringFixed(2), r.Return*100, r.HoldReturn*100)
+	fmt.Fprintf(tw, "Max drawdown\t%.2f%%\n", r.MaxDrawdown*100)
+	fmt.Fprintf(tw, "Sharpe\t%.2f\n", r.Sharpe)
+	fmt.Fprintf(tw, "Win rate\t%.2f%%\n", r.WinRate*100)
+
+	if trades && len(r.Trades) > 0 {
+		fmt.Fprintf(tw, "\nTime\tType\tPrice\tSize\tValue\tFee\tProfit\n")
+		for _, t := range r.Trades {
+			profit := ""
+			if t.TradeType == model.Sell {
+				profit = t.Profit.StringFixed(2)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				t.Time.Format(time.RFC3339),
+				t.TradeType,
+				t.Price.StringFixed(2),
+				t.Size.StringFixed(8),
+				t.Value.StringFixed(2),
+				t.Fee.StringFixed(2),
+				profit,
+			)
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Netflix/go-env"
+	awsconfig "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awslambda "github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/cshep4/kripto/services/trade-decider/internal/handler/aws"
+	"github.com/cshep4/kripto/services/trade-decider/internal/rate"
+	"github.com/cshep4/kripto/services/trade-decider/internal/service"
+	"github.com/cshep4/kripto/services/trade-decider/internal/strategy"
+	"github.com/cshep4/kripto/services/trade-decider/internal/trade"
+	"github.com/cshep4/kripto/services/trade-decider/internal/wallet"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/cshep4/kripto/shared/go/lambda"
+	"github.com/cshep4/kripto/shared/go/log"
+)
+
+const (
+	logLevel     = "info"
+	serviceName  = "trade-decider"
+	functionName = "trade-decider"
+)
+
+type config struct {
+	Region                string `env:"REGION"`
+	ReaderFunctionName    string `env:"READER_FUNCTION_NAME"`
+	GetWalletFunctionName string `env:"GET_WALLET_FUNCTION_NAME"`
+	TraderFunctionName    string `env:"TRADER_FUNCTION_NAME"`
+	Strategy              string `env:"STRATEGY"`
+}
+
+var (
+	cfg = lambda.FunctionConfig{
+		LogLevel:     logLevel,
+		ServiceName:  serviceName,
+		FunctionName: functionName,
+		Setup:        setup,
+		Initialised:  func() bool { return handler.Service != nil },
+	}
+
+	handler aws.Handler
+
+	runner = lambda.New(
+		handler.Decide,
+		lambda.WithPreExecute(log.Middleware(logLevel, serviceName, functionName)),
+		lambda.WithErrorResponse(apperror.Wrap),
+	)
+)
+
+func main() {
+	runner.Start(cfg)
+}
+
+func setup(ctx context.Context) error {
+	var c config
+	if _, err := env.UnmarshalFromEnviron(&c); err != nil {
+		return fmt.Errorf("unmarshal_environment_variables: %w", err)
+	}
+
+	s, err := strategy.New(c.Strategy)
+	if err != nil {
+		return fmt.Errorf("initialise_strategy: %w", err)
+	}
+
+	sess, err := session.NewSession(&awsconfig.Config{
+		Region: &c.Region,
+	})
+	if err != nil {
+		return fmt.Errorf("new_session: %w", err)
+	}
+	invoker := awslambda.New(sess)
+
+	rates, err := rate.New(invoker, c.ReaderFunctionName)
+	if err != nil {
+		return fmt.Errorf("initialise_rate_getter: %w", err)
+	}
+
+	balances, err := wallet.New(invoker, c.GetWalletFunctionName)
+	if err != nil {
+		return fmt.Errorf("initialise_wallet_getter: %w", err)
+	}
+
+	trader, err := trade.New(invoker, c.TraderFunctionName)
+	if err != nil {
+		return fmt.Errorf("initialise_trader: %w", err)
+	}
+
+	handler.Service, err = service.New(rates, balances, trader, s)
+	if err != nil {
+		return fmt.Errorf("initialise_service: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,10 @@
+package decider
+
+//go:generate mockgen -destination=internal/mocks/service/servicer.gen.go -package=service_mocks github.com/cshep4/kripto/services/trade-decider/internal/handler/aws Servicer
+//go:generate mockgen -destination=internal/mocks/rate/getter.gen.go -package=rate_mocks github.com/cshep4/kripto/services/trade-decider/internal/service RateGetter
+//go:generate mockgen -destination=internal/mocks/wallet/getter.gen.go -package=wallet_mocks github.com/cshep4/kripto/services/trade-decider/internal/service BalanceGetter
+//go:generate mockgen -destination=internal/mocks/trade/trader.gen.go -package=trade_mocks github.com/cshep4/kripto/services/trade-decider/internal/service Trader
+//go:generate mockgen -destination=internal/mocks/strategy/strategy.gen.go -package=strategy_mocks github.com/cshep4/kripto/services/trade-decider/internal/service Strategy
+//go:generate mockgen -destination=internal/mocks/rate/invoker.gen.go -package=rate_mocks github.com/cshep4/kripto/services/trade-decider/internal/rate Invoker
+//go:generate mockgen -destination=internal/mocks/wallet/invoker.gen.go -package=wallet_mocks github.com/cshep4/kripto/services/trade-decider/internal/wallet Invoker
+//go:generate mockgen -destination=internal/mocks/trade/invoker.gen.go -package=trade_mocks github.com/cshep4/kripto/services/trade-decider/internal/trade Invoker
@@ -0,0 +1,267 @@
+// Package backtest replays historic rates through a strategy, trading on a
+// simulated exchange, to see how the strategy would have performed.
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/cshep4/kripto/services/trade-decider/internal/trade"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// The trade-decider runs every two minutes against the previous month's rates.
+	defaultInterval = 2 * time.Minute
+	defaultWindow   = 30 * 24 * time.Hour
+
+	year = 365 * 24 * time.Hour
+)
+
+var (
+	defaultFeeRate = decimal.New(5, -3)
+	defaultBalance = model.Balance{Base: decimal.Zero, Quote: decimal.New(1000, 0)}
+)
+
+// ErrNotEnoughRates is returned when there are too few rates to run a backtest.
+var ErrNotEnoughRates = errors.New("not enough rates")
+
+type (
+	// Strategy decides whether to trade from the rates, oldest first, and the
+	// available balances.
+	Strategy interface {
+		Decide(rates []model.Rate, balance model.Balance) (model.Decision, bool)
+	}
+
+	backtester struct {
+		strategy Strategy
+		feeRate  decimal.Decimal
+		balance  model.Balance
+		interval time.Duration
+		window   time.Duration
+	}
+
+	// Report is the result of a backtest. Values are in the quote currency.
+	Report struct {
+		Start    time.Time `json:"start"`
+		End      time.Time `json:"end"`
+		Rates    int       `json:"rates"`
+		Trades   []Trade   `json:"trades"`
+		Rejected int       `json:"rejected"` // Trades the balance couldn't cover.
+
+		StartBalance model.Balance   `json:"startBalance"`
+		EndBalance   model.Balance   `json:"endBalance"`
+		StartValue   decimal.Decimal `json:"startValue"`
+		EndValue     decimal.Decimal `json:"endValue"`
+		Fees         decimal.Decimal `json:"fees"`
+
+		// PnL is the change in value, and Return the change as a fraction of the start value.
+		PnL    decimal.Decimal `json:"pnl"`
+		Return float64         `json:"return"`
+		// HoldReturn is the return of holding the starting balances, to compare the strategy against.
+		HoldReturn float64 `json:"holdReturn"`
+		// MaxDrawdown is the largest fall in value from a peak, as a fraction of the peak.
+		MaxDrawdown float64 `json:"maxDrawdown"`
+		// Sharpe is the annualised Sharpe ratio of the returns between each decision, with no risk-free rate.
+		Sharpe float64 `json:"sharpe"`
+		// WinRate is the fraction of sells which made a profit.
+		WinRate float64 `json:"winRate"`
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func New(strategy Strategy, opts ...Option) (*backtester, error) {
+	if strategy == nil {
+		return nil, InvalidParameterError{Parameter: "strategy"}
+	}
+
+	b := &backtester{
+		strategy: strategy,
+		feeRate:  defaultFeeRate,
+		balance:  defaultBalance,
+		interval: defaultInterval,
+		window:   defaultWindow,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b, nil
+}
+
+// Run replays the rates, deciding every interval from the first rate using the
+// rates in the window before it, the same as the trade-decider. Decisions near
+// the start see fewer rates, as if the trade-decider had just been deployed.
+// Trades are filled at the latest rate, and orders are raised to the minimum
+// amount of the trade function.
+func (b *backtester) Run(rates []model.Rate) (*Report, error) {
+	if len(rates) < 2 {
+		return nil, ErrNotEnoughRates
+	}
+
+	rates = append([]model.Rate(nil), rates...)
+	sort.SliceStable(rates, func(i, j int) bool {
+		return rates[i].DateTime.Before(rates[j].DateTime)
+	})
+
+	var (
+		first = decimal.NewFromFloat(rates[0].Rate)
+		last  = rates[len(rates)-1]
+
+		ex     = newExchange(b.balance, b.feeRate, first)
+		report = &Report{
+			Start:        rates[0].DateTime,
+			End:          last.DateTime,
+			Rates:        len(rates),
+			StartBalance: b.balance,
+			StartValue:   ex.value(first),
+			Fees:         decimal.Zero,
+		}
+
+		values   []float64
+		from, to int
+	)
+
+	for t := rates[0].DateTime; !t.After(last.DateTime); t = t.Add(b.interval) {
+		for to+1 < len(rates) && !rates[to+1].DateTime.After(t) {
+			to++
+		}
+		for !rates[from].DateTime.After(t.Add(-b.window)) && from < to {
+			from++
+		}
+
+		price := decimal.NewFromFloat(rates[to].Rate)
+
+		if decision, ok := b.strategy.Decide(rates[from:to+1], ex.balance); ok {
+			tr, err := b.trade(ex, decision, price)
+			switch {
+			case errors.Is(err, ErrInsufficientFunds):
+				report.Rejected++
+			case err != nil:
+				return nil, err
+			default:
+				tr.Time = rates[to].DateTime
+				report.Trades = append(report.Trades, tr)
+				report.Fees = report.Fees.Add(tr.Fee)
+			}
+		}
+
+		v, _ := ex.value(price).Float64()
+		values = append(values, v)
+	}
+
+	lastPrice := decimal.NewFromFloat(last.Rate)
+	report.EndBalance = ex.balance
+	report.EndValue = ex.value(lastPrice)
+	report.PnL = report.EndValue.Sub(report.StartValue)
+	report.Return = ratio(report.PnL, report.StartValue)
+	report.HoldReturn = ratio(newExchange(b.balance, b.feeRate, first).value(lastPrice).Sub(report.StartValue), report.StartValue)
+	report.MaxDrawdown = maxDrawdown(values)
+	report.Sharpe = sharpe(values, b.interval)
+	report.WinRate = winRate(report.Trades)
+
+	return report, nil
+}
+
+func (b *backtester) trade(ex *exchange, decision model.Decision, price decimal.Decimal) (Trade, error) {
+	amount := decision.Amount
+	if amount.LessThan(trade.MinAmount) {
+		amount = trade.MinAmount
+	}
+
+	switch decision.TradeType {
+	case model.Buy:
+		return ex.buy(amount, price)
+	case model.Sell:
+		return ex.sell(amount, price)
+	default:
+		return Trade{}, fmt.Errorf("invalid trade type %q", decision.TradeType)
+	}
+}
+
+func ratio(a, b decimal.Decimal) float64 {
+	if b.IsZero() {
+		return 0
+	}
+
+	r, _ := a.Div(b).Float64()
+	return r
+}
+
+// maxDrawdown returns the largest fall from a peak value to a later value, as a
+// fraction of the peak.
+func maxDrawdown(values []float64) float64 {
+	var peak, drawdown float64
+	for _, v := range values {
+		if v > peak {
+			peak = v
+		}
+		if peak > 0 {
+			drawdown = math.Max(drawdown, (peak-v)/peak)
+		}
+	}
+
+	return drawdown
+}
+
+// sharpe returns the mean return between values divided by the standard
+// deviation of the returns, annualised using the interval between values.
+func sharpe(values []float64, interval time.Duration) float64 {
+	var returns []float64
+	for i := 1; i < len(values); i++ {
+		if values[i-1] != 0 {
+			returns = append(returns, values[i]/values[i-1]-1)
+		}
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(returns)-1))
+	if stdDev == 0 {
+		return 0
+	}
+
+	return mean / stdDev * math.Sqrt(float64(year)/float64(interval))
+}
+
+func winRate(trades []Trade) float64 {
+	var sells, wins int
+	for _, t := range trades {
+		if t.TradeType != model.Sell {
+			continue
+		}
+		sells++
+		if t.Profit.IsPositive() {
+			wins++
+		}
+	}
+	if sells == 0 {
+		return 0
+	}
+
+	return float64(wins) / float64(sells)
+}
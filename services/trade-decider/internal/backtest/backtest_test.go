@@ -0,0 +1,227 @@
+package backtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/backtest"
+	"github.com/cshep4/kripto/services/trade-decider/internal/mocks/strategy"
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2020, 5, 19, 19, 0, 0, 0, time.UTC)
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if strategy is empty", func(t *testing.T) {
+		b, err := backtest.New(nil)
+		require.Error(t, err)
+
+		assert.Nil(t, b)
+
+		ipErr, ok := err.(backtest.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "strategy", ipErr.Parameter)
+	})
+
+	t.Run("returns backtester", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		b, err := backtest.New(strategy_mocks.NewMockStrategy(ctrl))
+		require.NoError(t, err)
+
+		assert.NotNil(t, b)
+	})
+}
+
+func TestBacktester_Run(t *testing.T) {
+	t.Run("returns error if there are not enough rates", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		b, err := backtest.New(strategy_mocks.NewMockStrategy(ctrl))
+		require.NoError(t, err)
+
+		report, err := b.Run([]model.Rate{{Rate: 100, DateTime: start}})
+		require.Error(t, err)
+
+		assert.Nil(t, report)
+		assert.Equal(t, backtest.ErrNotEnoughRates, err)
+	})
+
+	t.Run("returns report with no trades if strategy never trades", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := strategy_mocks.NewMockStrategy(ctrl)
+
+		b, err := backtest.New(s,
+			backtest.WithBalance(model.Balance{Base: decimal.New(1, 0), Quote: decimal.New(100, 0)}),
+			backtest.WithInterval(time.Minute),
+		)
+		require.NoError(t, err)
+
+		s.EXPECT().Decide(gomock.Any(), gomock.Any()).Return(model.Decision{}, false).Times(3)
+
+		report, err := b.Run([]model.Rate{
+			{Rate: 150, DateTime: start.Add(2 * time.Minute)},
+			{Rate: 100, DateTime: start},
+			{Rate: 50, DateTime: start.Add(time.Minute)},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, start, report.Start)
+		assert.Equal(t, start.Add(2*time.Minute), report.End)
+		assert.Equal(t, 3, report.Rates)
+		assert.Empty(t, report.Trades)
+		assert.True(t, report.EndBalance.Base.Equal(decimal.New(1, 0)))
+		assert.True(t, report.EndBalance.Quote.Equal(decimal.New(100, 0)))
+		assert.True(t, report.StartValue.Equal(decimal.New(200, 0)))
+		assert.True(t, report.EndValue.Equal(decimal.New(250, 0)))
+		assert.True(t, report.PnL.Equal(decimal.New(50, 0)))
+		assert.InDelta(t, 0.25, report.Return, 1e-9)
+		assert.InDelta(t, 0.25, report.HoldReturn, 1e-9)
+		assert.InDelta(t, 0.25, report.MaxDrawdown, 1e-9)
+	})
+
+	t.Run("returns report of trades filled at the latest rate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := strategy_mocks.NewMockStrategy(ctrl)
+
+		b, err := backtest.New(s)
+		require.NoError(t, err)
+
+		gomock.InOrder(
+			s.EXPECT().Decide(gomock.Any(), model.Balance{Base: decimal.Zero, Quote: decimal.New(1000, 0)}).
+				Return(model.Decision{TradeType: model.Buy, Amount: decimal.New(100, 0)}, true),
+			s.EXPECT().Decide(gomock.Any(), gomock.Any()).
+				Return(model.Decision{TradeType: model.Sell, Amount: decimal.New(199, 0)}, true),
+		)
+
+		report, err := b.Run([]model.Rate{
+			{Rate: 100, DateTime: start},
+			{Rate: 200, DateTime: start.Add(2 * time.Minute)},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, report.Trades, 2)
+
+		buy := report.Trades[0]
+		assert.Equal(t, start, buy.Time)
+		assert.Equal(t, model.Buy, buy.TradeType)
+		assert.True(t, buy.Price.Equal(decimal.New(100, 0)))
+		assert.True(t, buy.Size.Equal(decimal.RequireFromString("0.995")))
+		assert.True(t, buy.Value.Equal(decimal.RequireFromString("99.5")))
+		assert.True(t, buy.Fee.Equal(decimal.RequireFromString("0.5")))
+
+		sell := report.Trades[1]
+		assert.Equal(t, start.Add(2*time.Minute), sell.Time)
+		assert.Equal(t, model.Sell, sell.TradeType)
+		assert.True(t, sell.Price.Equal(decimal.New(200, 0)))
+		assert.True(t, sell.Size.Equal(decimal.RequireFromString("0.995")))
+		assert.True(t, sell.Value.Equal(decimal.New(199, 0)))
+		assert.True(t, sell.Fee.Equal(decimal.RequireFromString("0.995")))
+		assert.True(t, sell.Profit.Equal(decimal.RequireFromString("98.005")))
+
+		assert.Zero(t, report.Rejected)
+		assert.True(t, report.EndBalance.Base.IsZero())
+		assert.True(t, report.EndBalance.Quote.Equal(decimal.RequireFromString("1098.005")))
+		assert.True(t, report.EndValue.Equal(decimal.RequireFromString("1098.005")))
+		assert.True(t, report.Fees.Equal(decimal.RequireFromString("1.495")))
+		assert.True(t, report.PnL.Equal(decimal.RequireFromString("98.005")))
+		assert.InDelta(t, 0.098005, report.Return, 1e-9)
+		assert.Zero(t, report.HoldReturn)
+		assert.Zero(t, report.MaxDrawdown)
+		assert.Equal(t, float64(1), report.WinRate)
+	})
+
+	t.Run("raises orders to the minimum amount", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := strategy_mocks.NewMockStrategy(ctrl)
+
+		b, err := backtest.New(s, backtest.WithFeeRate(decimal.Zero))
+		require.NoError(t, err)
+
+		gomock.InOrder(
+			s.EXPECT().Decide(gomock.Any(), gomock.Any()).Return(model.Decision{TradeType: model.Buy, Amount: decimal.New(1, 0)}, true),
+			s.EXPECT().Decide(gomock.Any(), gomock.Any()).Return(model.Decision{}, false),
+		)
+
+		report, err := b.Run([]model.Rate{
+			{Rate: 100, DateTime: start},
+			{Rate: 100, DateTime: start.Add(2 * time.Minute)},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, report.Trades, 1)
+		assert.True(t, report.Trades[0].Value.Equal(decimal.New(10, 0)))
+		assert.True(t, report.EndBalance.Quote.Equal(decimal.New(990, 0)))
+	})
+
+	t.Run("counts trades the balance can't cover as rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := strategy_mocks.NewMockStrategy(ctrl)
+
+		b, err := backtest.New(s)
+		require.NoError(t, err)
+
+		gomock.InOrder(
+			s.EXPECT().Decide(gomock.Any(), gomock.Any()).Return(model.Decision{TradeType: model.Sell, Amount: decimal.New(100, 0)}, true),
+			s.EXPECT().Decide(gomock.Any(), gomock.Any()).Return(model.Decision{TradeType: model.Buy, Amount: decimal.New(1001, 0)}, true),
+		)
+
+		report, err := b.Run([]model.Rate{
+			{Rate: 100, DateTime: start},
+			{Rate: 100, DateTime: start.Add(2 * time.Minute)},
+		})
+		require.NoError(t, err)
+
+		assert.Empty(t, report.Trades)
+		assert.Equal(t, 2, report.Rejected)
+		assert.True(t, report.PnL.IsZero())
+	})
+
+	t.Run("passes the rates in the window to the strategy", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := strategy_mocks.NewMockStrategy(ctrl)
+
+		b, err := backtest.New(s,
+			backtest.WithInterval(time.Minute),
+			backtest.WithWindow(2*time.Minute),
+		)
+		require.NoError(t, err)
+
+		rates := make([]model.Rate, 4)
+		for i := range rates {
+			rates[i] = model.Rate{Rate: float64(100 + i), DateTime: start.Add(time.Duration(i) * time.Minute)}
+		}
+
+		var windows [][]model.Rate
+		s.EXPECT().Decide(gomock.Any(), gomock.Any()).DoAndReturn(func(rates []model.Rate, _ model.Balance) (model.Decision, bool) {
+			windows = append(windows, rates)
+			return model.Decision{}, false
+		}).Times(4)
+
+		_, err = b.Run(rates)
+		require.NoError(t, err)
+
+		assert.Equal(t, [][]model.Rate{
+			rates[0:1],
+			rates[0:2],
+			rates[1:3],
+			rates[2:4],
+		}, windows)
+	})
+}
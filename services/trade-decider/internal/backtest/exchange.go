@@ -0,0 +1,95 @@
+package backtest
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// ErrInsufficientFunds is returned when the balance can't cover a trade.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+type (
+	// exchange fills market orders in full at the rate, charging a fee on the
+	// value of each fill.
+	exchange struct {
+		balance model.Balance
+		feeRate decimal.Decimal
+		// cost is what the base balance cost, including fees, so the profit of
+		// each sell can be worked out from the average cost of the base sold.
+		cost decimal.Decimal
+	}
+
+	// Trade is a trade filled by the simulated exchange.
+	Trade struct {
+		Time      time.Time       `json:"time"`
+		TradeType model.TradeType `json:"tradeType"`
+		Price     decimal.Decimal `json:"price"`
+		Size      decimal.Decimal `json:"size"`   // Base currency bought or sold.
+		Value     decimal.Decimal `json:"value"`  // Quote currency value of the size, excluding the fee.
+		Fee       decimal.Decimal `json:"fee"`    // Quote currency.
+		Profit    decimal.Decimal `json:"profit"` // Sells only, proceeds less the average cost of the size sold.
+	}
+)
+
+func newExchange(balance model.Balance, feeRate decimal.Decimal, price decimal.Decimal) *exchange {
+	return &exchange{
+		balance: balance,
+		feeRate: feeRate,
+		cost:    balance.Base.Mul(price),
+	}
+}
+
+// buy spends amount of the quote currency, including the fee.
+func (e *exchange) buy(amount, price decimal.Decimal) (Trade, error) {
+	if amount.GreaterThan(e.balance.Quote) {
+		return Trade{}, ErrInsufficientFunds
+	}
+
+	fee := amount.Mul(e.feeRate)
+	value := amount.Sub(fee)
+	size := value.Div(price)
+
+	e.balance.Quote = e.balance.Quote.Sub(amount)
+	e.balance.Base = e.balance.Base.Add(size)
+	e.cost = e.cost.Add(amount)
+
+	return Trade{
+		TradeType: model.Buy,
+		Price:     price,
+		Size:      size,
+		Value:     value,
+		Fee:       fee,
+	}, nil
+}
+
+// sell sells amount worth of the base currency, the fee is taken from the proceeds.
+func (e *exchange) sell(amount, price decimal.Decimal) (Trade, error) {
+	size := amount.Div(price)
+	if size.GreaterThan(e.balance.Base) {
+		return Trade{}, ErrInsufficientFunds
+	}
+
+	fee := amount.Mul(e.feeRate)
+	cost := e.cost.Mul(size).Div(e.balance.Base)
+
+	e.balance.Base = e.balance.Base.Sub(size)
+	e.balance.Quote = e.balance.Quote.Add(amount.Sub(fee))
+	e.cost = e.cost.Sub(cost)
+
+	return Trade{
+		TradeType: model.Sell,
+		Price:     price,
+		Size:      size,
+		Value:     amount,
+		Fee:       fee,
+		Profit:    amount.Sub(fee).Sub(cost),
+	}, nil
+}
+
+// value returns the total value of the balances in the quote currency.
+func (e *exchange) value(price decimal.Decimal) decimal.Decimal {
+	return e.balance.Quote.Add(e.balance.Base.Mul(price))
+}
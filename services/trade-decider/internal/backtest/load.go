@@ -0,0 +1,166 @@
+package backtest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+)
+
+type (
+	// exportedRate is a rate in a JSON export, either the response of the
+	// data-reader function or a mongoexport of the rate collection, where
+	// dates are extended JSON, e.g. {"$date": "2020-05-19T19:39:00Z"}.
+	exportedRate struct {
+		Rate     float64         `json:"rate"`
+		DateTime json.RawMessage `json:"dateTime"`
+	}
+
+	extendedDate struct {
+		Date json.RawMessage `json:"$date"`
+	}
+)
+
+// LoadJSON reads rates from a JSON array, or from one JSON object per line as
+// written by mongoexport without --jsonArray.
+func LoadJSON(r io.Reader) ([]model.Rate, error) {
+	br := bufio.NewReader(r)
+	array, err := startsWithArray(br)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(br)
+	if array {
+		if _, err := dec.Token(); err != nil {
+			return nil, fmt.Errorf("read_token: %w", err)
+		}
+	}
+
+	var rates []model.Rate
+	for dec.More() {
+		var e exportedRate
+		if err := dec.Decode(&e); err != nil {
+			return nil, fmt.Errorf("decode: %w", err)
+		}
+
+		t, err := parseDate(e.DateTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dateTime %s: %w", e.DateTime, err)
+		}
+
+		rates = append(rates, model.Rate{Rate: e.Rate, DateTime: t.UTC()})
+	}
+
+	return rates, nil
+}
+
+func startsWithArray(r *bufio.Reader) (bool, error) {
+	for {
+		c, _, err := r.ReadRune()
+		switch {
+		case err == io.EOF:
+			return false, nil
+		case err != nil:
+			return false, fmt.Errorf("read: %w", err)
+		case unicode.IsSpace(c):
+			continue
+		}
+
+		if err := r.UnreadRune(); err != nil {
+			return false, fmt.Errorf("unread: %w", err)
+		}
+		return c == '[', nil
+	}
+}
+
+// parseDate parses an RFC 3339 date, or an extended JSON date which is either
+// RFC 3339 or milliseconds since the epoch.
+func parseDate(raw json.RawMessage) (time.Time, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return time.Parse(time.RFC3339, s)
+	}
+
+	var d extendedDate
+	if err := json.Unmarshal(raw, &d); err != nil || d.Date == nil {
+		return time.Time{}, errors.New("expected string or $date")
+	}
+	if err := json.Unmarshal(d.Date, &s); err == nil {
+		return time.Parse(time.RFC3339, s)
+	}
+
+	var ms struct {
+		NumberLong string `json:"$numberLong"`
+	}
+	var n int64
+	switch {
+	case json.Unmarshal(d.Date, &n) == nil:
+	case json.Unmarshal(d.Date, &ms) == nil && ms.NumberLong != "":
+		var err error
+		if n, err = strconv.ParseInt(ms.NumberLong, 10, 64); err != nil {
+			return time.Time{}, err
+		}
+	default:
+		return time.Time{}, errors.New("invalid $date")
+	}
+
+	return time.Unix(0, n*int64(time.Millisecond)).UTC(), nil
+}
+
+// LoadCSV reads rates from CSV with a header row, which must have dateTime and
+// rate columns. Dates are RFC 3339 and other columns are ignored.
+func LoadCSV(r io.Reader) ([]model.Rate, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read_header: %w", err)
+	}
+
+	dateCol, rateCol := -1, -1
+	for i, h := range header {
+		switch strings.TrimSpace(h) {
+		case "dateTime":
+			dateCol = i
+		case "rate":
+			rateCol = i
+		}
+	}
+	if dateCol < 0 || rateCol < 0 {
+		return nil, errors.New("header must have dateTime and rate columns")
+	}
+
+	var rates []model.Rate
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read: %w", err)
+		}
+
+		t, err := time.Parse(time.RFC3339, record[dateCol])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid dateTime: %w", line, err)
+		}
+		rate, err := strconv.ParseFloat(record[rateCol], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate: %w", line, err)
+		}
+
+		rates = append(rates, model.Rate{Rate: rate, DateTime: t.UTC()})
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,100 @@
+package backtest_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/backtest"
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadJSON(t *testing.T) {
+	expected := []model.Rate{
+		{Rate: 8012.91, DateTime: time.Date(2020, 5, 19, 19, 39, 0, 0, time.UTC)},
+		{Rate: 8012.92, DateTime: time.Date(2020, 5, 19, 19, 41, 0, 0, time.UTC)},
+	}
+
+	t.Run("returns rates from json array", func(t *testing.T) {
+		rates, err := backtest.LoadJSON(strings.NewReader(`
+			[
+				{"rate": 8012.91, "dateTime": "2020-05-19T19:39:00Z"},
+				{"rate": 8012.92, "dateTime": "2020-05-19T20:41:00+01:00"}
+			]`,
+		))
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, rates)
+	})
+
+	t.Run("returns rates from mongoexport", func(t *testing.T) {
+		rates, err := backtest.LoadJSON(strings.NewReader(
+			`{"_id":{"$oid":"5ec4367c0000000000000000"},"rate":8012.91,"dateTime":{"$date":"2020-05-19T19:39:00Z"}}
+{"_id":{"$oid":"5ec4367c0000000000000001"},"rate":8012.92,"dateTime":{"$date":{"$numberLong":"1589917260000"}}}`,
+		))
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, rates)
+	})
+
+	t.Run("returns rates with dates in milliseconds", func(t *testing.T) {
+		rates, err := backtest.LoadJSON(strings.NewReader(`[{"rate":8012.91,"dateTime":{"$date":1589917140000}}]`))
+		require.NoError(t, err)
+
+		assert.Equal(t, expected[:1], rates)
+	})
+
+	t.Run("returns no rates if empty", func(t *testing.T) {
+		rates, err := backtest.LoadJSON(strings.NewReader(" "))
+		require.NoError(t, err)
+
+		assert.Empty(t, rates)
+	})
+
+	t.Run("returns error if json is invalid", func(t *testing.T) {
+		rates, err := backtest.LoadJSON(strings.NewReader(`[{"rate":}]`))
+		require.Error(t, err)
+
+		assert.Nil(t, rates)
+	})
+
+	t.Run("returns error if dateTime is invalid", func(t *testing.T) {
+		rates, err := backtest.LoadJSON(strings.NewReader(`[{"rate":8012.91,"dateTime":{"$date":true}}]`))
+		require.Error(t, err)
+
+		assert.Nil(t, rates)
+	})
+}
+
+func TestLoadCSV(t *testing.T) {
+	t.Run("returns rates", func(t *testing.T) {
+		rates, err := backtest.LoadCSV(strings.NewReader(
+			"_id,rate,dateTime\n" +
+				"5ec4367c0000000000000000,8012.91,2020-05-19T19:39:00Z\n" +
+				"5ec4367c0000000000000001, 8012.92, 2020-05-19T20:41:00+01:00\n",
+		))
+		require.NoError(t, err)
+
+		assert.Equal(t, []model.Rate{
+			{Rate: 8012.91, DateTime: time.Date(2020, 5, 19, 19, 39, 0, 0, time.UTC)},
+			{Rate: 8012.92, DateTime: time.Date(2020, 5, 19, 19, 41, 0, 0, time.UTC)},
+		}, rates)
+	})
+
+	t.Run("returns error if header is missing a column", func(t *testing.T) {
+		rates, err := backtest.LoadCSV(strings.NewReader("dateTime,price\n2020-05-19T19:39:00Z,8012.91\n"))
+		require.Error(t, err)
+
+		assert.Nil(t, rates)
+	})
+
+	t.Run("returns error with line if rate is invalid", func(t *testing.T) {
+		rates, err := backtest.LoadCSV(strings.NewReader("dateTime,rate\n2020-05-19T19:39:00Z,8012.91\n2020-05-19T19:41:00Z,abc\n"))
+		require.Error(t, err)
+
+		assert.Nil(t, rates)
+		assert.Contains(t, err.Error(), "line 3")
+	})
+}
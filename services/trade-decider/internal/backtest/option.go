@@ -0,0 +1,46 @@
+package backtest
+
+import (
+	"time"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+type Option func(*backtester)
+
+// WithFeeRate sets the fee charged on each trade as a fraction of its value, e.g. 0.005.
+func WithFeeRate(feeRate decimal.Decimal) Option {
+	return func(b *backtester) {
+		if !feeRate.IsNegative() && feeRate.LessThan(decimal.New(1, 0)) {
+			b.feeRate = feeRate
+		}
+	}
+}
+
+// WithBalance sets the starting balances.
+func WithBalance(balance model.Balance) Option {
+	return func(b *backtester) {
+		if !balance.Base.IsNegative() && !balance.Quote.IsNegative() {
+			b.balance = balance
+		}
+	}
+}
+
+// WithInterval sets how often the strategy decides whether to trade.
+func WithInterval(interval time.Duration) Option {
+	return func(b *backtester) {
+		if interval > 0 {
+			b.interval = interval
+		}
+	}
+}
+
+// WithWindow sets how far back the rates passed to the strategy go.
+func WithWindow(window time.Duration) Option {
+	return func(b *backtester) {
+		if window > 0 {
+			b.window = window
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/cshep4/kripto/shared/go/log"
+)
+
+const scheduleFormat = "2006-01-02T15:04Z"
+
+type (
+	Servicer interface {
+		Decide(ctx context.Context, idempotencyKey string) (*model.Decision, error)
+	}
+
+	Handler struct {
+		Service Servicer
+	}
+)
+
+// Decide decides whether to trade. The idempotency key is derived from the time
+// the event was scheduled for, so a retried invocation or a repeated delivery of
+// the event doesn't trade twice. An invocation which isn't scheduled, e.g. one
+// made by hand, uses the Lambda request ID instead.
+func (h *Handler) Decide(ctx context.Context, event events.CloudWatchEvent) error {
+	key, err := idempotencyKey(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	decision, err := h.Service.Decide(ctx, key)
+	if err != nil {
+		log.Error(ctx, "error_deciding_trade", log.ErrorParam(err))
+		return fmt.Errorf("decide: %w", err)
+	}
+	if decision != nil {
+		log.Info(ctx, "trade_decided",
+			log.SafeParam("tradeType", decision.TradeType),
+			log.SafeParam("amount", decision.Amount),
+		)
+	}
+
+	return nil
+}
+
+func idempotencyKey(ctx context.Context, event events.CloudWatchEvent) (string, error) {
+	if !event.Time.IsZero() {
+		return IdempotencyKey(event.Time), nil
+	}
+
+	lc, ok := lambdacontext.FromContext(ctx)
+	if !ok || lc.AwsRequestID == "" {
+		return "", errors.New("missing_request_id")
+	}
+
+	return lc.AwsRequestID, nil
+}
+
+// IdempotencyKey returns the idempotency key of the run scheduled for the time,
+// which is the same every time the run is invoked.
+func IdempotencyKey(scheduled time.Time) string {
+	return fmt.Sprintf("trade-decider-%s", scheduled.UTC().Format(scheduleFormat))
+}
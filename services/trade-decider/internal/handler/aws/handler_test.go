@@ -0,0 +1,98 @@
+package aws_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/cshep4/kripto/services/trade-decider/internal/handler/aws"
+	"github.com/cshep4/kripto/services/trade-decider/internal/mocks/service"
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const requestId = "request-id"
+
+var event = events.CloudWatchEvent{
+	DetailType: "Scheduled Event",
+	Source:     "aws.events",
+	Time:       time.Date(2021, 3, 1, 12, 2, 0, 0, time.UTC),
+}
+
+func TestHandler_Decide(t *testing.T) {
+	t.Run("returns error if event time and request id are missing", func(t *testing.T) {
+		handler := aws.Handler{}
+
+		err := handler.Decide(context.Background(), events.CloudWatchEvent{})
+		require.Error(t, err)
+	})
+
+	t.Run("returns error if error deciding trade", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: requestId})
+		testErr := errors.New("error")
+
+		servicer := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: servicer}
+
+		servicer.EXPECT().Decide(ctx, "trade-decider-2021-03-01T12:02Z").Return(nil, testErr)
+
+		err := handler.Decide(ctx, event)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("decides trade using scheduled time as idempotency key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: requestId})
+
+		servicer := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: servicer}
+
+		servicer.EXPECT().Decide(ctx, "trade-decider-2021-03-01T12:02Z").Return(&model.Decision{TradeType: model.Buy, Amount: decimal.New(150, 0)}, nil)
+
+		err := handler.Decide(ctx, event)
+		require.NoError(t, err)
+	})
+
+	t.Run("decides trade using request id as idempotency key if event isn't scheduled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: requestId})
+
+		servicer := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: servicer}
+
+		servicer.EXPECT().Decide(ctx, requestId).Return(nil, nil)
+
+		err := handler.Decide(ctx, events.CloudWatchEvent{})
+		require.NoError(t, err)
+	})
+
+	t.Run("returns nil if no trade is decided", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: requestId})
+
+		servicer := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: servicer}
+
+		servicer.EXPECT().Decide(ctx, "trade-decider-2021-03-01T12:02Z").Return(nil, nil)
+
+		err := handler.Decide(ctx, event)
+		require.NoError(t, err)
+	})
+}
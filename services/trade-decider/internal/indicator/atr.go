@@ -0,0 +1,30 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+)
+
+// ATR is Wilder's average true range over period candles. It can be
+// calculated once period candles have been added.
+type ATR struct {
+	average   *wilder
+	prevClose float64
+	primed    bool
+}
+
+func NewATR(period int) *ATR {
+	return &ATR{average: newWilder(period)}
+}
+
+func (a *ATR) Add(c model.Candle) (float64, bool) {
+	// The first candle has no previous close, so its true range is its range.
+	tr := c.High - c.Low
+	if a.primed {
+		tr = math.Max(tr, math.Max(math.Abs(c.High-a.prevClose), math.Abs(c.Low-a.prevClose)))
+	}
+	a.prevClose, a.primed = c.Close, true
+
+	return a.average.add(tr)
+}
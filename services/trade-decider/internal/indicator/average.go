@@ -0,0 +1,100 @@
+package indicator
+
+// SMA is the simple moving average of the last period values.
+type SMA struct {
+	window *window
+}
+
+func NewSMA(period int) *SMA {
+	return &SMA{window: newWindow(period)}
+}
+
+func (s *SMA) Add(v float64) (float64, bool) {
+	s.window.add(v)
+	if !s.window.full() {
+		return 0, false
+	}
+
+	return s.window.mean(), true
+}
+
+// EMA is the exponential moving average over period values, weighting each
+// value by 2/(period+1). It starts from the simple average of the first period
+// values, so it can be calculated once period values have been added.
+type EMA struct {
+	alpha  float64
+	seed   *SMA
+	value  float64
+	seeded bool
+}
+
+func NewEMA(period int) *EMA {
+	period = minPeriod(period)
+
+	return &EMA{
+		alpha: 2 / (float64(period) + 1),
+		seed:  NewSMA(period),
+	}
+}
+
+func (e *EMA) Add(v float64) (float64, bool) {
+	if !e.seeded {
+		e.value, e.seeded = e.seed.Add(v)
+		return e.value, e.seeded
+	}
+
+	e.value += e.alpha * (v - e.value)
+
+	return e.value, true
+}
+
+// AdjustedEMA is the exponential moving average over span values, the same as
+// pandas' ewm(span=span).mean(). Rather than starting from a simple average,
+// the weights of the first values are adjusted so the average isn't biased
+// towards the first value, so it can be calculated from the first value.
+type AdjustedEMA struct {
+	decay   float64
+	sum     float64
+	weights float64
+}
+
+func NewAdjustedEMA(span int) *AdjustedEMA {
+	return &AdjustedEMA{decay: 1 - 2/(float64(minPeriod(span))+1)}
+}
+
+func (e *AdjustedEMA) Add(v float64) (float64, bool) {
+	e.sum = v + e.decay*e.sum
+	e.weights = 1 + e.decay*e.weights
+
+	return e.sum / e.weights, true
+}
+
+// wilder is Wilder's moving average, an exponential moving average weighting
+// each value by 1/period, starting from the simple average of the first period
+// values.
+type wilder struct {
+	period float64
+	seed   *SMA
+	value  float64
+	seeded bool
+}
+
+func newWilder(period int) *wilder {
+	period = minPeriod(period)
+
+	return &wilder{
+		period: float64(period),
+		seed:   NewSMA(period),
+	}
+}
+
+func (w *wilder) add(v float64) (float64, bool) {
+	if !w.seeded {
+		w.value, w.seeded = w.seed.Add(v)
+		return w.value, w.seeded
+	}
+
+	w.value = (w.value*(w.period-1) + v) / w.period
+
+	return w.value, true
+}
@@ -0,0 +1,42 @@
+package indicator
+
+type (
+	// Bollinger is the Bollinger bands of the last period values, a number of
+	// standard deviations either side of their simple moving average, commonly
+	// 20 values and 2 standard deviations.
+	Bollinger struct {
+		window *window
+		width  float64
+	}
+
+	Bands struct {
+		Lower  float64
+		Middle float64
+		Upper  float64
+	}
+)
+
+// NewBollinger returns Bollinger bands which are width standard deviations
+// either side of the average. The standard deviation is of the population.
+func NewBollinger(period int, width float64) *Bollinger {
+	return &Bollinger{
+		window: newWindow(period),
+		width:  width,
+	}
+}
+
+func (b *Bollinger) Add(v float64) (Bands, bool) {
+	b.window.add(v)
+	if !b.window.full() {
+		return Bands{}, false
+	}
+
+	mean := b.window.mean()
+	offset := b.width * b.window.stdDev()
+
+	return Bands{
+		Lower:  mean - offset,
+		Middle: mean,
+		Upper:  mean + offset,
+	}, true
+}
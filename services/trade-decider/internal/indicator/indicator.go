@@ -0,0 +1,76 @@
+// Package indicator calculates technical indicators over rates.
+//
+// Each indicator is streaming, values are added one at a time, oldest first,
+// and the latest value of the indicator is returned along with false until
+// enough values have been added for it to be calculated. Periods less than 1
+// are treated as 1.
+package indicator
+
+import (
+	"math"
+	"time"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+)
+
+// Indicator is an indicator calculated from a single value at a time, e.g. a rate.
+type Indicator interface {
+	Add(v float64) (float64, bool)
+}
+
+// Series adds each rate, oldest first, to the indicator and returns its value
+// at each rate. Rates before the indicator can be calculated are NaN, the same
+// as pandas.
+func Series(rates []model.Rate, ind Indicator) []float64 {
+	values := make([]float64, len(rates))
+	for i, r := range rates {
+		v, ok := ind.Add(r.Rate)
+		if !ok {
+			v = math.NaN()
+		}
+		values[i] = v
+	}
+
+	return values
+}
+
+// RollingMean returns the mean of each window of period rates, the same as
+// pandas' rolling(period).mean().
+func RollingMean(rates []model.Rate, period int) []float64 {
+	return Series(rates, NewSMA(period))
+}
+
+// Candles groups the rates, oldest first, into candles of the period. Candles
+// start at multiples of the period since the zero time, e.g. on the hour for
+// hourly candles, and periods without rates are left out.
+func Candles(rates []model.Rate, period time.Duration) []model.Candle {
+	var candles []model.Candle
+	for _, r := range rates {
+		start := r.DateTime.Truncate(period)
+
+		if n := len(candles); n > 0 && candles[n-1].Time.Equal(start) {
+			c := &candles[n-1]
+			c.High = math.Max(c.High, r.Rate)
+			c.Low = math.Min(c.Low, r.Rate)
+			c.Close = r.Rate
+			continue
+		}
+
+		candles = append(candles, model.Candle{
+			Time:  start,
+			Open:  r.Rate,
+			High:  r.Rate,
+			Low:   r.Rate,
+			Close: r.Rate,
+		})
+	}
+
+	return candles
+}
+
+func minPeriod(period int) int {
+	if period < 1 {
+		return 1
+	}
+	return period
+}
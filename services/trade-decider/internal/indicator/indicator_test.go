@@ -0,0 +1,291 @@
+package indicator_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/indicator"
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const delta = 0.005
+
+var (
+	// closes are from the StockCharts moving average example.
+	closes = []float64{
+		22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29,
+		22.15, 22.39, 22.38, 22.61, 23.36, 24.05, 23.75, 23.83, 23.95, 23.63,
+		23.82, 23.87, 23.65, 23.19, 23.10, 23.33, 22.68, 23.10, 22.40, 22.17,
+	}
+
+	// rsiCloses are from the StockCharts RSI example.
+	rsiCloses = []float64{
+		44.3389, 44.0902, 44.1497, 43.6124, 44.3278, 44.8264, 45.0955, 45.4245, 45.8433, 46.0826,
+		45.8931, 46.0328, 45.6140, 46.2820, 46.2820, 46.0028, 46.0328, 46.4116, 46.2222, 45.6439,
+		46.2122, 46.2521, 45.7137, 46.4515, 45.7835, 45.3548, 44.0288, 44.1783, 44.2181, 44.5672,
+		43.4205, 42.6628, 43.1314,
+	}
+)
+
+func TestSMA(t *testing.T) {
+	t.Run("returns false until period values are added", func(t *testing.T) {
+		s := indicator.NewSMA(3)
+
+		_, ok := s.Add(1)
+		assert.False(t, ok)
+		_, ok = s.Add(2)
+		assert.False(t, ok)
+
+		v, ok := s.Add(3)
+		require.True(t, ok)
+		assert.Equal(t, 2.0, v)
+	})
+
+	t.Run("returns average of last period values", func(t *testing.T) {
+		s := indicator.NewSMA(10)
+
+		var v float64
+		for _, c := range closes[:12] {
+			v, _ = s.Add(c)
+		}
+
+		assert.InDelta(t, 22.229, v, 1e-9)
+	})
+}
+
+func TestEMA(t *testing.T) {
+	t.Run("returns StockCharts reference values", func(t *testing.T) {
+		expected := []float64{
+			22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28,
+			23.34, 23.43, 23.51, 23.53, 23.47, 23.40, 23.39, 23.26, 23.23, 23.08, 22.92,
+		}
+
+		e := indicator.NewEMA(10)
+
+		var actual []float64
+		for i, c := range closes {
+			v, ok := e.Add(c)
+			require.Equal(t, i >= 9, ok)
+			if ok {
+				actual = append(actual, v)
+			}
+		}
+
+		assertInDeltaSlice(t, expected, actual)
+	})
+}
+
+func TestAdjustedEMA(t *testing.T) {
+	t.Run("returns pandas reference values", func(t *testing.T) {
+		// pandas.Series([1, 2, 3, 4]).ewm(span=3).mean()
+		expected := []float64{1, 1.666667, 2.428571, 3.266667}
+
+		e := indicator.NewAdjustedEMA(3)
+
+		var actual []float64
+		for _, v := range []float64{1, 2, 3, 4} {
+			v, ok := e.Add(v)
+			require.True(t, ok)
+			actual = append(actual, v)
+		}
+
+		assertInDeltaSlice(t, expected, actual)
+	})
+}
+
+func TestRSI(t *testing.T) {
+	t.Run("returns StockCharts reference values", func(t *testing.T) {
+		expected := []float64{
+			70.53, 66.32, 66.55, 69.41, 66.36, 57.97, 62.93, 63.26, 56.06, 62.38,
+			54.71, 50.42, 39.99, 41.46, 41.87, 45.46, 37.30, 33.08, 37.77,
+		}
+
+		r := indicator.NewRSI(14)
+
+		var actual []float64
+		for i, c := range rsiCloses {
+			v, ok := r.Add(c)
+			require.Equal(t, i >= 14, ok)
+			if ok {
+				actual = append(actual, v)
+			}
+		}
+
+		assertInDeltaSlice(t, expected, actual)
+	})
+
+	t.Run("returns 100 if there are no losses", func(t *testing.T) {
+		r := indicator.NewRSI(2)
+
+		var v float64
+		for _, c := range []float64{1, 2, 3, 4} {
+			v, _ = r.Add(c)
+		}
+
+		assert.Equal(t, 100.0, v)
+	})
+
+	t.Run("returns 50 if there are no changes", func(t *testing.T) {
+		r := indicator.NewRSI(2)
+
+		var v float64
+		for _, c := range []float64{1, 1, 1} {
+			v, _ = r.Add(c)
+		}
+
+		assert.Equal(t, 50.0, v)
+	})
+}
+
+func TestMACD(t *testing.T) {
+	t.Run("returns reference values", func(t *testing.T) {
+		expected := []indicator.MACDValue{
+			{MACD: 0.048679, Signal: 0.039605, Histogram: 0.009074},
+			{MACD: 0.084512, Signal: 0.057568, Histogram: 0.026944},
+			{MACD: 0.212572, Signal: 0.119570, Histogram: 0.093002},
+		}
+
+		m := indicator.NewMACD(5, 10, 4)
+
+		var actual []indicator.MACDValue
+		for i, c := range closes[:15] {
+			v, ok := m.Add(c)
+			require.Equal(t, i >= 12, ok)
+			if ok {
+				actual = append(actual, v)
+			}
+		}
+
+		require.Len(t, actual, len(expected))
+		for i := range expected {
+			assert.InDelta(t, expected[i].MACD, actual[i].MACD, 1e-6)
+			assert.InDelta(t, expected[i].Signal, actual[i].Signal, 1e-6)
+			assert.InDelta(t, expected[i].Histogram, actual[i].Histogram, 1e-6)
+		}
+	})
+}
+
+func TestBollinger(t *testing.T) {
+	t.Run("returns false until period values are added", func(t *testing.T) {
+		b := indicator.NewBollinger(20, 2)
+
+		for _, c := range closes[:19] {
+			_, ok := b.Add(c)
+			assert.False(t, ok)
+		}
+	})
+
+	t.Run("returns bands either side of average", func(t *testing.T) {
+		b := indicator.NewBollinger(5, 2)
+
+		var (
+			bands indicator.Bands
+			ok    bool
+		)
+		for _, v := range []float64{1, 2, 3, 4, 5} {
+			bands, ok = b.Add(v)
+		}
+		require.True(t, ok)
+
+		// The population standard deviation of 1-5 is sqrt(2).
+		assert.InDelta(t, 3-2*math.Sqrt2, bands.Lower, 1e-9)
+		assert.InDelta(t, 3, bands.Middle, 1e-9)
+		assert.InDelta(t, 3+2*math.Sqrt2, bands.Upper, 1e-9)
+	})
+
+	t.Run("returns bands of last period values", func(t *testing.T) {
+		b := indicator.NewBollinger(20, 2)
+
+		var bands indicator.Bands
+		for _, c := range closes {
+			bands, _ = b.Add(c)
+		}
+
+		assert.InDelta(t, 21.9055, bands.Lower, 1e-4)
+		assert.InDelta(t, 23.1705, bands.Middle, 1e-4)
+		assert.InDelta(t, 24.4355, bands.Upper, 1e-4)
+	})
+}
+
+func TestATR(t *testing.T) {
+	t.Run("returns Wilder's average of true ranges", func(t *testing.T) {
+		candles := []model.Candle{
+			{High: 10, Low: 8, Close: 9},
+			{High: 11, Low: 9, Close: 10.5},
+			{High: 12, Low: 10, Close: 10},
+			{High: 10.5, Low: 7, Close: 8}, // True range from previous close to low.
+			{High: 9, Low: 8, Close: 8.5},
+		}
+		expected := []float64{2, 2.5, 2}
+
+		a := indicator.NewATR(3)
+
+		var actual []float64
+		for i, c := range candles {
+			v, ok := a.Add(c)
+			require.Equal(t, i >= 2, ok)
+			if ok {
+				actual = append(actual, v)
+			}
+		}
+
+		assertInDeltaSlice(t, expected, actual)
+	})
+}
+
+func TestSeries(t *testing.T) {
+	t.Run("returns NaN until indicator can be calculated", func(t *testing.T) {
+		values := indicator.RollingMean(rates(1, 2, 3, 4), 2)
+
+		require.Len(t, values, 4)
+		assert.True(t, math.IsNaN(values[0]))
+		assert.Equal(t, []float64{1.5, 2.5, 3.5}, values[1:])
+	})
+}
+
+func TestCandles(t *testing.T) {
+	t.Run("returns empty slice if there are no rates", func(t *testing.T) {
+		assert.Empty(t, indicator.Candles(nil, time.Hour))
+	})
+
+	t.Run("groups rates into candles", func(t *testing.T) {
+		start := time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)
+		r := []model.Rate{
+			{Rate: 10, DateTime: start.Add(5 * time.Minute)},
+			{Rate: 12, DateTime: start.Add(20 * time.Minute)},
+			{Rate: 9, DateTime: start.Add(40 * time.Minute)},
+			{Rate: 11, DateTime: start.Add(59 * time.Minute)},
+			{Rate: 11.5, DateTime: start.Add(3 * time.Hour)},
+		}
+
+		candles := indicator.Candles(r, time.Hour)
+
+		assert.Equal(t, []model.Candle{
+			{Time: start, Open: 10, High: 12, Low: 9, Close: 11},
+			{Time: start.Add(3 * time.Hour), Open: 11.5, High: 11.5, Low: 11.5, Close: 11.5},
+		}, candles)
+	})
+}
+
+func rates(r ...float64) []model.Rate {
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	rates := make([]model.Rate, len(r))
+	for i := range r {
+		rates[i] = model.Rate{Rate: r[i], DateTime: start.Add(time.Duration(i) * time.Minute)}
+	}
+
+	return rates
+}
+
+func assertInDeltaSlice(t *testing.T, expected, actual []float64) {
+	t.Helper()
+
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		assert.InDelta(t, expected[i], actual[i], delta, "index %d", i)
+	}
+}
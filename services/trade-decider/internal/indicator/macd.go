@@ -0,0 +1,47 @@
+package indicator
+
+type (
+	// MACD is the moving average convergence divergence, the difference between
+	// a fast and slow EMA, along with a signal EMA of the difference. It can be
+	// calculated once slow+signal-1 values have been added.
+	MACD struct {
+		fast   *EMA
+		slow   *EMA
+		signal *EMA
+	}
+
+	MACDValue struct {
+		MACD      float64
+		Signal    float64
+		Histogram float64 // MACD - Signal.
+	}
+)
+
+// NewMACD returns a MACD with the periods of each average, commonly 12, 26 and 9.
+func NewMACD(fast, slow, signal int) *MACD {
+	return &MACD{
+		fast:   NewEMA(fast),
+		slow:   NewEMA(slow),
+		signal: NewEMA(signal),
+	}
+}
+
+func (m *MACD) Add(v float64) (MACDValue, bool) {
+	fast, fastOk := m.fast.Add(v)
+	slow, slowOk := m.slow.Add(v)
+	if !fastOk || !slowOk {
+		return MACDValue{}, false
+	}
+
+	macd := fast - slow
+	signal, ok := m.signal.Add(macd)
+	if !ok {
+		return MACDValue{}, false
+	}
+
+	return MACDValue{
+		MACD:      macd,
+		Signal:    signal,
+		Histogram: macd - signal,
+	}, true
+}
@@ -0,0 +1,49 @@
+package indicator
+
+// RSI is Wilder's relative strength index over period changes in value, from
+// 0 to 100. It can be calculated once period+1 values have been added.
+type RSI struct {
+	gains  *wilder
+	losses *wilder
+	prev   float64
+	primed bool
+}
+
+func NewRSI(period int) *RSI {
+	return &RSI{
+		gains:  newWilder(period),
+		losses: newWilder(period),
+	}
+}
+
+func (r *RSI) Add(v float64) (float64, bool) {
+	if !r.primed {
+		r.prev, r.primed = v, true
+		return 0, false
+	}
+
+	change := v - r.prev
+	r.prev = v
+
+	var gain, loss float64
+	if change > 0 {
+		gain = change
+	} else {
+		loss = -change
+	}
+
+	avgGain, ok := r.gains.add(gain)
+	avgLoss, _ := r.losses.add(loss)
+	if !ok {
+		return 0, false
+	}
+
+	switch {
+	case avgLoss == 0 && avgGain == 0:
+		return 50, true
+	case avgLoss == 0:
+		return 100, true
+	default:
+		return 100 - 100/(1+avgGain/avgLoss), true
+	}
+}
@@ -0,0 +1,44 @@
+package indicator
+
+import "math"
+
+// window holds the last size values added.
+type window struct {
+	values []float64
+	next   int
+	count  int
+	sum    float64
+}
+
+func newWindow(size int) *window {
+	return &window{values: make([]float64, minPeriod(size))}
+}
+
+func (w *window) add(v float64) {
+	w.sum += v - w.values[w.next]
+	w.values[w.next] = v
+	w.next = (w.next + 1) % len(w.values)
+	if w.count < len(w.values) {
+		w.count++
+	}
+}
+
+func (w *window) full() bool {
+	return w.count == len(w.values)
+}
+
+func (w *window) mean() float64 {
+	return w.sum / float64(w.count)
+}
+
+// stdDev returns the population standard deviation of the values.
+func (w *window) stdDev() float64 {
+	mean := w.mean()
+
+	var sq float64
+	for _, v := range w.values[:w.count] {
+		sq += (v - mean) * (v - mean)
+	}
+
+	return math.Sqrt(sq / float64(w.count))
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	Buy  TradeType = "buy"
+	Sell TradeType = "sell"
+)
+
+type (
+	TradeType string
+
+	// Rate is a rate stored by the data-storer.
+	Rate struct {
+		Rate     float64   `json:"rate"`
+		DateTime time.Time `json:"dateTime"`
+	}
+
+	// Candle is the open, high, low and close rate over a period starting at Time.
+	Candle struct {
+		Time  time.Time
+		Open  float64
+		High  float64
+		Low   float64
+		Close float64
+	}
+
+	// Balance holds the available balances of the base and quote currencies of a product.
+	Balance struct {
+		Base  decimal.Decimal
+		Quote decimal.Decimal
+	}
+
+	// Decision is a trade chosen by a strategy. Amount is in the quote currency
+	// for both buys and sells.
+	Decision struct {
+		TradeType TradeType       `json:"tradeType"`
+		Amount    decimal.Decimal `json:"amount"`
+	}
+
+	// Order is a trade placed with the trade function. IdempotencyKey is the same
+	// for every attempt at the decision, so a retry doesn't trade twice.
+	Order struct {
+		IdempotencyKey string
+		ProductId      string
+		TradeType      TradeType
+		Amount         decimal.Decimal
+	}
+)
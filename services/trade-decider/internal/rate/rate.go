@@ -0,0 +1,83 @@
+// Package rate gets historic rates by invoking the data-storer data-reader function.
+package rate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/cshep4/kripto/shared/go/apperror"
+)
+
+type (
+	Invoker interface {
+		InvokeWithContext(ctx context.Context, input *lambda.InvokeInput, opts ...request.Option) (*lambda.InvokeOutput, error)
+	}
+
+	getter struct {
+		invoker      Invoker
+		functionName string
+	}
+
+	// errorResponse is returned by Lambda when the function fails.
+	errorResponse struct {
+		ErrorMessage string `json:"errorMessage"`
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func New(invoker Invoker, functionName string) (*getter, error) {
+	switch {
+	case invoker == nil:
+		return nil, InvalidParameterError{Parameter: "invoker"}
+	case functionName == "":
+		return nil, InvalidParameterError{Parameter: "functionName"}
+	}
+
+	return &getter{
+		invoker:      invoker,
+		functionName: functionName,
+	}, nil
+}
+
+// GetRates returns the rates from the previous month, oldest first. The data
+// reader returns the newest first, so they are sorted by time.
+func (g *getter) GetRates(ctx context.Context) ([]model.Rate, error) {
+	out, err := g.invoker.InvokeWithContext(ctx, &lambda.InvokeInput{
+		FunctionName: aws.String(g.functionName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invoke: %w", err)
+	}
+	if out.FunctionError != nil {
+		var res errorResponse
+		if err := json.Unmarshal(out.Payload, &res); err != nil || res.ErrorMessage == "" {
+			return nil, fmt.Errorf("function error - type: %s, payload: %s", aws.StringValue(out.FunctionError), out.Payload)
+		}
+		return nil, apperror.Parse(res.ErrorMessage)
+	}
+
+	var rates []model.Rate
+	if err := json.Unmarshal(out.Payload, &rates); err != nil {
+		return nil, fmt.Errorf("json_unmarshal: %w", err)
+	}
+
+	sort.SliceStable(rates, func(i, j int) bool {
+		return rates[i].DateTime.Before(rates[j].DateTime)
+	})
+
+	return rates, nil
+}
@@ -0,0 +1,153 @@
+package rate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/cshep4/kripto/services/trade-decider/internal/mocks/rate"
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/cshep4/kripto/services/trade-decider/internal/rate"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const functionName = "data-reader"
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if invoker is empty", func(t *testing.T) {
+		g, err := rate.New(nil, functionName)
+		require.Error(t, err)
+
+		assert.Nil(t, g)
+
+		ipErr, ok := err.(rate.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "invoker", ipErr.Parameter)
+	})
+
+	t.Run("returns error if functionName is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		g, err := rate.New(rate_mocks.NewMockInvoker(ctrl), "")
+		require.Error(t, err)
+
+		assert.Nil(t, g)
+
+		ipErr, ok := err.(rate.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "functionName", ipErr.Parameter)
+	})
+
+	t.Run("returns getter", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		g, err := rate.New(rate_mocks.NewMockInvoker(ctrl), functionName)
+		require.NoError(t, err)
+
+		assert.NotNil(t, g)
+	})
+}
+
+func TestGetter_GetRates(t *testing.T) {
+	t.Run("returns error if error invoking function", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := errors.New("error")
+
+		invoker := rate_mocks.NewMockInvoker(ctrl)
+
+		g, err := rate.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, &lambda.InvokeInput{
+			FunctionName: aws.String(functionName),
+		}).Return(nil, testErr)
+
+		rates, err := g.GetRates(ctx)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Nil(t, rates)
+	})
+
+	t.Run("returns error with code if function returns error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := rate_mocks.NewMockInvoker(ctrl)
+
+		g, err := rate.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, gomock.Any()).Return(&lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			Payload:       []byte(`{"errorMessage":"timeout: get_rates: context deadline exceeded"}`),
+		}, nil)
+
+		rates, err := g.GetRates(ctx)
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.Timeout, apperror.CodeOf(err))
+		assert.Nil(t, rates)
+	})
+
+	t.Run("returns error if response can't be read", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := rate_mocks.NewMockInvoker(ctrl)
+
+		g, err := rate.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, gomock.Any()).Return(&lambda.InvokeOutput{
+			Payload: []byte(`not json`),
+		}, nil)
+
+		rates, err := g.GetRates(ctx)
+		require.Error(t, err)
+
+		assert.Nil(t, rates)
+	})
+
+	t.Run("returns rates oldest first", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := rate_mocks.NewMockInvoker(ctrl)
+
+		g, err := rate.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, gomock.Any()).Return(&lambda.InvokeOutput{
+			Payload: []byte(`[
+				{"id":"2","rate":8012.92,"dateTime":"2020-05-19T19:40:00Z"},
+				{"id":"1","rate":8012.91,"dateTime":"2020-05-19T19:39:00Z"}
+			]`),
+		}, nil)
+
+		rates, err := g.GetRates(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, []model.Rate{
+			{Rate: 8012.91, DateTime: time.Date(2020, 5, 19, 19, 39, 0, 0, time.UTC)},
+			{Rate: 8012.92, DateTime: time.Date(2020, 5, 19, 19, 40, 0, 0, time.UTC)},
+		}, rates)
+	})
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/cshep4/kripto/shared/go/log"
+)
+
+// productId is the product traded, the rates stored by the data-storer are for it.
+const productId = "BTC-GBP"
+
+type (
+	RateGetter interface {
+		GetRates(ctx context.Context) ([]model.Rate, error)
+	}
+	BalanceGetter interface {
+		GetBalance(ctx context.Context, productId string) (model.Balance, error)
+	}
+	Trader interface {
+		Trade(ctx context.Context, order model.Order) error
+	}
+	// Strategy decides whether to trade from the rates, oldest first, and the
+	// available balances.
+	Strategy interface {
+		Decide(rates []model.Rate, balance model.Balance) (model.Decision, bool)
+	}
+
+	service struct {
+		rates    RateGetter
+		wallet   BalanceGetter
+		trader   Trader
+		strategy Strategy
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func New(rates RateGetter, wallet BalanceGetter, trader Trader, strategy Strategy) (*service, error) {
+	switch {
+	case rates == nil:
+		return nil, InvalidParameterError{Parameter: "rates"}
+	case wallet == nil:
+		return nil, InvalidParameterError{Parameter: "wallet"}
+	case trader == nil:
+		return nil, InvalidParameterError{Parameter: "trader"}
+	case strategy == nil:
+		return nil, InvalidParameterError{Parameter: "strategy"}
+	}
+
+	return &service{
+		rates:    rates,
+		wallet:   wallet,
+		trader:   trader,
+		strategy: strategy,
+	}, nil
+}
+
+// Decide runs the strategy against the latest rates and balances, and places
+// the trade it decides on using the idempotency key. The decision is returned,
+// or nil if the strategy doesn't trade.
+//
+// If the balances can't be retrieved because of an error which retrying won't
+// fix, e.g. bad credentials, the error is logged and nothing is traded, so the
+// function isn't retried.
+func (s *service) Decide(ctx context.Context, idempotencyKey string) (*model.Decision, error) {
+	rates, err := s.rates.GetRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get_rates: %w", err)
+	}
+
+	balance, err := s.wallet.GetBalance(ctx, productId)
+	switch {
+	case err != nil && apperror.IsRetryable(err):
+		return nil, fmt.Errorf("get_balance: %w", err)
+	case err != nil:
+		log.Error(ctx, "error_getting_balance", log.ErrorParam(err))
+		return nil, nil
+	}
+
+	decision, ok := s.strategy.Decide(rates, balance)
+	if !ok {
+		return nil, nil
+	}
+
+	err = s.trader.Trade(ctx, model.Order{
+		IdempotencyKey: idempotencyKey,
+		ProductId:      productId,
+		TradeType:      decision.TradeType,
+		Amount:         decision.Amount,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("trade: %w", err)
+	}
+
+	return &decision, nil
+}
@@ -0,0 +1,247 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/mocks/rate"
+	"github.com/cshep4/kripto/services/trade-decider/internal/mocks/strategy"
+	"github.com/cshep4/kripto/services/trade-decider/internal/mocks/trade"
+	"github.com/cshep4/kripto/services/trade-decider/internal/mocks/wallet"
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/cshep4/kripto/services/trade-decider/internal/service"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	idempotencyKey = "request-id"
+	productId      = "BTC-GBP"
+)
+
+var (
+	rates   = []model.Rate{{Rate: 8012.91}, {Rate: 8012.92}}
+	balance = model.Balance{Base: decimal.New(1, 0), Quote: decimal.New(1000, 0)}
+)
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if rates is empty", func(t *testing.T) {
+		s, err := service.New(nil, nil, nil, nil)
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+
+		ipErr, ok := err.(service.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "rates", ipErr.Parameter)
+	})
+
+	t.Run("returns error if wallet is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s, err := service.New(rate_mocks.NewMockRateGetter(ctrl), nil, nil, nil)
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+
+		ipErr, ok := err.(service.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "wallet", ipErr.Parameter)
+	})
+
+	t.Run("returns error if trader is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s, err := service.New(rate_mocks.NewMockRateGetter(ctrl), wallet_mocks.NewMockBalanceGetter(ctrl), nil, nil)
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+
+		ipErr, ok := err.(service.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "trader", ipErr.Parameter)
+	})
+
+	t.Run("returns error if strategy is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s, err := service.New(rate_mocks.NewMockRateGetter(ctrl), wallet_mocks.NewMockBalanceGetter(ctrl), trade_mocks.NewMockTrader(ctrl), nil)
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+
+		ipErr, ok := err.(service.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "strategy", ipErr.Parameter)
+	})
+
+	t.Run("returns service", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s, err := service.New(
+			rate_mocks.NewMockRateGetter(ctrl),
+			wallet_mocks.NewMockBalanceGetter(ctrl),
+			trade_mocks.NewMockTrader(ctrl),
+			strategy_mocks.NewMockStrategy(ctrl),
+		)
+		require.NoError(t, err)
+
+		assert.NotNil(t, s)
+	})
+}
+
+func TestService_Decide(t *testing.T) {
+	t.Run("returns error if error getting rates", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := errors.New("error")
+
+		rateGetter := rate_mocks.NewMockRateGetter(ctrl)
+
+		s, err := service.New(rateGetter, wallet_mocks.NewMockBalanceGetter(ctrl), trade_mocks.NewMockTrader(ctrl), strategy_mocks.NewMockStrategy(ctrl))
+		require.NoError(t, err)
+
+		rateGetter.EXPECT().GetRates(ctx).Return(nil, testErr)
+
+		decision, err := s.Decide(ctx, idempotencyKey)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Nil(t, decision)
+	})
+
+	t.Run("returns error if retryable error getting balance", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := apperror.Parse("rate_limited: get_wallet: Private rate limit exceeded")
+
+		rateGetter := rate_mocks.NewMockRateGetter(ctrl)
+		balanceGetter := wallet_mocks.NewMockBalanceGetter(ctrl)
+
+		s, err := service.New(rateGetter, balanceGetter, trade_mocks.NewMockTrader(ctrl), strategy_mocks.NewMockStrategy(ctrl))
+		require.NoError(t, err)
+
+		rateGetter.EXPECT().GetRates(ctx).Return(rates, nil)
+		balanceGetter.EXPECT().GetBalance(ctx, productId).Return(model.Balance{}, testErr)
+
+		decision, err := s.Decide(ctx, idempotencyKey)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Nil(t, decision)
+	})
+
+	t.Run("does nothing if error getting balance is not retryable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		rateGetter := rate_mocks.NewMockRateGetter(ctrl)
+		balanceGetter := wallet_mocks.NewMockBalanceGetter(ctrl)
+
+		s, err := service.New(rateGetter, balanceGetter, trade_mocks.NewMockTrader(ctrl), strategy_mocks.NewMockStrategy(ctrl))
+		require.NoError(t, err)
+
+		rateGetter.EXPECT().GetRates(ctx).Return(rates, nil)
+		balanceGetter.EXPECT().GetBalance(ctx, productId).Return(model.Balance{}, apperror.Parse("exchange_rejected: get_wallet: invalid signature"))
+
+		decision, err := s.Decide(ctx, idempotencyKey)
+		require.NoError(t, err)
+
+		assert.Nil(t, decision)
+	})
+
+	t.Run("does nothing if strategy doesn't trade", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		rateGetter := rate_mocks.NewMockRateGetter(ctrl)
+		balanceGetter := wallet_mocks.NewMockBalanceGetter(ctrl)
+		strategy := strategy_mocks.NewMockStrategy(ctrl)
+
+		s, err := service.New(rateGetter, balanceGetter, trade_mocks.NewMockTrader(ctrl), strategy)
+		require.NoError(t, err)
+
+		rateGetter.EXPECT().GetRates(ctx).Return(rates, nil)
+		balanceGetter.EXPECT().GetBalance(ctx, productId).Return(balance, nil)
+		strategy.EXPECT().Decide(rates, balance).Return(model.Decision{}, false)
+
+		decision, err := s.Decide(ctx, idempotencyKey)
+		require.NoError(t, err)
+
+		assert.Nil(t, decision)
+	})
+
+	t.Run("returns error if error trading", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := errors.New("error")
+
+		rateGetter := rate_mocks.NewMockRateGetter(ctrl)
+		balanceGetter := wallet_mocks.NewMockBalanceGetter(ctrl)
+		trader := trade_mocks.NewMockTrader(ctrl)
+		strategy := strategy_mocks.NewMockStrategy(ctrl)
+
+		s, err := service.New(rateGetter, balanceGetter, trader, strategy)
+		require.NoError(t, err)
+
+		rateGetter.EXPECT().GetRates(ctx).Return(rates, nil)
+		balanceGetter.EXPECT().GetBalance(ctx, productId).Return(balance, nil)
+		strategy.EXPECT().Decide(rates, balance).Return(model.Decision{TradeType: model.Buy, Amount: decimal.New(150, 0)}, true)
+		trader.EXPECT().Trade(ctx, gomock.Any()).Return(testErr)
+
+		decision, err := s.Decide(ctx, idempotencyKey)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Nil(t, decision)
+	})
+
+	t.Run("places trade decided by strategy", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		expected := model.Decision{TradeType: model.Sell, Amount: decimal.New(1200, 0)}
+
+		rateGetter := rate_mocks.NewMockRateGetter(ctrl)
+		balanceGetter := wallet_mocks.NewMockBalanceGetter(ctrl)
+		trader := trade_mocks.NewMockTrader(ctrl)
+		strategy := strategy_mocks.NewMockStrategy(ctrl)
+
+		s, err := service.New(rateGetter, balanceGetter, trader, strategy)
+		require.NoError(t, err)
+
+		rateGetter.EXPECT().GetRates(ctx).Return(rates, nil)
+		balanceGetter.EXPECT().GetBalance(ctx, productId).Return(balance, nil)
+		strategy.EXPECT().Decide(rates, balance).Return(expected, true)
+		trader.EXPECT().Trade(ctx, model.Order{
+			IdempotencyKey: idempotencyKey,
+			ProductId:      productId,
+			TradeType:      model.Sell,
+			Amount:         expected.Amount,
+		}).Return(nil)
+
+		decision, err := s.Decide(ctx, idempotencyKey)
+		require.NoError(t, err)
+
+		assert.Equal(t, &expected, decision)
+	})
+}
@@ -0,0 +1,109 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+)
+
+// The rates are stored by the data-storer rate-writer function.
+const (
+	db         = "rate"
+	collection = "rate"
+)
+
+type (
+	store struct {
+		client     *mongo.Client
+		collection *mongo.Collection
+	}
+
+	rate struct {
+		Rate     float64   `bson:"rate"`
+		DateTime time.Time `bson:"dateTime"`
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func New(ctx context.Context, client *mongo.Client) (*store, error) {
+	if client == nil {
+		return nil, InvalidParameterError{Parameter: "client"}
+	}
+
+	s := &store{
+		client:     client,
+		collection: client.Database(db).Collection(collection),
+	}
+
+	if err := s.ping(ctx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// GetRates returns the rates from from until to, oldest first. Either time can
+// be zero to leave that end of the range open.
+func (s *store) GetRates(ctx context.Context, from, to time.Time) ([]model.Rate, error) {
+	dateTime := bson.D{}
+	if !from.IsZero() {
+		dateTime = append(dateTime, bson.E{Key: "$gte", Value: from})
+	}
+	if !to.IsZero() {
+		dateTime = append(dateTime, bson.E{Key: "$lt", Value: to})
+	}
+
+	filter := bson.D{}
+	if len(dateTime) > 0 {
+		filter = bson.D{{Key: "dateTime", Value: dateTime}}
+	}
+
+	cur, err := s.collection.Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "dateTime", Value: 1}}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("find: %w", err)
+	}
+
+	var docs []rate
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	rates := make([]model.Rate, len(docs))
+	for i, d := range docs {
+		rates[i] = model.Rate{
+			Rate:     d.Rate,
+			DateTime: d.DateTime.UTC(),
+		}
+	}
+
+	return rates, nil
+}
+
+func (s *store) ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	return s.client.Ping(ctx, nil)
+}
+
+func (s *store) Close(ctx context.Context) error {
+	return s.client.Disconnect(ctx)
+}
@@ -0,0 +1,125 @@
+//+build integration
+
+package mongo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	store "github.com/cshep4/kripto/services/trade-decider/internal/store/rate/mongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if mongo client is nil", func(t *testing.T) {
+		s, err := store.New(context.Background(), nil)
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+
+		ipErr, ok := err.(store.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "client", ipErr.Parameter)
+	})
+
+	t.Run("returns error if ping fails", func(t *testing.T) {
+		ctx := context.Background()
+
+		client := newClient(t, ctx)
+
+		err := client.Disconnect(ctx)
+		require.NoError(t, err)
+
+		s, err := store.New(ctx, client)
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+	})
+
+	t.Run("returns store", func(t *testing.T) {
+		ctx := context.Background()
+
+		s, err := store.New(ctx, newClient(t, ctx))
+		require.NoError(t, err)
+
+		assert.NotNil(t, s)
+
+		err = s.Close(ctx)
+		require.NoError(t, err)
+	})
+}
+
+func TestStore_GetRates(t *testing.T) {
+	ctx := context.Background()
+
+	client := newClient(t, ctx)
+	collection := client.Database("rate").Collection("rate")
+
+	s, err := store.New(ctx, client)
+	require.NoError(t, err)
+
+	defer func() {
+		err := collection.Drop(ctx)
+		require.NoError(t, err)
+
+		err = s.Close(ctx)
+		require.NoError(t, err)
+	}()
+
+	start := time.Date(2020, 5, 19, 19, 0, 0, 0, time.UTC)
+	rates := []model.Rate{
+		{Rate: 8012.91, DateTime: start},
+		{Rate: 8012.92, DateTime: start.Add(2 * time.Minute)},
+		{Rate: 8012.93, DateTime: start.Add(4 * time.Minute)},
+	}
+
+	// Insert newest first to check the rates are sorted.
+	for i := len(rates) - 1; i >= 0; i-- {
+		_, err := collection.InsertOne(ctx, bson.D{
+			{Key: "_id", Value: primitive.NewObjectID()},
+			{Key: "rate", Value: rates[i].Rate},
+			{Key: "dateTime", Value: rates[i].DateTime},
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("returns all rates oldest first", func(t *testing.T) {
+		res, err := s.GetRates(ctx, time.Time{}, time.Time{})
+		require.NoError(t, err)
+
+		assert.Equal(t, rates, res)
+	})
+
+	t.Run("returns rates from from until to", func(t *testing.T) {
+		res, err := s.GetRates(ctx, start.Add(2*time.Minute), start.Add(4*time.Minute))
+		require.NoError(t, err)
+
+		assert.Equal(t, rates[1:2], res)
+	})
+
+	t.Run("returns empty slice if there are no rates in range", func(t *testing.T) {
+		res, err := s.GetRates(ctx, start.Add(time.Hour), time.Time{})
+		require.NoError(t, err)
+
+		assert.Empty(t, res)
+	})
+}
+
+func newClient(t *testing.T, ctx context.Context) *mongo.Client {
+	t.Helper()
+
+	client, err := mongo.NewClient(options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+
+	err = client.Connect(ctx)
+	require.NoError(t, err)
+
+	return client
+}
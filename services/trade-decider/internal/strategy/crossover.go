@@ -0,0 +1,79 @@
+package strategy
+
+import (
+	"github.com/cshep4/kripto/services/trade-decider/internal/indicator"
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// EMACrossoverName is the name of the EMA crossover strategy.
+const EMACrossoverName = "ema-crossover"
+
+const (
+	// Rates are retrieved every minute, so these are 9 and 21 days.
+	defaultShortSpan = 9 * 60 * 24
+	defaultLongSpan  = 21 * 60 * 24
+)
+
+var defaultTradePercentage = decimal.New(15, -2)
+
+type emaCrossover struct {
+	shortSpan       int
+	longSpan        int
+	tradePercentage decimal.Decimal
+}
+
+// NewEMACrossover returns a strategy which trades when the short exponential
+// moving average of the rate crosses the long one. It buys when the short
+// average moves above the long one and sells when it moves below, trading a
+// percentage of the available balance each time.
+func NewEMACrossover(opts ...Option) *emaCrossover {
+	c := &emaCrossover{
+		shortSpan:       defaultShortSpan,
+		longSpan:        defaultLongSpan,
+		tradePercentage: defaultTradePercentage,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Decide compares the averages at the latest rate with those at the rate
+// before it. The averages are weighted the same as pandas' ewm(span).mean().
+// Rates must be oldest first, and at least two are needed.
+func (c *emaCrossover) Decide(rates []model.Rate, balance model.Balance) (model.Decision, bool) {
+	if len(rates) < 2 {
+		return model.Decision{}, false
+	}
+
+	var (
+		short = indicator.NewAdjustedEMA(c.shortSpan)
+		long  = indicator.NewAdjustedEMA(c.longSpan)
+
+		above, wasAbove bool
+	)
+	for _, r := range rates {
+		s, _ := short.Add(r.Rate)
+		l, _ := long.Add(r.Rate)
+		wasAbove, above = above, s > l
+	}
+
+	switch {
+	case above && !wasAbove:
+		return model.Decision{
+			TradeType: model.Buy,
+			Amount:    balance.Quote.Mul(c.tradePercentage),
+		}, true
+	case !above && wasAbove:
+		price := decimal.NewFromFloat(rates[len(rates)-1].Rate)
+		return model.Decision{
+			TradeType: model.Sell,
+			Amount:    balance.Base.Mul(c.tradePercentage).Mul(price),
+		}, true
+	default:
+		return model.Decision{}, false
+	}
+}
@@ -0,0 +1,98 @@
+package strategy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/cshep4/kripto/services/trade-decider/internal/strategy"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var balance = model.Balance{
+	Base:  decimal.New(2, 0),
+	Quote: decimal.New(1000, 0),
+}
+
+func TestEMACrossover_Decide(t *testing.T) {
+	// A span of 1 makes the short average the rate itself.
+	s := strategy.NewEMACrossover(strategy.WithSpans(1, 3))
+
+	t.Run("returns false if there are fewer than two rates", func(t *testing.T) {
+		_, ok := s.Decide(rates(10), balance)
+		assert.False(t, ok)
+	})
+
+	t.Run("returns false if averages don't cross", func(t *testing.T) {
+		_, ok := s.Decide(rates(10, 12, 13), balance)
+		assert.False(t, ok)
+	})
+
+	t.Run("buys percentage of quote balance if short average crosses above long", func(t *testing.T) {
+		decision, ok := s.Decide(rates(10, 10, 12), balance)
+		require.True(t, ok)
+
+		assert.Equal(t, model.Buy, decision.TradeType)
+		assert.Equal(t, "150", decision.Amount.String())
+	})
+
+	t.Run("sells percentage of base balance if short average crosses below long", func(t *testing.T) {
+		decision, ok := s.Decide(rates(10, 12, 11), balance)
+		require.True(t, ok)
+
+		assert.Equal(t, model.Sell, decision.TradeType)
+		assert.Equal(t, "3.3", decision.Amount.String())
+	})
+
+	t.Run("weights averages the same as pandas", func(t *testing.T) {
+		// pandas.Series([1, 2, 1.55]).ewm(span=3).mean() is [1, 1.666667, 1.6], so the
+		// rate moves below the long average. Without adjusting for the first rates
+		// the long average would be 1.525 and there would be no crossover.
+		decision, ok := s.Decide(rates(1, 2, 1.55), balance)
+		require.True(t, ok)
+
+		assert.Equal(t, model.Sell, decision.TradeType)
+	})
+
+	t.Run("uses trade percentage", func(t *testing.T) {
+		s := strategy.NewEMACrossover(
+			strategy.WithSpans(1, 3),
+			strategy.WithTradePercentage(decimal.New(5, -1)),
+		)
+
+		decision, ok := s.Decide(rates(10, 10, 12), balance)
+		require.True(t, ok)
+
+		assert.Equal(t, "500", decision.Amount.String())
+	})
+
+	t.Run("uses 9 and 21 day spans by default", func(t *testing.T) {
+		s := strategy.NewEMACrossover()
+
+		// A day of slowly falling rates, followed by a jump, moves the 9 day
+		// average above the 21 day average.
+		r := make([]float64, 0, 60*24+1)
+		for i := 0; i < 60*24; i++ {
+			r = append(r, 9000-float64(i)/1000)
+		}
+		r = append(r, 9500)
+
+		decision, ok := s.Decide(rates(r...), balance)
+		require.True(t, ok)
+
+		assert.Equal(t, model.Buy, decision.TradeType)
+	})
+}
+
+func rates(r ...float64) []model.Rate {
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	rates := make([]model.Rate, len(r))
+	for i := range r {
+		rates[i] = model.Rate{Rate: r[i], DateTime: start.Add(time.Duration(i) * time.Minute)}
+	}
+
+	return rates
+}
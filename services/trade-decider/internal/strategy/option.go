@@ -0,0 +1,24 @@
+package strategy
+
+import "github.com/shopspring/decimal"
+
+type Option func(*emaCrossover)
+
+// WithSpans sets the number of rates spanned by the short and long averages.
+func WithSpans(short, long int) Option {
+	return func(c *emaCrossover) {
+		if short > 0 && long > short {
+			c.shortSpan = short
+			c.longSpan = long
+		}
+	}
+}
+
+// WithTradePercentage sets the fraction of the available balance traded, e.g. 0.15.
+func WithTradePercentage(p decimal.Decimal) Option {
+	return func(c *emaCrossover) {
+		if p.IsPositive() && p.LessThanOrEqual(decimal.New(1, 0)) {
+			c.tradePercentage = p
+		}
+	}
+}
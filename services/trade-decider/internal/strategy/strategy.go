@@ -0,0 +1,36 @@
+// Package strategy decides when to trade from historic rates.
+package strategy
+
+import (
+	"fmt"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+)
+
+type (
+	// Strategy decides whether to trade from the rates, oldest first, and the
+	// available balances.
+	Strategy interface {
+		Decide(rates []model.Rate, balance model.Balance) (model.Decision, bool)
+	}
+
+	// UnknownStrategyError is returned when there is no strategy with the name.
+	UnknownStrategyError struct {
+		Name string
+	}
+)
+
+func (u UnknownStrategyError) Error() string {
+	return fmt.Sprintf("unknown strategy %q", u.Name)
+}
+
+// New returns the strategy with the name, with its default settings. The EMA
+// crossover strategy is returned if the name is empty.
+func New(name string) (Strategy, error) {
+	switch name {
+	case "", EMACrossoverName:
+		return NewEMACrossover(), nil
+	default:
+		return nil, UnknownStrategyError{Name: name}
+	}
+}
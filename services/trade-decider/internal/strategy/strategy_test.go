@@ -0,0 +1,36 @@
+package strategy_test
+
+import (
+	"testing"
+
+	"github.com/cshep4/kripto/services/trade-decider/internal/strategy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if strategy is unknown", func(t *testing.T) {
+		s, err := strategy.New("buy-the-dip")
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+
+		usErr, ok := err.(strategy.UnknownStrategyError)
+		assert.True(t, ok)
+		assert.Equal(t, "buy-the-dip", usErr.Name)
+	})
+
+	t.Run("returns ema crossover if name is empty", func(t *testing.T) {
+		s, err := strategy.New("")
+		require.NoError(t, err)
+
+		assert.Equal(t, strategy.NewEMACrossover(), s)
+	})
+
+	t.Run("returns ema crossover", func(t *testing.T) {
+		s, err := strategy.New(strategy.EMACrossoverName)
+		require.NoError(t, err)
+
+		assert.Equal(t, strategy.NewEMACrossover(), s)
+	})
+}
@@ -0,0 +1,90 @@
+// Package trade places trades by invoking the trader trade function.
+package trade
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// MinAmount is the smallest amount traded, smaller orders are raised to it.
+var MinAmount = decimal.New(10, 0)
+
+type (
+	Invoker interface {
+		InvokeWithContext(ctx context.Context, input *lambda.InvokeInput, opts ...request.Option) (*lambda.InvokeOutput, error)
+	}
+
+	trader struct {
+		invoker      Invoker
+		functionName string
+	}
+
+	// tradeRequest is the request accepted by the trade function.
+	tradeRequest struct {
+		IdempotencyKey string `json:"idempotencyKey"`
+		ProductId      string `json:"productId"`
+		TradeType      string `json:"tradeType"`
+		Amount         string `json:"amount"`
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func New(invoker Invoker, functionName string) (*trader, error) {
+	switch {
+	case invoker == nil:
+		return nil, InvalidParameterError{Parameter: "invoker"}
+	case functionName == "":
+		return nil, InvalidParameterError{Parameter: "functionName"}
+	}
+
+	return &trader{
+		invoker:      invoker,
+		functionName: functionName,
+	}, nil
+}
+
+// Trade places a market order for the amount, in the quote currency. The trade
+// function is invoked asynchronously, so Lambda retries it if it fails and the
+// idempotency key stops it trading twice.
+func (t *trader) Trade(ctx context.Context, order model.Order) error {
+	amount := order.Amount
+	if amount.LessThan(MinAmount) {
+		amount = MinAmount
+	}
+
+	payload, err := json.Marshal(tradeRequest{
+		IdempotencyKey: order.IdempotencyKey,
+		ProductId:      order.ProductId,
+		TradeType:      string(order.TradeType),
+		Amount:         amount.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("json_marshal: %w", err)
+	}
+
+	_, err = t.invoker.InvokeWithContext(ctx, &lambda.InvokeInput{
+		FunctionName:   aws.String(t.functionName),
+		InvocationType: aws.String(lambda.InvocationTypeEvent),
+		Payload:        payload,
+	})
+	if err != nil {
+		return fmt.Errorf("invoke: %w", err)
+	}
+
+	return nil
+}
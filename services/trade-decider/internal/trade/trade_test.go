@@ -0,0 +1,130 @@
+package trade_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/cshep4/kripto/services/trade-decider/internal/mocks/trade"
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/cshep4/kripto/services/trade-decider/internal/trade"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const functionName = "trade"
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if invoker is empty", func(t *testing.T) {
+		tr, err := trade.New(nil, functionName)
+		require.Error(t, err)
+
+		assert.Nil(t, tr)
+
+		ipErr, ok := err.(trade.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "invoker", ipErr.Parameter)
+	})
+
+	t.Run("returns error if functionName is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tr, err := trade.New(trade_mocks.NewMockInvoker(ctrl), "")
+		require.Error(t, err)
+
+		assert.Nil(t, tr)
+
+		ipErr, ok := err.(trade.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "functionName", ipErr.Parameter)
+	})
+
+	t.Run("returns trader", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tr, err := trade.New(trade_mocks.NewMockInvoker(ctrl), functionName)
+		require.NoError(t, err)
+
+		assert.NotNil(t, tr)
+	})
+}
+
+func TestTrader_Trade(t *testing.T) {
+	t.Run("returns error if error invoking function", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := errors.New("error")
+
+		invoker := trade_mocks.NewMockInvoker(ctrl)
+
+		tr, err := trade.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, gomock.Any()).Return(nil, testErr)
+
+		err = tr.Trade(ctx, model.Order{Amount: decimal.New(20, 0)})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("invokes trade function asynchronously", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := trade_mocks.NewMockInvoker(ctrl)
+
+		tr, err := trade.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, &lambda.InvokeInput{
+			FunctionName:   aws.String(functionName),
+			InvocationType: aws.String(lambda.InvocationTypeEvent),
+			Payload:        []byte(`{"idempotencyKey":"request-id","productId":"BTC-GBP","tradeType":"sell","amount":"33.75"}`),
+		}).Return(&lambda.InvokeOutput{}, nil)
+
+		err = tr.Trade(ctx, model.Order{
+			IdempotencyKey: "request-id",
+			ProductId:      "BTC-GBP",
+			TradeType:      model.Sell,
+			Amount:         decimal.New(3375, -2),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("raises amount to minimum", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := trade_mocks.NewMockInvoker(ctrl)
+
+		tr, err := trade.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, &lambda.InvokeInput{
+			FunctionName:   aws.String(functionName),
+			InvocationType: aws.String(lambda.InvocationTypeEvent),
+			Payload:        []byte(`{"idempotencyKey":"request-id","productId":"BTC-GBP","tradeType":"buy","amount":"10"}`),
+		}).Return(&lambda.InvokeOutput{}, nil)
+
+		err = tr.Trade(ctx, model.Order{
+			IdempotencyKey: "request-id",
+			ProductId:      "BTC-GBP",
+			TradeType:      model.Buy,
+			Amount:         decimal.New(5, 0),
+		})
+		require.NoError(t, err)
+	})
+}
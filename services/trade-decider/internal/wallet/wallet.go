@@ -0,0 +1,96 @@
+// Package wallet gets account balances by invoking the trader get-wallet function.
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/cshep4/kripto/services/trade-decider/internal/model"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	Invoker interface {
+		InvokeWithContext(ctx context.Context, input *lambda.InvokeInput, opts ...request.Option) (*lambda.InvokeOutput, error)
+	}
+
+	getter struct {
+		invoker      Invoker
+		functionName string
+	}
+
+	// account is an account returned by get-wallet, which returns the accounts
+	// keyed by lower case currency code.
+	account struct {
+		Available decimal.Decimal `json:"available"`
+	}
+
+	// errorResponse is returned by Lambda when the function fails.
+	errorResponse struct {
+		ErrorMessage string `json:"errorMessage"`
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func New(invoker Invoker, functionName string) (*getter, error) {
+	switch {
+	case invoker == nil:
+		return nil, InvalidParameterError{Parameter: "invoker"}
+	case functionName == "":
+		return nil, InvalidParameterError{Parameter: "functionName"}
+	}
+
+	return &getter{
+		invoker:      invoker,
+		functionName: functionName,
+	}, nil
+}
+
+// GetBalance returns the available balances of the currencies of the product,
+// e.g. BTC and GBP for BTC-GBP. Currencies without an account have a zero
+// balance. Errors returned by get-wallet keep their code, so callers can tell
+// whether to retry.
+func (g *getter) GetBalance(ctx context.Context, productId string) (model.Balance, error) {
+	currencies := strings.Split(strings.ToLower(productId), "-")
+	if len(currencies) != 2 {
+		return model.Balance{}, fmt.Errorf("invalid product id %q", productId)
+	}
+
+	out, err := g.invoker.InvokeWithContext(ctx, &lambda.InvokeInput{
+		FunctionName: aws.String(g.functionName),
+	})
+	if err != nil {
+		return model.Balance{}, fmt.Errorf("invoke: %w", err)
+	}
+	if out.FunctionError != nil {
+		var res errorResponse
+		if err := json.Unmarshal(out.Payload, &res); err != nil || res.ErrorMessage == "" {
+			return model.Balance{}, fmt.Errorf("function error - type: %s, payload: %s", aws.StringValue(out.FunctionError), out.Payload)
+		}
+		return model.Balance{}, apperror.Parse(res.ErrorMessage)
+	}
+
+	var accounts map[string]account
+	if err := json.Unmarshal(out.Payload, &accounts); err != nil {
+		return model.Balance{}, fmt.Errorf("json_unmarshal: %w", err)
+	}
+
+	return model.Balance{
+		Base:  accounts[currencies[0]].Available,
+		Quote: accounts[currencies[1]].Available,
+	}, nil
+}
@@ -0,0 +1,182 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/cshep4/kripto/services/trade-decider/internal/mocks/wallet"
+	"github.com/cshep4/kripto/services/trade-decider/internal/wallet"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const functionName = "get-wallet"
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if invoker is empty", func(t *testing.T) {
+		g, err := wallet.New(nil, functionName)
+		require.Error(t, err)
+
+		assert.Nil(t, g)
+
+		ipErr, ok := err.(wallet.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "invoker", ipErr.Parameter)
+	})
+
+	t.Run("returns error if functionName is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		g, err := wallet.New(wallet_mocks.NewMockInvoker(ctrl), "")
+		require.Error(t, err)
+
+		assert.Nil(t, g)
+
+		ipErr, ok := err.(wallet.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "functionName", ipErr.Parameter)
+	})
+
+	t.Run("returns getter", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		g, err := wallet.New(wallet_mocks.NewMockInvoker(ctrl), functionName)
+		require.NoError(t, err)
+
+		assert.NotNil(t, g)
+	})
+}
+
+func TestGetter_GetBalance(t *testing.T) {
+	t.Run("returns error if product id is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		g, err := wallet.New(wallet_mocks.NewMockInvoker(ctrl), functionName)
+		require.NoError(t, err)
+
+		_, err = g.GetBalance(context.Background(), "BTC")
+		require.Error(t, err)
+	})
+
+	t.Run("returns error if error invoking function", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := errors.New("error")
+
+		invoker := wallet_mocks.NewMockInvoker(ctrl)
+
+		g, err := wallet.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, &lambda.InvokeInput{
+			FunctionName: aws.String(functionName),
+		}).Return(nil, testErr)
+
+		_, err = g.GetBalance(ctx, "BTC-GBP")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("returns error with code if function returns error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := wallet_mocks.NewMockInvoker(ctrl)
+
+		g, err := wallet.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, gomock.Any()).Return(&lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			Payload:       []byte(`{"errorMessage":"rate_limited: get_wallet: Private rate limit exceeded"}`),
+		}, nil)
+
+		_, err = g.GetBalance(ctx, "BTC-GBP")
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.RateLimited, apperror.CodeOf(err))
+		assert.True(t, apperror.IsRetryable(err))
+	})
+
+	t.Run("returns error if function error can't be read", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := wallet_mocks.NewMockInvoker(ctrl)
+
+		g, err := wallet.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, gomock.Any()).Return(&lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			Payload:       []byte(`not json`),
+		}, nil)
+
+		_, err = g.GetBalance(ctx, "BTC-GBP")
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.Internal, apperror.CodeOf(err))
+	})
+
+	t.Run("returns available balances of product currencies", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := wallet_mocks.NewMockInvoker(ctrl)
+
+		g, err := wallet.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, gomock.Any()).Return(&lambda.InvokeOutput{
+			Payload: []byte(`{
+				"btc":{"id":"1","balance":"0.5","hold":"0.1","available":"0.4"},
+				"gbp":{"id":"2","balance":"1000","hold":"0","available":"1000"}
+			}`),
+		}, nil)
+
+		balance, err := g.GetBalance(ctx, "BTC-GBP")
+		require.NoError(t, err)
+
+		assert.Equal(t, "0.4", balance.Base.String())
+		assert.Equal(t, "1000", balance.Quote.String())
+	})
+
+	t.Run("returns zero balance if currency has no account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := wallet_mocks.NewMockInvoker(ctrl)
+
+		g, err := wallet.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, gomock.Any()).Return(&lambda.InvokeOutput{
+			Payload: []byte(`{"gbp":{"available":"1000"}}`),
+		}, nil)
+
+		balance, err := g.GetBalance(ctx, "BTC-GBP")
+		require.NoError(t, err)
+
+		assert.True(t, balance.Base.IsZero())
+		assert.Equal(t, "1000", balance.Quote.String())
+	})
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cshep4/kripto/services/trader/internal/handler/aws"
+	"github.com/cshep4/kripto/services/trader/internal/secrets"
+	"github.com/cshep4/kripto/services/trader/internal/service"
+	exchangesetup "github.com/cshep4/kripto/services/trader/internal/setup"
+	"github.com/cshep4/kripto/services/trader/internal/trader"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/cshep4/kripto/shared/go/idempotency"
+	idempotent "github.com/cshep4/kripto/shared/go/idempotency/middleware"
+	"github.com/cshep4/kripto/shared/go/idempotency/middleware/invoke"
+	"github.com/cshep4/kripto/shared/go/lambda"
+	"github.com/cshep4/kripto/shared/go/log"
+	"github.com/cshep4/kripto/shared/go/mongodb"
+	"github.com/cshep4/kripto/shared/go/publisher"
+)
+
+const (
+	logLevel     = "info"
+	serviceName  = "trader"
+	functionName = "cancel-order"
+)
+
+var (
+	cfg = lambda.FunctionConfig{
+		LogLevel:     logLevel,
+		ServiceName:  serviceName,
+		FunctionName: functionName,
+		Setup:        setup,
+		Initialised:  func() bool { return handler.Service != nil && middleware != nil },
+	}
+
+	handler    aws.Handler
+	middleware idempotent.Middleware
+
+	runner = lambda.New(
+		handler.CancelOrder,
+		lambda.WithPreExecute(log.Middleware(logLevel, serviceName, functionName)),
+		lambda.WithErrorResponse(apperror.Wrap),
+	)
+)
+
+func main() {
+	runner.Start(cfg)
+}
+
+func setup(ctx context.Context) error {
+	var s secrets.Secrets
+	if err := s.Fetch(ctx); err != nil {
+		return err
+	}
+
+	exchange, err := exchangesetup.Exchange(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	trader, err := trader.New(exchange)
+	if err != nil {
+		return fmt.Errorf("initialise_trader: %w", err)
+	}
+	if err := trader.SelfCheck(ctx); err != nil {
+		return fmt.Errorf("self_check: %w", err)
+	}
+
+	handler.Service, err = service.New(publisher.NewMemory(), trader)
+	if err != nil {
+		return fmt.Errorf("initialise_service: %w", err)
+	}
+
+	mongoClient, err := mongodb.New(ctx)
+	if err != nil {
+		return fmt.Errorf("initialise_mongo_client: %w", err)
+	}
+
+	idempotencer, err := idempotency.New(ctx, functionName, mongoClient)
+	if err != nil {
+		return fmt.Errorf("initialise_idempotencer: %w", err)
+	}
+
+	middleware, err = invoke.NewMiddleware(idempotencer)
+	if err != nil {
+		return fmt.Errorf("initialise_idempotency_middleware: %w", err)
+	}
+
+	runner.Apply(
+		lambda.WithPreExecute(middleware.PreExecute),
+		lambda.WithPostExecute(middleware.PostExecute),
+		lambda.WithErrorHandler(middleware.HandleError),
+	)
+
+	return nil
+}
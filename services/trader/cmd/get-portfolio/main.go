@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	awsconfig "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awslambda "github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/cshep4/kripto/services/trader/internal/handler/aws"
+	"github.com/cshep4/kripto/services/trader/internal/history"
+	"github.com/cshep4/kripto/services/trader/internal/secrets"
+	"github.com/cshep4/kripto/services/trader/internal/service"
+	exchangesetup "github.com/cshep4/kripto/services/trader/internal/setup"
+	"github.com/cshep4/kripto/services/trader/internal/trader"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/cshep4/kripto/shared/go/lambda"
+	"github.com/cshep4/kripto/shared/go/log"
+	"github.com/cshep4/kripto/shared/go/publisher"
+)
+
+const (
+	logLevel     = "info"
+	serviceName  = "trader"
+	functionName = "get-portfolio"
+)
+
+var (
+	cfg = lambda.FunctionConfig{
+		LogLevel:     logLevel,
+		ServiceName:  serviceName,
+		FunctionName: functionName,
+		Setup:        setup,
+		Initialised:  func() bool { return handler.Service != nil },
+	}
+
+	handler aws.Handler
+
+	runner = lambda.New(
+		handler.GetPortfolio,
+		lambda.WithPreExecute(log.Middleware(logLevel, serviceName, functionName)),
+		lambda.WithErrorResponse(apperror.Wrap),
+	)
+)
+
+func main() {
+	runner.Start(cfg)
+}
+
+func setup(ctx context.Context) error {
+	var s secrets.Secrets
+	if err := s.Fetch(ctx); err != nil {
+		return err
+	}
+
+	exchange, err := exchangesetup.Exchange(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if s.TradeHistory.FunctionName == "" {
+		return errors.New("missing_environment_variable: TRADE_READER_FUNCTION_NAME")
+	}
+
+	sess, err := session.NewSession(&awsconfig.Config{
+		Region: &s.Region,
+	})
+	if err != nil {
+		return fmt.Errorf("new_session: %w", err)
+	}
+
+	history, err := history.New(awslambda.New(sess), s.TradeHistory.FunctionName)
+	if err != nil {
+		return fmt.Errorf("initialise_trade_history: %w", err)
+	}
+
+	trader, err := trader.New(exchange)
+	if err != nil {
+		return fmt.Errorf("initialise_trader: %w", err)
+	}
+	if err := trader.SelfCheck(ctx); err != nil {
+		return fmt.Errorf("self_check: %w", err)
+	}
+
+	handler.Service, err = service.New(publisher.NewMemory(), trader, service.WithTradeHistory(history))
+	if err != nil {
+		return fmt.Errorf("initialise_service: %w", err)
+	}
+
+	return nil
+}
@@ -4,14 +4,15 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/cshep4/kripto/services/trader/internal/handler/aws"
 	"github.com/cshep4/kripto/services/trader/internal/secrets"
 	"github.com/cshep4/kripto/services/trader/internal/service"
+	exchangesetup "github.com/cshep4/kripto/services/trader/internal/setup"
 	"github.com/cshep4/kripto/services/trader/internal/trader"
+	"github.com/cshep4/kripto/shared/go/apperror"
 	"github.com/cshep4/kripto/shared/go/lambda"
 	"github.com/cshep4/kripto/shared/go/log"
-	"github.com/preichenberger/go-coinbasepro/v2"
+	"github.com/cshep4/kripto/shared/go/publisher"
 )
 
 const (
@@ -34,6 +35,7 @@ var (
 	runner = lambda.New(
 		handler.GetWallet,
 		lambda.WithPreExecute(log.Middleware(logLevel, serviceName, functionName)),
+		lambda.WithErrorResponse(apperror.Wrap),
 	)
 )
 
@@ -43,34 +45,27 @@ func main() {
 
 func setup(ctx context.Context) error {
 	var s secrets.Secrets
-	if err := s.Fetch(); err != nil {
+	if err := s.Fetch(ctx); err != nil {
 		return err
 	}
 
-	coinbaseClient := initCoinbaseProClient(s)
+	exchange, err := exchangesetup.Exchange(ctx, s)
+	if err != nil {
+		return err
+	}
 
-	trader, err := trader.New(coinbaseClient)
+	trader, err := trader.New(exchange)
 	if err != nil {
 		return fmt.Errorf("initialise_trader: %w", err)
 	}
+	if err := trader.SelfCheck(ctx); err != nil {
+		return fmt.Errorf("self_check: %w", err)
+	}
 
-	handler.Service, err = service.New("topic", &sns.SNS{}, trader)
+	handler.Service, err = service.New(publisher.NewMemory(), trader)
 	if err != nil {
 		return fmt.Errorf("initialise_service: %w", err)
 	}
 
 	return nil
 }
-
-func initCoinbaseProClient(s secrets.Secrets) *coinbasepro.Client {
-	coinbaseClient := coinbasepro.NewClient()
-	if s.MockTrade {
-		coinbaseClient.UpdateConfig(&coinbasepro.ClientConfig{
-			BaseURL:    "https://api-public.sandbox.pro.coinbase.com",
-			Key:        s.CoinbasePro.Sandbox.Key,
-			Passphrase: s.CoinbasePro.Sandbox.Passphrase,
-			Secret:     s.CoinbasePro.Sandbox.Secret,
-		})
-	}
-	return coinbaseClient
-}
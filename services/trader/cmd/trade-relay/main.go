@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Netflix/go-env"
+	awsconfig "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/cshep4/kripto/services/trader/internal/handler/aws"
+	"github.com/cshep4/kripto/services/trader/internal/outbox"
+	"github.com/cshep4/kripto/services/trader/internal/relay"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/cshep4/kripto/shared/go/lambda"
+	"github.com/cshep4/kripto/shared/go/log"
+	"github.com/cshep4/kripto/shared/go/mongodb"
+	"github.com/cshep4/kripto/shared/go/publisher"
+)
+
+const (
+	logLevel     = "info"
+	serviceName  = "trader"
+	functionName = "trade-relay"
+)
+
+type config struct {
+	Region    string        `env:"REGION"`
+	Delay     time.Duration `env:"RELAY_DELAY"`
+	BatchSize int           `env:"RELAY_BATCH_SIZE"`
+	Publisher publisher.Config
+}
+
+var (
+	cfg = lambda.FunctionConfig{
+		LogLevel:     logLevel,
+		ServiceName:  serviceName,
+		FunctionName: functionName,
+		Setup:        setup,
+		Initialised:  func() bool { return handler.Relayer != nil },
+	}
+
+	handler aws.Handler
+
+	runner = lambda.New(
+		handler.RelayTrades,
+		lambda.WithPreExecute(log.Middleware(logLevel, serviceName, functionName)),
+		lambda.WithErrorResponse(apperror.Wrap),
+	)
+)
+
+func main() {
+	runner.Start(cfg)
+}
+
+func setup(ctx context.Context) error {
+	var c config
+	if _, err := env.UnmarshalFromEnviron(&c); err != nil {
+		return fmt.Errorf("unmarshal_environment_variables: %w", err)
+	}
+
+	sess, err := session.NewSession(&awsconfig.Config{
+		Region: &c.Region,
+	})
+	if err != nil {
+		return fmt.Errorf("new_session: %w", err)
+	}
+
+	publisher, err := publisher.New(sess, c.Publisher)
+	if err != nil {
+		return fmt.Errorf("initialise_publisher: %w", err)
+	}
+
+	mongoClient, err := mongodb.New(ctx)
+	if err != nil {
+		return fmt.Errorf("initialise_mongo_client: %w", err)
+	}
+
+	outbox, err := outbox.New(ctx, "trade", mongoClient)
+	if err != nil {
+		return fmt.Errorf("initialise_outbox: %w", err)
+	}
+
+	handler.Relayer, err = relay.New(publisher, outbox,
+		relay.WithDelay(c.Delay),
+		relay.WithBatchSize(c.BatchSize),
+	)
+	if err != nil {
+		return fmt.Errorf("initialise_relay: %w", err)
+	}
+
+	return nil
+}
@@ -6,18 +6,22 @@ import (
 
 	awsconfig "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/cshep4/kripto/services/trader/internal/handler/aws"
+	"github.com/cshep4/kripto/services/trader/internal/outbox"
+	"github.com/cshep4/kripto/services/trader/internal/risk"
 	"github.com/cshep4/kripto/services/trader/internal/secrets"
 	"github.com/cshep4/kripto/services/trader/internal/service"
+	exchangesetup "github.com/cshep4/kripto/services/trader/internal/setup"
 	"github.com/cshep4/kripto/services/trader/internal/trader"
+	"github.com/cshep4/kripto/shared/go/apperror"
 	"github.com/cshep4/kripto/shared/go/idempotency"
 	idempotent "github.com/cshep4/kripto/shared/go/idempotency/middleware"
 	"github.com/cshep4/kripto/shared/go/idempotency/middleware/invoke"
 	"github.com/cshep4/kripto/shared/go/lambda"
 	"github.com/cshep4/kripto/shared/go/log"
 	"github.com/cshep4/kripto/shared/go/mongodb"
-	"github.com/preichenberger/go-coinbasepro/v2"
+	"github.com/cshep4/kripto/shared/go/publisher"
+	"github.com/shopspring/decimal"
 )
 
 const (
@@ -41,6 +45,7 @@ var (
 	runner = lambda.New(
 		handler.Trade,
 		lambda.WithPreExecute(log.Middleware(logLevel, serviceName, functionName)),
+		lambda.WithErrorResponse(apperror.Wrap),
 	)
 )
 
@@ -50,29 +55,55 @@ func main() {
 
 func setup(ctx context.Context) error {
 	var s secrets.Secrets
-	if err := s.Fetch(); err != nil {
+	if err := s.Fetch(ctx); err != nil {
 		return err
 	}
 
-	coinbaseClient := initCoinbaseProClient(s)
+	exchange, err := exchangesetup.Exchange(ctx, s)
+	if err != nil {
+		return err
+	}
 
 	sess, err := session.NewSession(&awsconfig.Config{
-		Region: &s.SNS.Region,
+		Region: &s.Region,
 	})
 	if err != nil {
 		return fmt.Errorf("new_session: %w", err)
 	}
 
-	publisher := sns.New(sess)
+	publisher, err := publisher.New(sess, s.Publisher)
+	if err != nil {
+		return fmt.Errorf("initialise_publisher: %w", err)
+	}
+
+	traderOpts := []trader.Option{
+		trader.WithPollInterval(s.Settlement.PollInterval, s.Settlement.MaxPollInterval),
+		trader.WithSettlementTimeout(s.Settlement.Timeout),
+	}
+	if s.DryRunFeeRate != "" {
+		feeRate, err := decimal.NewFromString(s.DryRunFeeRate)
+		if err != nil {
+			return fmt.Errorf("DRY_RUN_FEE_RATE: %w", err)
+		}
+		traderOpts = append(traderOpts, trader.WithDryRunFeeRate(feeRate))
+	}
 
-	trader, err := trader.New(coinbaseClient)
+	trader, err := trader.New(exchange, traderOpts...)
 	if err != nil {
 		return fmt.Errorf("initialise_trader: %w", err)
 	}
+	if err := trader.SelfCheck(ctx); err != nil {
+		return fmt.Errorf("self_check: %w", err)
+	}
 
-	handler.Service, err = service.New(s.SNS.Topic, publisher, trader)
+	riskOpts, err := riskOptions(s)
 	if err != nil {
-		return fmt.Errorf("initialise_service: %w", err)
+		return fmt.Errorf("parse_risk_limits: %w", err)
+	}
+
+	checker, err := risk.New(trader, riskOpts...)
+	if err != nil {
+		return fmt.Errorf("initialise_risk_checker: %w", err)
 	}
 
 	mongoClient, err := mongodb.New(ctx)
@@ -80,6 +111,19 @@ func setup(ctx context.Context) error {
 		return fmt.Errorf("initialise_mongo_client: %w", err)
 	}
 
+	outbox, err := outbox.New(ctx, "trade", mongoClient)
+	if err != nil {
+		return fmt.Errorf("initialise_outbox: %w", err)
+	}
+
+	handler.Service, err = service.New(publisher, trader,
+		service.WithRiskChecker(checker),
+		service.WithOutbox(outbox),
+	)
+	if err != nil {
+		return fmt.Errorf("initialise_service: %w", err)
+	}
+
 	idempotencer, err := idempotency.New(ctx, "trade", mongoClient)
 	if err != nil {
 		return fmt.Errorf("initialise_idempotencer: %w", err)
@@ -99,15 +143,40 @@ func setup(ctx context.Context) error {
 	return nil
 }
 
-func initCoinbaseProClient(s secrets.Secrets) *coinbasepro.Client {
-	coinbaseClient := coinbasepro.NewClient()
-	if s.MockTrade {
-		coinbaseClient.UpdateConfig(&coinbasepro.ClientConfig{
-			BaseURL:    "https://api-public.sandbox.pro.coinbase.com",
-			Key:        s.CoinbasePro.Sandbox.Key,
-			Passphrase: s.CoinbasePro.Sandbox.Passphrase,
-			Secret:     s.CoinbasePro.Sandbox.Secret,
-		})
-	}
-	return coinbaseClient
+func riskOptions(s secrets.Secrets) ([]risk.Option, error) {
+	maxFunds, err := parseDecimal(s.Risk.MaxOrderFunds)
+	if err != nil {
+		return nil, fmt.Errorf("RISK_MAX_ORDER_FUNDS: %w", err)
+	}
+	maxSize, err := parseDecimal(s.Risk.MaxOrderSize)
+	if err != nil {
+		return nil, fmt.Errorf("RISK_MAX_ORDER_SIZE: %w", err)
+	}
+	maxDailyNotional, err := parseDecimal(s.Risk.MaxDailyNotional)
+	if err != nil {
+		return nil, fmt.Errorf("RISK_MAX_DAILY_NOTIONAL: %w", err)
+	}
+
+	opts := []risk.Option{
+		risk.WithKillSwitch(s.Risk.KillSwitch),
+		risk.WithMaxOrderSize(maxFunds, maxSize),
+		risk.WithMaxDailyNotional(maxDailyNotional),
+	}
+
+	minBalances, err := secrets.ParseAmounts(s.Risk.MinBalances)
+	if err != nil {
+		return nil, fmt.Errorf("RISK_MIN_BALANCES: %w", err)
+	}
+	for currency, min := range minBalances {
+		opts = append(opts, risk.WithMinBalance(currency, min))
+	}
+
+	return opts, nil
+}
+
+func parseDecimal(v string) (decimal.Decimal, error) {
+	if v == "" {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(v)
 }
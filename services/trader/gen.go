@@ -3,4 +3,15 @@ package trader
 //go:generate mockgen -destination=internal/mocks/service/servicer.gen.go -package=service_mocks github.com/cshep4/kripto/services/trader/internal/handler/aws Servicer
 //go:generate mockgen -destination=internal/mocks/trader/trader.gen.go -package=trader_mocks github.com/cshep4/kripto/services/trader/internal/service Trader
 //go:generate mockgen -destination=internal/mocks/publish/publish.gen.go -package=publish_mocks github.com/cshep4/kripto/services/trader/internal/service Publisher
-//go:generate mockgen -destination=internal/mocks/coinbase/coinbase.gen.go -package=coinbase_mocks github.com/cshep4/kripto/services/trader/internal/trader Coinbase
+//go:generate mockgen -destination=internal/mocks/exchange/exchange.gen.go -package=exchange_mocks github.com/cshep4/kripto/services/trader/internal/trader Exchange,OrderFinder
+//go:generate mockgen -destination=internal/mocks/coinbase/client.gen.go -package=coinbase_mocks github.com/cshep4/kripto/services/trader/internal/exchange/coinbase Client
+//go:generate mockgen -destination=internal/mocks/risk/checker.gen.go -package=risk_mocks github.com/cshep4/kripto/services/trader/internal/service RiskChecker
+//go:generate mockgen -destination=internal/mocks/risk/trader.gen.go -package=risk_mocks github.com/cshep4/kripto/services/trader/internal/risk Trader
+//go:generate mockgen -destination=internal/mocks/history/history.gen.go -package=history_mocks github.com/cshep4/kripto/services/trader/internal/service TradeHistory
+//go:generate mockgen -destination=internal/mocks/history/invoker.gen.go -package=history_mocks github.com/cshep4/kripto/services/trader/internal/history Invoker
+//go:generate mockgen -destination=internal/mocks/secrets/source.gen.go -package=secrets_mocks github.com/cshep4/kripto/services/trader/internal/secrets/provider Source
+//go:generate mockgen -destination=internal/mocks/retry/exchange.gen.go -package=retry_mocks github.com/cshep4/kripto/services/trader/internal/exchange/retry Exchange,OrderFinder
+//go:generate mockgen -destination=internal/mocks/outbox/outbox.gen.go -package=outbox_mocks github.com/cshep4/kripto/services/trader/internal/service Outbox
+//go:generate mockgen -destination=internal/mocks/relay/relay.gen.go -package=relay_mocks github.com/cshep4/kripto/services/trader/internal/relay Outbox,Publisher
+//go:generate mockgen -destination=internal/mocks/relay/relayer.gen.go -package=relay_mocks github.com/cshep4/kripto/services/trader/internal/handler/aws Relayer
+//go:generate mockgen -destination=internal/mocks/paper/paper.gen.go -package=paper_mocks github.com/cshep4/kripto/services/trader/internal/exchange/paper Store,RateGetter
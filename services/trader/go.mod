@@ -5,13 +5,20 @@ go 1.14
 require (
 	github.com/Netflix/go-env v0.0.0-20200512170851-5660fe1ab40a
 	github.com/aws/aws-sdk-go v1.31.0
+	github.com/cshep4/kripto/shared/go/apperror v0.0.0-00010101000000-000000000000
+	github.com/cshep4/kripto/shared/go/events v0.0.0-00010101000000-000000000000
 	github.com/cshep4/kripto/shared/go/idempotency v0.0.0-00010101000000-000000000000
 	github.com/cshep4/kripto/shared/go/lambda v0.0.0-00010101000000-000000000000
 	github.com/cshep4/kripto/shared/go/log v0.0.0-00010101000000-000000000000
 	github.com/cshep4/kripto/shared/go/mongodb v0.0.0-00010101000000-000000000000
+	github.com/cshep4/kripto/shared/go/publisher v0.0.0-00010101000000-000000000000
 	github.com/golang/mock v1.4.3
+	github.com/kevinburke/go.uuid v1.2.0
 	github.com/preichenberger/go-coinbasepro/v2 v2.0.5
+	github.com/shopspring/decimal v1.3.1
 	github.com/stretchr/testify v1.5.1
+	go.mongodb.org/mongo-driver v1.3.3
+	gopkg.in/yaml.v2 v2.2.5
 )
 
 replace github.com/cshep4/kripto/shared/go/mongodb => ../../shared/go/mongodb
@@ -21,3 +28,9 @@ replace github.com/cshep4/kripto/shared/go/log => ../../shared/go/log
 replace github.com/cshep4/kripto/shared/go/lambda => ../../shared/go/lambda
 
 replace github.com/cshep4/kripto/shared/go/idempotency => ../../shared/go/idempotency
+
+replace github.com/cshep4/kripto/shared/go/apperror => ../../shared/go/apperror
+
+replace github.com/cshep4/kripto/shared/go/publisher => ../../shared/go/publisher
+
+replace github.com/cshep4/kripto/shared/go/events => ../../shared/go/events
@@ -0,0 +1,204 @@
+package coinbase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/preichenberger/go-coinbasepro/v2"
+)
+
+type (
+	// Credentials are the API key, passphrase and secret requests are signed with.
+	Credentials struct {
+		Key        string
+		Passphrase string
+		Secret     string
+	}
+
+	// CredentialSource returns the API credentials. If refresh is set they are
+	// read from their source again within the context of the request, as the
+	// exchange rejected the current ones.
+	CredentialSource func(ctx context.Context, refresh bool) (Credentials, error)
+
+	client struct {
+		mu     sync.Mutex
+		client *coinbasepro.Client
+		source CredentialSource
+	}
+
+	// rateLimitTransport returns a rate limit error for a 429 response, as the
+	// Coinbase Pro client drops the response headers which say when to retry.
+	rateLimitTransport struct {
+		next http.RoundTripper
+	}
+)
+
+// NewClient returns a Coinbase Pro client which reads its credentials from the
+// source before each request, so rotated credentials are picked up without a
+// restart. A request rejected because of its credentials is made once more
+// with credentials read from the source again. A rate limited request returns
+// an exchange.RateLimitError with the delay from the Retry-After header.
+func NewClient(c *coinbasepro.Client, source CredentialSource) (*client, error) {
+	switch {
+	case c == nil:
+		return nil, InvalidParameterError{Parameter: "client"}
+	case source == nil:
+		return nil, InvalidParameterError{Parameter: "source"}
+	}
+
+	var httpClient http.Client
+	if c.HTTPClient != nil {
+		httpClient = *c.HTTPClient
+	}
+	httpClient.Transport = rateLimitTransport{next: httpClient.Transport}
+	c.HTTPClient = &httpClient
+
+	return &client{
+		client: c,
+		source: source,
+	}, nil
+}
+
+func (c *client) CreateOrder(ctx context.Context, order *coinbasepro.Order) (coinbasepro.Order, error) {
+	var res coinbasepro.Order
+	err := c.do(ctx, func() error {
+		var err error
+		res, err = c.client.CreateOrder(order)
+		return err
+	})
+	return res, err
+}
+
+func (c *client) GetOrder(ctx context.Context, id string) (coinbasepro.Order, error) {
+	var res coinbasepro.Order
+	err := c.do(ctx, func() error {
+		var err error
+		res, err = c.client.GetOrder(id)
+		return err
+	})
+	return res, err
+}
+
+func (c *client) GetAccounts(ctx context.Context) ([]coinbasepro.Account, error) {
+	var res []coinbasepro.Account
+	err := c.do(ctx, func() error {
+		var err error
+		res, err = c.client.GetAccounts()
+		return err
+	})
+	return res, err
+}
+
+// GetProducts requests the products itself rather than with the Coinbase Pro
+// client, so their base increment is decoded.
+func (c *client) GetProducts(ctx context.Context) ([]Product, error) {
+	var res []Product
+	err := c.do(ctx, func() error {
+		_, err := c.client.Request(http.MethodGet, "/products", nil, &res)
+		return err
+	})
+	return res, err
+}
+
+func (c *client) GetTicker(ctx context.Context, product string) (coinbasepro.Ticker, error) {
+	var res coinbasepro.Ticker
+	err := c.do(ctx, func() error {
+		var err error
+		res, err = c.client.GetTicker(product)
+		return err
+	})
+	return res, err
+}
+
+func (c *client) CancelOrder(ctx context.Context, id string) error {
+	return c.do(ctx, func() error {
+		return c.client.CancelOrder(id)
+	})
+}
+
+func (c *client) CancelAllOrders(ctx context.Context, p ...coinbasepro.CancelAllOrdersParams) ([]string, error) {
+	var res []string
+	err := c.do(ctx, func() error {
+		var err error
+		res, err = c.client.CancelAllOrders(p...)
+		return err
+	})
+	return res, err
+}
+
+// ListOrders returns a cursor which requests each page when it is read, so the
+// credentials can't be refreshed if they are rejected.
+func (c *client) ListOrders(ctx context.Context, p ...coinbasepro.ListOrdersParams) *coinbasepro.Cursor {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setCredentials(ctx, false)
+	return c.client.ListOrders(p...)
+}
+
+// ListFills returns a cursor which requests each page when it is read, so the
+// credentials can't be refreshed if they are rejected.
+func (c *client) ListFills(ctx context.Context, p coinbasepro.ListFillsParams) *coinbasepro.Cursor {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setCredentials(ctx, false)
+	return c.client.ListFills(p)
+}
+
+// do makes the request with the latest credentials, refreshing them and making
+// it again if they are rejected. The exchange rejects the request before acting
+// on it, so an order isn't placed twice.
+func (c *client) do(ctx context.Context, request func() error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setCredentials(ctx, false)
+
+	err := request()
+	if !isUnauthorized(err) || !c.setCredentials(ctx, true) {
+		return err
+	}
+
+	return request()
+}
+
+// setCredentials sets the credentials of the client from the source, returning
+// false if they can't be read. The client keeps its current credentials, so a
+// source which is briefly unavailable doesn't stop requests which would succeed.
+func (c *client) setCredentials(ctx context.Context, refresh bool) bool {
+	creds, err := c.source(ctx, refresh)
+	if err != nil {
+		return false
+	}
+
+	c.client.Key = creds.Key
+	c.client.Passphrase = creds.Passphrase
+	c.client.Secret = creds.Secret
+
+	return true
+}
+
+func (t rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	res, err := next.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusTooManyRequests {
+		return res, err
+	}
+	defer res.Body.Close()
+
+	cErr := coinbasepro.Error{Message: "rate limit exceeded"}
+	json.NewDecoder(res.Body).Decode(&cErr)
+
+	return nil, exchange.RateLimitError{
+		Err:        cErr,
+		RetryAfter: exchange.RetryAfter(res.Header.Get("Retry-After")),
+	}
+}
@@ -0,0 +1,156 @@
+package coinbase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/coinbase"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/preichenberger/go-coinbasepro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAuthServer returns a server which only accepts requests signed with the key.
+func newAuthServer(t *testing.T, key string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("CB-ACCESS-KEY") != key {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"Invalid API Key"}`))
+			return
+		}
+		json.NewEncoder(w).Encode([]coinbasepro.Account{{ID: "id", Currency: "GBP"}})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func credentials(key string) coinbase.Credentials {
+	return coinbase.Credentials{Key: key, Passphrase: "passphrase", Secret: "c2VjcmV0"}
+}
+
+func TestNewClient(t *testing.T) {
+	t.Run("returns error if client is empty", func(t *testing.T) {
+		c, err := coinbase.NewClient(nil, func(context.Context, bool) (coinbase.Credentials, error) { return coinbase.Credentials{}, nil })
+		require.Error(t, err)
+
+		assert.Nil(t, c)
+		assert.Equal(t, coinbase.InvalidParameterError{Parameter: "client"}, err)
+	})
+
+	t.Run("returns error if source is empty", func(t *testing.T) {
+		c, err := coinbase.NewClient(&coinbasepro.Client{}, nil)
+		require.Error(t, err)
+
+		assert.Nil(t, c)
+		assert.Equal(t, coinbase.InvalidParameterError{Parameter: "source"}, err)
+	})
+}
+
+func TestClient_GetAccounts(t *testing.T) {
+	t.Run("signs request with credentials from source", func(t *testing.T) {
+		server := newAuthServer(t, "rotated")
+
+		var refreshes []bool
+		c, err := coinbase.NewClient(&coinbasepro.Client{BaseURL: server.URL, HTTPClient: server.Client()}, func(_ context.Context, refresh bool) (coinbase.Credentials, error) {
+			refreshes = append(refreshes, refresh)
+			return credentials("rotated"), nil
+		})
+		require.NoError(t, err)
+
+		accounts, err := c.GetAccounts(context.Background())
+		require.NoError(t, err)
+
+		require.Len(t, accounts, 1)
+		assert.Equal(t, []bool{false}, refreshes)
+	})
+
+	t.Run("refreshes credentials and makes request again if credentials are rejected", func(t *testing.T) {
+		server := newAuthServer(t, "rotated")
+
+		type key struct{}
+		ctx := context.WithValue(context.Background(), key{}, "request")
+
+		var refreshes []bool
+		c, err := coinbase.NewClient(&coinbasepro.Client{BaseURL: server.URL, HTTPClient: server.Client()}, func(sourceCtx context.Context, refresh bool) (coinbase.Credentials, error) {
+			assert.Equal(t, ctx, sourceCtx)
+
+			refreshes = append(refreshes, refresh)
+			if refresh {
+				return credentials("rotated"), nil
+			}
+			return credentials("cached"), nil
+		})
+		require.NoError(t, err)
+
+		accounts, err := c.GetAccounts(ctx)
+		require.NoError(t, err)
+
+		require.Len(t, accounts, 1)
+		assert.Equal(t, []bool{false, true}, refreshes)
+	})
+
+	t.Run("returns error if credentials are rejected after refresh", func(t *testing.T) {
+		server := newAuthServer(t, "rotated")
+
+		c, err := coinbase.NewClient(&coinbasepro.Client{BaseURL: server.URL, HTTPClient: server.Client()}, func(context.Context, bool) (coinbase.Credentials, error) {
+			return credentials("revoked"), nil
+		})
+		require.NoError(t, err)
+
+		accounts, err := c.GetAccounts(context.Background())
+		require.Error(t, err)
+
+		assert.True(t, errors.As(err, &coinbasepro.Error{}))
+		assert.Empty(t, accounts)
+	})
+
+	t.Run("returns error if credentials are rejected and can't be refreshed", func(t *testing.T) {
+		server := newAuthServer(t, "rotated")
+
+		c, err := coinbase.NewClient(&coinbasepro.Client{BaseURL: server.URL, HTTPClient: server.Client()}, func(_ context.Context, refresh bool) (coinbase.Credentials, error) {
+			if refresh {
+				return coinbase.Credentials{}, errors.New("error")
+			}
+			return credentials("cached"), nil
+		})
+		require.NoError(t, err)
+
+		_, err = c.GetAccounts(context.Background())
+		require.Error(t, err)
+
+		assert.True(t, errors.As(err, &coinbasepro.Error{}))
+	})
+	t.Run("returns rate limit error with retry after header", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"Public rate limit exceeded"}`))
+		}))
+		defer server.Close()
+
+		client, err := coinbase.NewClient(&coinbasepro.Client{BaseURL: server.URL, HTTPClient: server.Client()}, func(context.Context, bool) (coinbase.Credentials, error) {
+			return credentials("key"), nil
+		})
+		require.NoError(t, err)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		_, err = c.GetAccounts(context.Background())
+		require.Error(t, err)
+
+		var rlErr exchange.RateLimitError
+		require.True(t, errors.As(err, &rlErr))
+		assert.Equal(t, 2*time.Second, rlErr.RetryAfter)
+		assert.Equal(t, "Public rate limit exceeded", rlErr.Err.Error())
+		assert.Equal(t, apperror.RateLimited, apperror.CodeOf(err))
+	})
+}
@@ -0,0 +1,291 @@
+// Package coinbase implements the exchange using the Coinbase Pro API.
+package coinbase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/preichenberger/go-coinbasepro/v2"
+)
+
+const (
+	stopLoss  = "loss"
+	stopEntry = "entry"
+
+	// clientOrderPrefix looks up an order by its client order ID rather than its ID.
+	clientOrderPrefix = "client:"
+)
+
+type (
+	Client interface {
+		CreateOrder(ctx context.Context, order *coinbasepro.Order) (coinbasepro.Order, error)
+		GetOrder(ctx context.Context, id string) (coinbasepro.Order, error)
+		GetAccounts(ctx context.Context) ([]coinbasepro.Account, error)
+		GetProducts(ctx context.Context) ([]Product, error)
+		GetTicker(ctx context.Context, product string) (coinbasepro.Ticker, error)
+		CancelOrder(ctx context.Context, id string) error
+		CancelAllOrders(ctx context.Context, p ...coinbasepro.CancelAllOrdersParams) ([]string, error)
+		ListOrders(ctx context.Context, p ...coinbasepro.ListOrdersParams) *coinbasepro.Cursor
+		ListFills(ctx context.Context, p coinbasepro.ListFillsParams) *coinbasepro.Cursor
+	}
+
+	// Product is a Coinbase Pro product with its base increment, which isn't
+	// decoded by coinbasepro.Product.
+	Product struct {
+		coinbasepro.Product
+		BaseIncrement string `json:"base_increment"`
+	}
+
+	coinbase struct {
+		client Client
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func New(client Client) (*coinbase, error) {
+	if client == nil {
+		return nil, InvalidParameterError{Parameter: "client"}
+	}
+
+	return &coinbase{
+		client: client,
+	}, nil
+}
+
+func (c *coinbase) CreateOrder(ctx context.Context, order exchange.NewOrder) (exchange.Order, error) {
+	o := &coinbasepro.Order{
+		Side:      string(order.Side),
+		ProductID: order.ProductId,
+		Funds:     order.Funds,
+		Size:      order.Size,
+		ClientOID: order.ClientOrderId,
+	}
+
+	switch order.Type {
+	case exchange.Limit:
+		o.Type = string(exchange.Limit)
+		o.Price = order.Price
+		o.TimeInForce = string(order.TimeInForce)
+		o.PostOnly = order.PostOnly
+	case exchange.Stop:
+		// A stop order is a market order which is only placed once the
+		// price falls to (sell) or rises to (buy) the stop price.
+		o.Type = string(exchange.Market)
+		o.StopPrice = order.Price
+		o.Stop = stopLoss
+		if order.Side == exchange.Buy {
+			o.Stop = stopEntry
+		}
+	default:
+		o.Type = string(exchange.Market)
+	}
+
+	res, err := c.client.CreateOrder(ctx, o)
+	if err != nil {
+		return exchange.Order{}, toError(err)
+	}
+
+	return toOrder(res), nil
+}
+
+func (c *coinbase) GetOrder(ctx context.Context, id string) (exchange.Order, error) {
+	res, err := c.client.GetOrder(ctx, id)
+	if err != nil {
+		return exchange.Order{}, toError(err)
+	}
+
+	return toOrder(res), nil
+}
+
+// GetOrderByClientId returns the order placed with the client order ID,
+// ErrOrderNotFound if there isn't one.
+func (c *coinbase) GetOrderByClientId(ctx context.Context, clientOrderId string) (exchange.Order, error) {
+	res, err := c.client.GetOrder(ctx, clientOrderPrefix+clientOrderId)
+	if err != nil {
+		if isNotFound(err) {
+			return exchange.Order{}, fmt.Errorf("%w: %s", exchange.ErrOrderNotFound, clientOrderId)
+		}
+		return exchange.Order{}, toError(err)
+	}
+
+	return toOrder(res), nil
+}
+
+func toOrder(o coinbasepro.Order) exchange.Order {
+	orderType := o.Type
+	if o.Stop != "" {
+		orderType = string(exchange.Stop)
+	}
+
+	return exchange.Order{
+		ID:            o.ID,
+		ProductId:     o.ProductID,
+		Side:          o.Side,
+		Type:          orderType,
+		Status:        o.Status,
+		Settled:       o.Settled,
+		Price:         o.Price,
+		Size:          o.Size,
+		StopPrice:     o.StopPrice,
+		TimeInForce:   o.TimeInForce,
+		PostOnly:      o.PostOnly,
+		CreatedAt:     time.Time(o.CreatedAt),
+		Funds:         o.Funds,
+		FillFees:      o.FillFees,
+		FilledSize:    o.FilledSize,
+		ExecutedValue: o.ExecutedValue,
+	}
+}
+
+func (c *coinbase) CancelOrder(ctx context.Context, id string) error {
+	return toError(c.client.CancelOrder(ctx, id))
+}
+
+func (c *coinbase) CancelAllOrders(ctx context.Context, productId string) ([]string, error) {
+	ids, err := c.client.CancelAllOrders(ctx, coinbasepro.CancelAllOrdersParams{ProductID: productId})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return ids, nil
+}
+
+func (c *coinbase) ListOpenOrders(ctx context.Context, productId string) ([]exchange.Order, error) {
+	cursor := c.client.ListOrders(ctx, coinbasepro.ListOrdersParams{ProductID: productId})
+
+	var orders []exchange.Order
+	for cursor.HasMore {
+		var page []coinbasepro.Order
+		if err := cursor.NextPage(&page); err != nil {
+			return nil, fmt.Errorf("next_page: %w", toError(err))
+		}
+
+		for _, o := range page {
+			orders = append(orders, toOrder(o))
+		}
+	}
+
+	return orders, nil
+}
+
+func (c *coinbase) ListFills(ctx context.Context, productId string, since time.Time) ([]exchange.Fill, error) {
+	cursor := c.client.ListFills(ctx, coinbasepro.ListFillsParams{ProductID: productId})
+
+	var fills []exchange.Fill
+	for cursor.HasMore {
+		var page []coinbasepro.Fill
+		if err := cursor.NextPage(&page); err != nil {
+			return nil, fmt.Errorf("next_page: %w", toError(err))
+		}
+
+		for _, f := range page {
+			// fills are returned newest first
+			if f.CreatedAt.Time().Before(since) {
+				return fills, nil
+			}
+
+			fills = append(fills, toFill(f))
+		}
+	}
+
+	return fills, nil
+}
+
+func (c *coinbase) GetOrderFills(ctx context.Context, orderId string) ([]exchange.Fill, error) {
+	cursor := c.client.ListFills(ctx, coinbasepro.ListFillsParams{OrderID: orderId})
+
+	var fills []exchange.Fill
+	for cursor.HasMore {
+		var page []coinbasepro.Fill
+		if err := cursor.NextPage(&page); err != nil {
+			return nil, fmt.Errorf("next_page: %w", toError(err))
+		}
+
+		for _, f := range page {
+			fills = append(fills, toFill(f))
+		}
+	}
+
+	return fills, nil
+}
+
+// toFill converts a Coinbase Pro fill, FillID holds the order ID of the fill.
+func toFill(f coinbasepro.Fill) exchange.Fill {
+	return exchange.Fill{
+		ID:        strconv.Itoa(f.TradeID),
+		OrderId:   f.FillID,
+		ProductId: f.ProductID,
+		Side:      f.Side,
+		Price:     f.Price,
+		Size:      f.Size,
+		Fee:       f.Fee,
+		Liquidity: f.Liquidity,
+		CreatedAt: f.CreatedAt.Time(),
+	}
+}
+
+func (c *coinbase) GetAccounts(ctx context.Context) ([]exchange.Account, error) {
+	res, err := c.client.GetAccounts(ctx)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	accounts := make([]exchange.Account, len(res))
+	for i, a := range res {
+		accounts[i] = exchange.Account{
+			ID:        a.ID,
+			Currency:  a.Currency,
+			Balance:   a.Balance,
+			Hold:      a.Hold,
+			Available: a.Available,
+		}
+	}
+
+	return accounts, nil
+}
+
+func (c *coinbase) GetProducts(ctx context.Context) ([]exchange.Product, error) {
+	res, err := c.client.GetProducts(ctx)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	products := make([]exchange.Product, len(res))
+	for i, p := range res {
+		products[i] = exchange.Product{
+			ID:             p.ID,
+			BaseCurrency:   p.BaseCurrency,
+			QuoteCurrency:  p.QuoteCurrency,
+			BaseMinSize:    p.BaseMinSize,
+			BaseMaxSize:    p.BaseMaxSize,
+			BaseIncrement:  p.BaseIncrement,
+			QuoteIncrement: p.QuoteIncrement,
+		}
+	}
+
+	return products, nil
+}
+
+func (c *coinbase) GetTicker(ctx context.Context, productId string) (exchange.Ticker, error) {
+	res, err := c.client.GetTicker(ctx, productId)
+	if err != nil {
+		return exchange.Ticker{}, toError(err)
+	}
+
+	return exchange.Ticker{
+		ProductId: productId,
+		Price:     res.Price,
+		Time:      res.Time.Time(),
+	}, nil
+}
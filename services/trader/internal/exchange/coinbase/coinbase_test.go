@@ -0,0 +1,558 @@
+package coinbase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/coinbase"
+	"github.com/cshep4/kripto/services/trader/internal/mocks/coinbase"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/golang/mock/gomock"
+	"github.com/preichenberger/go-coinbasepro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if client is empty", func(t *testing.T) {
+		c, err := coinbase.New(nil)
+		require.Error(t, err)
+
+		assert.Nil(t, c)
+
+		ipErr, ok := err.(coinbase.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "client", ipErr.Parameter)
+	})
+
+	t.Run("returns exchange", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c, err := coinbase.New(coinbase_mocks.NewMockClient(ctrl))
+		require.NoError(t, err)
+
+		assert.NotNil(t, c)
+	})
+}
+
+func TestCoinbase_CreateOrder(t *testing.T) {
+	t.Run("returns error if error creating order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+
+		client.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(coinbasepro.Order{}, testErr)
+
+		order, err := c.CreateOrder(context.Background(), exchange.NewOrder{ProductId: "BTC-GBP", Side: exchange.Buy, Type: exchange.Market, Funds: "10.00"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Empty(t, order)
+	})
+
+	t.Run("returns insufficient funds error if account can't cover order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(coinbasepro.Order{}, coinbasepro.Error{Message: "Insufficient funds"})
+
+		_, err = c.CreateOrder(context.Background(), exchange.NewOrder{ProductId: "BTC-GBP", Side: exchange.Buy, Type: exchange.Market, Funds: "10.00"})
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.InsufficientFunds, apperror.CodeOf(err))
+	})
+
+	t.Run("returns rate limited error if rate limit exceeded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(coinbasepro.Order{}, coinbasepro.Error{Message: "Private rate limit exceeded"})
+
+		_, err = c.CreateOrder(context.Background(), exchange.NewOrder{ProductId: "BTC-GBP", Side: exchange.Buy, Type: exchange.Market, Funds: "10.00"})
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.RateLimited, apperror.CodeOf(err))
+	})
+
+	t.Run("returns exchange unavailable error if coinbase fails to handle request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(coinbasepro.Order{}, coinbasepro.Error{Message: "Internal server error"})
+
+		_, err = c.CreateOrder(context.Background(), exchange.NewOrder{ProductId: "BTC-GBP", Side: exchange.Buy, Type: exchange.Market, Funds: "10.00"})
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.ExchangeUnavailable, apperror.CodeOf(err))
+	})
+
+	t.Run("returns exchange rejected error if order is refused", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(coinbasepro.Order{}, coinbasepro.Error{Message: "size is too small"})
+
+		_, err = c.CreateOrder(context.Background(), exchange.NewOrder{ProductId: "BTC-GBP", Side: exchange.Buy, Type: exchange.Market, Funds: "10.00"})
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.ExchangeRejected, apperror.CodeOf(err))
+	})
+
+	t.Run("returns timeout error if request times out", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(coinbasepro.Order{}, &url.Error{Op: "Post", URL: "https://api.pro.coinbase.com/orders", Err: timeoutError{}})
+
+		_, err = c.CreateOrder(context.Background(), exchange.NewOrder{ProductId: "BTC-GBP", Side: exchange.Buy, Type: exchange.Market, Funds: "10.00"})
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.Timeout, apperror.CodeOf(err))
+	})
+
+	t.Run("places market order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().CreateOrder(gomock.Any(), &coinbasepro.Order{
+			Side:      "buy",
+			ProductID: "BTC-GBP",
+			Type:      "market",
+			Funds:     "10.00",
+			ClientOID: "client-id",
+		}).Return(coinbasepro.Order{ID: "id", ProductID: "BTC-GBP", Type: "market", Status: "pending"}, nil)
+
+		order, err := c.CreateOrder(context.Background(), exchange.NewOrder{ProductId: "BTC-GBP", Side: exchange.Buy, Type: exchange.Market, Funds: "10.00", ClientOrderId: "client-id"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "id", order.ID)
+		assert.Equal(t, "BTC-GBP", order.ProductId)
+		assert.Equal(t, "market", order.Type)
+		assert.Equal(t, exchange.StatusPending, order.Status)
+	})
+
+	t.Run("places limit order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().CreateOrder(gomock.Any(), &coinbasepro.Order{
+			Side:        "sell",
+			ProductID:   "BTC-GBP",
+			Type:        "limit",
+			Price:       "8000.00",
+			Size:        "0.10000000",
+			TimeInForce: "GTC",
+			PostOnly:    true,
+		}).Return(coinbasepro.Order{ID: "id"}, nil)
+
+		order, err := c.CreateOrder(context.Background(), exchange.NewOrder{
+			ProductId:   "BTC-GBP",
+			Side:        exchange.Sell,
+			Type:        exchange.Limit,
+			Price:       "8000.00",
+			Size:        "0.10000000",
+			TimeInForce: exchange.GoodTillCancelled,
+			PostOnly:    true,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "id", order.ID)
+	})
+
+	t.Run("places sell stop order as stop loss market order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().CreateOrder(gomock.Any(), &coinbasepro.Order{
+			Side:      "sell",
+			ProductID: "BTC-GBP",
+			Type:      "market",
+			Size:      "0.10000000",
+			Stop:      "loss",
+			StopPrice: "7000.00",
+		}).Return(coinbasepro.Order{ID: "id", Type: "market", Stop: "loss", StopPrice: "7000.00"}, nil)
+
+		order, err := c.CreateOrder(context.Background(), exchange.NewOrder{
+			ProductId: "BTC-GBP",
+			Side:      exchange.Sell,
+			Type:      exchange.Stop,
+			Price:     "7000.00",
+			Size:      "0.10000000",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "stop", order.Type)
+		assert.Equal(t, "7000.00", order.StopPrice)
+	})
+
+	t.Run("places buy stop order as stop entry market order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().CreateOrder(gomock.Any(), &coinbasepro.Order{
+			Side:      "buy",
+			ProductID: "BTC-GBP",
+			Type:      "market",
+			Funds:     "100.00",
+			Stop:      "entry",
+			StopPrice: "9000.00",
+		}).Return(coinbasepro.Order{ID: "id"}, nil)
+
+		_, err = c.CreateOrder(context.Background(), exchange.NewOrder{
+			ProductId: "BTC-GBP",
+			Side:      exchange.Buy,
+			Type:      exchange.Stop,
+			Price:     "9000.00",
+			Funds:     "100.00",
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestCoinbase_GetOrderByClientId(t *testing.T) {
+	t.Run("returns order not found error if no order has client order id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().GetOrder(gomock.Any(), "client:client-id").Return(coinbasepro.Order{}, coinbasepro.Error{Message: "NotFound"})
+
+		_, err = c.GetOrderByClientId(context.Background(), "client-id")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, exchange.ErrOrderNotFound))
+	})
+
+	t.Run("returns error if error getting order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().GetOrder(gomock.Any(), "client:client-id").Return(coinbasepro.Order{}, coinbasepro.Error{Message: "Private rate limit exceeded"})
+
+		_, err = c.GetOrderByClientId(context.Background(), "client-id")
+		require.Error(t, err)
+
+		assert.False(t, errors.Is(err, exchange.ErrOrderNotFound))
+		assert.Equal(t, apperror.RateLimited, apperror.CodeOf(err))
+	})
+
+	t.Run("returns order placed with client order id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().GetOrder(gomock.Any(), "client:client-id").Return(coinbasepro.Order{ID: "id", ProductID: "BTC-GBP"}, nil)
+
+		order, err := c.GetOrderByClientId(context.Background(), "client-id")
+		require.NoError(t, err)
+
+		assert.Equal(t, "id", order.ID)
+	})
+}
+
+func TestCoinbase_GetAccounts(t *testing.T) {
+	t.Run("returns accounts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().GetAccounts(gomock.Any()).Return([]coinbasepro.Account{{
+			ID:        "id",
+			Currency:  "BTC",
+			Balance:   "1.5",
+			Hold:      "0.5",
+			Available: "1.0",
+		}}, nil)
+
+		accounts, err := c.GetAccounts(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []exchange.Account{{
+			ID:        "id",
+			Currency:  "BTC",
+			Balance:   "1.5",
+			Hold:      "0.5",
+			Available: "1.0",
+		}}, accounts)
+	})
+}
+
+func TestCoinbase_GetTicker(t *testing.T) {
+	t.Run("returns ticker", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().GetTicker(gomock.Any(), "BTC-GBP").Return(coinbasepro.Ticker{Price: "31234.56"}, nil)
+
+		ticker, err := c.GetTicker(context.Background(), "BTC-GBP")
+		require.NoError(t, err)
+
+		assert.Equal(t, "BTC-GBP", ticker.ProductId)
+		assert.Equal(t, "31234.56", ticker.Price)
+	})
+}
+
+func TestCoinbase_ListOpenOrders(t *testing.T) {
+	t.Run("returns error if error listing orders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message":"error"}`))
+		}))
+		defer server.Close()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().ListOrders(gomock.Any(), coinbasepro.ListOrdersParams{}).Return(newCursor(server.URL))
+
+		orders, err := c.ListOpenOrders(context.Background(), "")
+		require.Error(t, err)
+
+		assert.Empty(t, orders)
+	})
+
+	t.Run("returns orders from all pages", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("after") == "" {
+				w.Header().Set("CB-AFTER", "cursor")
+				json.NewEncoder(w).Encode([]coinbasepro.Order{{ID: "id1", Status: "open"}})
+				return
+			}
+			json.NewEncoder(w).Encode([]coinbasepro.Order{{ID: "id2", Status: "pending"}})
+		}))
+		defer server.Close()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().ListOrders(gomock.Any(), coinbasepro.ListOrdersParams{ProductID: "BTC-GBP"}).Return(newCursor(server.URL))
+
+		orders, err := c.ListOpenOrders(context.Background(), "BTC-GBP")
+		require.NoError(t, err)
+
+		require.Len(t, orders, 2)
+		assert.Equal(t, "id1", orders[0].ID)
+		assert.Equal(t, "open", orders[0].Status)
+		assert.Equal(t, "id2", orders[1].ID)
+		assert.Equal(t, "pending", orders[1].Status)
+	})
+}
+
+func TestCoinbase_ListFills(t *testing.T) {
+	since := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("returns error if error listing fills", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message":"error"}`))
+		}))
+		defer server.Close()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().ListFills(gomock.Any(), coinbasepro.ListFillsParams{ProductID: "BTC-GBP"}).Return(newCursor(server.URL))
+
+		fills, err := c.ListFills(context.Background(), "BTC-GBP", since)
+		require.Error(t, err)
+
+		assert.Empty(t, fills)
+	})
+
+	t.Run("returns fills since time from all pages", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("after") == "" {
+				w.Header().Set("CB-AFTER", "cursor")
+				w.Write([]byte(`[{"order_id":"id1","price":"30000.00","size":"0.001","fee":"0.15","liquidity":"T","created_at":"2021-05-01T12:00:00Z"}]`))
+				return
+			}
+			w.Write([]byte(`[
+				{"order_id":"id2","price":"30000.10","size":"0.002","fee":"0.3","liquidity":"M","created_at":"2021-05-01T01:00:00Z"},
+				{"order_id":"id3","price":"29000.00","size":"1","fee":"145","liquidity":"T","created_at":"2021-04-30T23:59:59Z"}
+			]`))
+		}))
+		defer server.Close()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().ListFills(gomock.Any(), coinbasepro.ListFillsParams{ProductID: "BTC-GBP"}).Return(newCursor(server.URL))
+
+		fills, err := c.ListFills(context.Background(), "BTC-GBP", since)
+		require.NoError(t, err)
+
+		require.Len(t, fills, 2)
+		assert.Equal(t, "id1", fills[0].OrderId)
+		assert.Equal(t, exchange.Taker, fills[0].Liquidity)
+		assert.Equal(t, "id2", fills[1].OrderId)
+		assert.Equal(t, exchange.Maker, fills[1].Liquidity)
+		assert.Equal(t, time.Date(2021, 5, 1, 1, 0, 0, 0, time.UTC), fills[1].CreatedAt)
+	})
+}
+
+func TestCoinbase_GetOrderFills(t *testing.T) {
+	t.Run("returns error if error listing fills", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message":"error"}`))
+		}))
+		defer server.Close()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().ListFills(gomock.Any(), coinbasepro.ListFillsParams{OrderID: "id"}).Return(newCursor(server.URL))
+
+		fills, err := c.GetOrderFills(context.Background(), "id")
+		require.Error(t, err)
+
+		assert.Empty(t, fills)
+	})
+
+	t.Run("returns fills of order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[
+				{"trade_id":2,"product_id":"BTC-GBP","order_id":"id","price":"30000.10","size":"0.002","fee":"0.3","liquidity":"T","side":"buy","created_at":"2021-05-01T01:00:01Z"},
+				{"trade_id":1,"product_id":"BTC-GBP","order_id":"id","price":"30000.00","size":"0.001","fee":"0","liquidity":"M","side":"buy","created_at":"2021-05-01T01:00:00Z"}
+			]`))
+		}))
+		defer server.Close()
+
+		client := coinbase_mocks.NewMockClient(ctrl)
+
+		c, err := coinbase.New(client)
+		require.NoError(t, err)
+
+		client.EXPECT().ListFills(gomock.Any(), coinbasepro.ListFillsParams{OrderID: "id"}).Return(newCursor(server.URL))
+
+		fills, err := c.GetOrderFills(context.Background(), "id")
+		require.NoError(t, err)
+
+		assert.Equal(t, []exchange.Fill{
+			{ID: "2", OrderId: "id", ProductId: "BTC-GBP", Side: "buy", Price: "30000.10", Size: "0.002", Fee: "0.3", Liquidity: exchange.Taker, CreatedAt: time.Date(2021, 5, 1, 1, 0, 1, 0, time.UTC)},
+			{ID: "1", OrderId: "id", ProductId: "BTC-GBP", Side: "buy", Price: "30000.00", Size: "0.001", Fee: "0", Liquidity: exchange.Maker, CreatedAt: time.Date(2021, 5, 1, 1, 0, 0, 0, time.UTC)},
+		}, fills)
+	})
+}
+
+func newCursor(url string) *coinbasepro.Cursor {
+	client := coinbasepro.NewClient()
+	client.UpdateConfig(&coinbasepro.ClientConfig{BaseURL: url})
+
+	return coinbasepro.NewCursor(client, http.MethodGet, "/orders", &coinbasepro.PaginationParams{})
+}
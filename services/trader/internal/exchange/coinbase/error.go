@@ -0,0 +1,69 @@
+package coinbase
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/preichenberger/go-coinbasepro/v2"
+)
+
+// toError categorises an error returned by the Coinbase Pro client. The client
+// doesn't expose the status code, so API errors are categorised by message,
+// other than rate limited requests which are returned by the client's transport.
+func toError(err error) error {
+	var rlErr exchange.RateLimitError
+	if errors.As(err, &rlErr) {
+		return rlErr
+	}
+
+	var cErr coinbasepro.Error
+	if !errors.As(err, &cErr) {
+		return exchange.RequestError(err)
+	}
+
+	msg := strings.ToLower(cErr.Message)
+	switch {
+	case strings.Contains(msg, "insufficient funds"):
+		return apperror.New(apperror.InsufficientFunds, err)
+	case strings.Contains(msg, "rate limit"):
+		return apperror.New(apperror.RateLimited, err)
+	case strings.Contains(msg, "timeout"):
+		return apperror.New(apperror.Timeout, err)
+	case msg == "",
+		strings.Contains(msg, "internal server error"),
+		strings.Contains(msg, "service unavailable"),
+		strings.Contains(msg, "gateway"),
+		strings.Contains(msg, "try again"):
+		return apperror.New(apperror.ExchangeUnavailable, err)
+	default:
+		return apperror.New(apperror.ExchangeRejected, err)
+	}
+}
+
+// isNotFound returns true if the Coinbase Pro API responded with 404 Not Found.
+func isNotFound(err error) bool {
+	var cErr coinbasepro.Error
+	if !errors.As(err, &cErr) {
+		return false
+	}
+
+	msg := strings.ToLower(strings.Replace(cErr.Message, " ", "", -1))
+	return msg == "notfound"
+}
+
+// isUnauthorized returns true if the Coinbase Pro API rejected the credentials
+// the request was signed with, e.g. because they have been rotated.
+func isUnauthorized(err error) bool {
+	var cErr coinbasepro.Error
+	if !errors.As(err, &cErr) {
+		return false
+	}
+
+	msg := strings.ToLower(cErr.Message)
+	return strings.Contains(msg, "invalid api key") ||
+		strings.Contains(msg, "invalid passphrase") ||
+		strings.Contains(msg, "invalid signature") ||
+		strings.Contains(msg, "unauthorized")
+}
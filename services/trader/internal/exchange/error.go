@@ -0,0 +1,79 @@
+package exchange
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cshep4/kripto/shared/go/apperror"
+)
+
+var (
+	// ErrOrderNotFound is returned when the exchange has no order with the ID.
+	ErrOrderNotFound = apperror.New(apperror.ExchangeRejected, errors.New("order not found"))
+	// ErrNotSupported is returned when the exchange doesn't support the call.
+	ErrNotSupported = errors.New("not supported by exchange")
+)
+
+// RateLimitError is returned when the exchange rejects a request because too
+// many have been made. RetryAfter is how long the exchange asked to wait before
+// retrying, zero if it didn't say.
+type RateLimitError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (r RateLimitError) Error() string {
+	if r.RetryAfter > 0 {
+		return fmt.Sprintf("%s, retry after %s", r.Err, r.RetryAfter)
+	}
+	return r.Err.Error()
+}
+
+func (r RateLimitError) Unwrap() error {
+	return r.Err
+}
+
+func (RateLimitError) ErrorCode() apperror.Code {
+	return apperror.RateLimited
+}
+
+// RequestError categorises an error sending a request to an exchange. Timeouts
+// and connection failures may succeed if retried, as may a response which
+// isn't valid JSON, e.g. an error page from a load balancer. Any other error is
+// returned as is.
+func RequestError(err error) error {
+	var (
+		netErr    net.Error
+		syntaxErr *json.SyntaxError
+	)
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return apperror.New(apperror.Timeout, err)
+	case errors.As(err, &netErr), errors.As(err, &syntaxErr):
+		return apperror.New(apperror.ExchangeUnavailable, err)
+	default:
+		return err
+	}
+}
+
+// RetryAfter parses the Retry-After header of a rate limited response, either a
+// number of seconds or a date. It returns zero if there is no header.
+func RetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil && time.Until(t) > 0 {
+		return time.Until(t)
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+// Package exchange contains the exchange-neutral types used to place and query
+// orders, implemented for each supported venue by its subpackages.
+package exchange
+
+import "time"
+
+const (
+	Buy  Side = "buy"
+	Sell Side = "sell"
+
+	Market OrderType = "market"
+	Limit  OrderType = "limit"
+	Stop   OrderType = "stop"
+
+	GoodTillCancelled TimeInForce = "GTC"
+	ImmediateOrCancel TimeInForce = "IOC"
+	FillOrKill        TimeInForce = "FOK"
+
+	// StatusPending is an order which has been received but not yet processed.
+	StatusPending = "pending"
+	// StatusOpen is an order resting on the order book.
+	StatusOpen = "open"
+	// StatusActive is a stop order waiting for the market to reach its stop price.
+	StatusActive = "active"
+	// StatusDone is an order which is no longer on the order book, filled or otherwise.
+	StatusDone = "done"
+
+	Maker = "M"
+	Taker = "T"
+)
+
+type (
+	Side        string
+	OrderType   string
+	TimeInForce string
+
+	// NewOrder is an order to be placed on an exchange. Amounts have already been
+	// rounded to the precision of the product. Funds and Price are in the quote
+	// currency and Size is in the base currency. Stop orders are placed as market
+	// orders once the market reaches Price. ClientOrderId is optional, if set the
+	// order can be found by it when the response to placing it is lost.
+	NewOrder struct {
+		ProductId     string
+		Side          Side
+		Type          OrderType
+		Funds         string
+		Size          string
+		Price         string
+		TimeInForce   TimeInForce
+		PostOnly      bool
+		ClientOrderId string
+	}
+
+	// Order is the state of an order on an exchange.
+	Order struct {
+		ID            string
+		ProductId     string
+		Side          string
+		Type          string
+		Status        string
+		Settled       bool
+		Price         string // Limit price in quote currency.
+		Size          string // Requested size in base currency.
+		StopPrice     string // Trigger price in quote currency.
+		TimeInForce   string
+		PostOnly      bool
+		CreatedAt     time.Time
+		Funds         string // Requested funds in quote currency.
+		FillFees      string // Fees in quote currency.
+		FilledSize    string // Filled size in base currency.
+		ExecutedValue string // Filled value in quote currency.
+	}
+
+	Product struct {
+		ID             string
+		BaseCurrency   string
+		QuoteCurrency  string
+		BaseMinSize    string
+		BaseMaxSize    string
+		BaseIncrement  string
+		QuoteIncrement string
+	}
+
+	Account struct {
+		ID        string
+		Currency  string
+		Balance   string
+		Hold      string
+		Available string
+	}
+
+	Ticker struct {
+		ProductId string
+		Price     string
+		Time      time.Time
+	}
+
+	Fill struct {
+		ID        string
+		OrderId   string
+		ProductId string
+		Side      string
+		Price     string
+		Size      string
+		Fee       string
+		Liquidity string // Maker or Taker.
+		CreatedAt time.Time
+	}
+)
@@ -0,0 +1,546 @@
+// Package fake implements a deterministic in-memory exchange for running the
+// trader locally and in end-to-end tests without calling a real exchange.
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/sim"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInsufficientFunds  = sim.ErrInsufficientFunds
+	ErrOrderNotFound      = exchange.ErrOrderNotFound
+	ErrUnsupportedProduct = apperror.New(apperror.ExchangeRejected, errors.New("unsupported product"))
+	ErrNoPrice            = apperror.New(apperror.ExchangeRejected, errors.New("no price set for product"))
+	ErrPostOnly           = sim.ErrPostOnly
+)
+
+type (
+	account struct {
+		balance decimal.Decimal
+		hold    decimal.Decimal
+	}
+
+	order struct {
+		exchange.Order
+		seq       int
+		req       sim.Order
+		filled    decimal.Decimal
+		value     decimal.Decimal
+		fees      decimal.Decimal
+		hold      decimal.Decimal
+		holdAsset string
+		clientId  string
+	}
+
+	fake struct {
+		lock      sync.Mutex
+		products  map[string]exchange.Product
+		prices    map[string]decimal.Decimal
+		accounts  map[string]*account
+		orders    map[string]*order
+		fills     []exchange.Fill
+		feeRate   decimal.Decimal
+		fillRatio decimal.Decimal
+		latency   time.Duration
+		errs      map[string]error
+		now       func() time.Time
+		nextId    int
+	}
+)
+
+// New creates an empty fake exchange, products, prices and balances are set using options.
+func New(opts ...Option) *fake {
+	f := &fake{
+		products:  make(map[string]exchange.Product),
+		prices:    make(map[string]decimal.Decimal),
+		accounts:  make(map[string]*account),
+		orders:    make(map[string]*order),
+		fillRatio: decimal.New(1, 0),
+		errs:      make(map[string]error),
+		now:       time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// call simulates the latency of a request and returns any error injected for the method.
+func (f *fake) call(method string) error {
+	if f.latency > 0 {
+		time.Sleep(f.latency)
+	}
+
+	return f.errs[method]
+}
+
+func (f *fake) CreateOrder(ctx context.Context, req exchange.NewOrder) (exchange.Order, error) {
+	if err := f.call("CreateOrder"); err != nil {
+		return exchange.Order{}, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	product, ok := f.products[req.ProductId]
+	if !ok {
+		return exchange.Order{}, fmt.Errorf("%w: %s", ErrUnsupportedProduct, req.ProductId)
+	}
+	price, ok := f.prices[req.ProductId]
+	if !ok {
+		return exchange.Order{}, fmt.Errorf("%w: %s", ErrNoPrice, req.ProductId)
+	}
+
+	o, err := f.newOrder(req)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	if err := f.place(o, product, price); err != nil {
+		return exchange.Order{}, err
+	}
+
+	f.orders[o.ID] = o
+
+	return o.toOrder(), nil
+}
+
+func (f *fake) newOrder(req exchange.NewOrder) (*order, error) {
+	o := &order{
+		Order: exchange.Order{
+			ProductId:   req.ProductId,
+			Side:        string(req.Side),
+			Type:        string(req.Type),
+			Status:      exchange.StatusPending,
+			Price:       req.Price,
+			Size:        req.Size,
+			Funds:       req.Funds,
+			TimeInForce: string(req.TimeInForce),
+			PostOnly:    req.PostOnly,
+			CreatedAt:   f.now().UTC(),
+		},
+		clientId: req.ClientOrderId,
+	}
+
+	var err error
+	if o.req, err = sim.NewOrder(req); err != nil {
+		return nil, err
+	}
+	o.Type = string(o.req.Type)
+
+	f.nextId++
+	o.seq = f.nextId
+	o.ID = fmt.Sprintf("fake-order-%d", o.seq)
+
+	return o, nil
+}
+
+// place fills the part of the order which executes straight away at the price,
+// holding the funds needed to fill the rest of a limit order resting on the book.
+func (f *fake) place(o *order, product exchange.Product, price decimal.Decimal) error {
+	fill, status, err := sim.Place(o.req, price, f.feeRate, f.fillRatio)
+	if err != nil {
+		return err
+	}
+	if err := f.execute(o, product, fill, exchange.Taker); err != nil {
+		return err
+	}
+
+	switch status {
+	case exchange.StatusOpen:
+		if err := f.placeHold(o, product); err != nil {
+			return err
+		}
+	case exchange.StatusActive:
+		o.StopPrice, o.Price = o.Price, ""
+	}
+
+	o.Status = status
+	o.Settled = status == exchange.StatusDone
+
+	return nil
+}
+
+// trigger fills a stop order as a market order at the price.
+func (f *fake) trigger(o *order, product exchange.Product, price decimal.Decimal) error {
+	req := o.req
+	req.Type = exchange.Market
+
+	fill, _, err := sim.Place(req, price, f.feeRate, f.fillRatio)
+	if err != nil {
+		return err
+	}
+
+	return f.execute(o, product, fill, exchange.Taker)
+}
+
+// placeHold holds the funds needed to fill the remainder of a resting order.
+func (f *fake) placeHold(o *order, product exchange.Product) error {
+	asset, amount := sim.Hold(product, o.req, o.filled, f.feeRate)
+
+	a := f.account(asset)
+	if a.balance.Sub(a.hold).LessThan(amount) {
+		return ErrInsufficientFunds
+	}
+
+	a.hold = a.hold.Add(amount)
+	o.hold, o.holdAsset = amount, asset
+
+	return nil
+}
+
+func (f *fake) releaseHold(o *order) {
+	if o.holdAsset == "" {
+		return
+	}
+
+	a := f.account(o.holdAsset)
+	a.hold = a.hold.Sub(o.hold)
+	o.hold, o.holdAsset = decimal.Zero, ""
+}
+
+// execute applies the fill of the order to the balances and records it.
+func (f *fake) execute(o *order, product exchange.Product, fill sim.Fill, liquidity string) error {
+	if !fill.Size.IsPositive() {
+		return nil
+	}
+
+	changes := sim.Changes(product, o.req.Side, fill)
+	if err := sim.Cover(f.available(), changes); err != nil {
+		return err
+	}
+	for currency, change := range changes {
+		a := f.account(currency)
+		a.balance = a.balance.Add(change)
+	}
+
+	o.filled = o.filled.Add(fill.Size)
+	o.value = o.value.Add(fill.Value)
+	o.fees = o.fees.Add(fill.Fee)
+
+	f.fills = append(f.fills, exchange.Fill{
+		ID:        fmt.Sprintf("fake-fill-%d", len(f.fills)+1),
+		OrderId:   o.ID,
+		ProductId: o.ProductId,
+		Side:      o.Side,
+		Price:     fill.Price.String(),
+		Size:      fill.Size.String(),
+		Fee:       fill.Fee.String(),
+		Liquidity: liquidity,
+		CreatedAt: f.now().UTC(),
+	})
+
+	return nil
+}
+
+// available returns the balance of each account which isn't held.
+func (f *fake) available() map[string]decimal.Decimal {
+	available := make(map[string]decimal.Decimal, len(f.accounts))
+	for currency, a := range f.accounts {
+		available[currency] = a.balance.Sub(a.hold)
+	}
+
+	return available
+}
+
+func (f *fake) account(currency string) *account {
+	currency = strings.ToUpper(currency)
+
+	a, ok := f.accounts[currency]
+	if !ok {
+		a = &account{}
+		f.accounts[currency] = a
+	}
+
+	return a
+}
+
+// SetPrice moves the market price of a product, filling any resting limit
+// orders and triggering any stop orders which the new price crosses.
+func (f *fake) SetPrice(productId string, price decimal.Decimal) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	product, ok := f.products[productId]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedProduct, productId)
+	}
+	f.prices[productId] = price
+
+	for _, o := range f.sortedOrders() {
+		if o.ProductId != productId {
+			continue
+		}
+
+		buy := o.Side == string(exchange.Buy)
+		switch o.Status {
+		case exchange.StatusOpen:
+			if (buy && price.GreaterThan(o.req.Price)) || (!buy && price.LessThan(o.req.Price)) {
+				continue
+			}
+			f.releaseHold(o)
+			if err := f.execute(o, product, sim.NewFill(o.req.Price, o.req.Size.Sub(o.filled), f.feeRate), exchange.Maker); err != nil {
+				return fmt.Errorf("fill_order (%s): %w", o.ID, err)
+			}
+		case exchange.StatusActive:
+			if (buy && price.LessThan(o.req.Price)) || (!buy && price.GreaterThan(o.req.Price)) {
+				continue
+			}
+			if err := f.trigger(o, product, price); err != nil {
+				return fmt.Errorf("trigger_order (%s): %w", o.ID, err)
+			}
+		default:
+			continue
+		}
+
+		o.Status = exchange.StatusDone
+		o.Settled = true
+	}
+
+	return nil
+}
+
+func (f *fake) GetOrder(ctx context.Context, id string) (exchange.Order, error) {
+	if err := f.call("GetOrder"); err != nil {
+		return exchange.Order{}, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	o, ok := f.orders[id]
+	if !ok {
+		return exchange.Order{}, fmt.Errorf("%w: %s", ErrOrderNotFound, id)
+	}
+
+	return o.toOrder(), nil
+}
+
+// GetOrderByClientId returns the order placed with the client order ID,
+// ErrOrderNotFound if there isn't one.
+func (f *fake) GetOrderByClientId(ctx context.Context, clientOrderId string) (exchange.Order, error) {
+	if err := f.call("GetOrderByClientId"); err != nil {
+		return exchange.Order{}, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, o := range f.orders {
+		if clientOrderId != "" && o.clientId == clientOrderId {
+			return o.toOrder(), nil
+		}
+	}
+
+	return exchange.Order{}, fmt.Errorf("%w: %s", ErrOrderNotFound, clientOrderId)
+}
+
+func (f *fake) CancelOrder(ctx context.Context, id string) error {
+	if err := f.call("CancelOrder"); err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	o, ok := f.orders[id]
+	if !ok || (o.Status != exchange.StatusOpen && o.Status != exchange.StatusActive) {
+		return fmt.Errorf("%w: %s", ErrOrderNotFound, id)
+	}
+
+	f.cancel(o)
+
+	return nil
+}
+
+func (f *fake) cancel(o *order) {
+	f.releaseHold(o)
+	o.Status = exchange.StatusDone
+	o.Settled = true
+}
+
+func (f *fake) CancelAllOrders(ctx context.Context, productId string) ([]string, error) {
+	if err := f.call("CancelAllOrders"); err != nil {
+		return nil, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var ids []string
+	for _, o := range f.sortedOrders() {
+		if !o.open() || (productId != "" && o.ProductId != productId) {
+			continue
+		}
+		f.cancel(o)
+		ids = append(ids, o.ID)
+	}
+
+	return ids, nil
+}
+
+func (f *fake) ListOpenOrders(ctx context.Context, productId string) ([]exchange.Order, error) {
+	if err := f.call("ListOpenOrders"); err != nil {
+		return nil, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var orders []exchange.Order
+	for _, o := range f.sortedOrders() {
+		if !o.open() || (productId != "" && o.ProductId != productId) {
+			continue
+		}
+		orders = append(orders, o.toOrder())
+	}
+
+	return orders, nil
+}
+
+func (f *fake) ListFills(ctx context.Context, productId string, since time.Time) ([]exchange.Fill, error) {
+	if err := f.call("ListFills"); err != nil {
+		return nil, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var fills []exchange.Fill
+	for i := len(f.fills) - 1; i >= 0; i-- {
+		fill := f.fills[i]
+		if fill.ProductId == productId && !fill.CreatedAt.Before(since) {
+			fills = append(fills, fill)
+		}
+	}
+
+	return fills, nil
+}
+
+func (f *fake) GetOrderFills(ctx context.Context, orderId string) ([]exchange.Fill, error) {
+	if err := f.call("GetOrderFills"); err != nil {
+		return nil, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, ok := f.orders[orderId]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, orderId)
+	}
+
+	var fills []exchange.Fill
+	for i := len(f.fills) - 1; i >= 0; i-- {
+		if f.fills[i].OrderId == orderId {
+			fills = append(fills, f.fills[i])
+		}
+	}
+
+	return fills, nil
+}
+
+func (f *fake) GetAccounts(ctx context.Context) ([]exchange.Account, error) {
+	if err := f.call("GetAccounts"); err != nil {
+		return nil, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	accounts := make([]exchange.Account, 0, len(f.accounts))
+	for currency, a := range f.accounts {
+		accounts = append(accounts, exchange.Account{
+			ID:        "fake-account-" + strings.ToLower(currency),
+			Currency:  currency,
+			Balance:   a.balance.String(),
+			Hold:      a.hold.String(),
+			Available: a.balance.Sub(a.hold).String(),
+		})
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Currency < accounts[j].Currency
+	})
+
+	return accounts, nil
+}
+
+func (f *fake) GetProducts(ctx context.Context) ([]exchange.Product, error) {
+	if err := f.call("GetProducts"); err != nil {
+		return nil, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	products := make([]exchange.Product, 0, len(f.products))
+	for _, p := range f.products {
+		products = append(products, p)
+	}
+
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].ID < products[j].ID
+	})
+
+	return products, nil
+}
+
+func (f *fake) GetTicker(ctx context.Context, productId string) (exchange.Ticker, error) {
+	if err := f.call("GetTicker"); err != nil {
+		return exchange.Ticker{}, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	price, ok := f.prices[productId]
+	if !ok {
+		return exchange.Ticker{}, fmt.Errorf("%w: %s", ErrNoPrice, productId)
+	}
+
+	return exchange.Ticker{
+		ProductId: productId,
+		Price:     price.String(),
+		Time:      f.now().UTC(),
+	}, nil
+}
+
+// sortedOrders returns the orders newest first.
+func (f *fake) sortedOrders() []*order {
+	orders := make([]*order, 0, len(f.orders))
+	for _, o := range f.orders {
+		orders = append(orders, o)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].seq > orders[j].seq
+	})
+
+	return orders
+}
+
+func (o *order) open() bool {
+	return o.Status == exchange.StatusOpen || o.Status == exchange.StatusActive
+}
+
+func (o *order) toOrder() exchange.Order {
+	res := o.Order
+	res.FilledSize = o.filled.String()
+	res.ExecutedValue = o.value.String()
+	res.FillFees = o.fees.String()
+
+	return res
+}
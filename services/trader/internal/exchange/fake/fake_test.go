@@ -0,0 +1,314 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/fake"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/retry"
+	"github.com/cshep4/kripto/services/trader/internal/trader"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const productId = "BTC-GBP"
+
+var (
+	now = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	_ trader.Exchange = fake.New()
+)
+
+func newExchange(opts ...fake.Option) exchangeUnderTest {
+	opts = append([]fake.Option{
+		fake.WithPrice(productId, decimal.RequireFromString("40000")),
+		fake.WithBalance("GBP", decimal.RequireFromString("1000")),
+		fake.WithBalance("BTC", decimal.RequireFromString("0.1")),
+		fake.WithClock(func() time.Time { return now }),
+	}, opts...)
+
+	return fake.New(opts...)
+}
+
+type exchangeUnderTest interface {
+	trader.Exchange
+	retry.OrderFinder
+	SetPrice(productId string, price decimal.Decimal) error
+}
+
+func balances(t *testing.T, ex exchangeUnderTest) map[string]exchange.Account {
+	accounts, err := ex.GetAccounts(context.Background())
+	require.NoError(t, err)
+
+	res := make(map[string]exchange.Account)
+	for _, a := range accounts {
+		res[a.Currency] = a
+	}
+	return res
+}
+
+func TestFake_CreateOrder(t *testing.T) {
+	t.Run("returns error if product is not supported", func(t *testing.T) {
+		ex := newExchange()
+
+		_, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: "ETH-GBP", Side: exchange.Buy, Funds: "10"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, fake.ErrUnsupportedProduct))
+	})
+
+	t.Run("returns injected error", func(t *testing.T) {
+		testErr := errors.New("error")
+		ex := newExchange(fake.WithError("CreateOrder", testErr))
+
+		_, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Buy, Funds: "10"})
+		require.Error(t, err)
+
+		assert.Equal(t, testErr, err)
+	})
+
+	t.Run("returns error if there are insufficient funds", func(t *testing.T) {
+		ex := newExchange()
+
+		_, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Sell, Size: "1"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, fake.ErrInsufficientFunds))
+	})
+
+	t.Run("fills market buy with funds including fees", func(t *testing.T) {
+		ex := newExchange(fake.WithFeeRate(decimal.RequireFromString("0.005")))
+
+		res, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Buy, Type: exchange.Market, Funds: "100.5"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "fake-order-1", res.ID)
+		assert.Equal(t, exchange.StatusDone, res.Status)
+		assert.True(t, res.Settled)
+		assert.Equal(t, "0.0025", res.FilledSize)
+		assert.Equal(t, "100", res.ExecutedValue)
+		assert.Equal(t, "0.5", res.FillFees)
+		assert.Equal(t, now, res.CreatedAt)
+
+		accounts := balances(t, ex)
+		assert.Equal(t, "899.5", accounts["GBP"].Balance)
+		assert.Equal(t, "0.1025", accounts["BTC"].Balance)
+
+		fills, err := ex.ListFills(context.Background(), productId, now)
+		require.NoError(t, err)
+		require.Len(t, fills, 1)
+		assert.Equal(t, exchange.Fill{
+			ID:        "fake-fill-1",
+			OrderId:   res.ID,
+			ProductId: productId,
+			Side:      string(exchange.Buy),
+			Price:     "40000",
+			Size:      "0.0025",
+			Fee:       "0.5",
+			Liquidity: exchange.Taker,
+			CreatedAt: now,
+		}, fills[0])
+	})
+
+	t.Run("partially fills market sell", func(t *testing.T) {
+		ex := newExchange(fake.WithFillRatio(decimal.RequireFromString("0.5")))
+
+		res, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Sell, Size: "0.1"})
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.StatusDone, res.Status)
+		assert.Equal(t, "0.05", res.FilledSize)
+		assert.Equal(t, "2000", res.ExecutedValue)
+
+		accounts := balances(t, ex)
+		assert.Equal(t, "3000", accounts["GBP"].Balance)
+		assert.Equal(t, "0.05", accounts["BTC"].Balance)
+	})
+
+	t.Run("returns error if post only limit order would execute", func(t *testing.T) {
+		ex := newExchange()
+
+		_, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Buy, Type: exchange.Limit, Price: "41000", Size: "0.01", PostOnly: true})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, fake.ErrPostOnly))
+	})
+
+	t.Run("rests limit order and holds funds until price crosses", func(t *testing.T) {
+		ex := newExchange()
+
+		res, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Buy, Type: exchange.Limit, Price: "30000", Size: "0.01"})
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.StatusOpen, res.Status)
+		assert.Equal(t, "300", balances(t, ex)["GBP"].Hold)
+		assert.Equal(t, "700", balances(t, ex)["GBP"].Available)
+
+		require.NoError(t, ex.SetPrice(productId, decimal.RequireFromString("29000")))
+
+		res, err = ex.GetOrder(context.Background(), res.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.StatusDone, res.Status)
+		assert.Equal(t, "0.01", res.FilledSize)
+		assert.Equal(t, "300", res.ExecutedValue)
+
+		accounts := balances(t, ex)
+		assert.Equal(t, "700", accounts["GBP"].Balance)
+		assert.Equal(t, "0", accounts["GBP"].Hold)
+		assert.Equal(t, "0.11", accounts["BTC"].Balance)
+
+		fills, err := ex.ListFills(context.Background(), productId, now)
+		require.NoError(t, err)
+		require.Len(t, fills, 1)
+		assert.Equal(t, exchange.Maker, fills[0].Liquidity)
+	})
+
+	t.Run("triggers stop order when price falls to stop price", func(t *testing.T) {
+		ex := newExchange()
+
+		res, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Sell, Type: exchange.Stop, Price: "35000", Size: "0.1"})
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.StatusActive, res.Status)
+		assert.Equal(t, "35000", res.StopPrice)
+
+		require.NoError(t, ex.SetPrice(productId, decimal.RequireFromString("36000")))
+		res, err = ex.GetOrder(context.Background(), res.ID)
+		require.NoError(t, err)
+		assert.Equal(t, exchange.StatusActive, res.Status)
+
+		require.NoError(t, ex.SetPrice(productId, decimal.RequireFromString("34000")))
+		res, err = ex.GetOrder(context.Background(), res.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.StatusDone, res.Status)
+		assert.Equal(t, "3400", res.ExecutedValue)
+	})
+}
+
+func TestFake_GetOrderByClientId(t *testing.T) {
+	t.Run("returns error if no order has client order id", func(t *testing.T) {
+		ex := newExchange()
+
+		_, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Buy, Type: exchange.Market, Funds: "100"})
+		require.NoError(t, err)
+
+		_, err = ex.GetOrderByClientId(context.Background(), "client-id")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, exchange.ErrOrderNotFound))
+	})
+
+	t.Run("returns order placed with client order id", func(t *testing.T) {
+		ex := newExchange()
+
+		res, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Buy, Type: exchange.Market, Funds: "100", ClientOrderId: "client-id"})
+		require.NoError(t, err)
+
+		order, err := ex.GetOrderByClientId(context.Background(), "client-id")
+		require.NoError(t, err)
+
+		assert.Equal(t, res.ID, order.ID)
+	})
+}
+
+func TestFake_CancelOrder(t *testing.T) {
+	t.Run("returns error if order is not open", func(t *testing.T) {
+		ex := newExchange()
+
+		err := ex.CancelOrder(context.Background(), "fake-order-1")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, fake.ErrOrderNotFound))
+	})
+
+	t.Run("cancels order and releases hold", func(t *testing.T) {
+		ex := newExchange()
+
+		res, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Sell, Type: exchange.Limit, Price: "50000", Size: "0.05"})
+		require.NoError(t, err)
+		assert.Equal(t, "0.05", balances(t, ex)["BTC"].Hold)
+
+		err = ex.CancelOrder(context.Background(), res.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, "0", balances(t, ex)["BTC"].Hold)
+
+		orders, err := ex.ListOpenOrders(context.Background(), productId)
+		require.NoError(t, err)
+		assert.Empty(t, orders)
+	})
+}
+
+func TestFake_CancelAllOrders(t *testing.T) {
+	t.Run("cancels all open orders, newest first", func(t *testing.T) {
+		ex := newExchange()
+
+		_, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Buy, Type: exchange.Limit, Price: "30000", Size: "0.01"})
+		require.NoError(t, err)
+		_, err = ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Buy, Funds: "10"})
+		require.NoError(t, err)
+		_, err = ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Sell, Type: exchange.Stop, Price: "30000", Size: "0.01"})
+		require.NoError(t, err)
+
+		orders, err := ex.ListOpenOrders(context.Background(), productId)
+		require.NoError(t, err)
+		require.Len(t, orders, 2)
+
+		ids, err := ex.CancelAllOrders(context.Background(), productId)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"fake-order-3", "fake-order-1"}, ids)
+	})
+}
+
+func TestFake_GetTicker(t *testing.T) {
+	t.Run("returns error if price not set", func(t *testing.T) {
+		ex := newExchange()
+
+		_, err := ex.GetTicker(context.Background(), "ETH-GBP")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, fake.ErrNoPrice))
+	})
+
+	t.Run("returns current price", func(t *testing.T) {
+		ex := newExchange()
+
+		res, err := ex.GetTicker(context.Background(), productId)
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.Ticker{ProductId: productId, Price: "40000", Time: now}, res)
+	})
+}
+
+func TestFake_GetProducts(t *testing.T) {
+	t.Run("returns products added by price", func(t *testing.T) {
+		ex := newExchange()
+
+		res, err := ex.GetProducts(context.Background())
+		require.NoError(t, err)
+
+		require.Len(t, res, 1)
+		assert.Equal(t, productId, res[0].ID)
+		assert.Equal(t, "BTC", res[0].BaseCurrency)
+		assert.Equal(t, "GBP", res[0].QuoteCurrency)
+	})
+}
+
+func TestFake_Latency(t *testing.T) {
+	t.Run("waits before responding", func(t *testing.T) {
+		ex := newExchange(fake.WithLatency(20 * time.Millisecond))
+
+		start := time.Now()
+		_, err := ex.GetAccounts(context.Background())
+		require.NoError(t, err)
+
+		assert.True(t, time.Since(start) >= 20*time.Millisecond)
+	})
+}
@@ -0,0 +1,92 @@
+package fake
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/shopspring/decimal"
+)
+
+type Option func(*fake)
+
+// WithProduct adds a product which can be traded on the exchange.
+func WithProduct(product exchange.Product) Option {
+	return func(f *fake) {
+		f.products[product.ID] = product
+	}
+}
+
+// WithPrice sets the starting price of a product, adding the product if it
+// has not been added using WithProduct. Product IDs must be in the BASE-QUOTE format.
+func WithPrice(productId string, price decimal.Decimal) Option {
+	return func(f *fake) {
+		f.prices[productId] = price
+
+		if _, ok := f.products[productId]; ok {
+			return
+		}
+		parts := strings.Split(productId, "-")
+		if len(parts) != 2 {
+			return
+		}
+		f.products[productId] = exchange.Product{
+			ID:             productId,
+			BaseCurrency:   parts[0],
+			QuoteCurrency:  parts[1],
+			BaseMinSize:    "0.0001",
+			BaseMaxSize:    "10000",
+			BaseIncrement:  "0.00000001",
+			QuoteIncrement: "0.01",
+		}
+	}
+}
+
+// WithBalance sets the starting balance of a currency.
+func WithBalance(currency string, balance decimal.Decimal) Option {
+	return func(f *fake) {
+		f.account(currency).balance = balance
+	}
+}
+
+// WithFeeRate sets the fee charged on each fill as a fraction of its value, e.g. 0.005 for 0.5%.
+func WithFeeRate(rate decimal.Decimal) Option {
+	return func(f *fake) {
+		if !rate.IsNegative() {
+			f.feeRate = rate
+		}
+	}
+}
+
+// WithFillRatio sets the fraction of an order which is filled when it is
+// placed, e.g. 0.5 to only fill half of each order.
+func WithFillRatio(ratio decimal.Decimal) Option {
+	return func(f *fake) {
+		if ratio.IsPositive() && ratio.LessThanOrEqual(decimal.New(1, 0)) {
+			f.fillRatio = ratio
+		}
+	}
+}
+
+// WithLatency sets how long each call to the exchange takes.
+func WithLatency(latency time.Duration) Option {
+	return func(f *fake) {
+		f.latency = latency
+	}
+}
+
+// WithError makes every call to the named method, e.g. "CreateOrder", return err.
+func WithError(method string, err error) Option {
+	return func(f *fake) {
+		f.errs[method] = err
+	}
+}
+
+// WithClock sets the function used to timestamp orders and fills.
+func WithClock(now func() time.Time) Option {
+	return func(f *fake) {
+		if now != nil {
+			f.now = now
+		}
+	}
+}
@@ -0,0 +1,547 @@
+// Package kraken implements the exchange using the Kraken REST API.
+package kraken
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	kraken struct {
+		baseURL string
+		key     string
+		secret  []byte
+		client  *http.Client
+
+		lock      sync.Mutex
+		nonce     int64
+		pairs     map[string]pair // keyed by product ID
+		pairNames map[string]pair // keyed by Kraken pair name and alt name
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+
+	// APIError is returned when the Kraken API responds with errors, e.g. EOrder:Insufficient funds.
+	APIError struct {
+		Errors []string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func (a APIError) Error() string {
+	return fmt.Sprintf("kraken error: %s", strings.Join(a.Errors, ", "))
+}
+
+// ErrorCode categorises the error by the first of the errors returned by Kraken.
+func (a APIError) ErrorCode() apperror.Code {
+	if len(a.Errors) == 0 {
+		return apperror.ExchangeRejected
+	}
+
+	switch e := a.Errors[0]; {
+	case strings.HasPrefix(e, "EOrder:Insufficient funds"):
+		return apperror.InsufficientFunds
+	case strings.Contains(e, "Rate limit"), strings.Contains(e, "Too many requests"):
+		return apperror.RateLimited
+	case strings.HasPrefix(e, "EService:"), strings.HasPrefix(e, "EGeneral:Internal error"):
+		return apperror.ExchangeUnavailable
+	default:
+		return apperror.ExchangeRejected
+	}
+}
+
+// New creates a Kraken exchange using the API key and its base64 encoded private key.
+func New(key, secret string, opts ...Option) (*kraken, error) {
+	if key == "" {
+		return nil, InvalidParameterError{Parameter: "key"}
+	}
+
+	s, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil || len(s) == 0 {
+		return nil, InvalidParameterError{Parameter: "secret"}
+	}
+
+	k := &kraken{
+		baseURL: defaultBaseURL,
+		key:     key,
+		secret:  s,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+
+	for _, opt := range opts {
+		opt(k)
+	}
+
+	return k, nil
+}
+
+func (k *kraken) CreateOrder(ctx context.Context, order exchange.NewOrder) (exchange.Order, error) {
+	p, err := k.pair(order.ProductId)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	params := url.Values{}
+	params.Set("pair", p.Key)
+	params.Set("type", string(order.Side))
+
+	var flags []string
+	switch order.Type {
+	case exchange.Limit:
+		params.Set("ordertype", orderTypeLimit)
+		params.Set("price", order.Price)
+		switch order.TimeInForce {
+		case "", exchange.GoodTillCancelled:
+		case exchange.ImmediateOrCancel:
+			params.Set("timeinforce", string(exchange.ImmediateOrCancel))
+		default:
+			return exchange.Order{}, fmt.Errorf("unsupported_time_in_force: %s", order.TimeInForce)
+		}
+		if order.PostOnly {
+			flags = append(flags, flagPostOnly)
+		}
+	case exchange.Stop:
+		params.Set("ordertype", orderTypeStopLoss)
+		params.Set("price", order.Price)
+	default:
+		params.Set("ordertype", orderTypeMarket)
+	}
+
+	params.Set("volume", order.Size)
+	if order.Size == "" {
+		params.Set("volume", order.Funds)
+		flags = append(flags, flagVolumeInQuote)
+	}
+	if len(flags) > 0 {
+		params.Set("oflags", strings.Join(flags, ","))
+	}
+
+	var res addOrderResult
+	if err := k.private("AddOrder", params, &res); err != nil {
+		return exchange.Order{}, err
+	}
+	if len(res.TxID) == 0 {
+		return exchange.Order{}, fmt.Errorf("missing_txid")
+	}
+
+	return exchange.Order{
+		ID:          res.TxID[0],
+		ProductId:   order.ProductId,
+		Side:        string(order.Side),
+		Type:        string(order.Type),
+		Status:      exchange.StatusPending,
+		Price:       params.Get("price"),
+		Size:        order.Size,
+		Funds:       order.Funds,
+		TimeInForce: string(order.TimeInForce),
+		PostOnly:    order.PostOnly,
+	}, nil
+}
+
+func (k *kraken) GetOrder(ctx context.Context, id string) (exchange.Order, error) {
+	var res map[string]order
+	if err := k.private("QueryOrders", url.Values{"txid": {id}}, &res); err != nil {
+		return exchange.Order{}, err
+	}
+
+	o, ok := res[id]
+	if !ok {
+		return exchange.Order{}, fmt.Errorf("order_not_found: %s", id)
+	}
+
+	return o.toOrder(id, k.productId(o.Descr.Pair)), nil
+}
+
+func (k *kraken) CancelOrder(ctx context.Context, id string) error {
+	return k.private("CancelOrder", url.Values{"txid": {id}}, nil)
+}
+
+// CancelAllOrders cancels each open order individually, as Kraken can only
+// cancel all orders across every pair and does not return their IDs.
+func (k *kraken) CancelAllOrders(ctx context.Context, productId string) ([]string, error) {
+	orders, err := k.ListOpenOrders(ctx, productId)
+	if err != nil {
+		return nil, fmt.Errorf("list_open_orders: %w", err)
+	}
+
+	ids := make([]string, 0, len(orders))
+	for _, o := range orders {
+		if err := k.CancelOrder(ctx, o.ID); err != nil {
+			return ids, fmt.Errorf("cancel_order (%s): %w", o.ID, err)
+		}
+		ids = append(ids, o.ID)
+	}
+
+	return ids, nil
+}
+
+func (k *kraken) ListOpenOrders(ctx context.Context, productId string) ([]exchange.Order, error) {
+	if productId != "" {
+		if _, err := k.pair(productId); err != nil {
+			return nil, err
+		}
+	}
+
+	var res openOrdersResult
+	if err := k.private("OpenOrders", url.Values{}, &res); err != nil {
+		return nil, err
+	}
+
+	var orders []exchange.Order
+	for id, o := range res.Open {
+		order := o.toOrder(id, k.productId(o.Descr.Pair))
+		if productId != "" && !strings.EqualFold(order.ProductId, productId) {
+			continue
+		}
+		orders = append(orders, order)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.After(orders[j].CreatedAt)
+	})
+
+	return orders, nil
+}
+
+func (k *kraken) ListFills(ctx context.Context, productId string, since time.Time) ([]exchange.Fill, error) {
+	p, err := k.pair(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	var fills []exchange.Fill
+	for offset := 0; ; {
+		params := url.Values{}
+		params.Set("start", strconv.FormatInt(since.Unix(), 10))
+		params.Set("ofs", strconv.Itoa(offset))
+
+		var res tradesHistoryResult
+		if err := k.private("TradesHistory", params, &res); err != nil {
+			return nil, err
+		}
+
+		for id, t := range res.Trades {
+			if t.Pair != p.Key && t.Pair != p.AltName {
+				continue
+			}
+			fills = append(fills, t.toFill(id, productId))
+		}
+
+		offset += len(res.Trades)
+		if len(res.Trades) == 0 || offset >= res.Count {
+			break
+		}
+	}
+
+	sort.Slice(fills, func(i, j int) bool {
+		return fills[i].CreatedAt.After(fills[j].CreatedAt)
+	})
+
+	return fills, nil
+}
+
+// GetOrderFills queries the trades of an order, which are only returned by QueryOrders when requested.
+func (k *kraken) GetOrderFills(ctx context.Context, orderId string) ([]exchange.Fill, error) {
+	var orders map[string]order
+	if err := k.private("QueryOrders", url.Values{"txid": {orderId}, "trades": {"true"}}, &orders); err != nil {
+		return nil, err
+	}
+
+	o, ok := orders[orderId]
+	if !ok {
+		return nil, fmt.Errorf("order_not_found: %s", orderId)
+	}
+	if len(o.Trades) == 0 {
+		return nil, nil
+	}
+
+	var trades map[string]trade
+	if err := k.private("QueryTrades", url.Values{"txid": {strings.Join(o.Trades, ",")}}, &trades); err != nil {
+		return nil, err
+	}
+
+	productId := k.productId(o.Descr.Pair)
+
+	fills := make([]exchange.Fill, 0, len(trades))
+	for id, t := range trades {
+		fills = append(fills, t.toFill(id, productId))
+	}
+
+	sort.Slice(fills, func(i, j int) bool {
+		return fills[i].CreatedAt.After(fills[j].CreatedAt)
+	})
+
+	return fills, nil
+}
+
+func (k *kraken) GetAccounts(ctx context.Context) ([]exchange.Account, error) {
+	var res map[string]balance
+	if err := k.private("BalanceEx", url.Values{}, &res); err != nil {
+		return nil, err
+	}
+
+	accounts := make([]exchange.Account, 0, len(res))
+	for asset, b := range res {
+		// Staked and opt-in rewards balances are suffixed, e.g. ETH2.S, and can't be traded.
+		if strings.Contains(asset, ".") {
+			continue
+		}
+
+		balance, err := decimal.NewFromString(b.Balance)
+		if err != nil {
+			return nil, fmt.Errorf("invalid_%s_balance (%s): %w", asset, b.Balance, err)
+		}
+		hold := decimal.Zero
+		if b.HoldTrade != "" {
+			if hold, err = decimal.NewFromString(b.HoldTrade); err != nil {
+				return nil, fmt.Errorf("invalid_%s_hold (%s): %w", asset, b.HoldTrade, err)
+			}
+		}
+
+		accounts = append(accounts, exchange.Account{
+			ID:        asset,
+			Currency:  currency(asset),
+			Balance:   b.Balance,
+			Hold:      hold.String(),
+			Available: balance.Sub(hold).String(),
+		})
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Currency < accounts[j].Currency
+	})
+
+	return accounts, nil
+}
+
+func (k *kraken) GetProducts(ctx context.Context) ([]exchange.Product, error) {
+	pairs, err := k.loadPairs()
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]exchange.Product, 0, len(pairs))
+	for _, p := range pairs {
+		products = append(products, p.toProduct())
+	}
+
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].ID < products[j].ID
+	})
+
+	return products, nil
+}
+
+func (k *kraken) GetTicker(ctx context.Context, productId string) (exchange.Ticker, error) {
+	p, err := k.pair(productId)
+	if err != nil {
+		return exchange.Ticker{}, err
+	}
+
+	var res map[string]ticker
+	if err := k.public("Ticker", url.Values{"pair": {p.Key}}, &res); err != nil {
+		return exchange.Ticker{}, err
+	}
+
+	t, ok := res[p.Key]
+	if !ok || len(t.Close) == 0 {
+		return exchange.Ticker{}, fmt.Errorf("ticker_not_found: %s", productId)
+	}
+
+	return exchange.Ticker{
+		ProductId: productId,
+		Price:     t.Close[0],
+		Time:      time.Now().UTC(),
+	}, nil
+}
+
+// pair returns the Kraken pair for a product ID, loading the pairs on first use.
+func (k *kraken) pair(productId string) (pair, error) {
+	k.lock.Lock()
+	p, ok := k.pairs[strings.ToUpper(productId)]
+	loaded := k.pairs != nil
+	k.lock.Unlock()
+
+	if ok {
+		return p, nil
+	}
+	if !loaded {
+		if _, err := k.loadPairs(); err != nil {
+			return pair{}, err
+		}
+		return k.pair(productId)
+	}
+
+	return pair{}, fmt.Errorf("unsupported_product: %s", productId)
+}
+
+// productId returns the product ID for a Kraken pair name, or the name itself if
+// the pair is unknown. The pairs are loaded on first use.
+func (k *kraken) productId(name string) string {
+	k.lock.Lock()
+	loaded := k.pairs != nil
+	k.lock.Unlock()
+
+	if !loaded {
+		// fall back to the pair name if the pairs can't be loaded
+		_, _ = k.loadPairs()
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if p, ok := k.pairNames[name]; ok {
+		return p.productId()
+	}
+
+	return name
+}
+
+func (k *kraken) loadPairs() (map[string]pair, error) {
+	var res map[string]pair
+	if err := k.public("AssetPairs", url.Values{}, &res); err != nil {
+		return nil, fmt.Errorf("get_asset_pairs: %w", err)
+	}
+
+	pairs := make(map[string]pair, len(res))
+	pairNames := make(map[string]pair, 2*len(res))
+	for key, p := range res {
+		if p.Status != "" && p.Status != "online" {
+			continue
+		}
+
+		p.Key = key
+		p.BaseCurrency, p.QuoteCurrency = p.currencies()
+
+		pairs[p.productId()] = p
+		pairNames[key] = p
+		pairNames[p.AltName] = p
+	}
+
+	k.lock.Lock()
+	k.pairs, k.pairNames = pairs, pairNames
+	k.lock.Unlock()
+
+	return pairs, nil
+}
+
+func (k *kraken) public(method string, params url.Values, result interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, k.baseURL+"/0/public/"+method+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("new_request: %w", err)
+	}
+
+	return k.do(req, result)
+}
+
+// private calls an authenticated endpoint, signing the request as described at
+// https://docs.kraken.com/rest/#section/Authentication/Headers-and-Signature.
+func (k *kraken) private(method string, params url.Values, result interface{}) error {
+	path := "/0/private/" + method
+
+	params.Set("nonce", strconv.FormatInt(k.nextNonce(), 10))
+	body := params.Encode()
+
+	req, err := http.NewRequest(http.MethodPost, k.baseURL+path, strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("new_request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("API-Key", k.key)
+	req.Header.Set("API-Sign", sign(k.secret, path, params.Get("nonce"), body))
+
+	return k.do(req, result)
+}
+
+func sign(secret []byte, path, nonce, body string) string {
+	sha := sha256.Sum256([]byte(nonce + body))
+
+	mac := hmac.New(sha512.New, secret)
+	mac.Write([]byte(path))
+	mac.Write(sha[:])
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// nextNonce returns an always increasing nonce, as required by Kraken.
+func (k *kraken) nextNonce() int64 {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	n := time.Now().UnixNano() / int64(time.Millisecond)
+	if n <= k.nonce {
+		n = k.nonce + 1
+	}
+	k.nonce = n
+
+	return n
+}
+
+func (k *kraken) do(req *http.Request, result interface{}) error {
+	res, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do_request: %w", exchange.RequestError(err))
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusTooManyRequests {
+		return exchange.RateLimitError{
+			Err:        fmt.Errorf("unexpected_status_code: %d", res.StatusCode),
+			RetryAfter: exchange.RetryAfter(res.Header.Get("Retry-After")),
+		}
+	}
+
+	var body struct {
+		Error  []string        `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return fmt.Errorf("decode_response (status %d): %w", res.StatusCode, exchange.RequestError(err))
+	}
+	if len(body.Error) > 0 {
+		return APIError{Errors: body.Error}
+	}
+	if res.StatusCode != http.StatusOK {
+		return apperror.Errorf(statusCode(res.StatusCode), "unexpected_status_code: %d", res.StatusCode)
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body.Result, result); err != nil {
+		return fmt.Errorf("unmarshal_result: %w", err)
+	}
+
+	return nil
+}
+
+func statusCode(status int) apperror.Code {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return apperror.ExchangeUnavailable
+	default:
+		return apperror.ExchangeRejected
+	}
+}
@@ -0,0 +1,648 @@
+package kraken_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/kraken"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	key    = "key"
+	secret = "kQH5HW/8p1uGOVjbgWA7FunAmGO8lsSUXNsu3eow76sz84Q18fWxnyRzBHCd3pd5nE9qa99HAZtuZuj6F1huXg=="
+
+	assetPairs = `{"error":[],"result":{
+		"XXBTZGBP":{"altname":"XBTGBP","wsname":"XBT/GBP","base":"XXBT","quote":"ZGBP","pair_decimals":1,"lot_decimals":8,"ordermin":"0.0001","status":"online"},
+		"XETHXXBT":{"altname":"ETHXBT","wsname":"ETH/XBT","base":"XETH","quote":"XXBT","pair_decimals":5,"lot_decimals":8,"ordermin":"0.01","status":"online"},
+		"DOTGBP":{"altname":"DOTGBP","wsname":"DOT/GBP","base":"DOT","quote":"ZGBP","pair_decimals":4,"lot_decimals":8,"ordermin":"1","status":"delisted"}
+	}}`
+)
+
+type (
+	request struct {
+		path   string
+		params url.Values
+	}
+
+	// server is a fake Kraken API which responds to each path with a fixed body.
+	server struct {
+		*httptest.Server
+		t         *testing.T
+		responses map[string]string
+		lock      sync.Mutex
+		requests  []request
+	}
+)
+
+func newServer(t *testing.T, responses map[string]string) *server {
+	s := &server{
+		t:         t,
+		responses: responses,
+	}
+	if _, ok := responses["/0/public/AssetPairs"]; !ok {
+		responses["/0/public/AssetPairs"] = assetPairs
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	return s
+}
+
+func (s *server) handle(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	if r.Method == http.MethodPost {
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(s.t, err)
+
+		params, err = url.ParseQuery(string(b))
+		require.NoError(s.t, err)
+
+		assert.Equal(s.t, key, r.Header.Get("API-Key"))
+		assert.Equal(s.t, expectedSignature(r.URL.Path, params.Get("nonce"), string(b)), r.Header.Get("API-Sign"))
+	}
+
+	s.lock.Lock()
+	s.requests = append(s.requests, request{path: r.URL.Path, params: params})
+	s.lock.Unlock()
+
+	res, ok := s.responses[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":["EGeneral:Unknown method"]}`)
+		return
+	}
+
+	fmt.Fprint(w, res)
+}
+
+func (s *server) request(path string) (request, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, r := range s.requests {
+		if r.path == path {
+			return r, true
+		}
+	}
+
+	return request{}, false
+}
+
+func expectedSignature(path, nonce, body string) string {
+	s, _ := base64.StdEncoding.DecodeString(secret)
+	sha := sha256.Sum256([]byte(nonce + body))
+
+	mac := hmac.New(sha512.New, s)
+	mac.Write(append([]byte(path), sha[:]...))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if key is empty", func(t *testing.T) {
+		k, err := kraken.New("", secret)
+		require.Error(t, err)
+
+		assert.Nil(t, k)
+
+		ipErr, ok := err.(kraken.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "key", ipErr.Parameter)
+	})
+
+	t.Run("returns error if secret is not base64 encoded", func(t *testing.T) {
+		k, err := kraken.New(key, "❌")
+		require.Error(t, err)
+
+		assert.Nil(t, k)
+
+		ipErr, ok := err.(kraken.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "secret", ipErr.Parameter)
+	})
+
+	t.Run("returns exchange", func(t *testing.T) {
+		k, err := kraken.New(key, secret)
+		require.NoError(t, err)
+
+		assert.NotNil(t, k)
+	})
+}
+
+func TestKraken_GetProducts(t *testing.T) {
+	t.Run("returns error if error getting asset pairs", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/public/AssetPairs": `{"error":["EService:Unavailable"]}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		products, err := k.GetProducts(context.Background())
+		require.Error(t, err)
+
+		assert.Empty(t, products)
+
+		var apiErr kraken.APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, []string{"EService:Unavailable"}, apiErr.Errors)
+		assert.Equal(t, apperror.ExchangeUnavailable, apperror.CodeOf(err))
+	})
+
+	t.Run("returns exchange unavailable error if kraken can't be reached", func(t *testing.T) {
+		s := newServer(t, map[string]string{})
+		s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		_, err = k.GetProducts(context.Background())
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.ExchangeUnavailable, apperror.CodeOf(err))
+		assert.True(t, apperror.IsRetryable(err))
+	})
+
+	t.Run("returns rate limit error with retry after header", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		_, err = k.GetProducts(context.Background())
+		require.Error(t, err)
+
+		var rlErr exchange.RateLimitError
+		require.True(t, errors.As(err, &rlErr))
+		assert.Equal(t, 3*time.Second, rlErr.RetryAfter)
+		assert.Equal(t, apperror.RateLimited, apperror.CodeOf(err))
+	})
+
+	t.Run("returns exchange unavailable error if response is not json", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/public/AssetPairs": `<html>502 Bad Gateway</html>`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		_, err = k.GetProducts(context.Background())
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.ExchangeUnavailable, apperror.CodeOf(err))
+	})
+
+	t.Run("returns online pairs as products", func(t *testing.T) {
+		s := newServer(t, map[string]string{})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		products, err := k.GetProducts(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []exchange.Product{
+			{
+				ID:             "BTC-GBP",
+				BaseCurrency:   "BTC",
+				QuoteCurrency:  "GBP",
+				BaseMinSize:    "0.0001",
+				BaseIncrement:  "0.00000001",
+				QuoteIncrement: "0.1",
+			},
+			{
+				ID:             "ETH-BTC",
+				BaseCurrency:   "ETH",
+				QuoteCurrency:  "BTC",
+				BaseMinSize:    "0.01",
+				BaseIncrement:  "0.00000001",
+				QuoteIncrement: "0.00001",
+			},
+		}, products)
+	})
+}
+
+func TestKraken_GetTicker(t *testing.T) {
+	t.Run("returns error if product is not supported", func(t *testing.T) {
+		s := newServer(t, map[string]string{})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		_, err = k.GetTicker(context.Background(), "DOT-GBP")
+		require.Error(t, err)
+	})
+
+	t.Run("returns last trade price", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/public/Ticker": `{"error":[],"result":{"XXBTZGBP":{"a":["31000.1","1","1.000"],"c":["30999.9","0.01"]}}}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		ticker, err := k.GetTicker(context.Background(), "btc-gbp")
+		require.NoError(t, err)
+
+		assert.Equal(t, "30999.9", ticker.Price)
+
+		req, ok := s.request("/0/public/Ticker")
+		require.True(t, ok)
+		assert.Equal(t, "XXBTZGBP", req.params.Get("pair"))
+	})
+}
+
+func TestKraken_GetAccounts(t *testing.T) {
+	t.Run("returns error if error getting balances", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/BalanceEx": `{"error":["EAPI:Invalid key"]}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		accounts, err := k.GetAccounts(context.Background())
+		require.Error(t, err)
+
+		assert.Empty(t, accounts)
+	})
+
+	t.Run("returns tradeable balances less amounts on hold", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/BalanceEx": `{"error":[],"result":{
+				"ZGBP":{"balance":"100.5000","hold_trade":"20.2500"},
+				"XXBT":{"balance":"0.0123456789"},
+				"DOT.S":{"balance":"10.0"}
+			}}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		accounts, err := k.GetAccounts(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []exchange.Account{
+			{ID: "XXBT", Currency: "BTC", Balance: "0.0123456789", Hold: "0", Available: "0.0123456789"},
+			{ID: "ZGBP", Currency: "GBP", Balance: "100.5000", Hold: "20.25", Available: "80.25"},
+		}, accounts)
+	})
+}
+
+func TestKraken_CreateOrder(t *testing.T) {
+	t.Run("returns error if order is rejected", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/AddOrder": `{"error":["EOrder:Insufficient funds"]}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		order, err := k.CreateOrder(context.Background(), exchange.NewOrder{ProductId: "BTC-GBP", Side: exchange.Buy, Type: exchange.Market, Funds: "10.0"})
+		require.Error(t, err)
+
+		assert.Empty(t, order)
+
+		apiErr, ok := err.(kraken.APIError)
+		require.True(t, ok)
+		assert.Equal(t, []string{"EOrder:Insufficient funds"}, apiErr.Errors)
+		assert.Equal(t, apperror.InsufficientFunds, apperror.CodeOf(err))
+	})
+
+	t.Run("returns rate limited error if rate limit exceeded", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/AddOrder": `{"error":["EAPI:Rate limit exceeded"]}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		_, err = k.CreateOrder(context.Background(), exchange.NewOrder{ProductId: "BTC-GBP", Side: exchange.Buy, Type: exchange.Market, Funds: "10.0"})
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.RateLimited, apperror.CodeOf(err))
+	})
+
+	t.Run("returns exchange rejected error if order is invalid", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/AddOrder": `{"error":["EOrder:Invalid price"]}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		_, err = k.CreateOrder(context.Background(), exchange.NewOrder{ProductId: "BTC-GBP", Side: exchange.Buy, Type: exchange.Market, Funds: "10.0"})
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.ExchangeRejected, apperror.CodeOf(err))
+	})
+
+	t.Run("returns error if time in force is not supported", func(t *testing.T) {
+		s := newServer(t, map[string]string{})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		_, err = k.CreateOrder(context.Background(), exchange.NewOrder{
+			ProductId:   "BTC-GBP",
+			Side:        exchange.Buy,
+			Type:        exchange.Limit,
+			Price:       "30000.0",
+			Size:        "0.1",
+			TimeInForce: exchange.FillOrKill,
+		})
+		require.Error(t, err)
+
+		_, ok := s.request("/0/private/AddOrder")
+		assert.False(t, ok)
+	})
+
+	t.Run("places market order using funds", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/AddOrder": `{"error":[],"result":{"descr":{"order":"buy 10.0 XBTGBP @ market"},"txid":["OUF4EM-FRGI2-MQMWZD"]}}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		order, err := k.CreateOrder(context.Background(), exchange.NewOrder{ProductId: "BTC-GBP", Side: exchange.Buy, Type: exchange.Market, Funds: "10.0"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "OUF4EM-FRGI2-MQMWZD", order.ID)
+		assert.Equal(t, exchange.StatusPending, order.Status)
+
+		req, ok := s.request("/0/private/AddOrder")
+		require.True(t, ok)
+		assert.Equal(t, "XXBTZGBP", req.params.Get("pair"))
+		assert.Equal(t, "buy", req.params.Get("type"))
+		assert.Equal(t, "market", req.params.Get("ordertype"))
+		assert.Equal(t, "10.0", req.params.Get("volume"))
+		assert.Equal(t, "viqc", req.params.Get("oflags"))
+	})
+
+	t.Run("places post only limit order", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/AddOrder": `{"error":[],"result":{"txid":["OUF4EM-FRGI2-MQMWZD"]}}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		_, err = k.CreateOrder(context.Background(), exchange.NewOrder{
+			ProductId:   "BTC-GBP",
+			Side:        exchange.Sell,
+			Type:        exchange.Limit,
+			Price:       "30000.0",
+			Size:        "0.10000000",
+			TimeInForce: exchange.GoodTillCancelled,
+			PostOnly:    true,
+		})
+		require.NoError(t, err)
+
+		req, ok := s.request("/0/private/AddOrder")
+		require.True(t, ok)
+		assert.Equal(t, "sell", req.params.Get("type"))
+		assert.Equal(t, "limit", req.params.Get("ordertype"))
+		assert.Equal(t, "30000.0", req.params.Get("price"))
+		assert.Equal(t, "0.10000000", req.params.Get("volume"))
+		assert.Equal(t, "post", req.params.Get("oflags"))
+		assert.Empty(t, req.params.Get("timeinforce"))
+	})
+
+	t.Run("places stop order as stop loss order", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/AddOrder": `{"error":[],"result":{"txid":["OUF4EM-FRGI2-MQMWZD"]}}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		_, err = k.CreateOrder(context.Background(), exchange.NewOrder{
+			ProductId: "BTC-GBP",
+			Side:      exchange.Sell,
+			Type:      exchange.Stop,
+			Price:     "25000.0",
+			Size:      "0.10000000",
+		})
+		require.NoError(t, err)
+
+		req, ok := s.request("/0/private/AddOrder")
+		require.True(t, ok)
+		assert.Equal(t, "stop-loss", req.params.Get("ordertype"))
+		assert.Equal(t, "25000.0", req.params.Get("price"))
+		assert.Empty(t, req.params.Get("oflags"))
+	})
+}
+
+func TestKraken_GetOrder(t *testing.T) {
+	t.Run("returns error if order is not found", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/QueryOrders": `{"error":[],"result":{}}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		_, err = k.GetOrder(context.Background(), "id")
+		require.Error(t, err)
+	})
+
+	t.Run("returns closed order as settled", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/QueryOrders": `{"error":[],"result":{"OUF4EM-FRGI2-MQMWZD":{
+				"status":"closed","opentm":1620000000.5,"vol":"10.0","vol_exec":"0.00032000","cost":"9.97","fee":"0.03",
+				"oflags":"fciq,viqc","descr":{"pair":"XBTGBP","type":"buy","ordertype":"market","price":"0"}
+			}}}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		_, err = k.GetProducts(context.Background())
+		require.NoError(t, err)
+
+		order, err := k.GetOrder(context.Background(), "OUF4EM-FRGI2-MQMWZD")
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.Order{
+			ID:            "OUF4EM-FRGI2-MQMWZD",
+			ProductId:     "BTC-GBP",
+			Side:          "buy",
+			Type:          "market",
+			Status:        exchange.StatusDone,
+			Settled:       true,
+			CreatedAt:     time.Date(2021, 5, 3, 0, 0, 0, int(500*time.Millisecond), time.UTC),
+			Funds:         "10.0",
+			FillFees:      "0.03",
+			FilledSize:    "0.00032000",
+			ExecutedValue: "9.97",
+		}, order)
+
+		req, ok := s.request("/0/private/QueryOrders")
+		require.True(t, ok)
+		assert.Equal(t, "OUF4EM-FRGI2-MQMWZD", req.params.Get("txid"))
+	})
+
+	t.Run("returns untriggered stop order as active", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/QueryOrders": `{"error":[],"result":{"id":{
+				"status":"open","opentm":1620000000,"vol":"0.1","vol_exec":"0","cost":"0","fee":"0",
+				"descr":{"pair":"XBTGBP","type":"sell","ordertype":"stop-loss","price":"25000.0"}
+			}}}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		order, err := k.GetOrder(context.Background(), "id")
+		require.NoError(t, err)
+
+		assert.Equal(t, "stop", order.Type)
+		assert.Equal(t, exchange.StatusActive, order.Status)
+		assert.Equal(t, "25000.0", order.StopPrice)
+		assert.Equal(t, "0.1", order.Size)
+		assert.False(t, order.Settled)
+	})
+}
+
+func TestKraken_CancelAllOrders(t *testing.T) {
+	t.Run("cancels open orders for product", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/OpenOrders": `{"error":[],"result":{"open":{
+				"id1":{"status":"open","opentm":1620000001,"vol":"0.1","descr":{"pair":"XBTGBP","type":"sell","ordertype":"limit","price":"40000.0"}},
+				"id2":{"status":"open","opentm":1620000002,"vol":"1","descr":{"pair":"ETHXBT","type":"sell","ordertype":"limit","price":"0.1"}}
+			}}}`,
+			"/0/private/CancelOrder": `{"error":[],"result":{"count":1}}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		ids, err := k.CancelAllOrders(context.Background(), "BTC-GBP")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"id1"}, ids)
+
+		req, ok := s.request("/0/private/CancelOrder")
+		require.True(t, ok)
+		assert.Equal(t, "id1", req.params.Get("txid"))
+	})
+}
+
+func TestKraken_ListFills(t *testing.T) {
+	t.Run("returns fills for product since time", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/TradesHistory": `{"error":[],"result":{"count":3,"trades":{
+				"T1":{"ordertxid":"id1","pair":"XXBTZGBP","time":1620000001,"type":"buy","price":"30000.0","vol":"0.001","fee":"0.08","maker":false},
+				"T2":{"ordertxid":"id2","pair":"XXBTZGBP","time":1620000002,"type":"sell","price":"30100.0","vol":"0.002","fee":"0.1","maker":true},
+				"T3":{"ordertxid":"id3","pair":"XETHXXBT","time":1620000003,"type":"buy","price":"0.1","vol":"1","fee":"0.0001","maker":false}
+			}}}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		since := time.Unix(1620000000, 0)
+
+		fills, err := k.ListFills(context.Background(), "BTC-GBP", since)
+		require.NoError(t, err)
+
+		require.Len(t, fills, 2)
+		assert.Equal(t, "id2", fills[0].OrderId)
+		assert.Equal(t, exchange.Maker, fills[0].Liquidity)
+		assert.Equal(t, "id1", fills[1].OrderId)
+		assert.Equal(t, exchange.Taker, fills[1].Liquidity)
+		assert.Equal(t, "BTC-GBP", fills[1].ProductId)
+
+		req, ok := s.request("/0/private/TradesHistory")
+		require.True(t, ok)
+		assert.Equal(t, "1620000000", req.params.Get("start"))
+	})
+}
+
+func TestKraken_GetOrderFills(t *testing.T) {
+	t.Run("returns empty if order has no trades", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/QueryOrders": `{"error":[],"result":{"id":{"status":"canceled","descr":{"pair":"XBTGBP","type":"buy","ordertype":"limit","price":"30000.0"}}}}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		fills, err := k.GetOrderFills(context.Background(), "id")
+		require.NoError(t, err)
+
+		assert.Empty(t, fills)
+	})
+
+	t.Run("returns trades of order", func(t *testing.T) {
+		s := newServer(t, map[string]string{
+			"/0/private/QueryOrders": `{"error":[],"result":{"id":{"status":"closed","trades":["T1","T2"],"descr":{"pair":"XBTGBP","type":"buy","ordertype":"market","price":"0"}}}}`,
+			"/0/private/QueryTrades": `{"error":[],"result":{
+				"T1":{"ordertxid":"id","pair":"XXBTZGBP","time":1620000001,"type":"buy","price":"30000.0","vol":"0.001","fee":"0.08","maker":false},
+				"T2":{"ordertxid":"id","pair":"XXBTZGBP","time":1620000002,"type":"buy","price":"30100.0","vol":"0.002","fee":"0.1","maker":true}
+			}}`,
+		})
+		defer s.Close()
+
+		k, err := kraken.New(key, secret, kraken.WithBaseURL(s.URL))
+		require.NoError(t, err)
+
+		fills, err := k.GetOrderFills(context.Background(), "id")
+		require.NoError(t, err)
+
+		require.Len(t, fills, 2)
+		assert.Equal(t, "T2", fills[0].ID)
+		assert.Equal(t, exchange.Maker, fills[0].Liquidity)
+		assert.Equal(t, "T1", fills[1].ID)
+		assert.Equal(t, "BTC-GBP", fills[1].ProductId)
+
+		req, ok := s.request("/0/private/QueryOrders")
+		require.True(t, ok)
+		assert.Equal(t, "true", req.params.Get("trades"))
+
+		req, ok = s.request("/0/private/QueryTrades")
+		require.True(t, ok)
+		assert.Equal(t, "T1,T2", req.params.Get("txid"))
+	})
+}
@@ -0,0 +1,203 @@
+package kraken
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	statusOpen     = "open"
+	statusClosed   = "closed"
+	statusCanceled = "canceled"
+	statusExpired  = "expired"
+
+	orderTypeMarket   = "market"
+	orderTypeLimit    = "limit"
+	orderTypeStopLoss = "stop-loss"
+
+	flagPostOnly      = "post"
+	flagVolumeInQuote = "viqc"
+)
+
+// aliases maps the Kraken names of assets to the names used by other exchanges.
+var aliases = map[string]string{
+	"XBT": "BTC",
+	"XDG": "DOGE",
+}
+
+type (
+	pair struct {
+		Key           string `json:"-"`
+		AltName       string `json:"altname"`
+		WSName        string `json:"wsname"`
+		Base          string `json:"base"`
+		Quote         string `json:"quote"`
+		PairDecimals  int32  `json:"pair_decimals"`
+		LotDecimals   int32  `json:"lot_decimals"`
+		OrderMin      string `json:"ordermin"`
+		Status        string `json:"status"`
+		BaseCurrency  string `json:"-"`
+		QuoteCurrency string `json:"-"`
+	}
+
+	ticker struct {
+		Close []string `json:"c"`
+	}
+
+	balance struct {
+		Balance   string `json:"balance"`
+		HoldTrade string `json:"hold_trade"`
+	}
+
+	addOrderResult struct {
+		TxID []string `json:"txid"`
+	}
+
+	order struct {
+		Status         string   `json:"status"`
+		OpenTime       float64  `json:"opentm"`
+		Volume         string   `json:"vol"`
+		VolumeExecuted string   `json:"vol_exec"`
+		Cost           string   `json:"cost"`
+		Fee            string   `json:"fee"`
+		OFlags         string   `json:"oflags"`
+		Trades         []string `json:"trades"`
+		Descr          struct {
+			Pair      string `json:"pair"`
+			Type      string `json:"type"`
+			OrderType string `json:"ordertype"`
+			Price     string `json:"price"`
+		} `json:"descr"`
+	}
+
+	openOrdersResult struct {
+		Open map[string]order `json:"open"`
+	}
+
+	trade struct {
+		OrderTxID string  `json:"ordertxid"`
+		Pair      string  `json:"pair"`
+		Time      float64 `json:"time"`
+		Type      string  `json:"type"`
+		Price     string  `json:"price"`
+		Volume    string  `json:"vol"`
+		Fee       string  `json:"fee"`
+		Maker     bool    `json:"maker"`
+	}
+
+	tradesHistoryResult struct {
+		Trades map[string]trade `json:"trades"`
+		Count  int              `json:"count"`
+	}
+)
+
+// productId returns the product ID of the pair in the BASE-QUOTE format.
+func (p pair) productId() string {
+	return p.BaseCurrency + "-" + p.QuoteCurrency
+}
+
+func (p pair) toProduct() exchange.Product {
+	return exchange.Product{
+		ID:             p.productId(),
+		BaseCurrency:   p.BaseCurrency,
+		QuoteCurrency:  p.QuoteCurrency,
+		BaseMinSize:    p.OrderMin,
+		BaseIncrement:  decimal.New(1, -p.LotDecimals).String(),
+		QuoteIncrement: decimal.New(1, -p.PairDecimals).String(),
+	}
+}
+
+// currency returns the common name of a Kraken asset, e.g. XXBT => BTC, ZGBP => GBP.
+func currency(asset string) string {
+	if len(asset) == 4 && (asset[0] == 'X' || asset[0] == 'Z') {
+		asset = asset[1:]
+	}
+	if a, ok := aliases[asset]; ok {
+		return a
+	}
+
+	return asset
+}
+
+// currencies returns the base and quote currency of a pair, preferring the
+// websocket name (e.g. XBT/GBP) as asset names are not always prefixed.
+func (p pair) currencies() (string, string) {
+	if parts := strings.Split(p.WSName, "/"); len(parts) == 2 {
+		return currency(parts[0]), currency(parts[1])
+	}
+
+	return currency(p.Base), currency(p.Quote)
+}
+
+func (o order) toOrder(id, productId string) exchange.Order {
+	res := exchange.Order{
+		ID:            id,
+		ProductId:     productId,
+		Side:          o.Descr.Type,
+		Type:          o.Descr.OrderType,
+		Status:        o.Status,
+		PostOnly:      strings.Contains(o.OFlags, flagPostOnly),
+		CreatedAt:     unixTime(o.OpenTime),
+		FillFees:      o.Fee,
+		FilledSize:    o.VolumeExecuted,
+		ExecutedValue: o.Cost,
+	}
+
+	if strings.Contains(o.OFlags, flagVolumeInQuote) {
+		res.Funds = o.Volume
+	} else {
+		res.Size = o.Volume
+	}
+
+	switch o.Descr.OrderType {
+	case orderTypeLimit:
+		res.Price = o.Descr.Price
+	case orderTypeStopLoss:
+		res.Type = string(exchange.Stop)
+		res.StopPrice = o.Descr.Price
+	}
+
+	switch o.Status {
+	case statusOpen:
+		res.Status = exchange.StatusOpen
+		// Stop orders are open on Kraken until triggered.
+		if res.Type == string(exchange.Stop) {
+			res.Status = exchange.StatusActive
+		}
+	case statusClosed, statusCanceled, statusExpired:
+		res.Status = exchange.StatusDone
+		res.Settled = true
+	default:
+		res.Status = exchange.StatusPending
+	}
+
+	return res
+}
+
+func (t trade) toFill(id, productId string) exchange.Fill {
+	liquidity := exchange.Taker
+	if t.Maker {
+		liquidity = exchange.Maker
+	}
+
+	return exchange.Fill{
+		ID:        id,
+		OrderId:   t.OrderTxID,
+		ProductId: productId,
+		Side:      t.Type,
+		Price:     t.Price,
+		Size:      t.Volume,
+		Fee:       t.Fee,
+		Liquidity: liquidity,
+		CreatedAt: unixTime(t.Time),
+	}
+}
+
+// unixTime converts the fractional unix timestamps returned by Kraken.
+func unixTime(t float64) time.Time {
+	return time.Unix(0, int64(math.Round(t*1e6))*int64(time.Microsecond)).UTC()
+}
@@ -0,0 +1,25 @@
+package kraken
+
+import "net/http"
+
+const defaultBaseURL = "https://api.kraken.com"
+
+type Option func(*kraken)
+
+// WithBaseURL sets the URL of the Kraken REST API.
+func WithBaseURL(url string) Option {
+	return func(k *kraken) {
+		if url != "" {
+			k.baseURL = url
+		}
+	}
+}
+
+// WithHTTPClient sets the client used to call the Kraken REST API.
+func WithHTTPClient(client *http.Client) Option {
+	return func(k *kraken) {
+		if client != nil {
+			k.client = client
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package paper
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Option func(*paper)
+
+// WithProduct sets the product of the rates, which is the only product which
+// can be traded. Product IDs must be in the BASE-QUOTE format.
+func WithProduct(productId string) Option {
+	return func(p *paper) {
+		if productId != "" {
+			p.product = newProduct(productId)
+		}
+	}
+}
+
+// WithBalance sets the starting balance of a currency, only used when the
+// wallet is created.
+func WithBalance(currency string, balance decimal.Decimal) Option {
+	return func(p *paper) {
+		if !balance.IsNegative() {
+			p.balances[strings.ToUpper(currency)] = balance
+		}
+	}
+}
+
+// WithFeeRate sets the fee charged on each fill as a fraction of its value, e.g. 0.005 for 0.5%.
+func WithFeeRate(rate decimal.Decimal) Option {
+	return func(p *paper) {
+		if !rate.IsNegative() {
+			p.feeRate = rate
+		}
+	}
+}
+
+// WithMaxRateAge sets how old the latest rate can be before orders are
+// rejected, so orders aren't filled at a stale price if rates stop being stored.
+func WithMaxRateAge(age time.Duration) Option {
+	return func(p *paper) {
+		if age > 0 {
+			p.maxRateAge = age
+		}
+	}
+}
+
+// WithClock sets the function used to timestamp orders and fills.
+func WithClock(now func() time.Time) Option {
+	return func(p *paper) {
+		if now != nil {
+			p.now = now
+		}
+	}
+}
@@ -0,0 +1,356 @@
+// Package paper implements a paper-trading exchange, which fills orders at the
+// latest real rate stored by the data-storer and keeps a simulated wallet and
+// its orders in a store, so strategies can be run live without risking money.
+package paper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/sim"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultProductId  = "BTC-GBP"
+	defaultMaxRateAge = 10 * time.Minute
+	defaultTimeout    = 10 * time.Second
+)
+
+var (
+	defaultFeeRate = decimal.New(5, -3)
+
+	ErrOrderNotFound      = exchange.ErrOrderNotFound
+	ErrUnsupportedProduct = apperror.New(apperror.ExchangeRejected, errors.New("unsupported product"))
+	ErrRestingOrder       = apperror.New(apperror.ExchangeRejected, errors.New("orders which would rest on the book are not supported"))
+	ErrNoRate             = apperror.New(apperror.ExchangeUnavailable, errors.New("no recent rate"))
+)
+
+type (
+	// Order is an order placed on the paper exchange, with its fills.
+	Order struct {
+		exchange.Order
+		ClientOrderId string
+		Fills         []exchange.Fill
+	}
+
+	// Rate is a rate stored by the data-storer.
+	Rate struct {
+		Rate     float64
+		DateTime time.Time
+	}
+
+	// Store keeps the balances of the wallet and the orders placed.
+	Store interface {
+		// CreateWallet creates the wallet with the balances if it doesn't already exist.
+		CreateWallet(ctx context.Context, balances map[string]decimal.Decimal) error
+		GetBalances(ctx context.Context) (map[string]decimal.Decimal, error)
+		// UpdateBalances adds the changes to the balances, failing with an
+		// insufficient funds error if it would leave a balance negative.
+		UpdateBalances(ctx context.Context, changes map[string]decimal.Decimal) error
+		SaveOrder(ctx context.Context, order Order) error
+		GetOrder(ctx context.Context, id string) (Order, error)
+		GetOrderByClientId(ctx context.Context, clientOrderId string) (Order, error)
+		ListOrders(ctx context.Context, productId string, since time.Time) ([]Order, error)
+	}
+
+	// RateGetter gets the latest rate of the product.
+	RateGetter interface {
+		GetLatestRate(ctx context.Context) (Rate, error)
+	}
+
+	paper struct {
+		store      Store
+		rates      RateGetter
+		product    exchange.Product
+		balances   map[string]decimal.Decimal // Starting balances.
+		feeRate    decimal.Decimal
+		maxRateAge time.Duration
+		timeout    time.Duration
+		now        func() time.Time
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+// New creates the paper exchange, creating its wallet with the starting
+// balances if it doesn't already exist. Only the product of the rates, BTC-GBP
+// unless set using WithProduct, can be traded.
+func New(ctx context.Context, store Store, rates RateGetter, opts ...Option) (*paper, error) {
+	switch {
+	case store == nil:
+		return nil, InvalidParameterError{Parameter: "store"}
+	case rates == nil:
+		return nil, InvalidParameterError{Parameter: "rates"}
+	}
+
+	p := &paper{
+		store:      store,
+		rates:      rates,
+		product:    newProduct(defaultProductId),
+		balances:   make(map[string]decimal.Decimal),
+		feeRate:    defaultFeeRate,
+		maxRateAge: defaultMaxRateAge,
+		timeout:    defaultTimeout,
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if err := store.CreateWallet(ctx, p.balances); err != nil {
+		return nil, fmt.Errorf("create_wallet: %w", err)
+	}
+
+	return p, nil
+}
+
+func newProduct(productId string) exchange.Product {
+	base, quote := productId, ""
+	if parts := strings.Split(productId, "-"); len(parts) == 2 {
+		base, quote = parts[0], parts[1]
+	}
+
+	return exchange.Product{
+		ID:             productId,
+		BaseCurrency:   base,
+		QuoteCurrency:  quote,
+		BaseMinSize:    "0.0001",
+		BaseMaxSize:    "10000",
+		BaseIncrement:  "0.00000001",
+		QuoteIncrement: "0.01",
+	}
+}
+
+// context returns the context for a call to the store, limited to the timeout.
+func (p *paper) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, p.timeout)
+}
+
+// CreateOrder fills the order at the latest rate using the balances of the
+// wallet. The order is filled the same way as by the fake exchange and a dry
+// run, and the change in balances is then applied to the wallet, so an order
+// placed at the same time which spends the same funds is rejected. Market
+// orders and limit orders which cross the rate are filled, orders which would
+// rest on the book are rejected as there is no order book to fill them later.
+func (p *paper) CreateOrder(ctx context.Context, req exchange.NewOrder) (exchange.Order, error) {
+	ctx, cancel := p.context(ctx)
+	defer cancel()
+
+	if req.ProductId != p.product.ID {
+		return exchange.Order{}, fmt.Errorf("%w: %s", ErrUnsupportedProduct, req.ProductId)
+	}
+
+	price, _, err := p.latestRate(ctx)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	balances, err := p.store.GetBalances(ctx)
+	if err != nil {
+		return exchange.Order{}, fmt.Errorf("get_balances: %w", err)
+	}
+
+	res, err := sim.Execute(sim.Market{
+		Product:  p.product,
+		Price:    price,
+		Balances: balances,
+		FeeRate:  p.feeRate,
+	}, req, p.now())
+	if err != nil {
+		return exchange.Order{}, err
+	}
+	if res.Order.Status != exchange.StatusDone {
+		return exchange.Order{}, ErrRestingOrder
+	}
+
+	o, fills, changes := res.Order, res.Fills, res.Changes
+	o.ID = uuid.NewV4().String()
+	for i := range fills {
+		fills[i].ID = uuid.NewV4().String()
+		fills[i].OrderId = o.ID
+	}
+
+	if err := p.store.UpdateBalances(ctx, changes); err != nil {
+		return exchange.Order{}, fmt.Errorf("update_balances: %w", err)
+	}
+
+	err = p.store.SaveOrder(ctx, Order{Order: o, ClientOrderId: req.ClientOrderId, Fills: fills})
+	if err != nil {
+		// Undo the fill so the wallet matches the orders.
+		if revertErr := p.store.UpdateBalances(ctx, negate(changes)); revertErr != nil {
+			return exchange.Order{}, fmt.Errorf("revert_balances: %v, save_order: %w", revertErr, err)
+		}
+		return exchange.Order{}, fmt.Errorf("save_order: %w", err)
+	}
+
+	return o, nil
+}
+
+// latestRate returns the latest rate and when it was stored, ErrNoRate if it
+// is older than the maximum age.
+func (p *paper) latestRate(ctx context.Context) (decimal.Decimal, time.Time, error) {
+	rate, err := p.rates.GetLatestRate(ctx)
+	if err != nil {
+		return decimal.Zero, time.Time{}, fmt.Errorf("get_latest_rate: %w", err)
+	}
+	if rate.Rate <= 0 || p.now().Sub(rate.DateTime) > p.maxRateAge {
+		return decimal.Zero, time.Time{}, fmt.Errorf("%w: latest is %v at %s", ErrNoRate, rate.Rate, rate.DateTime.UTC().Format(time.RFC3339))
+	}
+
+	return decimal.NewFromFloat(rate.Rate), rate.DateTime.UTC(), nil
+}
+
+func negate(changes map[string]decimal.Decimal) map[string]decimal.Decimal {
+	negated := make(map[string]decimal.Decimal, len(changes))
+	for currency, change := range changes {
+		negated[currency] = change.Neg()
+	}
+
+	return negated
+}
+
+func (p *paper) GetOrder(ctx context.Context, id string) (exchange.Order, error) {
+	ctx, cancel := p.context(ctx)
+	defer cancel()
+
+	o, err := p.store.GetOrder(ctx, id)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return o.Order, nil
+}
+
+// GetOrderByClientId returns the order placed with the client order ID,
+// ErrOrderNotFound if there isn't one.
+func (p *paper) GetOrderByClientId(ctx context.Context, clientOrderId string) (exchange.Order, error) {
+	ctx, cancel := p.context(ctx)
+	defer cancel()
+
+	o, err := p.store.GetOrderByClientId(ctx, clientOrderId)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return o.Order, nil
+}
+
+// CancelOrder returns ErrOrderNotFound, orders are done as soon as they are
+// placed so there are never open orders to cancel.
+func (p *paper) CancelOrder(ctx context.Context, id string) error {
+	return fmt.Errorf("%w: %s", ErrOrderNotFound, id)
+}
+
+func (p *paper) CancelAllOrders(ctx context.Context, productId string) ([]string, error) {
+	return nil, nil
+}
+
+func (p *paper) ListOpenOrders(ctx context.Context, productId string) ([]exchange.Order, error) {
+	return nil, nil
+}
+
+// ListFills returns the fills of the product since the time, newest first.
+func (p *paper) ListFills(ctx context.Context, productId string, since time.Time) ([]exchange.Fill, error) {
+	ctx, cancel := p.context(ctx)
+	defer cancel()
+
+	orders, err := p.store.ListOrders(ctx, productId, since)
+	if err != nil {
+		return nil, fmt.Errorf("list_orders: %w", err)
+	}
+
+	var fills []exchange.Fill
+	for _, o := range orders {
+		for _, f := range o.Fills {
+			if !f.CreatedAt.Before(since) {
+				fills = append(fills, f)
+			}
+		}
+	}
+
+	sort.SliceStable(fills, func(i, j int) bool {
+		return fills[i].CreatedAt.After(fills[j].CreatedAt)
+	})
+
+	return fills, nil
+}
+
+func (p *paper) GetOrderFills(ctx context.Context, orderId string) ([]exchange.Fill, error) {
+	ctx, cancel := p.context(ctx)
+	defer cancel()
+
+	o, err := p.store.GetOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.Fills, nil
+}
+
+// GetAccounts returns the balances of the wallet, nothing is ever held.
+func (p *paper) GetAccounts(ctx context.Context) ([]exchange.Account, error) {
+	ctx, cancel := p.context(ctx)
+	defer cancel()
+
+	balances, err := p.store.GetBalances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get_balances: %w", err)
+	}
+
+	accounts := make([]exchange.Account, 0, len(balances))
+	for currency, balance := range balances {
+		accounts = append(accounts, exchange.Account{
+			ID:        "paper-account-" + strings.ToLower(currency),
+			Currency:  currency,
+			Balance:   balance.String(),
+			Hold:      decimal.Zero.String(),
+			Available: balance.String(),
+		})
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Currency < accounts[j].Currency
+	})
+
+	return accounts, nil
+}
+
+func (p *paper) GetProducts(ctx context.Context) ([]exchange.Product, error) {
+	return []exchange.Product{p.product}, nil
+}
+
+func (p *paper) GetTicker(ctx context.Context, productId string) (exchange.Ticker, error) {
+	ctx, cancel := p.context(ctx)
+	defer cancel()
+
+	if productId != p.product.ID {
+		return exchange.Ticker{}, fmt.Errorf("%w: %s", ErrUnsupportedProduct, productId)
+	}
+
+	price, t, err := p.latestRate(ctx)
+	if err != nil {
+		return exchange.Ticker{}, err
+	}
+
+	return exchange.Ticker{
+		ProductId: productId,
+		Price:     price.String(),
+		Time:      t,
+	}, nil
+}
@@ -0,0 +1,400 @@
+package paper_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/paper"
+	"github.com/cshep4/kripto/services/trader/internal/mocks/paper"
+	"github.com/cshep4/kripto/services/trader/internal/trader"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const productId = "BTC-GBP"
+
+var (
+	now  = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	rate = paper.Rate{Rate: 40000, DateTime: now.Add(-time.Minute)}
+
+	balances = map[string]decimal.Decimal{
+		"GBP": decimal.RequireFromString("1000"),
+		"BTC": decimal.RequireFromString("0.1"),
+	}
+)
+
+func newExchange(t *testing.T, ctrl *gomock.Controller, opts ...paper.Option) (trader.Exchange, *paper_mocks.MockStore, *paper_mocks.MockRateGetter) {
+	t.Helper()
+
+	store := paper_mocks.NewMockStore(ctrl)
+	rates := paper_mocks.NewMockRateGetter(ctrl)
+
+	store.EXPECT().CreateWallet(gomock.Any(), gomock.Any()).Return(nil)
+
+	opts = append([]paper.Option{paper.WithClock(func() time.Time { return now })}, opts...)
+	ex, err := paper.New(context.Background(), store, rates, opts...)
+	require.NoError(t, err)
+
+	return ex, store, rates
+}
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns error if store is empty", func(t *testing.T) {
+		ex, err := paper.New(ctx, nil, nil)
+		require.Error(t, err)
+
+		assert.Nil(t, ex)
+
+		ipErr, ok := err.(paper.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "store", ipErr.Parameter)
+	})
+
+	t.Run("returns error if rates is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, err := paper.New(ctx, paper_mocks.NewMockStore(ctrl), nil)
+		require.Error(t, err)
+
+		assert.Nil(t, ex)
+
+		ipErr, ok := err.(paper.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "rates", ipErr.Parameter)
+	})
+
+	t.Run("returns error if wallet can't be created", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := paper_mocks.NewMockStore(ctrl)
+		testErr := errors.New("error")
+
+		store.EXPECT().CreateWallet(ctx, map[string]decimal.Decimal{}).Return(testErr)
+
+		ex, err := paper.New(ctx, store, paper_mocks.NewMockRateGetter(ctrl))
+		require.Error(t, err)
+
+		assert.Nil(t, ex)
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("creates wallet with starting balances", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := paper_mocks.NewMockStore(ctrl)
+
+		store.EXPECT().CreateWallet(ctx, map[string]decimal.Decimal{"GBP": decimal.RequireFromString("1000")}).Return(nil)
+
+		ex, err := paper.New(ctx, store, paper_mocks.NewMockRateGetter(ctrl), paper.WithBalance("gbp", decimal.RequireFromString("1000")))
+		require.NoError(t, err)
+
+		assert.NotNil(t, ex)
+	})
+}
+
+func TestPaper_CreateOrder(t *testing.T) {
+	t.Run("returns error if product is not supported", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, _, _ := newExchange(t, ctrl)
+
+		_, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: "ETH-GBP", Side: exchange.Buy, Funds: "10"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, paper.ErrUnsupportedProduct))
+	})
+
+	t.Run("returns retryable error if latest rate is too old", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, _, rates := newExchange(t, ctrl, paper.WithMaxRateAge(time.Minute))
+
+		rates.EXPECT().GetLatestRate(gomock.Any()).Return(paper.Rate{Rate: 40000, DateTime: now.Add(-2 * time.Minute)}, nil)
+
+		_, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Buy, Funds: "10"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, paper.ErrNoRate))
+		assert.True(t, apperror.IsRetryable(err))
+	})
+
+	t.Run("returns error if balances can't be read", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, store, rates := newExchange(t, ctrl)
+		testErr := errors.New("error")
+
+		rates.EXPECT().GetLatestRate(gomock.Any()).Return(rate, nil)
+		store.EXPECT().GetBalances(gomock.Any()).Return(nil, testErr)
+
+		_, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Buy, Funds: "10"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("returns insufficient funds error if balance can't cover order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, store, rates := newExchange(t, ctrl)
+
+		rates.EXPECT().GetLatestRate(gomock.Any()).Return(rate, nil)
+		store.EXPECT().GetBalances(gomock.Any()).Return(balances, nil)
+
+		_, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Sell, Size: "1"})
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.InsufficientFunds, apperror.CodeOf(err))
+	})
+
+	t.Run("returns error if order would rest on the book", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, store, rates := newExchange(t, ctrl)
+
+		rates.EXPECT().GetLatestRate(gomock.Any()).Return(rate, nil)
+		store.EXPECT().GetBalances(gomock.Any()).Return(balances, nil)
+
+		_, err := ex.CreateOrder(context.Background(), exchange.NewOrder{
+			ProductId:   productId,
+			Side:        exchange.Buy,
+			Type:        exchange.Limit,
+			Size:        "0.01",
+			Price:       "30000",
+			TimeInForce: exchange.GoodTillCancelled,
+		})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, paper.ErrRestingOrder))
+	})
+
+	t.Run("returns error if balances can't be updated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, store, rates := newExchange(t, ctrl)
+		testErr := errors.New("error")
+
+		rates.EXPECT().GetLatestRate(gomock.Any()).Return(rate, nil)
+		store.EXPECT().GetBalances(gomock.Any()).Return(balances, nil)
+		store.EXPECT().UpdateBalances(gomock.Any(), gomock.Any()).Return(testErr)
+
+		_, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Buy, Funds: "100"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("reverts balances if order can't be saved", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, store, rates := newExchange(t, ctrl, paper.WithFeeRate(decimal.Zero))
+		testErr := errors.New("error")
+
+		var changes []map[string]decimal.Decimal
+		updateBalances := func(_ context.Context, c map[string]decimal.Decimal) error {
+			changes = append(changes, c)
+			return nil
+		}
+
+		rates.EXPECT().GetLatestRate(gomock.Any()).Return(rate, nil)
+		store.EXPECT().GetBalances(gomock.Any()).Return(balances, nil)
+		gomock.InOrder(
+			store.EXPECT().UpdateBalances(gomock.Any(), gomock.Any()).DoAndReturn(updateBalances),
+			store.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(testErr),
+			store.EXPECT().UpdateBalances(gomock.Any(), gomock.Any()).DoAndReturn(updateBalances),
+		)
+
+		_, err := ex.CreateOrder(context.Background(), exchange.NewOrder{ProductId: productId, Side: exchange.Sell, Size: "0.01"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+
+		require.Len(t, changes, 2)
+		assert.Equal(t, "400", changes[0]["GBP"].String())
+		assert.Equal(t, "-0.01", changes[0]["BTC"].String())
+		assert.Equal(t, "-400", changes[1]["GBP"].String())
+		assert.Equal(t, "0.01", changes[1]["BTC"].String())
+	})
+
+	t.Run("fills market order at latest rate and saves it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, store, rates := newExchange(t, ctrl)
+
+		var (
+			changes map[string]decimal.Decimal
+			saved   paper.Order
+		)
+		rates.EXPECT().GetLatestRate(gomock.Any()).Return(rate, nil)
+		store.EXPECT().GetBalances(gomock.Any()).Return(balances, nil)
+		store.EXPECT().UpdateBalances(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c map[string]decimal.Decimal) error {
+			changes = c
+			return nil
+		})
+		store.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, o paper.Order) error {
+			saved = o
+			return nil
+		})
+
+		o, err := ex.CreateOrder(context.Background(), exchange.NewOrder{
+			ProductId:     productId,
+			Side:          exchange.Buy,
+			Type:          exchange.Market,
+			Funds:         "100",
+			ClientOrderId: "client-order-id",
+		})
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, o.ID)
+		assert.Equal(t, exchange.StatusDone, o.Status)
+		assert.True(t, o.Settled)
+		assert.Equal(t, now, o.CreatedAt)
+		assert.Equal(t, "0.00248756", o.FilledSize)
+		assert.Equal(t, "99.5024", o.ExecutedValue)
+		assert.Equal(t, "0.497512", o.FillFees)
+
+		require.Len(t, changes, 2)
+		assert.Equal(t, "-99.999912", changes["GBP"].String())
+		assert.Equal(t, "0.00248756", changes["BTC"].String())
+
+		assert.Equal(t, o, saved.Order)
+		assert.Equal(t, "client-order-id", saved.ClientOrderId)
+		require.Len(t, saved.Fills, 1)
+		assert.NotEmpty(t, saved.Fills[0].ID)
+		assert.Equal(t, o.ID, saved.Fills[0].OrderId)
+		assert.Equal(t, "40000", saved.Fills[0].Price)
+		assert.Equal(t, "0.00248756", saved.Fills[0].Size)
+		assert.Equal(t, exchange.Taker, saved.Fills[0].Liquidity)
+	})
+}
+
+func TestPaper_GetOrderByClientId(t *testing.T) {
+	t.Run("returns order not found error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, store, _ := newExchange(t, ctrl)
+
+		store.EXPECT().GetOrderByClientId(gomock.Any(), "client-order-id").Return(paper.Order{}, exchange.ErrOrderNotFound)
+
+		_, err := ex.(trader.OrderFinder).GetOrderByClientId(context.Background(), "client-order-id")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, exchange.ErrOrderNotFound))
+	})
+
+	t.Run("returns order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, store, _ := newExchange(t, ctrl)
+		order := exchange.Order{ID: "order-id", ProductId: productId}
+
+		store.EXPECT().GetOrderByClientId(gomock.Any(), "client-order-id").Return(paper.Order{Order: order, ClientOrderId: "client-order-id"}, nil)
+
+		o, err := ex.(trader.OrderFinder).GetOrderByClientId(context.Background(), "client-order-id")
+		require.NoError(t, err)
+
+		assert.Equal(t, order, o)
+	})
+}
+
+func TestPaper_CancelOrder(t *testing.T) {
+	t.Run("returns order not found error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, _, _ := newExchange(t, ctrl)
+
+		err := ex.CancelOrder(context.Background(), "order-id")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, exchange.ErrOrderNotFound))
+	})
+}
+
+func TestPaper_ListFills(t *testing.T) {
+	t.Run("returns fills since time newest first", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, store, _ := newExchange(t, ctrl)
+		since := now.Add(-time.Hour)
+
+		store.EXPECT().ListOrders(gomock.Any(), productId, since).Return([]paper.Order{
+			{Fills: []exchange.Fill{{ID: "3", CreatedAt: now}}},
+			{Fills: []exchange.Fill{{ID: "1", CreatedAt: since.Add(-time.Second)}, {ID: "2", CreatedAt: since}}},
+		}, nil)
+
+		fills, err := ex.ListFills(context.Background(), productId, since)
+		require.NoError(t, err)
+
+		assert.Equal(t, []exchange.Fill{{ID: "3", CreatedAt: now}, {ID: "2", CreatedAt: since}}, fills)
+	})
+}
+
+func TestPaper_GetAccounts(t *testing.T) {
+	t.Run("returns balances of wallet", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, store, _ := newExchange(t, ctrl)
+
+		store.EXPECT().GetBalances(gomock.Any()).Return(balances, nil)
+
+		accounts, err := ex.GetAccounts(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []exchange.Account{
+			{ID: "paper-account-btc", Currency: "BTC", Balance: "0.1", Hold: "0", Available: "0.1"},
+			{ID: "paper-account-gbp", Currency: "GBP", Balance: "1000", Hold: "0", Available: "1000"},
+		}, accounts)
+	})
+}
+
+func TestPaper_GetTicker(t *testing.T) {
+	t.Run("returns error if product is not supported", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, _, _ := newExchange(t, ctrl)
+
+		_, err := ex.GetTicker(context.Background(), "ETH-GBP")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, paper.ErrUnsupportedProduct))
+	})
+
+	t.Run("returns latest rate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex, _, rates := newExchange(t, ctrl, paper.WithProduct("BTC-EUR"))
+
+		rates.EXPECT().GetLatestRate(gomock.Any()).Return(rate, nil)
+
+		ticker, err := ex.GetTicker(context.Background(), "BTC-EUR")
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.Ticker{ProductId: "BTC-EUR", Price: "40000", Time: rate.DateTime}, ticker)
+	})
+}
@@ -0,0 +1,51 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultMaxAttempts = 3
+	defaultBaseDelay   = 200 * time.Millisecond
+	defaultMaxDelay    = 2 * time.Second
+)
+
+type Option func(*retrier)
+
+// WithMaxAttempts sets the maximum number of times a call is made, including the first.
+func WithMaxAttempts(attempts int) Option {
+	return func(r *retrier) {
+		if attempts > 0 {
+			r.maxAttempts = attempts
+		}
+	}
+}
+
+// WithBackoff sets the delay before the first retry and the maximum delay. The
+// delay doubles after each attempt up to max, and a random delay up to it is used.
+func WithBackoff(base, max time.Duration) Option {
+	return func(r *retrier) {
+		if base > 0 {
+			r.baseDelay = base
+		}
+		if max > 0 {
+			r.maxDelay = max
+		}
+	}
+}
+
+// WithSleep sets the function used to wait between attempts. It returns an error
+// if the context is done before the duration has passed.
+func WithSleep(sleep func(context.Context, time.Duration) error) Option {
+	return func(r *retrier) {
+		r.sleep = sleep
+	}
+}
+
+// WithoutJitter waits for the full backoff between attempts rather than a random delay up to it.
+func WithoutJitter() Option {
+	return func(r *retrier) {
+		r.jitter = func(d time.Duration) time.Duration { return d }
+	}
+}
@@ -0,0 +1,297 @@
+// Package retry decorates an exchange, retrying calls which fail with a
+// transient error using exponential backoff with jitter.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/shared/go/apperror"
+)
+
+type (
+	Exchange interface {
+		CreateOrder(ctx context.Context, order exchange.NewOrder) (exchange.Order, error)
+		GetOrder(ctx context.Context, id string) (exchange.Order, error)
+		CancelOrder(ctx context.Context, id string) error
+		CancelAllOrders(ctx context.Context, productId string) ([]string, error)
+		ListOpenOrders(ctx context.Context, productId string) ([]exchange.Order, error)
+		ListFills(ctx context.Context, productId string, since time.Time) ([]exchange.Fill, error)
+		GetOrderFills(ctx context.Context, orderId string) ([]exchange.Fill, error)
+		GetAccounts(ctx context.Context) ([]exchange.Account, error)
+		GetProducts(ctx context.Context) ([]exchange.Product, error)
+		GetTicker(ctx context.Context, productId string) (exchange.Ticker, error)
+	}
+
+	// OrderFinder is implemented by exchanges which can look up an order by its
+	// client order ID. Orders are only placed again if the exchange implements it.
+	OrderFinder interface {
+		GetOrderByClientId(ctx context.Context, clientOrderId string) (exchange.Order, error)
+	}
+
+	retrier struct {
+		exchange    Exchange
+		maxAttempts int
+		baseDelay   time.Duration
+		maxDelay    time.Duration
+		sleep       func(context.Context, time.Duration) error
+		jitter      func(time.Duration) time.Duration
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+
+	// stopError stops retrying, returning the wrapped error.
+	stopError struct {
+		err error
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func (s stopError) Error() string {
+	return s.err.Error()
+}
+
+// New wraps the exchange, retrying calls which fail with an exchange unavailable,
+// rate limited or timeout error.
+func New(exchange Exchange, opts ...Option) (*retrier, error) {
+	if exchange == nil {
+		return nil, InvalidParameterError{Parameter: "exchange"}
+	}
+
+	r := &retrier{
+		exchange:    exchange,
+		maxAttempts: defaultMaxAttempts,
+		baseDelay:   defaultBaseDelay,
+		maxDelay:    defaultMaxDelay,
+		sleep:       sleep,
+		jitter:      fullJitter,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r, nil
+}
+
+// CreateOrder is only retried if the order has a client order ID and the exchange
+// can look orders up by it. Before placing the order again the exchange is checked
+// for the order, as the failed request may have placed it. If the check fails the
+// original error is returned rather than risk placing the order twice.
+func (r *retrier) CreateOrder(ctx context.Context, order exchange.NewOrder) (exchange.Order, error) {
+	finder, ok := r.exchange.(OrderFinder)
+	if !ok || order.ClientOrderId == "" {
+		return r.exchange.CreateOrder(ctx, order)
+	}
+
+	var res exchange.Order
+	err := r.do(ctx, func() error {
+		var err error
+		res, err = r.exchange.CreateOrder(ctx, order)
+		if err == nil || !apperror.IsRetryable(err) {
+			return err
+		}
+
+		o, findErr := finder.GetOrderByClientId(ctx, order.ClientOrderId)
+		switch {
+		case findErr == nil:
+			res = o
+			return nil
+		case errors.Is(findErr, exchange.ErrOrderNotFound):
+			return err
+		default:
+			return stopError{err: err}
+		}
+	})
+
+	return res, err
+}
+
+func (r *retrier) GetOrder(ctx context.Context, id string) (exchange.Order, error) {
+	var res exchange.Order
+	err := r.do(ctx, func() error {
+		var err error
+		res, err = r.exchange.GetOrder(ctx, id)
+		return err
+	})
+
+	return res, err
+}
+
+// GetOrderByClientId is retried if the exchange can look orders up by client order ID.
+func (r *retrier) GetOrderByClientId(ctx context.Context, clientOrderId string) (exchange.Order, error) {
+	finder, ok := r.exchange.(OrderFinder)
+	if !ok {
+		return exchange.Order{}, exchange.ErrNotSupported
+	}
+
+	var res exchange.Order
+	err := r.do(ctx, func() error {
+		var err error
+		res, err = finder.GetOrderByClientId(ctx, clientOrderId)
+		return err
+	})
+
+	return res, err
+}
+
+// CancelOrder is retried as cancelling an order which has already been cancelled
+// leaves it cancelled.
+func (r *retrier) CancelOrder(ctx context.Context, id string) error {
+	return r.do(ctx, func() error {
+		return r.exchange.CancelOrder(ctx, id)
+	})
+}
+
+func (r *retrier) CancelAllOrders(ctx context.Context, productId string) ([]string, error) {
+	var res []string
+	err := r.do(ctx, func() error {
+		var err error
+		res, err = r.exchange.CancelAllOrders(ctx, productId)
+		return err
+	})
+
+	return res, err
+}
+
+func (r *retrier) ListOpenOrders(ctx context.Context, productId string) ([]exchange.Order, error) {
+	var res []exchange.Order
+	err := r.do(ctx, func() error {
+		var err error
+		res, err = r.exchange.ListOpenOrders(ctx, productId)
+		return err
+	})
+
+	return res, err
+}
+
+func (r *retrier) ListFills(ctx context.Context, productId string, since time.Time) ([]exchange.Fill, error) {
+	var res []exchange.Fill
+	err := r.do(ctx, func() error {
+		var err error
+		res, err = r.exchange.ListFills(ctx, productId, since)
+		return err
+	})
+
+	return res, err
+}
+
+func (r *retrier) GetOrderFills(ctx context.Context, orderId string) ([]exchange.Fill, error) {
+	var res []exchange.Fill
+	err := r.do(ctx, func() error {
+		var err error
+		res, err = r.exchange.GetOrderFills(ctx, orderId)
+		return err
+	})
+
+	return res, err
+}
+
+func (r *retrier) GetAccounts(ctx context.Context) ([]exchange.Account, error) {
+	var res []exchange.Account
+	err := r.do(ctx, func() error {
+		var err error
+		res, err = r.exchange.GetAccounts(ctx)
+		return err
+	})
+
+	return res, err
+}
+
+func (r *retrier) GetProducts(ctx context.Context) ([]exchange.Product, error) {
+	var res []exchange.Product
+	err := r.do(ctx, func() error {
+		var err error
+		res, err = r.exchange.GetProducts(ctx)
+		return err
+	})
+
+	return res, err
+}
+
+func (r *retrier) GetTicker(ctx context.Context, productId string) (exchange.Ticker, error) {
+	var res exchange.Ticker
+	err := r.do(ctx, func() error {
+		var err error
+		res, err = r.exchange.GetTicker(ctx, productId)
+		return err
+	})
+
+	return res, err
+}
+
+// do calls fn until it succeeds, fails with an error which isn't retryable or
+// the maximum number of attempts is reached. If the context is done while waiting
+// for the next attempt the last error is returned.
+func (r *retrier) do(ctx context.Context, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+
+		var stop stopError
+		switch {
+		case err == nil:
+			return nil
+		case errors.As(err, &stop):
+			return stop.err
+		case !apperror.IsRetryable(err), attempt >= r.maxAttempts:
+			return err
+		}
+
+		delay, ok := r.delay(attempt, err)
+		if !ok {
+			return err
+		}
+		if r.sleep(ctx, delay) != nil {
+			return err
+		}
+	}
+}
+
+// delay returns how long to wait before the next attempt. If the exchange asked
+// to wait for longer than the maximum delay the call isn't retried.
+func (r *retrier) delay(attempt int, err error) (time.Duration, bool) {
+	var rlErr exchange.RateLimitError
+	if errors.As(err, &rlErr) && rlErr.RetryAfter > 0 {
+		return rlErr.RetryAfter, rlErr.RetryAfter <= r.maxDelay
+	}
+
+	backoff := r.baseDelay << uint(attempt-1)
+	if backoff > r.maxDelay || backoff <= 0 {
+		backoff = r.maxDelay
+	}
+
+	return r.jitter(backoff), true
+}
+
+// sleep waits for the duration, returning early with the context's error if it
+// is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// fullJitter returns a random duration up to d, spreading out retries from
+// concurrent callers.
+func fullJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
@@ -0,0 +1,319 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/retry"
+	"github.com/cshep4/kripto/services/trader/internal/mocks/retry"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// finderExchange is an exchange which can look orders up by client order ID.
+	finderExchange struct {
+		*retry_mocks.MockExchange
+		*retry_mocks.MockOrderFinder
+	}
+
+	sleeper struct {
+		delays []time.Duration
+	}
+)
+
+func (s *sleeper) sleep(_ context.Context, d time.Duration) error {
+	s.delays = append(s.delays, d)
+	return nil
+}
+
+var (
+	unavailableErr = apperror.New(apperror.ExchangeUnavailable, errors.New("service unavailable"))
+	rejectedErr    = apperror.New(apperror.ExchangeRejected, errors.New("size is too small"))
+)
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if exchange is empty", func(t *testing.T) {
+		r, err := retry.New(nil)
+		require.Error(t, err)
+
+		assert.Nil(t, r)
+
+		ipErr, ok := err.(retry.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "exchange", ipErr.Parameter)
+	})
+
+	t.Run("returns retrier", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		r, err := retry.New(retry_mocks.NewMockExchange(ctrl))
+		require.NoError(t, err)
+
+		assert.NotNil(t, r)
+	})
+}
+
+func TestRetrier_GetAccounts(t *testing.T) {
+	t.Run("returns error without retrying if error is not retryable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := retry_mocks.NewMockExchange(ctrl)
+		s := &sleeper{}
+
+		r, err := retry.New(ex, retry.WithSleep(s.sleep))
+		require.NoError(t, err)
+
+		ex.EXPECT().GetAccounts(gomock.Any()).Return(nil, rejectedErr)
+
+		accounts, err := r.GetAccounts(context.Background())
+		require.Error(t, err)
+
+		assert.Equal(t, rejectedErr, err)
+		assert.Nil(t, accounts)
+		assert.Empty(t, s.delays)
+	})
+
+	t.Run("returns error after max attempts with exponential backoff", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := retry_mocks.NewMockExchange(ctrl)
+		s := &sleeper{}
+
+		r, err := retry.New(ex,
+			retry.WithSleep(s.sleep),
+			retry.WithMaxAttempts(4),
+			retry.WithBackoff(100*time.Millisecond, 300*time.Millisecond),
+			retry.WithoutJitter(),
+		)
+		require.NoError(t, err)
+
+		ex.EXPECT().GetAccounts(gomock.Any()).Return(nil, unavailableErr).Times(4)
+
+		_, err = r.GetAccounts(context.Background())
+		require.Error(t, err)
+
+		assert.Equal(t, unavailableErr, err)
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}, s.delays)
+	})
+
+	t.Run("waits for a random delay up to the backoff", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := retry_mocks.NewMockExchange(ctrl)
+		s := &sleeper{}
+
+		r, err := retry.New(ex, retry.WithSleep(s.sleep), retry.WithBackoff(100*time.Millisecond, time.Second))
+		require.NoError(t, err)
+
+		ex.EXPECT().GetAccounts(gomock.Any()).Return(nil, unavailableErr).Times(3)
+
+		_, err = r.GetAccounts(context.Background())
+		require.Error(t, err)
+
+		require.Len(t, s.delays, 2)
+		assert.True(t, s.delays[0] <= 100*time.Millisecond)
+		assert.True(t, s.delays[1] <= 200*time.Millisecond)
+	})
+
+	t.Run("waits for the delay requested by the exchange", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := retry_mocks.NewMockExchange(ctrl)
+		s := &sleeper{}
+
+		r, err := retry.New(ex, retry.WithSleep(s.sleep))
+		require.NoError(t, err)
+
+		accounts := []exchange.Account{{ID: "id"}}
+
+		gomock.InOrder(
+			ex.EXPECT().GetAccounts(gomock.Any()).Return(nil, exchange.RateLimitError{Err: errors.New("rate limit exceeded"), RetryAfter: time.Second}),
+			ex.EXPECT().GetAccounts(gomock.Any()).Return(accounts, nil),
+		)
+
+		res, err := r.GetAccounts(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, accounts, res)
+		assert.Equal(t, []time.Duration{time.Second}, s.delays)
+	})
+
+	t.Run("returns error without retrying if exchange asks to wait longer than max delay", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := retry_mocks.NewMockExchange(ctrl)
+		s := &sleeper{}
+
+		r, err := retry.New(ex, retry.WithSleep(s.sleep), retry.WithBackoff(time.Millisecond, time.Second))
+		require.NoError(t, err)
+
+		rlErr := exchange.RateLimitError{Err: errors.New("rate limit exceeded"), RetryAfter: time.Minute}
+
+		ex.EXPECT().GetAccounts(gomock.Any()).Return(nil, rlErr)
+
+		_, err = r.GetAccounts(context.Background())
+		require.Error(t, err)
+
+		assert.Equal(t, rlErr, err)
+		assert.Empty(t, s.delays)
+	})
+	t.Run("stops waiting to retry if context of call is done", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := retry_mocks.NewMockExchange(ctrl)
+
+		r, err := retry.New(ex, retry.WithBackoff(time.Minute, time.Minute), retry.WithoutJitter())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ex.EXPECT().GetAccounts(ctx).DoAndReturn(func(context.Context) ([]exchange.Account, error) {
+			time.AfterFunc(10*time.Millisecond, cancel)
+			return nil, unavailableErr
+		})
+
+		start := time.Now()
+		_, err = r.GetAccounts(ctx)
+		require.Error(t, err)
+
+		assert.Equal(t, unavailableErr, err)
+		assert.True(t, time.Since(start) < time.Second)
+	})
+}
+
+func TestRetrier_CreateOrder(t *testing.T) {
+	order := exchange.NewOrder{ProductId: "BTC-GBP", Side: exchange.Buy, Type: exchange.Market, Funds: "10.00", ClientOrderId: "client-id"}
+
+	t.Run("doesn't retry if order has no client order id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := finderExchange{retry_mocks.NewMockExchange(ctrl), retry_mocks.NewMockOrderFinder(ctrl)}
+
+		r, err := retry.New(ex, retry.WithSleep(func(context.Context, time.Duration) error { return nil }))
+		require.NoError(t, err)
+
+		o := order
+		o.ClientOrderId = ""
+
+		ex.MockExchange.EXPECT().CreateOrder(gomock.Any(), o).Return(exchange.Order{}, unavailableErr)
+
+		_, err = r.CreateOrder(context.Background(), o)
+		require.Error(t, err)
+
+		assert.Equal(t, unavailableErr, err)
+	})
+
+	t.Run("doesn't retry if exchange can't find orders by client order id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := retry_mocks.NewMockExchange(ctrl)
+
+		r, err := retry.New(ex, retry.WithSleep(func(context.Context, time.Duration) error { return nil }))
+		require.NoError(t, err)
+
+		ex.EXPECT().CreateOrder(gomock.Any(), order).Return(exchange.Order{}, unavailableErr)
+
+		_, err = r.CreateOrder(context.Background(), order)
+		require.Error(t, err)
+
+		assert.Equal(t, unavailableErr, err)
+	})
+
+	t.Run("places order again if it wasn't placed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := finderExchange{retry_mocks.NewMockExchange(ctrl), retry_mocks.NewMockOrderFinder(ctrl)}
+
+		r, err := retry.New(ex, retry.WithSleep(func(context.Context, time.Duration) error { return nil }))
+		require.NoError(t, err)
+
+		placed := exchange.Order{ID: "id"}
+
+		gomock.InOrder(
+			ex.MockExchange.EXPECT().CreateOrder(gomock.Any(), order).Return(exchange.Order{}, unavailableErr),
+			ex.MockOrderFinder.EXPECT().GetOrderByClientId(gomock.Any(), "client-id").Return(exchange.Order{}, exchange.ErrOrderNotFound),
+			ex.MockExchange.EXPECT().CreateOrder(gomock.Any(), order).Return(placed, nil),
+		)
+
+		res, err := r.CreateOrder(context.Background(), order)
+		require.NoError(t, err)
+
+		assert.Equal(t, placed, res)
+	})
+
+	t.Run("returns order placed by failed request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := finderExchange{retry_mocks.NewMockExchange(ctrl), retry_mocks.NewMockOrderFinder(ctrl)}
+
+		r, err := retry.New(ex, retry.WithSleep(func(context.Context, time.Duration) error { return nil }))
+		require.NoError(t, err)
+
+		placed := exchange.Order{ID: "id"}
+
+		gomock.InOrder(
+			ex.MockExchange.EXPECT().CreateOrder(gomock.Any(), order).Return(exchange.Order{}, unavailableErr),
+			ex.MockOrderFinder.EXPECT().GetOrderByClientId(gomock.Any(), "client-id").Return(placed, nil),
+		)
+
+		res, err := r.CreateOrder(context.Background(), order)
+		require.NoError(t, err)
+
+		assert.Equal(t, placed, res)
+	})
+
+	t.Run("returns error if order can't be looked up", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := finderExchange{retry_mocks.NewMockExchange(ctrl), retry_mocks.NewMockOrderFinder(ctrl)}
+
+		r, err := retry.New(ex, retry.WithSleep(func(context.Context, time.Duration) error { return nil }))
+		require.NoError(t, err)
+
+		gomock.InOrder(
+			ex.MockExchange.EXPECT().CreateOrder(gomock.Any(), order).Return(exchange.Order{}, unavailableErr),
+			ex.MockOrderFinder.EXPECT().GetOrderByClientId(gomock.Any(), "client-id").Return(exchange.Order{}, unavailableErr),
+		)
+
+		_, err = r.CreateOrder(context.Background(), order)
+		require.Error(t, err)
+
+		assert.Equal(t, unavailableErr, err)
+	})
+
+	t.Run("doesn't retry if order is rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := finderExchange{retry_mocks.NewMockExchange(ctrl), retry_mocks.NewMockOrderFinder(ctrl)}
+
+		r, err := retry.New(ex, retry.WithSleep(func(context.Context, time.Duration) error { return nil }))
+		require.NoError(t, err)
+
+		ex.MockExchange.EXPECT().CreateOrder(gomock.Any(), order).Return(exchange.Order{}, rejectedErr)
+
+		_, err = r.CreateOrder(context.Background(), order)
+		require.Error(t, err)
+
+		assert.Equal(t, rejectedErr, err)
+	})
+}
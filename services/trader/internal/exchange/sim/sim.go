@@ -0,0 +1,281 @@
+// Package sim simulates filling an order at a single price against a set of
+// balances. It is shared by the fake and paper exchanges and by dry runs, so an
+// order is filled the same way wherever it is simulated.
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/shopspring/decimal"
+)
+
+const sizeDecimals = 8
+
+var (
+	ErrInsufficientFunds = apperror.New(apperror.InsufficientFunds, errors.New("insufficient funds"))
+	ErrPostOnly          = apperror.New(apperror.ExchangeRejected, errors.New("post only order would execute immediately"))
+
+	one = decimal.New(1, 0)
+)
+
+type (
+	// Order is a new order with its amounts parsed.
+	Order struct {
+		Side        exchange.Side
+		Type        exchange.OrderType
+		TimeInForce exchange.TimeInForce
+		PostOnly    bool
+		Funds       decimal.Decimal
+		Size        decimal.Decimal
+		Price       decimal.Decimal
+	}
+
+	// Fill is the part of an order filled at a price.
+	Fill struct {
+		Price decimal.Decimal
+		Size  decimal.Decimal
+		Value decimal.Decimal
+		Fee   decimal.Decimal
+	}
+
+	// Market is the product an order is placed on, its price and the balances
+	// available to fill the order. FillRatio is the fraction of the order which
+	// is filled, all of it if zero.
+	Market struct {
+		Product   exchange.Product
+		Price     decimal.Decimal
+		Balances  map[string]decimal.Decimal
+		FeeRate   decimal.Decimal
+		FillRatio decimal.Decimal
+	}
+
+	// Result is an order placed on a market, its fill and the change in each
+	// balance from the fill.
+	Result struct {
+		Order   exchange.Order
+		Fills   []exchange.Fill
+		Changes map[string]decimal.Decimal
+	}
+)
+
+// NewOrder parses the amounts of the order. Orders without a type are market orders.
+func NewOrder(req exchange.NewOrder) (Order, error) {
+	o := Order{
+		Side:        req.Side,
+		Type:        req.Type,
+		TimeInForce: req.TimeInForce,
+		PostOnly:    req.PostOnly,
+	}
+	if o.Type == "" {
+		o.Type = exchange.Market
+	}
+
+	var err error
+	if req.Funds != "" {
+		if o.Funds, err = decimal.NewFromString(req.Funds); err != nil {
+			return Order{}, fmt.Errorf("invalid_funds: %w", err)
+		}
+	}
+	if req.Size != "" {
+		if o.Size, err = decimal.NewFromString(req.Size); err != nil {
+			return Order{}, fmt.Errorf("invalid_size: %w", err)
+		}
+	}
+	if req.Price != "" {
+		if o.Price, err = decimal.NewFromString(req.Price); err != nil {
+			return Order{}, fmt.Errorf("invalid_price: %w", err)
+		}
+	}
+
+	return o, nil
+}
+
+// NewFill returns the fill of size at price, charged the fee rate.
+func NewFill(price, size, feeRate decimal.Decimal) Fill {
+	value := size.Mul(price)
+
+	return Fill{
+		Price: price,
+		Size:  size,
+		Value: value,
+		Fee:   value.Mul(feeRate),
+	}
+}
+
+// Place returns the part of the order filled straight away at the price and
+// the status of the order once it is placed. Market orders are filled at the
+// price, limit orders are only filled if they cross it and rest on the book
+// unless they are fully filled, IOC or FOK, and stop orders wait for the market
+// to reach their stop price. Only the fill ratio of the order is filled.
+func Place(o Order, price, feeRate, fillRatio decimal.Decimal) (Fill, string, error) {
+	if !fillRatio.IsPositive() {
+		fillRatio = one
+	}
+
+	switch o.Type {
+	case exchange.Stop:
+		return Fill{}, exchange.StatusActive, nil
+	case exchange.Limit:
+		return placeLimit(o, price, feeRate, fillRatio)
+	default:
+		size := o.Size
+		if o.Funds.IsPositive() {
+			// funds include fees when buying
+			value := o.Funds
+			if o.Side == exchange.Buy {
+				value = value.Div(one.Add(feeRate))
+			}
+			size = value.Div(price)
+		}
+		size = size.Mul(fillRatio).Truncate(sizeDecimals)
+
+		return NewFill(price, size, feeRate), exchange.StatusDone, nil
+	}
+}
+
+func placeLimit(o Order, price, feeRate, fillRatio decimal.Decimal) (Fill, string, error) {
+	marketable := (o.Side == exchange.Buy && price.LessThanOrEqual(o.Price)) ||
+		(o.Side == exchange.Sell && price.GreaterThanOrEqual(o.Price))
+
+	var fill Fill
+	switch {
+	case marketable && o.PostOnly:
+		return Fill{}, "", ErrPostOnly
+	case marketable && o.TimeInForce == exchange.FillOrKill && fillRatio.LessThan(one):
+		return Fill{}, exchange.StatusDone, nil
+	case marketable:
+		fill = NewFill(price, o.Size.Mul(fillRatio).Truncate(sizeDecimals), feeRate)
+	}
+
+	if fill.Size.Equal(o.Size) || o.TimeInForce == exchange.ImmediateOrCancel || o.TimeInForce == exchange.FillOrKill {
+		return fill, exchange.StatusDone, nil
+	}
+
+	return fill, exchange.StatusOpen, nil
+}
+
+// Hold returns the currency and amount held to fill the rest of a limit order
+// resting on the book, which is the quote currency including fees when buying.
+func Hold(product exchange.Product, o Order, filled, feeRate decimal.Decimal) (string, decimal.Decimal) {
+	remaining := o.Size.Sub(filled)
+	if o.Side == exchange.Buy {
+		return product.QuoteCurrency, remaining.Mul(o.Price).Mul(one.Add(feeRate))
+	}
+
+	return product.BaseCurrency, remaining
+}
+
+// Changes returns the change in the base and quote balances from the fill.
+// Buying spends the value plus fees, selling receives the value less fees.
+func Changes(product exchange.Product, side exchange.Side, fill Fill) map[string]decimal.Decimal {
+	if side == exchange.Buy {
+		return map[string]decimal.Decimal{
+			product.BaseCurrency:  fill.Size,
+			product.QuoteCurrency: fill.Value.Add(fill.Fee).Neg(),
+		}
+	}
+
+	return map[string]decimal.Decimal{
+		product.BaseCurrency:  fill.Size.Neg(),
+		product.QuoteCurrency: fill.Value.Sub(fill.Fee),
+	}
+}
+
+// Cover returns ErrInsufficientFunds if the available balances can't cover the
+// changes. Currencies are matched case insensitively.
+func Cover(available, changes map[string]decimal.Decimal) error {
+	for currency, change := range changes {
+		if balance(available, currency).Add(change).IsNegative() {
+			return ErrInsufficientFunds
+		}
+	}
+
+	return nil
+}
+
+func balance(balances map[string]decimal.Decimal, currency string) decimal.Decimal {
+	for c, b := range balances {
+		if strings.EqualFold(c, currency) {
+			return b
+		}
+	}
+
+	return decimal.Zero
+}
+
+// Execute places the order on the market. The balances must cover the fill and
+// any funds held by the order resting on the book, ErrInsufficientFunds is
+// returned if they don't. The order and fill have no ID, they are set by the
+// caller.
+func Execute(m Market, req exchange.NewOrder, now time.Time) (Result, error) {
+	o, err := NewOrder(req)
+	if err != nil {
+		return Result{}, err
+	}
+
+	fill, status, err := Place(o, m.Price, m.FeeRate, m.FillRatio)
+	if err != nil {
+		return Result{}, err
+	}
+
+	changes := Changes(m.Product, o.Side, fill)
+	if err := Cover(m.Balances, withHold(m.Product, o, fill, status, m.FeeRate, changes)); err != nil {
+		return Result{}, err
+	}
+
+	res := exchange.Order{
+		ProductId:     req.ProductId,
+		Side:          string(o.Side),
+		Type:          string(o.Type),
+		Status:        status,
+		Settled:       status == exchange.StatusDone,
+		Price:         req.Price,
+		Size:          req.Size,
+		Funds:         req.Funds,
+		TimeInForce:   string(req.TimeInForce),
+		PostOnly:      req.PostOnly,
+		CreatedAt:     now.UTC(),
+		FilledSize:    fill.Size.String(),
+		ExecutedValue: fill.Value.String(),
+		FillFees:      fill.Fee.String(),
+	}
+	if o.Type == exchange.Stop {
+		res.StopPrice, res.Price = req.Price, ""
+	}
+
+	if !fill.Size.IsPositive() {
+		return Result{Order: res}, nil
+	}
+
+	return Result{Order: res, Changes: changes, Fills: []exchange.Fill{{
+		ProductId: req.ProductId,
+		Side:      string(o.Side),
+		Price:     fill.Price.String(),
+		Size:      fill.Size.String(),
+		Fee:       fill.Fee.String(),
+		Liquidity: exchange.Taker,
+		CreatedAt: now.UTC(),
+	}}}, nil
+}
+
+// withHold returns the changes plus the funds held by an order resting on the book.
+func withHold(product exchange.Product, o Order, fill Fill, status string, feeRate decimal.Decimal, changes map[string]decimal.Decimal) map[string]decimal.Decimal {
+	if status != exchange.StatusOpen {
+		return changes
+	}
+
+	res := make(map[string]decimal.Decimal, len(changes))
+	for currency, change := range changes {
+		res[currency] = change
+	}
+
+	currency, hold := Hold(product, o, fill.Size, feeRate)
+	res[currency] = res[currency].Sub(hold)
+
+	return res
+}
@@ -0,0 +1,146 @@
+package sim_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/sim"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	now = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	product = exchange.Product{ID: "BTC-GBP", BaseCurrency: "BTC", QuoteCurrency: "GBP"}
+)
+
+func newMarket() sim.Market {
+	return sim.Market{
+		Product: product,
+		Price:   decimal.RequireFromString("40000"),
+		Balances: map[string]decimal.Decimal{
+			"GBP": decimal.RequireFromString("1000"),
+			"BTC": decimal.RequireFromString("0.1"),
+		},
+		FeeRate: decimal.RequireFromString("0.005"),
+	}
+}
+
+func TestPlace(t *testing.T) {
+	price, feeRate, fillRatio := decimal.RequireFromString("40000"), decimal.RequireFromString("0.005"), decimal.Zero
+
+	t.Run("fills market buy with funds including fees", func(t *testing.T) {
+		fill, status, err := sim.Place(sim.Order{Side: exchange.Buy, Type: exchange.Market, Funds: decimal.RequireFromString("100")}, price, feeRate, fillRatio)
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.StatusDone, status)
+		assert.Equal(t, "0.00248756", fill.Size.String())
+		assert.Equal(t, "99.5024", fill.Value.String())
+		assert.Equal(t, "0.497512", fill.Fee.String())
+	})
+
+	t.Run("only fills the fill ratio of the order", func(t *testing.T) {
+		fill, status, err := sim.Place(sim.Order{Side: exchange.Sell, Type: exchange.Market, Size: decimal.RequireFromString("0.1")}, price, feeRate, decimal.RequireFromString("0.5"))
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.StatusDone, status)
+		assert.Equal(t, "0.05", fill.Size.String())
+	})
+
+	t.Run("rests limit order which doesn't cross the price", func(t *testing.T) {
+		fill, status, err := sim.Place(sim.Order{Side: exchange.Buy, Type: exchange.Limit, Size: decimal.RequireFromString("0.01"), Price: decimal.RequireFromString("35000")}, price, feeRate, fillRatio)
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.StatusOpen, status)
+		assert.True(t, fill.Size.IsZero())
+	})
+
+	t.Run("doesn't rest IOC limit order which doesn't cross the price", func(t *testing.T) {
+		fill, status, err := sim.Place(sim.Order{Side: exchange.Buy, Type: exchange.Limit, TimeInForce: exchange.ImmediateOrCancel, Size: decimal.RequireFromString("0.01"), Price: decimal.RequireFromString("35000")}, price, feeRate, fillRatio)
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.StatusDone, status)
+		assert.True(t, fill.Size.IsZero())
+	})
+
+	t.Run("returns error if post only limit order would execute", func(t *testing.T) {
+		_, _, err := sim.Place(sim.Order{Side: exchange.Buy, Type: exchange.Limit, PostOnly: true, Size: decimal.RequireFromString("0.01"), Price: decimal.RequireFromString("45000")}, price, feeRate, fillRatio)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, sim.ErrPostOnly))
+	})
+
+	t.Run("doesn't fill stop order", func(t *testing.T) {
+		fill, status, err := sim.Place(sim.Order{Side: exchange.Sell, Type: exchange.Stop, Size: decimal.RequireFromString("0.01"), Price: decimal.RequireFromString("35000")}, price, feeRate, fillRatio)
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.StatusActive, status)
+		assert.True(t, fill.Size.IsZero())
+	})
+}
+
+func TestExecute(t *testing.T) {
+	t.Run("returns error if amount is invalid", func(t *testing.T) {
+		_, err := sim.Execute(newMarket(), exchange.NewOrder{ProductId: product.ID, Side: exchange.Buy, Funds: "ten"}, now)
+		require.Error(t, err)
+	})
+
+	t.Run("returns error if balances can't cover fill", func(t *testing.T) {
+		_, err := sim.Execute(newMarket(), exchange.NewOrder{ProductId: product.ID, Side: exchange.Sell, Size: "0.2"}, now)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, sim.ErrInsufficientFunds))
+	})
+
+	t.Run("returns error if balances can't cover funds held by resting order", func(t *testing.T) {
+		_, err := sim.Execute(newMarket(), exchange.NewOrder{ProductId: product.ID, Side: exchange.Buy, Type: exchange.Limit, Size: "1", Price: "35000"}, now)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, sim.ErrInsufficientFunds))
+	})
+
+	t.Run("returns resting order without fills", func(t *testing.T) {
+		res, err := sim.Execute(newMarket(), exchange.NewOrder{ProductId: product.ID, Side: exchange.Buy, Type: exchange.Limit, Size: "0.01", Price: "35000"}, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.StatusOpen, res.Order.Status)
+		assert.False(t, res.Order.Settled)
+		assert.Empty(t, res.Fills)
+		assert.Empty(t, res.Changes)
+	})
+
+	t.Run("returns filled order with its fill and balance changes", func(t *testing.T) {
+		res, err := sim.Execute(newMarket(), exchange.NewOrder{ProductId: product.ID, Side: exchange.Sell, Size: "0.01"}, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, exchange.Order{
+			ProductId:     product.ID,
+			Side:          "sell",
+			Type:          "market",
+			Status:        exchange.StatusDone,
+			Settled:       true,
+			Size:          "0.01",
+			CreatedAt:     now,
+			FilledSize:    "0.01",
+			ExecutedValue: "400",
+			FillFees:      "2",
+		}, res.Order)
+		assert.Equal(t, []exchange.Fill{{
+			ProductId: product.ID,
+			Side:      "sell",
+			Price:     "40000",
+			Size:      "0.01",
+			Fee:       "2",
+			Liquidity: exchange.Taker,
+			CreatedAt: now,
+		}}, res.Fills)
+
+		require.Len(t, res.Changes, 2)
+		assert.Equal(t, "-0.01", res.Changes["BTC"].String())
+		assert.Equal(t, "398", res.Changes["GBP"].String())
+	})
+}
@@ -2,21 +2,49 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/cshep4/kripto/services/trader/internal/model"
-	"strconv"
+	"strings"
 
+	"github.com/cshep4/kripto/services/trader/internal/model"
+	"github.com/cshep4/kripto/services/trader/internal/risk"
+	"github.com/cshep4/kripto/services/trader/internal/trader"
+	"github.com/cshep4/kripto/shared/go/apperror"
 	"github.com/cshep4/kripto/shared/go/log"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultProduct  = "BTC-GBP"
+	defaultCurrency = "GBP"
+
+	market = "market"
+	limit  = "limit"
+	stop   = "stop"
+
+	goodTillCancelled = "GTC"
+	immediateOrCancel = "IOC"
+	fillOrKill        = "FOK"
 )
 
 type (
 	Servicer interface {
-		Trade(ctx context.Context, tradeType, amount string) error
-		GetWallet(ctx context.Context) (*model.Wallet, error)
+		Trade(ctx context.Context, order model.Order) (*trader.TradeResponse, error)
+		GetWallet(ctx context.Context) (model.Wallet, error)
+		GetPortfolio(ctx context.Context, currency string) (model.Portfolio, error)
+		CancelOrder(ctx context.Context, id string) error
+		CancelAllOrders(ctx context.Context, productId string) ([]string, error)
+		ListOpenOrders(ctx context.Context, productId string) ([]model.OpenOrder, error)
+	}
+
+	// Relayer publishes trades left in the outbox.
+	Relayer interface {
+		Relay(ctx context.Context) (int, error)
 	}
 
 	Handler struct {
 		Service Servicer
+		Relayer Relayer
 	}
 
 	// InvalidParameterError is returned when a required parameter passed to New is invalid.
@@ -25,10 +53,44 @@ type (
 		Err       string
 	}
 
+	// RejectedError is returned when a trade is rejected by a risk check, the same
+	// request will be rejected again if it is retried.
+	RejectedError struct {
+		Check  string
+		Reason string
+	}
+
+	// TradeRequest is a request to place an order. If DryRun is set the order is
+	// validated, risk checked and filled at the current price without being placed.
 	TradeRequest struct {
 		IdempotencyKey string `json:"idempotencyKey"`
+		ProductId      string `json:"productId"`
 		TradeType      string `json:"tradeType"`
+		OrderType      string `json:"orderType"`
 		Amount         string `json:"amount"`
+		Price          string `json:"price"`
+		Size           string `json:"size"`
+		TimeInForce    string `json:"timeInForce"`
+		PostOnly       bool   `json:"postOnly"`
+		DryRun         bool   `json:"dryRun"`
+	}
+
+	CancelOrderRequest struct {
+		IdempotencyKey string `json:"idempotencyKey"`
+		OrderId        string `json:"orderId"`
+	}
+
+	CancelAllOrdersRequest struct {
+		IdempotencyKey string `json:"idempotencyKey"`
+		ProductId      string `json:"productId"`
+	}
+
+	ListOrdersRequest struct {
+		ProductId string `json:"productId"`
+	}
+
+	PortfolioRequest struct {
+		Currency string `json:"currency"`
 	}
 )
 
@@ -36,35 +98,168 @@ func (i BadRequestError) Error() string {
 	return fmt.Sprintf("bad request - param: %s, error: %s", i.Parameter, i.Err)
 }
 
-func (h *Handler) Trade(ctx context.Context, req TradeRequest) error {
+func (r RejectedError) Error() string {
+	return fmt.Sprintf("trade rejected - check: %s, reason: %s", r.Check, r.Reason)
+}
+
+func (BadRequestError) ErrorCode() apperror.Code {
+	return apperror.Validation
+}
+
+func (RejectedError) ErrorCode() apperror.Code {
+	return apperror.Validation
+}
+
+func (h *Handler) Trade(ctx context.Context, req TradeRequest) (*trader.TradeResponse, error) {
 	switch {
 	case req.TradeType == "":
-		return BadRequestError{Parameter: "tradeType", Err: "empty"}
+		return nil, BadRequestError{Parameter: "tradeType", Err: "empty"}
 	case req.TradeType != "buy" && req.TradeType != "sell":
-		return BadRequestError{Parameter: "tradeType", Err: "invalid value - should be either buy/sell"}
-	case req.Amount == "":
-		return BadRequestError{Parameter: "amount", Err: "empty"}
+		return nil, BadRequestError{Parameter: "tradeType", Err: "invalid value - should be either buy/sell"}
 	}
 
-	a, err := strconv.ParseFloat(req.Amount, 64)
+	order, err := req.toOrder()
 	if err != nil {
-		return BadRequestError{Parameter: "amount", Err: "invalid value - should be numeric"}
+		return nil, err
 	}
 
-	err = h.Service.Trade(ctx, req.TradeType, fmt.Sprintf("%.2f", a))
+	res, err := h.Service.Trade(ctx, order)
+	var rErr risk.RejectedError
+	if errors.As(err, &rErr) {
+		log.Warn(ctx, "trade_rejected",
+			log.SafeParam("check", rErr.Check),
+			log.SafeParam("reason", rErr.Reason),
+			log.SafeParam("productId", order.ProductId),
+			log.SafeParam("tradeType", req.TradeType),
+			log.SafeParam("dryRun", req.DryRun),
+		)
+		return nil, RejectedError{Check: rErr.Check, Reason: rErr.Reason}
+	}
 	if err != nil {
 		log.Error(ctx, "error_trading",
 			log.ErrorParam(err),
+			log.SafeParam("productId", order.ProductId),
 			log.SafeParam("tradeType", req.TradeType),
+			log.SafeParam("orderType", order.Type),
 			log.SafeParam("amount", req.Amount),
+			log.SafeParam("price", req.Price),
+			log.SafeParam("size", req.Size),
+			log.SafeParam("dryRun", req.DryRun),
 		)
-		return fmt.Errorf("trade: %w", err)
+		return nil, fmt.Errorf("trade: %w", err)
 	}
 
-	return nil
+	return res, nil
+}
+
+func (r TradeRequest) toOrder() (model.Order, error) {
+	order := model.Order{
+		ProductId:      strings.ToUpper(strings.TrimSpace(r.ProductId)),
+		Side:           r.TradeType,
+		Type:           r.OrderType,
+		IdempotencyKey: r.IdempotencyKey,
+		DryRun:         r.DryRun,
+	}
+	if order.ProductId == "" {
+		order.ProductId = defaultProduct
+	}
+	if order.Type == "" {
+		order.Type = market
+	}
+
+	if base, quote := splitProduct(order.ProductId); base == "" || quote == "" {
+		return model.Order{}, BadRequestError{Parameter: "productId", Err: "invalid value - should be in the format BASE-QUOTE"}
+	}
+
+	switch order.Type {
+	case market:
+		switch {
+		case r.Amount == "" && r.Size == "":
+			return model.Order{}, BadRequestError{Parameter: "amount", Err: "empty"}
+		case r.Amount != "" && r.Size != "":
+			return model.Order{}, BadRequestError{Parameter: "size", Err: "invalid value - cannot be used with amount"}
+		case r.Price != "":
+			return model.Order{}, BadRequestError{Parameter: "price", Err: "only valid for limit/stop orders"}
+		}
+	case limit:
+		switch {
+		case r.Price == "":
+			return model.Order{}, BadRequestError{Parameter: "price", Err: "empty"}
+		case r.Size == "":
+			return model.Order{}, BadRequestError{Parameter: "size", Err: "empty"}
+		case r.Amount != "":
+			return model.Order{}, BadRequestError{Parameter: "amount", Err: "only valid for market/stop orders"}
+		}
+	case stop:
+		switch {
+		case r.Price == "":
+			return model.Order{}, BadRequestError{Parameter: "price", Err: "empty"}
+		case r.Amount == "" && r.Size == "":
+			return model.Order{}, BadRequestError{Parameter: "amount", Err: "empty"}
+		case r.Amount != "" && r.Size != "":
+			return model.Order{}, BadRequestError{Parameter: "size", Err: "invalid value - cannot be used with amount"}
+		}
+	default:
+		return model.Order{}, BadRequestError{Parameter: "orderType", Err: "invalid value - should be either market/limit/stop"}
+	}
+
+	if order.Type != limit {
+		switch {
+		case r.TimeInForce != "":
+			return model.Order{}, BadRequestError{Parameter: "timeInForce", Err: "only valid for limit orders"}
+		case r.PostOnly:
+			return model.Order{}, BadRequestError{Parameter: "postOnly", Err: "only valid for limit orders"}
+		}
+	}
+
+	switch r.TimeInForce {
+	case "", goodTillCancelled:
+	case immediateOrCancel, fillOrKill:
+		if r.PostOnly {
+			return model.Order{}, BadRequestError{Parameter: "postOnly", Err: "invalid value - cannot be used with IOC/FOK"}
+		}
+	default:
+		return model.Order{}, BadRequestError{Parameter: "timeInForce", Err: "invalid value - should be either GTC/IOC/FOK"}
+	}
+	order.TimeInForce = r.TimeInForce
+	order.PostOnly = r.PostOnly
+
+	// Amounts are only checked to be numeric here, they are rounded to the
+	// precision of the product by the trader.
+	if r.Amount != "" {
+		if _, err := decimal.NewFromString(r.Amount); err != nil {
+			return model.Order{}, BadRequestError{Parameter: "amount", Err: "invalid value - should be numeric"}
+		}
+		order.Funds = r.Amount
+	}
+
+	if r.Size != "" {
+		if _, err := decimal.NewFromString(r.Size); err != nil {
+			return model.Order{}, BadRequestError{Parameter: "size", Err: "invalid value - should be numeric"}
+		}
+		order.Size = r.Size
+	}
+
+	if r.Price != "" {
+		if _, err := decimal.NewFromString(r.Price); err != nil {
+			return model.Order{}, BadRequestError{Parameter: "price", Err: "invalid value - should be numeric"}
+		}
+		order.Price = r.Price
+	}
+
+	return order, nil
+}
+
+func splitProduct(productId string) (string, string) {
+	parts := strings.Split(productId, "-")
+	if len(parts) != 2 {
+		return "", ""
+	}
+
+	return parts[0], parts[1]
 }
 
-func (h *Handler) GetWallet(ctx context.Context) (*model.Wallet, error) {
+func (h *Handler) GetWallet(ctx context.Context) (model.Wallet, error) {
 	wallet, err := h.Service.GetWallet(ctx)
 	if err != nil {
 		log.Error(ctx, "error_getting_wallet", log.ErrorParam(err))
@@ -73,3 +268,101 @@ func (h *Handler) GetWallet(ctx context.Context) (*model.Wallet, error) {
 
 	return wallet, nil
 }
+
+func (h *Handler) RelayTrades(ctx context.Context) error {
+	published, err := h.Relayer.Relay(ctx)
+	if published > 0 {
+		log.Info(ctx, "trades_relayed", log.SafeParam("published", published))
+	}
+	if err != nil {
+		log.Error(ctx, "error_relaying_trades", log.ErrorParam(err))
+		return fmt.Errorf("relay: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) GetPortfolio(ctx context.Context, req PortfolioRequest) (*model.Portfolio, error) {
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = defaultCurrency
+	}
+
+	portfolio, err := h.Service.GetPortfolio(ctx, currency)
+	if err != nil {
+		log.Error(ctx, "error_getting_portfolio",
+			log.ErrorParam(err),
+			log.SafeParam("currency", currency),
+		)
+		return nil, fmt.Errorf("get_portfolio: %w", err)
+	}
+
+	return &portfolio, nil
+}
+
+func (h *Handler) CancelOrder(ctx context.Context, req CancelOrderRequest) error {
+	if req.OrderId == "" {
+		return BadRequestError{Parameter: "orderId", Err: "empty"}
+	}
+
+	err := h.Service.CancelOrder(ctx, req.OrderId)
+	if err != nil {
+		log.Error(ctx, "error_cancelling_order",
+			log.ErrorParam(err),
+			log.SafeParam("orderId", req.OrderId),
+		)
+		return fmt.Errorf("cancel_order: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) CancelAllOrders(ctx context.Context, req CancelAllOrdersRequest) ([]string, error) {
+	productId, err := parseProductFilter(req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := h.Service.CancelAllOrders(ctx, productId)
+	if err != nil {
+		log.Error(ctx, "error_cancelling_all_orders",
+			log.ErrorParam(err),
+			log.SafeParam("productId", productId),
+		)
+		return nil, fmt.Errorf("cancel_all_orders: %w", err)
+	}
+
+	return ids, nil
+}
+
+func (h *Handler) ListOpenOrders(ctx context.Context, req ListOrdersRequest) ([]model.OpenOrder, error) {
+	productId, err := parseProductFilter(req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := h.Service.ListOpenOrders(ctx, productId)
+	if err != nil {
+		log.Error(ctx, "error_listing_open_orders",
+			log.ErrorParam(err),
+			log.SafeParam("productId", productId),
+		)
+		return nil, fmt.Errorf("list_open_orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// parseProductFilter validates an optional product ID, an empty value applies to all products.
+func parseProductFilter(productId string) (string, error) {
+	productId = strings.ToUpper(strings.TrimSpace(productId))
+	if productId == "" {
+		return "", nil
+	}
+
+	if base, quote := splitProduct(productId); base == "" || quote == "" {
+		return "", BadRequestError{Parameter: "productId", Err: "invalid value - should be in the format BASE-QUOTE"}
+	}
+
+	return productId, nil
+}
@@ -3,11 +3,17 @@ package aws_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/cshep4/kripto/services/trader/internal/handler/aws"
+	"github.com/cshep4/kripto/services/trader/internal/mocks/relay"
 	"github.com/cshep4/kripto/services/trader/internal/mocks/service"
+	"github.com/cshep4/kripto/services/trader/internal/model"
+	"github.com/cshep4/kripto/services/trader/internal/risk"
+	trade "github.com/cshep4/kripto/services/trader/internal/trader"
 	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,7 +28,7 @@ func TestHandler_Trade(t *testing.T) {
 			handler = aws.Handler{}
 		)
 
-		err := handler.Trade(ctx, aws.TradeRequest{})
+		_, err := handler.Trade(ctx, aws.TradeRequest{})
 		require.Error(t, err)
 
 		brErr, ok := err.(aws.BadRequestError)
@@ -40,7 +46,7 @@ func TestHandler_Trade(t *testing.T) {
 			handler = aws.Handler{}
 		)
 
-		err := handler.Trade(ctx, aws.TradeRequest{TradeType: "invalid"})
+		_, err := handler.Trade(ctx, aws.TradeRequest{TradeType: "invalid"})
 		require.Error(t, err)
 
 		brErr, ok := err.(aws.BadRequestError)
@@ -58,7 +64,7 @@ func TestHandler_Trade(t *testing.T) {
 			handler = aws.Handler{}
 		)
 
-		err := handler.Trade(ctx, aws.TradeRequest{TradeType: "buy"})
+		_, err := handler.Trade(ctx, aws.TradeRequest{TradeType: "buy"})
 		require.Error(t, err)
 
 		brErr, ok := err.(aws.BadRequestError)
@@ -80,7 +86,7 @@ func TestHandler_Trade(t *testing.T) {
 		service := service_mocks.NewMockServicer(ctrl)
 		handler := aws.Handler{Service: service}
 
-		err := handler.Trade(ctx, aws.TradeRequest{
+		_, err := handler.Trade(ctx, aws.TradeRequest{
 			TradeType: tradeType,
 			Amount:    amount,
 		})
@@ -103,15 +109,14 @@ func TestHandler_Trade(t *testing.T) {
 		const (
 			tradeType = "buy"
 			reqAmount = "10"
-			amount    = "10.00"
 		)
 
 		service := service_mocks.NewMockServicer(ctrl)
 		handler := aws.Handler{Service: service}
 
-		service.EXPECT().Trade(ctx, tradeType, amount).Return(testErr)
+		service.EXPECT().Trade(ctx, model.Order{ProductId: "BTC-GBP", Side: tradeType, Type: "market", Funds: reqAmount}).Return(nil, testErr)
 
-		err := handler.Trade(ctx, aws.TradeRequest{
+		_, err := handler.Trade(ctx, aws.TradeRequest{
 			TradeType: tradeType,
 			Amount:    reqAmount,
 		})
@@ -128,18 +133,508 @@ func TestHandler_Trade(t *testing.T) {
 		const (
 			tradeType = "buy"
 			reqAmount = "10"
-			amount    = "10.00"
 		)
 
 		service := service_mocks.NewMockServicer(ctrl)
 		handler := aws.Handler{Service: service}
 
-		service.EXPECT().Trade(ctx, tradeType, amount).Return(nil)
+		service.EXPECT().Trade(ctx, model.Order{ProductId: "BTC-GBP", Side: tradeType, Type: "market", Funds: reqAmount, IdempotencyKey: "key"}).Return(&trade.TradeResponse{Id: "tradeId"}, nil)
 
-		err := handler.Trade(ctx, aws.TradeRequest{
-			TradeType: tradeType,
-			Amount:    reqAmount,
+		res, err := handler.Trade(ctx, aws.TradeRequest{
+			IdempotencyKey: "key",
+			TradeType:      tradeType,
+			Amount:         reqAmount,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "tradeId", res.Id)
+	})
+	t.Run("returns error if productId is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			ctx     = context.Background()
+			handler = aws.Handler{}
+		)
+
+		_, err := handler.Trade(ctx, aws.TradeRequest{TradeType: "buy", ProductId: "BTCGBP", Amount: "10"})
+		require.Error(t, err)
+
+		brErr, ok := err.(aws.BadRequestError)
+		assert.True(t, ok)
+		assert.Equal(t, "productId", brErr.Parameter)
+		assert.Equal(t, "invalid value - should be in the format BASE-QUOTE", brErr.Err)
+	})
+
+	t.Run("returns error if orderType is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			ctx     = context.Background()
+			handler = aws.Handler{}
+		)
+
+		_, err := handler.Trade(ctx, aws.TradeRequest{TradeType: "buy", OrderType: "invalid"})
+		require.Error(t, err)
+
+		brErr, ok := err.(aws.BadRequestError)
+		assert.True(t, ok)
+		assert.Equal(t, "orderType", brErr.Parameter)
+		assert.Equal(t, "invalid value - should be either market/limit/stop", brErr.Err)
+	})
+
+	t.Run("returns error if limit order price is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			ctx     = context.Background()
+			handler = aws.Handler{}
+		)
+
+		_, err := handler.Trade(ctx, aws.TradeRequest{TradeType: "buy", OrderType: "limit", Size: "0.1"})
+		require.Error(t, err)
+
+		brErr, ok := err.(aws.BadRequestError)
+		assert.True(t, ok)
+		assert.Equal(t, "price", brErr.Parameter)
+		assert.Equal(t, "empty", brErr.Err)
+	})
+
+	t.Run("returns error if limit order size is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			ctx     = context.Background()
+			handler = aws.Handler{}
+		)
+
+		_, err := handler.Trade(ctx, aws.TradeRequest{TradeType: "buy", OrderType: "limit", Price: "8000"})
+		require.Error(t, err)
+
+		brErr, ok := err.(aws.BadRequestError)
+		assert.True(t, ok)
+		assert.Equal(t, "size", brErr.Parameter)
+		assert.Equal(t, "empty", brErr.Err)
+	})
+
+	t.Run("returns error if timeInForce is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			ctx     = context.Background()
+			handler = aws.Handler{}
+		)
+
+		_, err := handler.Trade(ctx, aws.TradeRequest{
+			TradeType:   "buy",
+			OrderType:   "limit",
+			Price:       "8000",
+			Size:        "0.1",
+			TimeInForce: "invalid",
 		})
+		require.Error(t, err)
+
+		brErr, ok := err.(aws.BadRequestError)
+		assert.True(t, ok)
+		assert.Equal(t, "timeInForce", brErr.Parameter)
+		assert.Equal(t, "invalid value - should be either GTC/IOC/FOK", brErr.Err)
+	})
+
+	t.Run("returns error if postOnly is used with immediate time in force", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			ctx     = context.Background()
+			handler = aws.Handler{}
+		)
+
+		_, err := handler.Trade(ctx, aws.TradeRequest{
+			TradeType:   "buy",
+			OrderType:   "limit",
+			Price:       "8000",
+			Size:        "0.1",
+			TimeInForce: "IOC",
+			PostOnly:    true,
+		})
+		require.Error(t, err)
+
+		brErr, ok := err.(aws.BadRequestError)
+		assert.True(t, ok)
+		assert.Equal(t, "postOnly", brErr.Parameter)
+	})
+
+	t.Run("returns error if postOnly is set on market order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			ctx     = context.Background()
+			handler = aws.Handler{}
+		)
+
+		_, err := handler.Trade(ctx, aws.TradeRequest{
+			TradeType: "buy",
+			Amount:    "10",
+			PostOnly:  true,
+		})
+		require.Error(t, err)
+
+		brErr, ok := err.(aws.BadRequestError)
+		assert.True(t, ok)
+		assert.Equal(t, "postOnly", brErr.Parameter)
+		assert.Equal(t, "only valid for limit orders", brErr.Err)
+	})
+
+	t.Run("returns error if timeInForce is set on market order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			ctx     = context.Background()
+			handler = aws.Handler{}
+		)
+
+		_, err := handler.Trade(ctx, aws.TradeRequest{
+			TradeType:   "buy",
+			Amount:      "10",
+			TimeInForce: "GTC",
+		})
+		require.Error(t, err)
+
+		brErr, ok := err.(aws.BadRequestError)
+		assert.True(t, ok)
+		assert.Equal(t, "timeInForce", brErr.Parameter)
+		assert.Equal(t, "only valid for limit orders", brErr.Err)
+	})
+
+	t.Run("returns nil if successfully placed limit order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		service := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: service}
+
+		service.EXPECT().Trade(ctx, model.Order{
+			ProductId:   "ETH-GBP",
+			Side:        "sell",
+			Type:        "limit",
+			Size:        "0.1",
+			Price:       "8000.5",
+			TimeInForce: "GTC",
+			PostOnly:    true,
+		}).Return(&trade.TradeResponse{}, nil)
+
+		_, err := handler.Trade(ctx, aws.TradeRequest{
+			ProductId:   "eth-gbp",
+			TradeType:   "sell",
+			OrderType:   "limit",
+			Price:       "8000.5",
+			Size:        "0.1",
+			TimeInForce: "GTC",
+			PostOnly:    true,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("returns simulated trade if dry run", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		service := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: service}
+
+		service.EXPECT().Trade(ctx, model.Order{
+			ProductId: "BTC-GBP",
+			Side:      "buy",
+			Type:      "market",
+			Funds:     "10",
+			DryRun:    true,
+		}).Return(&trade.TradeResponse{Id: "dry-run-tradeId", DryRun: true}, nil)
+
+		res, err := handler.Trade(ctx, aws.TradeRequest{
+			TradeType: "buy",
+			Amount:    "10",
+			DryRun:    true,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "dry-run-tradeId", res.Id)
+		assert.True(t, res.DryRun)
+	})
+
+	t.Run("returns rejected error if trade fails risk check", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		service := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: service}
+
+		rejectErr := risk.RejectedError{Check: risk.MaxOrderSize, Reason: "order value of 2000.00 GBP exceeds limit of 1000"}
+		service.EXPECT().Trade(ctx, gomock.Any()).Return(nil, fmt.Errorf("risk_check: %w", rejectErr))
+
+		_, err := handler.Trade(ctx, aws.TradeRequest{
+			TradeType: "buy",
+			Amount:    "2000",
+		})
+		require.Error(t, err)
+
+		rErr, ok := err.(aws.RejectedError)
+		require.True(t, ok)
+		assert.Equal(t, risk.MaxOrderSize, rErr.Check)
+		assert.Equal(t, "order value of 2000.00 GBP exceeds limit of 1000", rErr.Reason)
+	})
+}
+
+func TestHandler_CancelOrder(t *testing.T) {
+	t.Run("returns error if orderId is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := aws.Handler{}
+
+		err := handler.CancelOrder(context.Background(), aws.CancelOrderRequest{})
+		require.Error(t, err)
+
+		brErr, ok := err.(aws.BadRequestError)
+		assert.True(t, ok)
+		assert.Equal(t, "orderId", brErr.Parameter)
+		assert.Equal(t, "empty", brErr.Err)
+	})
+
+	t.Run("returns error if error cancelling order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			ctx     = context.Background()
+			testErr = errors.New("error")
+		)
+
+		service := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: service}
+
+		service.EXPECT().CancelOrder(ctx, "id").Return(testErr)
+
+		err := handler.CancelOrder(ctx, aws.CancelOrderRequest{OrderId: "id"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("returns nil if order cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		service := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: service}
+
+		service.EXPECT().CancelOrder(ctx, "id").Return(nil)
+
+		err := handler.CancelOrder(ctx, aws.CancelOrderRequest{OrderId: "id"})
+		require.NoError(t, err)
+	})
+}
+
+func TestHandler_CancelAllOrders(t *testing.T) {
+	t.Run("returns error if productId is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := aws.Handler{}
+
+		ids, err := handler.CancelAllOrders(context.Background(), aws.CancelAllOrdersRequest{ProductId: "BTC"})
+		require.Error(t, err)
+
+		assert.Empty(t, ids)
+
+		brErr, ok := err.(aws.BadRequestError)
+		assert.True(t, ok)
+		assert.Equal(t, "productId", brErr.Parameter)
+	})
+
+	t.Run("returns error if error cancelling orders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			ctx     = context.Background()
+			testErr = errors.New("error")
+		)
+
+		service := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: service}
+
+		service.EXPECT().CancelAllOrders(ctx, "").Return(nil, testErr)
+
+		ids, err := handler.CancelAllOrders(ctx, aws.CancelAllOrdersRequest{})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Empty(t, ids)
+	})
+
+	t.Run("returns cancelled order ids", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		service := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: service}
+
+		service.EXPECT().CancelAllOrders(ctx, "BTC-GBP").Return([]string{"id"}, nil)
+
+		ids, err := handler.CancelAllOrders(ctx, aws.CancelAllOrdersRequest{ProductId: "btc-gbp"})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"id"}, ids)
+	})
+}
+
+func TestHandler_ListOpenOrders(t *testing.T) {
+	t.Run("returns error if productId is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := aws.Handler{}
+
+		orders, err := handler.ListOpenOrders(context.Background(), aws.ListOrdersRequest{ProductId: "BTC"})
+		require.Error(t, err)
+
+		assert.Empty(t, orders)
+
+		brErr, ok := err.(aws.BadRequestError)
+		assert.True(t, ok)
+		assert.Equal(t, "productId", brErr.Parameter)
+	})
+
+	t.Run("returns error if error listing orders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			ctx     = context.Background()
+			testErr = errors.New("error")
+		)
+
+		service := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: service}
+
+		service.EXPECT().ListOpenOrders(ctx, "").Return(nil, testErr)
+
+		orders, err := handler.ListOpenOrders(ctx, aws.ListOrdersRequest{})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Empty(t, orders)
+	})
+
+	t.Run("returns open orders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		orders := []model.OpenOrder{{ID: "id", Status: "open"}}
+
+		service := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: service}
+
+		service.EXPECT().ListOpenOrders(ctx, "BTC-GBP").Return(orders, nil)
+
+		res, err := handler.ListOpenOrders(ctx, aws.ListOrdersRequest{ProductId: "BTC-GBP"})
+		require.NoError(t, err)
+
+		assert.Equal(t, orders, res)
+	})
+}
+
+func TestHandler_GetPortfolio(t *testing.T) {
+	t.Run("returns error if error getting portfolio", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			ctx     = context.Background()
+			testErr = errors.New("error")
+		)
+
+		service := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: service}
+
+		service.EXPECT().GetPortfolio(ctx, "GBP").Return(model.Portfolio{}, testErr)
+
+		portfolio, err := handler.GetPortfolio(ctx, aws.PortfolioRequest{})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Nil(t, portfolio)
+	})
+
+	t.Run("returns portfolio in requested currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		portfolio := model.Portfolio{Currency: "EUR", Value: decimal.RequireFromString("100")}
+
+		service := service_mocks.NewMockServicer(ctrl)
+		handler := aws.Handler{Service: service}
+
+		service.EXPECT().GetPortfolio(ctx, "EUR").Return(portfolio, nil)
+
+		res, err := handler.GetPortfolio(ctx, aws.PortfolioRequest{Currency: " eur"})
+		require.NoError(t, err)
+
+		assert.Equal(t, &portfolio, res)
+	})
+}
+
+func TestHandler_RelayTrades(t *testing.T) {
+	t.Run("returns error if error relaying trades", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		relayer := relay_mocks.NewMockRelayer(ctrl)
+		handler := aws.Handler{Relayer: relayer}
+
+		testErr := errors.New("error")
+
+		relayer.EXPECT().Relay(ctx).Return(1, testErr)
+
+		err := handler.RelayTrades(ctx)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("returns nil if trades relayed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		relayer := relay_mocks.NewMockRelayer(ctrl)
+		handler := aws.Handler{Relayer: relayer}
+
+		relayer.EXPECT().Relay(ctx).Return(2, nil)
+
+		err := handler.RelayTrades(ctx)
 		require.NoError(t, err)
 	})
 }
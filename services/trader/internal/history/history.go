@@ -0,0 +1,139 @@
+// Package history reads previous trades from the data-storer trade-reader function.
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/cshep4/kripto/services/trader/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	buy  = "buy"
+	sell = "sell"
+)
+
+type (
+	Invoker interface {
+		InvokeWithContext(ctx context.Context, input *lambda.InvokeInput, opts ...request.Option) (*lambda.InvokeOutput, error)
+	}
+
+	history struct {
+		invoker      Invoker
+		functionName string
+	}
+
+	getTradesRequest struct {
+		ProductId string `json:"productId"`
+	}
+
+	// trade is a trade as returned by data-storer.
+	trade struct {
+		TradeType string          `json:"tradeType"`
+		ProductId string          `json:"productId"`
+		CreatedAt time.Time       `json:"createdAt"`
+		Fees      decimal.Decimal `json:"fillFees"`
+		Value     value           `json:"value"`
+		Net       value           `json:"net"`
+	}
+
+	// value is an amount of each currency of the product.
+	value struct {
+		Base  decimal.Decimal `json:"base"`
+		Quote decimal.Decimal `json:"quote"`
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+
+	// FunctionError is returned when the trade-reader function returns an error.
+	FunctionError struct {
+		Type    string
+		Payload string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func (f FunctionError) Error() string {
+	return fmt.Sprintf("function error - type: %s, payload: %s", f.Type, f.Payload)
+}
+
+func New(invoker Invoker, functionName string) (*history, error) {
+	switch {
+	case invoker == nil:
+		return nil, InvalidParameterError{Parameter: "invoker"}
+	case functionName == "":
+		return nil, InvalidParameterError{Parameter: "functionName"}
+	}
+
+	return &history{
+		invoker:      invoker,
+		functionName: functionName,
+	}, nil
+}
+
+// GetTrades returns all previous trades of the product.
+func (h *history) GetTrades(ctx context.Context, productId string) ([]model.Trade, error) {
+	payload, err := json.Marshal(getTradesRequest{ProductId: productId})
+	if err != nil {
+		return nil, fmt.Errorf("json_marshal: %w", err)
+	}
+
+	out, err := h.invoker.InvokeWithContext(ctx, &lambda.InvokeInput{
+		FunctionName: aws.String(h.functionName),
+		Payload:      payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invoke: %w", err)
+	}
+	if out.FunctionError != nil {
+		return nil, FunctionError{Type: aws.StringValue(out.FunctionError), Payload: string(out.Payload)}
+	}
+
+	var res []trade
+	if err := json.Unmarshal(out.Payload, &res); err != nil {
+		return nil, fmt.Errorf("json_unmarshal: %w", err)
+	}
+
+	trades := make([]model.Trade, len(res))
+	for i, t := range res {
+		trades[i] = t.toTrade()
+	}
+
+	return trades, nil
+}
+
+// toTrade works out the size and cost of the trade. Trades stored before the
+// net amounts were recorded are worked out from the executed value and fees.
+func (t trade) toTrade() model.Trade {
+	res := model.Trade{
+		Side:      t.TradeType,
+		ProductId: t.ProductId,
+		CreatedAt: t.CreatedAt,
+		Size:      t.Net.Base.Abs(),
+		Cost:      t.Net.Quote.Abs(),
+	}
+
+	if res.Size.IsZero() {
+		res.Size = t.Value.Base
+		switch t.TradeType {
+		case buy:
+			res.Cost = t.Value.Quote.Add(t.Fees)
+		case sell:
+			res.Cost = t.Value.Quote.Sub(t.Fees)
+		}
+	}
+
+	return res
+}
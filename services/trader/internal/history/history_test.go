@@ -0,0 +1,143 @@
+package history_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/cshep4/kripto/services/trader/internal/history"
+	"github.com/cshep4/kripto/services/trader/internal/mocks/history"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const functionName = "trade-reader"
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if invoker is empty", func(t *testing.T) {
+		h, err := history.New(nil, functionName)
+		require.Error(t, err)
+
+		assert.Nil(t, h)
+
+		ipErr, ok := err.(history.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "invoker", ipErr.Parameter)
+	})
+
+	t.Run("returns error if functionName is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		h, err := history.New(history_mocks.NewMockInvoker(ctrl), "")
+		require.Error(t, err)
+
+		assert.Nil(t, h)
+
+		ipErr, ok := err.(history.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "functionName", ipErr.Parameter)
+	})
+
+	t.Run("returns history", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		h, err := history.New(history_mocks.NewMockInvoker(ctrl), functionName)
+		require.NoError(t, err)
+
+		assert.NotNil(t, h)
+	})
+}
+
+func TestHistory_GetTrades(t *testing.T) {
+	t.Run("returns error if error invoking function", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		testErr := errors.New("error")
+
+		invoker := history_mocks.NewMockInvoker(ctrl)
+
+		h, err := history.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, &lambda.InvokeInput{
+			FunctionName: aws.String(functionName),
+			Payload:      []byte(`{"productId":"BTC-GBP"}`),
+		}).Return(nil, testErr)
+
+		trades, err := h.GetTrades(ctx, "BTC-GBP")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Nil(t, trades)
+	})
+
+	t.Run("returns error if function returns error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := history_mocks.NewMockInvoker(ctrl)
+
+		h, err := history.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, gomock.Any()).Return(&lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			Payload:       []byte(`{"errorMessage":"error"}`),
+		}, nil)
+
+		trades, err := h.GetTrades(ctx, "BTC-GBP")
+		require.Error(t, err)
+
+		fErr, ok := err.(history.FunctionError)
+		assert.True(t, ok)
+		assert.Equal(t, "Unhandled", fErr.Type)
+		assert.Nil(t, trades)
+	})
+
+	t.Run("returns trades, using executed value for trades without net amounts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		invoker := history_mocks.NewMockInvoker(ctrl)
+
+		h, err := history.New(invoker, functionName)
+		require.NoError(t, err)
+
+		invoker.EXPECT().InvokeWithContext(ctx, gomock.Any()).Return(&lambda.InvokeOutput{
+			Payload: []byte(`[
+				{"id":"1","tradeType":"buy","productId":"BTC-GBP","createdAt":"2021-05-01T12:00:00Z","fillFees":"0.05","value":{"quote":"9.95","base":"0.001"},"net":{"quote":"0","base":"0"}},
+				{"id":"2","tradeType":"sell","productId":"BTC-GBP","createdAt":"2021-05-02T12:00:00Z","fillFees":"0.05","value":{"quote":"10","base":"0.001"}},
+				{"id":"3","tradeType":"buy","productId":"BTC-GBP","createdAt":"2021-05-03T12:00:00Z","fillFees":"0.1","value":{"quote":"20","base":"0.002"},"net":{"quote":"-20.1","base":"0.002"}}
+			]`),
+		}, nil)
+
+		trades, err := h.GetTrades(ctx, "BTC-GBP")
+		require.NoError(t, err)
+
+		require.Len(t, trades, 3)
+
+		assert.Equal(t, "buy", trades[0].Side)
+		assert.Equal(t, time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC), trades[0].CreatedAt)
+		assert.Equal(t, "0.001", trades[0].Size.String())
+		assert.Equal(t, "10", trades[0].Cost.String())
+
+		assert.Equal(t, "sell", trades[1].Side)
+		assert.Equal(t, "0.001", trades[1].Size.String())
+		assert.Equal(t, "9.95", trades[1].Cost.String())
+
+		assert.Equal(t, "0.002", trades[2].Size.String())
+		assert.Equal(t, "20.1", trades[2].Cost.String())
+	})
+}
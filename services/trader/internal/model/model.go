@@ -1,15 +1,98 @@
 package model
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
 type (
-	Wallet struct {
-		GBP Account `json:"gbp"`
-		BTC Account `json:"btc"`
-	}
+	// Wallet holds the account for each currency, keyed by lower case currency code, e.g. "gbp".
+	Wallet map[string]Account
 
+	// Account is the balance of a currency. The amounts are written as JSON
+	// numbers, as clients read them before they were exact.
 	Account struct {
-		ID        string  `json:"id"`
-		Balance   float32 `json:"balance"`
-		Hold      float32 `json:"hold"`
-		Available float32 `json:"available"`
+		ID        string          `json:"id"`
+		Balance   decimal.Decimal `json:"balance"`
+		Hold      decimal.Decimal `json:"hold"`
+		Available decimal.Decimal `json:"available"`
+	}
+
+	// Order is an order requested by a client. IdempotencyKey identifies the
+	// request, so retrying the request doesn't place a second order. Dry-run
+	// orders are only simulated and are never placed on the exchange.
+	Order struct {
+		ProductId      string
+		Side           string
+		Type           string
+		Funds          string
+		Size           string
+		Price          string
+		TimeInForce    string
+		PostOnly       bool
+		IdempotencyKey string
+		DryRun         bool
+	}
+
+	// Portfolio is the value of each asset in the wallet in the valuation currency.
+	Portfolio struct {
+		Currency      string          `json:"currency"`
+		Value         decimal.Decimal `json:"value"`
+		CostBasis     decimal.Decimal `json:"costBasis"`
+		UnrealisedPnL decimal.Decimal `json:"unrealisedPnl"`
+		Assets        []Asset         `json:"assets"`
+	}
+
+	// Asset is the valuation of a single currency. Assets which can't be traded
+	// for the valuation currency have a zero price and value.
+	Asset struct {
+		Currency      string          `json:"currency"`
+		Balance       decimal.Decimal `json:"balance"`
+		Price         decimal.Decimal `json:"price"`
+		Value         decimal.Decimal `json:"value"`
+		Allocation    decimal.Decimal `json:"allocation"` // Percentage of the portfolio value.
+		CostBasis     decimal.Decimal `json:"costBasis"`
+		UnrealisedPnL decimal.Decimal `json:"unrealisedPnl"`
+	}
+
+	// Trade is a previous trade used to work out the cost basis of an asset.
+	Trade struct {
+		Side      string
+		ProductId string
+		CreatedAt time.Time
+		Size      decimal.Decimal // Base currency bought or sold.
+		Cost      decimal.Decimal // Quote currency spent including fees, or received less fees.
+	}
+
+	OpenOrder struct {
+		ID          string    `json:"id"`
+		ProductId   string    `json:"productId"`
+		Side        string    `json:"side"`
+		Type        string    `json:"type"`
+		Status      string    `json:"status"`
+		Funds       string    `json:"funds,omitempty"`
+		Size        string    `json:"size,omitempty"`
+		Price       string    `json:"price,omitempty"`
+		StopPrice   string    `json:"stopPrice,omitempty"`
+		TimeInForce string    `json:"timeInForce,omitempty"`
+		PostOnly    bool      `json:"postOnly,omitempty"`
+		FilledSize  string    `json:"filledSize,omitempty"`
+		CreatedAt   time.Time `json:"createdAt"`
 	}
 )
+
+func (a Account) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ID        string      `json:"id"`
+		Balance   json.Number `json:"balance"`
+		Hold      json.Number `json:"hold"`
+		Available json.Number `json:"available"`
+	}{
+		ID:        a.ID,
+		Balance:   json.Number(a.Balance.String()),
+		Hold:      json.Number(a.Hold.String()),
+		Available: json.Number(a.Available.String()),
+	})
+}
@@ -0,0 +1,27 @@
+package model_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cshep4/kripto/services/trader/internal/model"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccount_MarshalJSON(t *testing.T) {
+	t.Run("returns amounts as numbers", func(t *testing.T) {
+		b, err := json.Marshal(model.Wallet{
+			"gbp": {
+				ID:        "🆔",
+				Balance:   decimal.RequireFromString("100.25"),
+				Hold:      decimal.RequireFromString("0.25"),
+				Available: decimal.RequireFromString("100"),
+			},
+		})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"gbp":{"id":"🆔","balance":100.25,"hold":0.25,"available":100}}`, string(b))
+	})
+}
@@ -0,0 +1,214 @@
+// Package outbox stores executed trades until they have been published, so a
+// trade isn't lost if publishing it fails after the order has been placed.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cshep4/kripto/shared/go/publisher"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+const (
+	collection = "outbox"
+	fourDays   = int32(345600)
+)
+
+type (
+	// Entry is a trade event waiting to be published.
+	Entry struct {
+		Id          string          `bson:"_id"`
+		Event       publisher.Event `bson:"event"`
+		Published   bool            `bson:"published"`
+		Attempts    int             `bson:"attempts"`
+		CreatedAt   time.Time       `bson:"createdAt"`
+		PublishedAt *time.Time      `bson:"publishedAt,omitempty"`
+	}
+
+	mongoOutbox struct {
+		client     *mongo.Client
+		collection *mongo.Collection
+		now        func() time.Time
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func New(ctx context.Context, database string, client *mongo.Client) (*mongoOutbox, error) {
+	switch {
+	case client == nil:
+		return nil, InvalidParameterError{Parameter: "client"}
+	case database == "":
+		return nil, InvalidParameterError{Parameter: "database"}
+	}
+
+	o := &mongoOutbox{
+		client:     client,
+		collection: client.Database(database).Collection(collection),
+		now:        time.Now,
+	}
+
+	if err := o.ping(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := o.ensureIndexes(ctx); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// ensureIndexes indexes unpublished entries by age, and removes entries four
+// days after they have been published.
+func (m *mongoOutbox) ensureIndexes(ctx context.Context) error {
+	_, err := m.collection.
+		Indexes().
+		CreateMany(
+			ctx,
+			[]mongo.IndexModel{
+				{
+					Keys: bsonx.Doc{
+						{Key: "published", Value: bsonx.Int64(1)},
+						{Key: "createdAt", Value: bsonx.Int64(1)},
+					},
+					Options: options.Index().
+						SetName("publishedCreatedAtIdx").
+						SetBackground(true),
+				},
+				{
+					Keys: bsonx.Doc{
+						{Key: "publishedAt", Value: bsonx.Int64(1)},
+					},
+					Options: options.Index().
+						SetName("publishedAtIdx").
+						SetBackground(true).
+						SetExpireAfterSeconds(fourDays),
+				},
+			},
+		)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Add stores the event, keyed by its id. Adding an event which is already in
+// the outbox leaves the existing entry as it is.
+func (m *mongoOutbox) Add(ctx context.Context, event publisher.Event) error {
+	_, err := m.collection.
+		UpdateOne(
+			ctx,
+			bson.D{{Key: "_id", Value: event.Id}},
+			bson.D{{
+				Key: "$setOnInsert",
+				Value: bson.D{
+					{Key: "event", Value: event},
+					{Key: "published", Value: false},
+					{Key: "attempts", Value: 0},
+					{Key: "createdAt", Value: m.now()},
+				},
+			}},
+			options.Update().SetUpsert(true),
+		)
+	if err != nil {
+		return fmt.Errorf("update_one: %w", err)
+	}
+
+	return nil
+}
+
+func (m *mongoOutbox) MarkPublished(ctx context.Context, id string) error {
+	res, err := m.collection.
+		UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, bson.D{
+			{
+				Key: "$set",
+				Value: bson.D{
+					{Key: "published", Value: true},
+					{Key: "publishedAt", Value: m.now()},
+				},
+			},
+			{
+				Key:   "$inc",
+				Value: bson.D{{Key: "attempts", Value: 1}},
+			},
+		})
+	if err != nil {
+		return fmt.Errorf("update_one: %w", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return errors.New("item not found")
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed attempt at publishing the trade.
+func (m *mongoOutbox) MarkFailed(ctx context.Context, id string) error {
+	res, err := m.collection.
+		UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, bson.D{{
+			Key:   "$inc",
+			Value: bson.D{{Key: "attempts", Value: 1}},
+		}})
+	if err != nil {
+		return fmt.Errorf("update_one: %w", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return errors.New("item not found")
+	}
+
+	return nil
+}
+
+// ListUnpublished returns up to limit unpublished entries added before the
+// time, oldest first.
+func (m *mongoOutbox) ListUnpublished(ctx context.Context, before time.Time, limit int) ([]Entry, error) {
+	cur, err := m.collection.
+		Find(
+			ctx,
+			bson.D{
+				{Key: "published", Value: false},
+				{Key: "createdAt", Value: bson.D{{Key: "$lt", Value: before}}},
+			},
+			options.Find().
+				SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}).
+				SetLimit(int64(limit)),
+		)
+	if err != nil {
+		return nil, fmt.Errorf("find: %w", err)
+	}
+
+	var entries []Entry
+	if err := cur.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (m *mongoOutbox) ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	return m.client.Ping(ctx, nil)
+}
+
+func (m *mongoOutbox) Close(ctx context.Context) error {
+	return m.client.Disconnect(ctx)
+}
@@ -0,0 +1,133 @@
+//+build integration
+
+package outbox_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/outbox"
+	"github.com/cshep4/kripto/shared/go/publisher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestMongoOutbox_Add(t *testing.T) {
+	t.Run("keeps existing entry if trade is added again", func(t *testing.T) {
+		ctx := context.Background()
+		o := newOutbox(t, ctx)
+
+		err := o.Add(ctx, event("id", "message"))
+		require.NoError(t, err)
+
+		err = o.Add(ctx, event("id", "other message"))
+		require.NoError(t, err)
+
+		entries, err := o.ListUnpublished(ctx, time.Now().Add(time.Minute), 10)
+		require.NoError(t, err)
+
+		require.Len(t, entries, 1)
+		assert.Equal(t, "id", entries[0].Id)
+		assert.Equal(t, "id", entries[0].Event.Id)
+		assert.JSONEq(t, `"message"`, string(entries[0].Event.Data))
+	})
+}
+
+func TestMongoOutbox_MarkPublished(t *testing.T) {
+	t.Run("returns error if trade is not in outbox", func(t *testing.T) {
+		ctx := context.Background()
+		o := newOutbox(t, ctx)
+
+		err := o.MarkPublished(ctx, "id")
+		require.Error(t, err)
+	})
+
+	t.Run("removes trade from unpublished trades", func(t *testing.T) {
+		ctx := context.Background()
+		o := newOutbox(t, ctx)
+
+		require.NoError(t, o.Add(ctx, event("id", "message")))
+		require.NoError(t, o.Add(ctx, event("id2", "message")))
+
+		err := o.MarkPublished(ctx, "id")
+		require.NoError(t, err)
+
+		entries, err := o.ListUnpublished(ctx, time.Now().Add(time.Minute), 10)
+		require.NoError(t, err)
+
+		require.Len(t, entries, 1)
+		assert.Equal(t, "id2", entries[0].Id)
+	})
+}
+
+func TestMongoOutbox_ListUnpublished(t *testing.T) {
+	t.Run("returns trades added before time, oldest first", func(t *testing.T) {
+		ctx := context.Background()
+		o := newOutbox(t, ctx)
+
+		require.NoError(t, o.Add(ctx, event("id", "message")))
+		require.NoError(t, o.Add(ctx, event("id2", "message")))
+		require.NoError(t, o.MarkFailed(ctx, "id"))
+
+		entries, err := o.ListUnpublished(ctx, time.Now().Add(-time.Minute), 10)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+
+		entries, err = o.ListUnpublished(ctx, time.Now().Add(time.Minute), 10)
+		require.NoError(t, err)
+
+		require.Len(t, entries, 2)
+		assert.Equal(t, "id", entries[0].Id)
+		assert.Equal(t, 1, entries[0].Attempts)
+		assert.Equal(t, "id2", entries[1].Id)
+	})
+}
+
+func newOutbox(t *testing.T, ctx context.Context) outboxUnderTest {
+	t.Helper()
+
+	client, err := mongo.NewClient(options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+
+	err = client.Connect(ctx)
+	require.NoError(t, err)
+
+	o, err := outbox.New(ctx, "database", client)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, err := client.
+			Database("database").
+			Collection("outbox").
+			DeleteMany(ctx, bson.M{})
+		require.NoError(t, err)
+
+		err = o.Close(ctx)
+		require.NoError(t, err)
+	})
+
+	return o
+}
+
+type outboxUnderTest interface {
+	Add(ctx context.Context, event publisher.Event) error
+	MarkPublished(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string) error
+	ListUnpublished(ctx context.Context, before time.Time, limit int) ([]outbox.Entry, error)
+	Close(ctx context.Context) error
+}
+
+func event(id, message string) publisher.Event {
+	return publisher.Event{
+		Id:        id,
+		Type:      "TradeExecuted",
+		Version:   1,
+		Source:    "trader",
+		Timestamp: time.Now().UTC(),
+		Data:      []byte(`"` + message + `"`),
+	}
+}
@@ -0,0 +1,36 @@
+package relay
+
+import "time"
+
+const (
+	defaultDelay     = time.Minute
+	defaultBatchSize = 100
+)
+
+type Option func(*relay)
+
+// WithDelay sets how old a trade must be before it is relayed, leaving the
+// trade function time to publish it.
+func WithDelay(delay time.Duration) Option {
+	return func(r *relay) {
+		if delay > 0 {
+			r.delay = delay
+		}
+	}
+}
+
+// WithBatchSize sets the maximum number of trades published each run.
+func WithBatchSize(size int) Option {
+	return func(r *relay) {
+		if size > 0 {
+			r.batchSize = size
+		}
+	}
+}
+
+// WithClock sets the function used to get the current time.
+func WithClock(now func() time.Time) Option {
+	return func(r *relay) {
+		r.now = now
+	}
+}
@@ -0,0 +1,110 @@
+// Package relay publishes trades left in the outbox, e.g. because publishing
+// failed after the order was placed.
+package relay
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/outbox"
+	"github.com/cshep4/kripto/shared/go/log"
+	"github.com/cshep4/kripto/shared/go/publisher"
+)
+
+type (
+	Outbox interface {
+		ListUnpublished(ctx context.Context, before time.Time, limit int) ([]outbox.Entry, error)
+		MarkPublished(ctx context.Context, id string) error
+		MarkFailed(ctx context.Context, id string) error
+	}
+	Publisher interface {
+		Publish(ctx context.Context, event publisher.Event) error
+	}
+
+	relay struct {
+		publisher Publisher
+		outbox    Outbox
+		delay     time.Duration
+		batchSize int
+		now       func() time.Time
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func New(publisher Publisher, outbox Outbox, opts ...Option) (*relay, error) {
+	switch {
+	case publisher == nil:
+		return nil, InvalidParameterError{Parameter: "publisher"}
+	case outbox == nil:
+		return nil, InvalidParameterError{Parameter: "outbox"}
+	}
+
+	r := &relay{
+		publisher: publisher,
+		outbox:    outbox,
+		delay:     defaultDelay,
+		batchSize: defaultBatchSize,
+		now:       time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r, nil
+}
+
+// Relay publishes the unpublished trades, returning the number published. Trades
+// added within the delay are skipped as the trade function may still be
+// publishing them. A trade which fails to publish is left for the next run.
+func (r *relay) Relay(ctx context.Context) (int, error) {
+	entries, err := r.outbox.ListUnpublished(ctx, r.now().Add(-r.delay), r.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("list_unpublished: %w", err)
+	}
+
+	var (
+		published int
+		pubErr    error
+	)
+	for _, e := range entries {
+		if err := r.publish(ctx, e); err != nil {
+			log.Error(ctx, "error_relaying_trade", log.ErrorParam(err), log.SafeParam("tradeId", e.Id), log.SafeParam("attempts", e.Attempts+1))
+			if pubErr == nil {
+				pubErr = err
+			}
+			continue
+		}
+		published++
+	}
+
+	if pubErr != nil {
+		return published, fmt.Errorf("relay (%d of %d failed): %w", len(entries)-published, len(entries), pubErr)
+	}
+
+	return published, nil
+}
+
+func (r *relay) publish(ctx context.Context, e outbox.Entry) error {
+	if err := r.publisher.Publish(ctx, e.Event); err != nil {
+		if mErr := r.outbox.MarkFailed(ctx, e.Id); mErr != nil {
+			log.Warn(ctx, "error_marking_trade_failed", log.ErrorParam(mErr), log.SafeParam("tradeId", e.Id))
+		}
+		return fmt.Errorf("publish: %w", err)
+	}
+
+	if err := r.outbox.MarkPublished(ctx, e.Id); err != nil {
+		return fmt.Errorf("mark_published: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package relay_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/mocks/relay"
+	"github.com/cshep4/kripto/services/trader/internal/outbox"
+	"github.com/cshep4/kripto/services/trader/internal/relay"
+	"github.com/cshep4/kripto/shared/go/publisher"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if publisher is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		r, err := relay.New(nil, relay_mocks.NewMockOutbox(ctrl))
+		require.Error(t, err)
+
+		assert.Nil(t, r)
+
+		ipErr, ok := err.(relay.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "publisher", ipErr.Parameter)
+	})
+
+	t.Run("returns error if outbox is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		r, err := relay.New(relay_mocks.NewMockPublisher(ctrl), nil)
+		require.Error(t, err)
+
+		assert.Nil(t, r)
+
+		ipErr, ok := err.(relay.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "outbox", ipErr.Parameter)
+	})
+
+	t.Run("returns relay", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		r, err := relay.New(relay_mocks.NewMockPublisher(ctrl), relay_mocks.NewMockOutbox(ctrl))
+		require.NoError(t, err)
+
+		assert.NotNil(t, r)
+	})
+}
+
+func TestRelay_Relay(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns error if error listing unpublished trades", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := relay_mocks.NewMockPublisher(ctrl)
+		ob := relay_mocks.NewMockOutbox(ctrl)
+
+		r, err := relay.New(publisher, ob, relay.WithClock(func() time.Time { return now }))
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+
+		ob.EXPECT().ListUnpublished(ctx, now.Add(-time.Minute), 100).Return(nil, testErr)
+
+		published, err := r.Relay(ctx)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Zero(t, published)
+	})
+
+	t.Run("publishes trades older than delay and marks them published", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := relay_mocks.NewMockPublisher(ctrl)
+		ob := relay_mocks.NewMockOutbox(ctrl)
+
+		r, err := relay.New(publisher, ob,
+			relay.WithClock(func() time.Time { return now }),
+			relay.WithDelay(5*time.Minute),
+			relay.WithBatchSize(10),
+		)
+		require.NoError(t, err)
+
+		gomock.InOrder(
+			ob.EXPECT().ListUnpublished(ctx, now.Add(-5*time.Minute), 10).Return([]outbox.Entry{
+				{Id: "1", Event: event("1")},
+				{Id: "2", Event: event("2")},
+			}, nil),
+			publisher.EXPECT().Publish(ctx, event("1")).Return(nil),
+			ob.EXPECT().MarkPublished(ctx, "1").Return(nil),
+			publisher.EXPECT().Publish(ctx, event("2")).Return(nil),
+			ob.EXPECT().MarkPublished(ctx, "2").Return(nil),
+		)
+
+		published, err := r.Relay(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, published)
+	})
+
+	t.Run("publishes remaining trades and returns error if a trade fails to publish", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := relay_mocks.NewMockPublisher(ctrl)
+		ob := relay_mocks.NewMockOutbox(ctrl)
+
+		r, err := relay.New(publisher, ob, relay.WithClock(func() time.Time { return now }))
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+
+		gomock.InOrder(
+			ob.EXPECT().ListUnpublished(ctx, now.Add(-time.Minute), 100).Return([]outbox.Entry{
+				{Id: "1", Event: event("1")},
+				{Id: "2", Event: event("2")},
+			}, nil),
+			publisher.EXPECT().Publish(ctx, event("1")).Return(testErr),
+			ob.EXPECT().MarkFailed(ctx, "1").Return(nil),
+			publisher.EXPECT().Publish(ctx, event("2")).Return(nil),
+			ob.EXPECT().MarkPublished(ctx, "2").Return(nil),
+		)
+
+		published, err := r.Relay(ctx)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Equal(t, 1, published)
+	})
+}
+
+func event(id string) publisher.Event {
+	return publisher.Event{
+		Id:        id,
+		Type:      "TradeExecuted",
+		Version:   1,
+		Source:    "trader",
+		Timestamp: now,
+		Data:      []byte(`{"id":"` + id + `"}`),
+	}
+}
@@ -0,0 +1,44 @@
+package risk
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+type Option func(*checker)
+
+// WithKillSwitch rejects every order while enabled.
+func WithKillSwitch(enabled bool) Option {
+	return func(c *checker) {
+		c.killSwitch = enabled
+	}
+}
+
+// WithMaxOrderSize limits the value of a single order in the quote currency and
+// its size in the base currency, a zero limit is not applied.
+func WithMaxOrderSize(maxFunds, maxSize decimal.Decimal) Option {
+	return func(c *checker) {
+		c.maxFunds = maxFunds
+		c.maxSize = maxSize
+	}
+}
+
+// WithMaxDailyNotional limits the value traded per product each day in the quote
+// currency, a zero limit is not applied.
+func WithMaxDailyNotional(max decimal.Decimal) Option {
+	return func(c *checker) {
+		c.maxDailyNotional = max
+	}
+}
+
+// WithMinBalance sets the minimum available balance of a currency that must remain
+// after an order which spends it.
+func WithMinBalance(currency string, min decimal.Decimal) Option {
+	return func(c *checker) {
+		if currency == "" {
+			return
+		}
+		c.minBalances[strings.ToUpper(currency)] = min
+	}
+}
@@ -0,0 +1,243 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/model"
+	"github.com/cshep4/kripto/services/trader/internal/trader"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	KillSwitch       = "kill_switch"
+	MaxOrderSize     = "max_order_size"
+	MaxDailyNotional = "max_daily_notional"
+	MinBalance       = "min_balance"
+)
+
+type (
+	Trader interface {
+		GetAccounts(ctx context.Context) ([]trader.Account, error)
+		GetPrice(ctx context.Context, productId string) (decimal.Decimal, error)
+		GetExecutedNotional(ctx context.Context, productId string, since time.Time) (decimal.Decimal, error)
+	}
+
+	checker struct {
+		trader           Trader
+		killSwitch       bool
+		maxFunds         decimal.Decimal
+		maxSize          decimal.Decimal
+		maxDailyNotional decimal.Decimal
+		minBalances      map[string]decimal.Decimal
+		now              func() time.Time
+	}
+
+	// estimate is the size of an order in both the base and quote currency of the product.
+	estimate struct {
+		base     string
+		quote    string
+		size     decimal.Decimal
+		notional decimal.Decimal
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+
+	// RejectedError is returned when an order fails a risk check, the same order will
+	// be rejected again if it is retried.
+	RejectedError struct {
+		Check  string
+		Reason string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func (r RejectedError) Error() string {
+	return fmt.Sprintf("order rejected - check: %s, reason: %s", r.Check, r.Reason)
+}
+
+func (RejectedError) ErrorCode() apperror.Code {
+	return apperror.Validation
+}
+
+func New(trader Trader, opts ...Option) (*checker, error) {
+	if trader == nil {
+		return nil, InvalidParameterError{Parameter: "trader"}
+	}
+
+	c := &checker{
+		trader:      trader,
+		minBalances: make(map[string]decimal.Decimal),
+		now:         time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// Check runs every configured risk check against the order, returning a RejectedError
+// for the first check that fails.
+func (c *checker) Check(ctx context.Context, order model.Order) error {
+	if c.killSwitch {
+		return RejectedError{Check: KillSwitch, Reason: "trading is disabled"}
+	}
+
+	if c.maxFunds.IsZero() && c.maxSize.IsZero() && c.maxDailyNotional.IsZero() && len(c.minBalances) == 0 {
+		return nil
+	}
+
+	e, err := c.estimate(ctx, order)
+	if err != nil {
+		return fmt.Errorf("estimate: %w", err)
+	}
+
+	if err := c.checkOrderSize(e); err != nil {
+		return err
+	}
+
+	if err := c.checkDailyNotional(ctx, order.ProductId, e); err != nil {
+		return err
+	}
+
+	if err := c.checkBalance(ctx, order.Side, e); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *checker) estimate(ctx context.Context, order model.Order) (estimate, error) {
+	parts := strings.Split(order.ProductId, "-")
+	if len(parts) != 2 {
+		return estimate{}, fmt.Errorf("invalid_product_id (%s)", order.ProductId)
+	}
+
+	e := estimate{
+		base:  parts[0],
+		quote: parts[1],
+	}
+
+	var (
+		price decimal.Decimal
+		err   error
+	)
+	if order.Price != "" {
+		price, err = decimal.NewFromString(order.Price)
+		if err != nil {
+			return estimate{}, fmt.Errorf("invalid_price (%s): %w", order.Price, err)
+		}
+	} else {
+		price, err = c.trader.GetPrice(ctx, order.ProductId)
+		if err != nil {
+			return estimate{}, fmt.Errorf("get_price: %w", err)
+		}
+	}
+	if !price.IsPositive() {
+		return estimate{}, fmt.Errorf("invalid_price (%s)", price)
+	}
+
+	if order.Funds != "" {
+		e.notional, err = decimal.NewFromString(order.Funds)
+		if err != nil {
+			return estimate{}, fmt.Errorf("invalid_funds (%s): %w", order.Funds, err)
+		}
+		e.size = e.notional.Div(price)
+
+		return e, nil
+	}
+
+	e.size, err = decimal.NewFromString(order.Size)
+	if err != nil {
+		return estimate{}, fmt.Errorf("invalid_size (%s): %w", order.Size, err)
+	}
+	e.notional = e.size.Mul(price)
+
+	return e, nil
+}
+
+func (c *checker) checkOrderSize(e estimate) error {
+	switch {
+	case !c.maxFunds.IsZero() && e.notional.GreaterThan(c.maxFunds):
+		return RejectedError{
+			Check:  MaxOrderSize,
+			Reason: fmt.Sprintf("order value of %s %s exceeds limit of %s", e.notional.StringFixed(2), e.quote, c.maxFunds),
+		}
+	case !c.maxSize.IsZero() && e.size.GreaterThan(c.maxSize):
+		return RejectedError{
+			Check:  MaxOrderSize,
+			Reason: fmt.Sprintf("order size of %s %s exceeds limit of %s", e.size, e.base, c.maxSize),
+		}
+	}
+
+	return nil
+}
+
+// checkDailyNotional limits the value traded per product since midnight UTC,
+// including the value of the order being checked.
+func (c *checker) checkDailyNotional(ctx context.Context, productId string, e estimate) error {
+	if c.maxDailyNotional.IsZero() {
+		return nil
+	}
+
+	executed, err := c.trader.GetExecutedNotional(ctx, productId, c.now().UTC().Truncate(24*time.Hour))
+	if err != nil {
+		return fmt.Errorf("get_executed_notional: %w", err)
+	}
+
+	if total := executed.Add(e.notional); total.GreaterThan(c.maxDailyNotional) {
+		return RejectedError{
+			Check:  MaxDailyNotional,
+			Reason: fmt.Sprintf("daily value of %s %s would exceed limit of %s", total.StringFixed(2), e.quote, c.maxDailyNotional),
+		}
+	}
+
+	return nil
+}
+
+// checkBalance ensures the available balance of the currency being spent stays
+// above its configured minimum once the order has been placed.
+func (c *checker) checkBalance(ctx context.Context, side string, e estimate) error {
+	currency, spend := e.quote, e.notional
+	if side == string(trader.Sell) {
+		currency, spend = e.base, e.size
+	}
+
+	min, ok := c.minBalances[strings.ToUpper(currency)]
+	if !ok {
+		return nil
+	}
+
+	accounts, err := c.trader.GetAccounts(ctx)
+	if err != nil {
+		return fmt.Errorf("get_accounts: %w", err)
+	}
+
+	available := decimal.Zero
+	for _, a := range accounts {
+		if strings.EqualFold(a.Currency, currency) {
+			available = a.Available
+			break
+		}
+	}
+
+	if remaining := available.Sub(spend); remaining.LessThan(min) {
+		return RejectedError{
+			Check:  MinBalance,
+			Reason: fmt.Sprintf("remaining %s balance of %s would be below minimum of %s", currency, remaining, min),
+		}
+	}
+
+	return nil
+}
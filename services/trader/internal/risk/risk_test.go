@@ -0,0 +1,262 @@
+package risk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cshep4/kripto/services/trader/internal/mocks/risk"
+	"github.com/cshep4/kripto/services/trader/internal/model"
+	"github.com/cshep4/kripto/services/trader/internal/risk"
+	"github.com/cshep4/kripto/services/trader/internal/trader"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if trader is empty", func(t *testing.T) {
+		c, err := risk.New(nil)
+		require.Error(t, err)
+
+		assert.Nil(t, c)
+
+		ipErr, ok := err.(risk.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "trader", ipErr.Parameter)
+	})
+
+	t.Run("returns checker", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c, err := risk.New(risk_mocks.NewMockTrader(ctrl))
+		require.NoError(t, err)
+
+		assert.NotNil(t, c)
+	})
+}
+
+func TestChecker_Check(t *testing.T) {
+	ctx := context.Background()
+	d := decimal.RequireFromString
+
+	t.Run("rejects every order if kill switch is enabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c, err := risk.New(risk_mocks.NewMockTrader(ctrl),
+			risk.WithKillSwitch(true),
+			risk.WithMaxOrderSize(d("1000"), decimal.Zero),
+		)
+		require.NoError(t, err)
+
+		err = c.Check(ctx, model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "10"})
+		require.Error(t, err)
+
+		rErr, ok := err.(risk.RejectedError)
+		require.True(t, ok)
+		assert.Equal(t, risk.KillSwitch, rErr.Check)
+	})
+
+	t.Run("returns nil without calling exchange if no limits are set", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c, err := risk.New(risk_mocks.NewMockTrader(ctrl), risk.WithKillSwitch(false))
+		require.NoError(t, err)
+
+		err = c.Check(ctx, model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "10"})
+		require.NoError(t, err)
+	})
+
+	t.Run("returns error if error getting price", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tr := risk_mocks.NewMockTrader(ctrl)
+
+		c, err := risk.New(tr, risk.WithMaxOrderSize(d("1000"), decimal.Zero))
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+		tr.EXPECT().GetPrice(gomock.Any(), "BTC-GBP").Return(decimal.Zero, testErr)
+
+		err = c.Check(ctx, model.Order{ProductId: "BTC-GBP", Side: "buy", Size: "0.1"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		_, ok := err.(risk.RejectedError)
+		assert.False(t, ok)
+	})
+
+	t.Run("rejects order if value is above max order size", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tr := risk_mocks.NewMockTrader(ctrl)
+
+		c, err := risk.New(tr, risk.WithMaxOrderSize(d("1000"), decimal.Zero))
+		require.NoError(t, err)
+
+		tr.EXPECT().GetPrice(gomock.Any(), "BTC-GBP").Return(d("30000"), nil)
+
+		err = c.Check(ctx, model.Order{ProductId: "BTC-GBP", Side: "buy", Size: "0.1"})
+		require.Error(t, err)
+
+		rErr, ok := err.(risk.RejectedError)
+		require.True(t, ok)
+		assert.Equal(t, risk.MaxOrderSize, rErr.Check)
+		assert.Equal(t, "order value of 3000.00 GBP exceeds limit of 1000", rErr.Reason)
+	})
+
+	t.Run("rejects order if size is above max order size", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c, err := risk.New(risk_mocks.NewMockTrader(ctrl), risk.WithMaxOrderSize(decimal.Zero, d("0.05")))
+		require.NoError(t, err)
+
+		err = c.Check(ctx, model.Order{ProductId: "BTC-GBP", Side: "sell", Type: "limit", Size: "0.1", Price: "30000"})
+		require.Error(t, err)
+
+		rErr, ok := err.(risk.RejectedError)
+		require.True(t, ok)
+		assert.Equal(t, risk.MaxOrderSize, rErr.Check)
+		assert.Equal(t, "order size of 0.1 BTC exceeds limit of 0.05", rErr.Reason)
+	})
+
+	t.Run("returns error if error getting executed notional", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tr := risk_mocks.NewMockTrader(ctrl)
+
+		c, err := risk.New(tr, risk.WithMaxDailyNotional(d("1000")))
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+		tr.EXPECT().GetPrice(gomock.Any(), "BTC-GBP").Return(d("30000"), nil)
+		tr.EXPECT().GetExecutedNotional(gomock.Any(), "BTC-GBP", gomock.Any()).Return(decimal.Zero, testErr)
+
+		err = c.Check(ctx, model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "100"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("rejects order if daily notional would be exceeded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tr := risk_mocks.NewMockTrader(ctrl)
+
+		c, err := risk.New(tr, risk.WithMaxDailyNotional(d("1000")))
+		require.NoError(t, err)
+
+		tr.EXPECT().GetPrice(gomock.Any(), "BTC-GBP").Return(d("30000"), nil)
+		tr.EXPECT().GetExecutedNotional(gomock.Any(), "BTC-GBP", gomock.Any()).Return(d("950"), nil)
+
+		err = c.Check(ctx, model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "100"})
+		require.Error(t, err)
+
+		rErr, ok := err.(risk.RejectedError)
+		require.True(t, ok)
+		assert.Equal(t, risk.MaxDailyNotional, rErr.Check)
+		assert.Equal(t, "daily value of 1050.00 GBP would exceed limit of 1000", rErr.Reason)
+	})
+
+	t.Run("returns error if error getting accounts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tr := risk_mocks.NewMockTrader(ctrl)
+
+		c, err := risk.New(tr, risk.WithMinBalance("GBP", d("10")))
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+		tr.EXPECT().GetPrice(gomock.Any(), "BTC-GBP").Return(d("30000"), nil)
+		tr.EXPECT().GetAccounts(gomock.Any()).Return(nil, testErr)
+
+		err = c.Check(ctx, model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "100"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("rejects buy if remaining quote balance would be below minimum", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tr := risk_mocks.NewMockTrader(ctrl)
+
+		c, err := risk.New(tr, risk.WithMinBalance("gbp", d("10")))
+		require.NoError(t, err)
+
+		tr.EXPECT().GetPrice(gomock.Any(), "BTC-GBP").Return(d("30000"), nil)
+		tr.EXPECT().GetAccounts(gomock.Any()).Return([]trader.Account{
+			{Currency: "BTC", Available: d("1")},
+			{Currency: "GBP", Available: d("105")},
+		}, nil)
+
+		err = c.Check(ctx, model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "100"})
+		require.Error(t, err)
+
+		rErr, ok := err.(risk.RejectedError)
+		require.True(t, ok)
+		assert.Equal(t, risk.MinBalance, rErr.Check)
+		assert.Equal(t, "remaining GBP balance of 5 would be below minimum of 10", rErr.Reason)
+	})
+
+	t.Run("rejects sell if remaining base balance would be below minimum", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tr := risk_mocks.NewMockTrader(ctrl)
+
+		c, err := risk.New(tr,
+			risk.WithMinBalance("GBP", d("10")),
+			risk.WithMinBalance("BTC", d("0.01")),
+		)
+		require.NoError(t, err)
+
+		tr.EXPECT().GetPrice(gomock.Any(), "BTC-GBP").Return(d("30000"), nil)
+		tr.EXPECT().GetAccounts(gomock.Any()).Return([]trader.Account{
+			{Currency: "BTC", Available: d("0.1")},
+			{Currency: "GBP", Available: d("0")},
+		}, nil)
+
+		err = c.Check(ctx, model.Order{ProductId: "BTC-GBP", Side: "sell", Funds: "2790"})
+		require.Error(t, err)
+
+		rErr, ok := err.(risk.RejectedError)
+		require.True(t, ok)
+		assert.Equal(t, risk.MinBalance, rErr.Check)
+		assert.Equal(t, "remaining BTC balance of 0.007 would be below minimum of 0.01", rErr.Reason)
+	})
+
+	t.Run("returns nil if order passes all checks", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tr := risk_mocks.NewMockTrader(ctrl)
+
+		c, err := risk.New(tr,
+			risk.WithMaxOrderSize(d("1000"), d("0.1")),
+			risk.WithMaxDailyNotional(d("5000")),
+			risk.WithMinBalance("GBP", d("10")),
+		)
+		require.NoError(t, err)
+
+		tr.EXPECT().GetPrice(gomock.Any(), "BTC-GBP").Return(d("30000"), nil)
+		tr.EXPECT().GetExecutedNotional(gomock.Any(), "BTC-GBP", gomock.Any()).Return(d("1000"), nil)
+		tr.EXPECT().GetAccounts(gomock.Any()).Return([]trader.Account{
+			{Currency: "GBP", Available: d("500")},
+		}, nil)
+
+		err = c.Check(ctx, model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "100"})
+		require.NoError(t, err)
+	})
+}
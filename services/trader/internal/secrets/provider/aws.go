@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// currentVersion is the staging label of the latest version of a rotated secret.
+const currentVersion = "AWSCURRENT"
+
+type (
+	SecretsManagerClient interface {
+		GetSecretValueWithContext(ctx aws.Context, input *secretsmanager.GetSecretValueInput, opts ...request.Option) (*secretsmanager.GetSecretValueOutput, error)
+	}
+	ParameterStoreClient interface {
+		GetParametersByPathPagesWithContext(ctx aws.Context, input *ssm.GetParametersByPathInput, fn func(*ssm.GetParametersByPathOutput, bool) bool, opts ...request.Option) error
+	}
+
+	secretsManager struct {
+		client   SecretsManagerClient
+		secretId string
+	}
+
+	parameterStore struct {
+		client ParameterStoreClient
+		path   string
+	}
+)
+
+// NewSecretsManager returns a source which reads a secret from AWS Secrets
+// Manager holding a JSON object of names to values, e.g. {"COINBASE_PRO_KEY": "key"}.
+// The current version is always read, so a rotated secret is picked up on the next load.
+func NewSecretsManager(client SecretsManagerClient, secretId string) (*secretsManager, error) {
+	switch {
+	case client == nil:
+		return nil, InvalidParameterError{Parameter: "client"}
+	case secretId == "":
+		return nil, InvalidParameterError{Parameter: "secretId"}
+	}
+
+	return &secretsManager{
+		client:   client,
+		secretId: secretId,
+	}, nil
+}
+
+func (s *secretsManager) Load(ctx context.Context) (map[string]string, error) {
+	out, err := s.client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId:     aws.String(s.secretId),
+		VersionStage: aws.String(currentVersion),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get_secret_value: %w", err)
+	}
+
+	b := out.SecretBinary
+	if out.SecretString != nil {
+		b = []byte(aws.StringValue(out.SecretString))
+	}
+
+	var secrets map[string]string
+	if err := json.Unmarshal(b, &secrets); err != nil {
+		return nil, fmt.Errorf("json_unmarshal: %w", err)
+	}
+
+	return secrets, nil
+}
+
+// NewParameterStore returns a source which reads every parameter under the
+// path from AWS SSM Parameter Store, decrypting SecureString parameters. Each
+// secret is named by the parameter name without the path, e.g. the parameter
+// /kripto/prod/COINBASE_PRO_KEY is read as COINBASE_PRO_KEY from path /kripto/prod.
+func NewParameterStore(client ParameterStoreClient, path string) (*parameterStore, error) {
+	switch {
+	case client == nil:
+		return nil, InvalidParameterError{Parameter: "client"}
+	case !strings.HasPrefix(path, "/"):
+		return nil, InvalidParameterError{Parameter: "path"}
+	}
+
+	return &parameterStore{
+		client: client,
+		path:   path,
+	}, nil
+}
+
+func (p *parameterStore) Load(ctx context.Context) (map[string]string, error) {
+	secrets := make(map[string]string)
+	prefix := strings.TrimSuffix(p.path, "/") + "/"
+
+	err := p.client.GetParametersByPathPagesWithContext(ctx, &ssm.GetParametersByPathInput{
+		Path:           aws.String(p.path),
+		WithDecryption: aws.Bool(true),
+	}, func(out *ssm.GetParametersByPathOutput, _ bool) bool {
+		for _, param := range out.Parameters {
+			name := strings.TrimPrefix(aws.StringValue(param.Name), prefix)
+			secrets[name] = aws.StringValue(param.Value)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get_parameters_by_path: %w", err)
+	}
+
+	return secrets, nil
+}
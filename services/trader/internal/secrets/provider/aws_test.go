@@ -0,0 +1,227 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/cshep4/kripto/services/trader/internal/secrets/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// awsStub is a local stub of the AWS JSON API, responding to each target,
+// e.g. secretsmanager.GetSecretValue, with the next of its responses.
+type awsStub struct {
+	t         *testing.T
+	responses map[string][]stubResponse
+	requests  map[string][]map[string]interface{}
+}
+
+type stubResponse struct {
+	status int
+	body   interface{}
+}
+
+func newAWSStub(t *testing.T) (*awsStub, *session.Session) {
+	stub := &awsStub{
+		t:         t,
+		responses: make(map[string][]stubResponse),
+		requests:  make(map[string][]map[string]interface{}),
+	}
+
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:    aws.String(srv.URL),
+		Region:      aws.String("eu-west-1"),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+		MaxRetries:  aws.Int(0),
+	})
+	require.NoError(t, err)
+
+	return stub, sess
+}
+
+func (s *awsStub) respond(target string, status int, body interface{}) {
+	s.responses[target] = append(s.responses[target], stubResponse{status: status, body: body})
+}
+
+func (s *awsStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	target := r.Header.Get("X-Amz-Target")
+
+	var req map[string]interface{}
+	require.NoError(s.t, json.NewDecoder(r.Body).Decode(&req))
+	s.requests[target] = append(s.requests[target], req)
+
+	responses := s.responses[target]
+	if len(responses) == 0 {
+		s.t.Errorf("unexpected request: %s", target)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	s.responses[target] = responses[1:]
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	w.WriteHeader(responses[0].status)
+	require.NoError(s.t, json.NewEncoder(w).Encode(responses[0].body))
+}
+
+func TestNewSecretsManager(t *testing.T) {
+	t.Run("returns error if client is empty", func(t *testing.T) {
+		s, err := provider.NewSecretsManager(nil, "kripto/trader")
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+
+		ipErr, ok := err.(provider.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "client", ipErr.Parameter)
+	})
+
+	t.Run("returns error if secretId is empty", func(t *testing.T) {
+		s, err := provider.NewSecretsManager(&secretsmanager.SecretsManager{}, "")
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+
+		ipErr, ok := err.(provider.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "secretId", ipErr.Parameter)
+	})
+}
+
+func TestSecretsManager_Load(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns error if secret can't be read", func(t *testing.T) {
+		stub, sess := newAWSStub(t)
+		stub.respond("secretsmanager.GetSecretValue", http.StatusBadRequest, map[string]string{
+			"__type":  "ResourceNotFoundException",
+			"message": "Secrets Manager can't find the specified secret.",
+		})
+
+		s, err := provider.NewSecretsManager(secretsmanager.New(sess), "kripto/trader")
+		require.NoError(t, err)
+
+		res, err := s.Load(ctx)
+		require.Error(t, err)
+
+		assert.Contains(t, err.Error(), "ResourceNotFoundException")
+		assert.Nil(t, res)
+	})
+
+	t.Run("returns error if secret is not a json object", func(t *testing.T) {
+		stub, sess := newAWSStub(t)
+		stub.respond("secretsmanager.GetSecretValue", http.StatusOK, map[string]string{
+			"Name":         "kripto/trader",
+			"SecretString": "key",
+		})
+
+		s, err := provider.NewSecretsManager(secretsmanager.New(sess), "kripto/trader")
+		require.NoError(t, err)
+
+		res, err := s.Load(ctx)
+		require.Error(t, err)
+
+		assert.Nil(t, res)
+	})
+
+	t.Run("returns current version of secret", func(t *testing.T) {
+		stub, sess := newAWSStub(t)
+		stub.respond("secretsmanager.GetSecretValue", http.StatusOK, map[string]string{
+			"Name":         "kripto/trader",
+			"SecretString": `{"COINBASE_PRO_KEY": "key", "COINBASE_PRO_SECRET": "secret"}`,
+		})
+
+		s, err := provider.NewSecretsManager(secretsmanager.New(sess), "kripto/trader")
+		require.NoError(t, err)
+
+		res, err := s.Load(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{"COINBASE_PRO_KEY": "key", "COINBASE_PRO_SECRET": "secret"}, res)
+
+		req := stub.requests["secretsmanager.GetSecretValue"]
+		require.Len(t, req, 1)
+		assert.Equal(t, "kripto/trader", req[0]["SecretId"])
+		assert.Equal(t, "AWSCURRENT", req[0]["VersionStage"])
+	})
+}
+
+func TestNewParameterStore(t *testing.T) {
+	t.Run("returns error if client is empty", func(t *testing.T) {
+		p, err := provider.NewParameterStore(nil, "/kripto/prod")
+		require.Error(t, err)
+
+		assert.Nil(t, p)
+
+		ipErr, ok := err.(provider.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "client", ipErr.Parameter)
+	})
+
+	t.Run("returns error if path is not absolute", func(t *testing.T) {
+		p, err := provider.NewParameterStore(&ssm.SSM{}, "kripto/prod")
+		require.Error(t, err)
+
+		assert.Nil(t, p)
+
+		ipErr, ok := err.(provider.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "path", ipErr.Parameter)
+	})
+}
+
+func TestParameterStore_Load(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns error if parameters can't be read", func(t *testing.T) {
+		stub, sess := newAWSStub(t)
+		stub.respond("AmazonSSM.GetParametersByPath", http.StatusBadRequest, map[string]string{
+			"__type":  "AccessDeniedException",
+			"message": "access denied",
+		})
+
+		p, err := provider.NewParameterStore(ssm.New(sess), "/kripto/prod")
+		require.NoError(t, err)
+
+		res, err := p.Load(ctx)
+		require.Error(t, err)
+
+		assert.Contains(t, err.Error(), "AccessDeniedException")
+		assert.Nil(t, res)
+	})
+
+	t.Run("returns decrypted parameters from every page", func(t *testing.T) {
+		stub, sess := newAWSStub(t)
+		stub.respond("AmazonSSM.GetParametersByPath", http.StatusOK, map[string]interface{}{
+			"Parameters": []map[string]string{{"Name": "/kripto/prod/COINBASE_PRO_KEY", "Type": "SecureString", "Value": "key"}},
+			"NextToken":  "next",
+		})
+		stub.respond("AmazonSSM.GetParametersByPath", http.StatusOK, map[string]interface{}{
+			"Parameters": []map[string]string{{"Name": "/kripto/prod/COINBASE_PRO_SECRET", "Type": "SecureString", "Value": "secret"}},
+		})
+
+		p, err := provider.NewParameterStore(ssm.New(sess), "/kripto/prod/")
+		require.NoError(t, err)
+
+		res, err := p.Load(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{"COINBASE_PRO_KEY": "key", "COINBASE_PRO_SECRET": "secret"}, res)
+
+		req := stub.requests["AmazonSSM.GetParametersByPath"]
+		require.Len(t, req, 2)
+		assert.Equal(t, true, req[0]["WithDecryption"])
+		assert.Equal(t, "next", req[1]["NextToken"])
+	})
+}
@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+type env struct{}
+
+// NewEnv returns a source which reads secrets from environment variables.
+func NewEnv() *env {
+	return &env{}
+}
+
+func (env) Load(context.Context) (map[string]string, error) {
+	environ := os.Environ()
+
+	secrets := make(map[string]string, len(environ))
+	for _, e := range environ {
+		parts := strings.SplitN(e, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		secrets[parts[0]] = parts[1]
+	}
+
+	return secrets, nil
+}
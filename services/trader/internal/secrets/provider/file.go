@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type file struct {
+	path string
+}
+
+// NewFile returns a source which reads secrets from a JSON or YAML file
+// containing a single object of names to values, e.g. {"COINBASE_PRO_KEY": "key"}.
+// The format is chosen by the file extension.
+func NewFile(path string) (*file, error) {
+	if path == "" {
+		return nil, InvalidParameterError{Parameter: "path"}
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+	default:
+		return nil, InvalidParameterError{Parameter: "path"}
+	}
+
+	return &file{path: path}, nil
+}
+
+func (f *file) Load(context.Context) (map[string]string, error) {
+	b, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("read_file: %w", err)
+	}
+
+	var secrets map[string]string
+	switch strings.ToLower(filepath.Ext(f.path)) {
+	case ".json":
+		if err := json.Unmarshal(b, &secrets); err != nil {
+			return nil, fmt.Errorf("json_unmarshal: %w", err)
+		}
+	default:
+		if err := yaml.Unmarshal(b, &secrets); err != nil {
+			return nil, fmt.Errorf("yaml_unmarshal: %w", err)
+		}
+	}
+
+	return secrets, nil
+}
@@ -0,0 +1,92 @@
+package provider_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cshep4/kripto/services/trader/internal/secrets/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	return path
+}
+
+func TestNewFile(t *testing.T) {
+	t.Run("returns error if path is empty", func(t *testing.T) {
+		f, err := provider.NewFile("")
+		require.Error(t, err)
+
+		assert.Nil(t, f)
+
+		ipErr, ok := err.(provider.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "path", ipErr.Parameter)
+	})
+
+	t.Run("returns error if file is not json or yaml", func(t *testing.T) {
+		f, err := provider.NewFile("secrets.txt")
+		require.Error(t, err)
+
+		assert.Nil(t, f)
+
+		ipErr, ok := err.(provider.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "path", ipErr.Parameter)
+	})
+}
+
+func TestFile_Load(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns error if file can't be read", func(t *testing.T) {
+		f, err := provider.NewFile(filepath.Join(os.TempDir(), "missing", "secrets.json"))
+		require.NoError(t, err)
+
+		res, err := f.Load(ctx)
+		require.Error(t, err)
+
+		assert.Nil(t, res)
+	})
+
+	t.Run("returns error if file is invalid", func(t *testing.T) {
+		f, err := provider.NewFile(writeFile(t, "secrets.json", "["))
+		require.NoError(t, err)
+
+		res, err := f.Load(ctx)
+		require.Error(t, err)
+
+		assert.Nil(t, res)
+	})
+
+	t.Run("returns secrets from json file", func(t *testing.T) {
+		f, err := provider.NewFile(writeFile(t, "secrets.json", `{"COINBASE_PRO_KEY": "key", "COINBASE_PRO_SECRET": "secret"}`))
+		require.NoError(t, err)
+
+		res, err := f.Load(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{"COINBASE_PRO_KEY": "key", "COINBASE_PRO_SECRET": "secret"}, res)
+	})
+
+	t.Run("returns secrets from yaml file", func(t *testing.T) {
+		f, err := provider.NewFile(writeFile(t, "secrets.yml", "COINBASE_PRO_KEY: key\nCOINBASE_PRO_SECRET: secret\n"))
+		require.NoError(t, err)
+
+		res, err := f.Load(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{"COINBASE_PRO_KEY": "key", "COINBASE_PRO_SECRET": "secret"}, res)
+	})
+}
@@ -0,0 +1,23 @@
+package provider
+
+import "time"
+
+type Option func(*provider)
+
+// WithTTL sets how long secrets are cached before they are reloaded from the source.
+func WithTTL(ttl time.Duration) Option {
+	return func(p *provider) {
+		if ttl > 0 {
+			p.ttl = ttl
+		}
+	}
+}
+
+// WithClock sets the function used to check if the cache has expired.
+func WithClock(now func() time.Time) Option {
+	return func(p *provider) {
+		if now != nil {
+			p.now = now
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Package provider reads secrets, such as exchange credentials, from a pluggable source.
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultTTL = 5 * time.Minute
+
+// ErrNotFound is returned when the source does not hold the requested secret.
+var ErrNotFound = errors.New("secret not found")
+
+type (
+	// Source loads all of the secrets held by a backend, keyed by name.
+	Source interface {
+		Load(ctx context.Context) (map[string]string, error)
+	}
+
+	provider struct {
+		source Source
+		ttl    time.Duration
+		now    func() time.Time
+
+		mu       sync.Mutex
+		secrets  map[string]string
+		loadedAt time.Time
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+// New returns a provider which caches the secrets loaded from source. The
+// cache is refreshed once it is older than the TTL, so rotated secrets are
+// picked up without a restart.
+func New(source Source, opts ...Option) (*provider, error) {
+	if source == nil {
+		return nil, InvalidParameterError{Parameter: "source"}
+	}
+
+	p := &provider{
+		source: source,
+		ttl:    defaultTTL,
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p, nil
+}
+
+// GetSecret returns the named secret. If the cache has expired and the source
+// can't be read, the previous value is returned so a failed refresh doesn't
+// stop callers using credentials which are still valid. A secret missing from
+// the cache causes a refresh, in case it was added since the last load.
+func (p *provider) GetSecret(ctx context.Context, name string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	refreshed := false
+	if p.secrets == nil || p.now().Sub(p.loadedAt) >= p.ttl {
+		err := p.load(ctx)
+		switch {
+		case err == nil:
+			refreshed = true
+		case p.secrets == nil:
+			return "", err
+		}
+	}
+
+	if v, ok := p.secrets[name]; ok {
+		return v, nil
+	}
+
+	if !refreshed {
+		if err := p.load(ctx); err != nil {
+			return "", err
+		}
+		if v, ok := p.secrets[name]; ok {
+			return v, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: %w", name, ErrNotFound)
+}
+
+// Refresh reloads the secrets from the source, e.g. after the exchange rejects
+// credentials which may have been rotated.
+func (p *provider) Refresh(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.load(ctx)
+}
+
+func (p *provider) load(ctx context.Context) error {
+	secrets, err := p.source.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("load_secrets: %w", err)
+	}
+
+	p.secrets = secrets
+	p.loadedAt = p.now()
+
+	return nil
+}
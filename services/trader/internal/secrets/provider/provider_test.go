@@ -0,0 +1,204 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/mocks/secrets"
+	"github.com/cshep4/kripto/services/trader/internal/secrets/provider"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	name  = "COINBASE_PRO_KEY"
+	value = "key"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if source is empty", func(t *testing.T) {
+		p, err := provider.New(nil)
+		require.Error(t, err)
+
+		assert.Nil(t, p)
+
+		ipErr, ok := err.(provider.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "source", ipErr.Parameter)
+	})
+
+	t.Run("returns provider", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		p, err := provider.New(secrets_mocks.NewMockSource(ctrl))
+		require.NoError(t, err)
+
+		assert.NotNil(t, p)
+	})
+}
+
+func TestProvider_GetSecret(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns error if secrets can't be loaded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		source := secrets_mocks.NewMockSource(ctrl)
+		p, err := provider.New(source)
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+		source.EXPECT().Load(ctx).Return(nil, testErr)
+
+		res, err := p.GetSecret(ctx, name)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Empty(t, res)
+	})
+
+	t.Run("returns error if secret is not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		source := secrets_mocks.NewMockSource(ctrl)
+		p, err := provider.New(source)
+		require.NoError(t, err)
+
+		source.EXPECT().Load(ctx).Return(map[string]string{}, nil)
+
+		res, err := p.GetSecret(ctx, name)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, provider.ErrNotFound))
+		assert.Empty(t, res)
+	})
+
+	t.Run("returns cached secret until ttl expires", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c := &clock{now: time.Now()}
+		source := secrets_mocks.NewMockSource(ctrl)
+		p, err := provider.New(source, provider.WithTTL(time.Minute), provider.WithClock(c.Now))
+		require.NoError(t, err)
+
+		source.EXPECT().Load(ctx).Return(map[string]string{name: value}, nil)
+
+		res, err := p.GetSecret(ctx, name)
+		require.NoError(t, err)
+		assert.Equal(t, value, res)
+
+		c.now = c.now.Add(30 * time.Second)
+		res, err = p.GetSecret(ctx, name)
+		require.NoError(t, err)
+		assert.Equal(t, value, res)
+
+		source.EXPECT().Load(ctx).Return(map[string]string{name: "rotated"}, nil)
+
+		c.now = c.now.Add(30 * time.Second)
+		res, err = p.GetSecret(ctx, name)
+		require.NoError(t, err)
+		assert.Equal(t, "rotated", res)
+	})
+
+	t.Run("returns previous secret if refresh fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c := &clock{now: time.Now()}
+		source := secrets_mocks.NewMockSource(ctrl)
+		p, err := provider.New(source, provider.WithTTL(time.Minute), provider.WithClock(c.Now))
+		require.NoError(t, err)
+
+		source.EXPECT().Load(ctx).Return(map[string]string{name: value}, nil)
+
+		_, err = p.GetSecret(ctx, name)
+		require.NoError(t, err)
+
+		source.EXPECT().Load(ctx).Return(nil, errors.New("error"))
+
+		c.now = c.now.Add(time.Minute)
+		res, err := p.GetSecret(ctx, name)
+		require.NoError(t, err)
+
+		assert.Equal(t, value, res)
+	})
+
+	t.Run("reloads secrets if secret is not cached", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		source := secrets_mocks.NewMockSource(ctrl)
+		p, err := provider.New(source)
+		require.NoError(t, err)
+
+		gomock.InOrder(
+			source.EXPECT().Load(ctx).Return(map[string]string{}, nil),
+			source.EXPECT().Load(ctx).Return(map[string]string{name: value}, nil),
+		)
+
+		_, err = p.GetSecret(ctx, "COINBASE_PRO_SECRET")
+		require.Error(t, err)
+
+		res, err := p.GetSecret(ctx, name)
+		require.NoError(t, err)
+
+		assert.Equal(t, value, res)
+	})
+}
+
+func TestProvider_Refresh(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reloads secrets before ttl expires", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		source := secrets_mocks.NewMockSource(ctrl)
+		p, err := provider.New(source, provider.WithTTL(time.Hour))
+		require.NoError(t, err)
+
+		gomock.InOrder(
+			source.EXPECT().Load(ctx).Return(map[string]string{name: value}, nil),
+			source.EXPECT().Load(ctx).Return(map[string]string{name: "rotated"}, nil),
+		)
+
+		_, err = p.GetSecret(ctx, name)
+		require.NoError(t, err)
+
+		err = p.Refresh(ctx)
+		require.NoError(t, err)
+
+		res, err := p.GetSecret(ctx, name)
+		require.NoError(t, err)
+
+		assert.Equal(t, "rotated", res)
+	})
+}
+
+func TestEnv_Load(t *testing.T) {
+	t.Run("returns environment variables", func(t *testing.T) {
+		os.Setenv("PROVIDER_TEST_SECRET", "a=b")
+		defer os.Unsetenv("PROVIDER_TEST_SECRET")
+
+		res, err := provider.NewEnv().Load(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, "a=b", res["PROVIDER_TEST_SECRET"])
+	})
+}
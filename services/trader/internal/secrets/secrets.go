@@ -1,12 +1,80 @@
 package secrets
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Netflix/go-env"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/cshep4/kripto/services/trader/internal/secrets/provider"
+	"github.com/cshep4/kripto/shared/go/publisher"
+	"github.com/shopspring/decimal"
 )
 
+const (
+	Coinbase = "coinbase"
+	Kraken   = "kraken"
+	Fake     = "fake"
+	Paper    = "paper"
+
+	EnvProvider            = "env"
+	FileProvider           = "file"
+	SecretsManagerProvider = "secretsmanager"
+	ParameterStoreProvider = "ssm"
+
+	LiveEnvironment    Environment = "live"
+	SandboxEnvironment Environment = "sandbox"
+	FakeEnvironment    Environment = "fake"
+	PaperEnvironment   Environment = "paper"
+
+	coinbaseProURL        = "https://api.pro.coinbase.com"
+	coinbaseProSandboxURL = "https://api-public.sandbox.pro.coinbase.com"
+)
+
+// Environment is where orders are placed: live on the exchange, on the
+// exchange's sandbox, on the in-memory fake exchange or on the paper exchange.
+// The paper exchange fills orders at the latest real rate, unlike the sandbox
+// whose prices don't match the market.
+type Environment string
+
+type CoinbaseProCredentials struct {
+	Key        string
+	Passphrase string
+	Secret     string
+}
+
+// Provider looks up a secret by name, e.g. COINBASE_PRO_KEY. Refresh reads the
+// secrets from their source again, e.g. after the exchange rejects credentials
+// which may have been rotated.
+type Provider interface {
+	GetSecret(ctx context.Context, name string) (string, error)
+	Refresh(ctx context.Context) error
+}
+
 type Secrets struct {
+	// Environment is either live, sandbox, fake or paper. If not set it is sandbox
+	// when MOCK_TRADE is true, fake or paper when EXCHANGE is and live otherwise.
+	Environment Environment `env:"ENVIRONMENT"`
+	// Provider configures where the exchange credentials are read from.
+	Provider struct {
+		// Type is either env (default), file, secretsmanager or ssm.
+		Type string `env:"SECRETS_PROVIDER"`
+		// File is the path of a JSON or YAML file of secrets, used by the file provider.
+		File string `env:"SECRETS_FILE"`
+		// SecretId is the Secrets Manager secret holding a JSON object of secrets.
+		SecretId string `env:"SECRETS_MANAGER_SECRET_ID"`
+		// ParameterPath is the SSM Parameter Store path the secrets are stored under.
+		ParameterPath string        `env:"SECRETS_PARAMETER_PATH"`
+		CacheTTL      time.Duration `env:"SECRETS_CACHE_TTL"`
+	}
+	// Exchange is the venue orders are placed on, either coinbase (default), kraken, fake or paper.
+	Exchange    string `env:"EXCHANGE"`
 	CoinbasePro struct {
 		Live struct {
 			Key        string `env:"COINBASE_PRO_KEY"`
@@ -19,31 +87,313 @@ type Secrets struct {
 			Secret     string `env:"COINBASE_PRO_SANDBOX_SECRET"`
 		}
 	}
-	SNS struct {
-		Topic  string `env:"TOPIC"`
-		Region string `env:"REGION"`
+	Kraken struct {
+		Key    string `env:"KRAKEN_API_KEY"`
+		Secret string `env:"KRAKEN_API_SECRET"`
+	}
+	// Fake configures the in-memory exchange used for running offline.
+	Fake struct {
+		// Balances is a comma separated list of currency:amount pairs, e.g. GBP:1000,BTC:0.1
+		Balances string `env:"FAKE_BALANCES"`
+		// Prices is a comma separated list of product:price pairs, e.g. BTC-GBP:40000
+		Prices    string        `env:"FAKE_PRICES"`
+		FeeRate   string        `env:"FAKE_FEE_RATE"`
+		FillRatio string        `env:"FAKE_FILL_RATIO"`
+		Latency   time.Duration `env:"FAKE_LATENCY"`
 	}
+	// Paper configures the paper-trading exchange, which fills orders at the
+	// latest rate stored by the data-storer and keeps its wallet in the database.
+	Paper struct {
+		// Balances is a comma separated list of currency:amount pairs the wallet
+		// starts with, e.g. GBP:1000. Only used when the wallet is created.
+		Balances   string        `env:"PAPER_BALANCES"`
+		FeeRate    string        `env:"PAPER_FEE_RATE"`
+		MaxRateAge time.Duration `env:"PAPER_MAX_RATE_AGE"`
+	}
+	TradeHistory struct {
+		FunctionName string `env:"TRADE_READER_FUNCTION_NAME"`
+	}
+	Region string `env:"REGION"`
+	// Publisher configures where executed trades are published.
+	Publisher  publisher.Config
+	Settlement struct {
+		PollInterval    time.Duration `env:"SETTLEMENT_POLL_INTERVAL"`
+		MaxPollInterval time.Duration `env:"SETTLEMENT_MAX_POLL_INTERVAL"`
+		Timeout         time.Duration `env:"SETTLEMENT_TIMEOUT"`
+	}
+	// DryRunFeeRate is the fee charged on simulated fills, e.g. 0.005 for 0.5%.
+	DryRunFeeRate string `env:"DRY_RUN_FEE_RATE"`
+	// Retry configures retrying calls to the exchange which fail with a transient error.
+	Retry struct {
+		MaxAttempts int           `env:"RETRY_MAX_ATTEMPTS"`
+		BaseDelay   time.Duration `env:"RETRY_BASE_DELAY"`
+		MaxDelay    time.Duration `env:"RETRY_MAX_DELAY"`
+	}
+	Risk struct {
+		KillSwitch       bool   `env:"RISK_KILL_SWITCH"`
+		MaxOrderFunds    string `env:"RISK_MAX_ORDER_FUNDS"`
+		MaxOrderSize     string `env:"RISK_MAX_ORDER_SIZE"`
+		MaxDailyNotional string `env:"RISK_MAX_DAILY_NOTIONAL"`
+		// MinBalances is a comma separated list of currency:amount pairs, e.g. GBP:10,BTC:0.001
+		MinBalances string `env:"RISK_MIN_BALANCES"`
+	}
+	// MockTrade places orders on the sandbox, superseded by ENVIRONMENT.
 	MockTrade bool `env:"MOCK_TRADE"`
+
+	// provider is the provider the credentials were read from, kept to reload them.
+	provider Provider
 }
 
-func (s *Secrets) Fetch() error {
+// Fetch reads the configuration from environment variables and the exchange
+// credentials from the provider set by SECRETS_PROVIDER. Credentials missing
+// from the provider keep the value of their environment variable.
+func (s *Secrets) Fetch(ctx context.Context) error {
 	_, err := env.UnmarshalFromEnviron(s)
 	if err != nil {
 		return fmt.Errorf("unmarshal_environment_variables: %w", err)
 	}
+	if err := s.resolveEnvironment(); err != nil {
+		return err
+	}
+
+	if s.provider, err = s.newProvider(); err != nil {
+		return fmt.Errorf("initialise_secret_provider: %w", err)
+	}
+	if err := s.loadCredentials(ctx); err != nil {
+		return fmt.Errorf("load_credentials: %w", err)
+	}
+
+	switch s.Exchange {
+	case Coinbase:
+		return s.validateCoinbasePro()
+	case Kraken:
+		return s.validateKraken()
+	case Fake:
+		return s.validateFake()
+	case Paper:
+		return nil
+	default:
+		return fmt.Errorf("invalid_environment_variable: EXCHANGE - should be either %s/%s/%s/%s", Coinbase, Kraken, Fake, Paper)
+	}
+}
+
+// resolveEnvironment sets the environment from MOCK_TRADE and EXCHANGE if it
+// isn't set, otherwise it checks they agree with it. The fake and paper
+// exchanges are only used in, and are the only exchanges of, their environments.
+func (s *Secrets) resolveEnvironment() error {
+	switch s.Environment {
+	case "":
+		switch {
+		case s.Exchange == Fake:
+			s.Environment = FakeEnvironment
+		case s.Exchange == Paper:
+			s.Environment = PaperEnvironment
+		case s.MockTrade:
+			s.Environment = SandboxEnvironment
+		default:
+			s.Environment = LiveEnvironment
+		}
+	case LiveEnvironment:
+		if s.MockTrade {
+			return fmt.Errorf("invalid_environment_variable: MOCK_TRADE - can't be true in %s environment", s.Environment)
+		}
+	case SandboxEnvironment:
+		s.MockTrade = true
+	case FakeEnvironment:
+		if s.Exchange == "" {
+			s.Exchange = Fake
+		}
+	case PaperEnvironment:
+		if s.Exchange == "" {
+			s.Exchange = Paper
+		}
+	default:
+		return fmt.Errorf("invalid_environment_variable: ENVIRONMENT - should be either %s/%s/%s/%s", LiveEnvironment, SandboxEnvironment, FakeEnvironment, PaperEnvironment)
+	}
+
+	if s.Exchange == "" {
+		s.Exchange = Coinbase
+	}
+	if (s.Environment == FakeEnvironment) != (s.Exchange == Fake) || (s.Environment == PaperEnvironment) != (s.Exchange == Paper) {
+		return fmt.Errorf("invalid_environment_variable: EXCHANGE - %s can't be used in %s environment", s.Exchange, s.Environment)
+	}
+
+	return nil
+}
+
+// CoinbaseProURL returns the url of the Coinbase Pro API for the environment.
+func (s Secrets) CoinbaseProURL() string {
+	if s.Environment == SandboxEnvironment {
+		return coinbaseProSandboxURL
+	}
+	return coinbaseProURL
+}
+
+// CoinbaseProCredentials returns the Coinbase Pro credentials for the environment.
+func (s Secrets) CoinbaseProCredentials() CoinbaseProCredentials {
+	if s.Environment == SandboxEnvironment {
+		return CoinbaseProCredentials(s.CoinbasePro.Sandbox)
+	}
+	return CoinbaseProCredentials(s.CoinbasePro.Live)
+}
+
+func (s *Secrets) newProvider() (Provider, error) {
+	var (
+		source provider.Source
+		err    error
+	)
+
+	switch s.Provider.Type {
+	case "", EnvProvider:
+		source = provider.NewEnv()
+	case FileProvider:
+		source, err = provider.NewFile(s.Provider.File)
+	case SecretsManagerProvider:
+		var sess *session.Session
+		if sess, err = s.newSession(); err == nil {
+			source, err = provider.NewSecretsManager(secretsmanager.New(sess), s.Provider.SecretId)
+		}
+	case ParameterStoreProvider:
+		var sess *session.Session
+		if sess, err = s.newSession(); err == nil {
+			source, err = provider.NewParameterStore(ssm.New(sess), s.Provider.ParameterPath)
+		}
+	default:
+		return nil, fmt.Errorf("invalid_environment_variable: SECRETS_PROVIDER - should be either %s/%s/%s/%s", EnvProvider, FileProvider, SecretsManagerProvider, ParameterStoreProvider)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.New(source, provider.WithTTL(s.Provider.CacheTTL))
+}
+
+func (s *Secrets) newSession() (*session.Session, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(s.Region),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create_aws_session: %w", err)
+	}
+	return sess, nil
+}
+
+// ReloadCredentials reads the exchange credentials from the provider again. The
+// provider only reads them from its source once its cache has expired, unless
+// refresh is set, e.g. after the exchange rejects the credentials.
+func (s *Secrets) ReloadCredentials(ctx context.Context, refresh bool) error {
+	if s.provider == nil {
+		return nil
+	}
+
+	if refresh {
+		if err := s.provider.Refresh(ctx); err != nil {
+			return fmt.Errorf("refresh_secrets: %w", err)
+		}
+	}
+
+	if err := s.loadCredentials(ctx); err != nil {
+		return fmt.Errorf("load_credentials: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Secrets) loadCredentials(ctx context.Context) error {
+	for name, v := range s.credentials() {
+		secret, err := s.provider.GetSecret(ctx, name)
+		switch {
+		case errors.Is(err, provider.ErrNotFound):
+			continue
+		case err != nil:
+			return fmt.Errorf("get_secret: %w", err)
+		}
+		*v = secret
+	}
+	return nil
+}
+
+// credentials returns the credentials used by the exchange, keyed by secret name.
+func (s *Secrets) credentials() map[string]*string {
+	switch {
+	case s.Exchange == Coinbase && s.Environment == SandboxEnvironment:
+		return map[string]*string{
+			"COINBASE_PRO_SANDBOX_KEY":        &s.CoinbasePro.Sandbox.Key,
+			"COINBASE_PRO_SANDBOX_PASSPHRASE": &s.CoinbasePro.Sandbox.Passphrase,
+			"COINBASE_PRO_SANDBOX_SECRET":     &s.CoinbasePro.Sandbox.Secret,
+		}
+	case s.Exchange == Coinbase:
+		return map[string]*string{
+			"COINBASE_PRO_KEY":        &s.CoinbasePro.Live.Key,
+			"COINBASE_PRO_PASSPHRASE": &s.CoinbasePro.Live.Passphrase,
+			"COINBASE_PRO_SECRET":     &s.CoinbasePro.Live.Secret,
+		}
+	case s.Exchange == Kraken:
+		return map[string]*string{
+			"KRAKEN_API_KEY":    &s.Kraken.Key,
+			"KRAKEN_API_SECRET": &s.Kraken.Secret,
+		}
+	}
+	return nil
+}
+
+func (s *Secrets) validateCoinbasePro() error {
+	live := s.Environment == LiveEnvironment
 	switch {
-	case !s.MockTrade && s.CoinbasePro.Live.Key == "":
+	case live && s.CoinbasePro.Live.Key == "":
 		return fmt.Errorf("missing_environment_variable: COINBASE_PRO_KEY")
-	case !s.MockTrade && s.CoinbasePro.Live.Passphrase == "":
+	case live && s.CoinbasePro.Live.Passphrase == "":
 		return fmt.Errorf("missing_environment_variable: COINBASE_PRO_PASSPHRASE")
-	case !s.MockTrade && s.CoinbasePro.Live.Secret == "":
+	case live && s.CoinbasePro.Live.Secret == "":
 		return fmt.Errorf("missing_environment_variable: COINBASE_PRO_SECRET")
-	case s.MockTrade && s.CoinbasePro.Sandbox.Key == "":
+	case !live && s.CoinbasePro.Sandbox.Key == "":
 		return fmt.Errorf("missing_environment_variable: COINBASE_PRO_SANDBOX_KEY")
-	case s.MockTrade && s.CoinbasePro.Sandbox.Passphrase == "":
+	case !live && s.CoinbasePro.Sandbox.Passphrase == "":
 		return fmt.Errorf("missing_environment_variable: COINBASE_PRO_SANDBOX_PASSPHRASE")
-	case s.MockTrade && s.CoinbasePro.Sandbox.Secret == "":
+	case !live && s.CoinbasePro.Sandbox.Secret == "":
 		return fmt.Errorf("missing_environment_variable: COINBASE_PRO_SANDBOX_SECRET")
 	}
-	return err
+	return nil
+}
+
+func (s *Secrets) validateKraken() error {
+	switch {
+	case s.Environment == SandboxEnvironment:
+		return fmt.Errorf("invalid_environment_variable: ENVIRONMENT - kraken has no sandbox")
+	case s.Kraken.Key == "":
+		return fmt.Errorf("missing_environment_variable: KRAKEN_API_KEY")
+	case s.Kraken.Secret == "":
+		return fmt.Errorf("missing_environment_variable: KRAKEN_API_SECRET")
+	}
+	return nil
+}
+
+func (s *Secrets) validateFake() error {
+	if s.Fake.Prices == "" {
+		return fmt.Errorf("missing_environment_variable: FAKE_PRICES")
+	}
+	return nil
+}
+
+// ParseAmounts parses a comma separated list of key:amount pairs, e.g. GBP:10,BTC:0.001
+func ParseAmounts(v string) (map[string]decimal.Decimal, error) {
+	amounts := make(map[string]decimal.Decimal)
+	for _, a := range strings.Split(v, ",") {
+		if strings.TrimSpace(a) == "" {
+			continue
+		}
+
+		parts := strings.Split(a, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid value %s - should be in the format KEY:AMOUNT", a)
+		}
+		amount, err := decimal.NewFromString(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, err
+		}
+
+		amounts[strings.TrimSpace(parts[0])] = amount
+	}
+
+	return amounts, nil
 }
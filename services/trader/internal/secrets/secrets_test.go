@@ -0,0 +1,230 @@
+package secrets_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cshep4/kripto/services/trader/internal/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setenv(t *testing.T, env map[string]string) {
+	for k, v := range env {
+		require.NoError(t, os.Setenv(k, v))
+	}
+	t.Cleanup(func() {
+		for k := range env {
+			os.Unsetenv(k)
+		}
+	})
+}
+
+func TestSecrets_Fetch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns error if environment is invalid", func(t *testing.T) {
+		setenv(t, map[string]string{"ENVIRONMENT": "staging"})
+
+		var s secrets.Secrets
+		err := s.Fetch(ctx)
+		require.Error(t, err)
+
+		assert.Contains(t, err.Error(), "ENVIRONMENT")
+	})
+
+	t.Run("returns error if mock trade is set in live environment", func(t *testing.T) {
+		setenv(t, map[string]string{"ENVIRONMENT": "live", "MOCK_TRADE": "true"})
+
+		var s secrets.Secrets
+		err := s.Fetch(ctx)
+		require.Error(t, err)
+
+		assert.Contains(t, err.Error(), "MOCK_TRADE")
+	})
+
+	t.Run("returns error if fake exchange is used outside fake environment", func(t *testing.T) {
+		setenv(t, map[string]string{"ENVIRONMENT": "sandbox", "EXCHANGE": "fake", "FAKE_PRICES": "BTC-GBP:40000"})
+
+		var s secrets.Secrets
+		err := s.Fetch(ctx)
+		require.Error(t, err)
+
+		assert.Contains(t, err.Error(), "EXCHANGE")
+	})
+
+	t.Run("returns error if kraken is used in sandbox environment", func(t *testing.T) {
+		setenv(t, map[string]string{"EXCHANGE": "kraken", "MOCK_TRADE": "true"})
+
+		var s secrets.Secrets
+		err := s.Fetch(ctx)
+		require.Error(t, err)
+
+		assert.Contains(t, err.Error(), "kraken has no sandbox")
+	})
+
+	t.Run("returns error if live credentials are missing", func(t *testing.T) {
+		setenv(t, map[string]string{"COINBASE_PRO_SANDBOX_KEY": "key"})
+
+		var s secrets.Secrets
+		err := s.Fetch(ctx)
+		require.Error(t, err)
+
+		assert.Contains(t, err.Error(), "COINBASE_PRO_KEY")
+	})
+
+	t.Run("uses fake environment for fake exchange", func(t *testing.T) {
+		setenv(t, map[string]string{"EXCHANGE": "fake", "FAKE_PRICES": "BTC-GBP:40000"})
+
+		var s secrets.Secrets
+		err := s.Fetch(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, secrets.FakeEnvironment, s.Environment)
+	})
+
+	t.Run("uses fake exchange in fake environment", func(t *testing.T) {
+		setenv(t, map[string]string{"ENVIRONMENT": "fake", "FAKE_PRICES": "BTC-GBP:40000"})
+
+		var s secrets.Secrets
+		err := s.Fetch(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, secrets.Fake, s.Exchange)
+	})
+
+	t.Run("returns error if paper exchange is used outside paper environment", func(t *testing.T) {
+		setenv(t, map[string]string{"ENVIRONMENT": "live", "EXCHANGE": "paper"})
+
+		var s secrets.Secrets
+		err := s.Fetch(ctx)
+		require.Error(t, err)
+
+		assert.Contains(t, err.Error(), "EXCHANGE")
+	})
+
+	t.Run("uses paper environment for paper exchange", func(t *testing.T) {
+		setenv(t, map[string]string{"EXCHANGE": "paper", "MOCK_TRADE": "true"})
+
+		var s secrets.Secrets
+		err := s.Fetch(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, secrets.PaperEnvironment, s.Environment)
+	})
+
+	t.Run("uses paper exchange in paper environment", func(t *testing.T) {
+		setenv(t, map[string]string{"ENVIRONMENT": "paper"})
+
+		var s secrets.Secrets
+		err := s.Fetch(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, secrets.Paper, s.Exchange)
+	})
+
+	t.Run("returns sandbox credentials and url in sandbox environment", func(t *testing.T) {
+		setenv(t, map[string]string{
+			"MOCK_TRADE":                      "true",
+			"COINBASE_PRO_KEY":                "live-key",
+			"COINBASE_PRO_SANDBOX_KEY":        "key",
+			"COINBASE_PRO_SANDBOX_PASSPHRASE": "passphrase",
+			"COINBASE_PRO_SANDBOX_SECRET":     "secret",
+		})
+
+		var s secrets.Secrets
+		err := s.Fetch(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, secrets.SandboxEnvironment, s.Environment)
+		assert.Equal(t, "https://api-public.sandbox.pro.coinbase.com", s.CoinbaseProURL())
+		assert.Equal(t, secrets.CoinbaseProCredentials{Key: "key", Passphrase: "passphrase", Secret: "secret"}, s.CoinbaseProCredentials())
+	})
+
+	t.Run("returns live credentials and url from secret provider in live environment", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "secrets")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		file := filepath.Join(dir, "secrets.json")
+		err = ioutil.WriteFile(file, []byte(`{"COINBASE_PRO_KEY": "key", "COINBASE_PRO_PASSPHRASE": "passphrase"}`), 0600)
+		require.NoError(t, err)
+
+		setenv(t, map[string]string{
+			"SECRETS_PROVIDER":    "file",
+			"SECRETS_FILE":        file,
+			"COINBASE_PRO_KEY":    "env-key",
+			"COINBASE_PRO_SECRET": "secret",
+		})
+
+		var s secrets.Secrets
+		err = s.Fetch(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, secrets.LiveEnvironment, s.Environment)
+		assert.Equal(t, "https://api.pro.coinbase.com", s.CoinbaseProURL())
+		assert.Equal(t, secrets.CoinbaseProCredentials{Key: "key", Passphrase: "passphrase", Secret: "secret"}, s.CoinbaseProCredentials())
+	})
+}
+
+func TestSecrets_ReloadCredentials(t *testing.T) {
+	ctx := context.Background()
+
+	writeSecrets := func(t *testing.T, file, key string) {
+		err := ioutil.WriteFile(file, []byte(`{"COINBASE_PRO_KEY": "`+key+`", "COINBASE_PRO_PASSPHRASE": "passphrase", "COINBASE_PRO_SECRET": "secret"}`), 0600)
+		require.NoError(t, err)
+	}
+
+	fetch := func(t *testing.T) (secrets.Secrets, string) {
+		dir, err := ioutil.TempDir("", "secrets")
+		require.NoError(t, err)
+		t.Cleanup(func() { os.RemoveAll(dir) })
+
+		file := filepath.Join(dir, "secrets.json")
+		writeSecrets(t, file, "key")
+
+		setenv(t, map[string]string{
+			"SECRETS_PROVIDER": "file",
+			"SECRETS_FILE":     file,
+		})
+
+		var s secrets.Secrets
+		err = s.Fetch(ctx)
+		require.NoError(t, err)
+
+		return s, file
+	}
+
+	t.Run("returns cached credentials until cache expires", func(t *testing.T) {
+		s, file := fetch(t)
+		writeSecrets(t, file, "rotated-key")
+
+		err := s.ReloadCredentials(ctx, false)
+		require.NoError(t, err)
+
+		assert.Equal(t, "key", s.CoinbaseProCredentials().Key)
+	})
+
+	t.Run("reads credentials from source again if refreshed", func(t *testing.T) {
+		s, file := fetch(t)
+		writeSecrets(t, file, "rotated-key")
+
+		err := s.ReloadCredentials(ctx, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, "rotated-key", s.CoinbaseProCredentials().Key)
+	})
+
+	t.Run("returns error if secrets can't be refreshed", func(t *testing.T) {
+		s, file := fetch(t)
+		require.NoError(t, os.Remove(file))
+
+		err := s.ReloadCredentials(ctx, true)
+		require.Error(t, err)
+
+		assert.Equal(t, "key", s.CoinbaseProCredentials().Key)
+	})
+}
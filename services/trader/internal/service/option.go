@@ -0,0 +1,25 @@
+package service
+
+type Option func(*service)
+
+// WithRiskChecker runs the risk checks against every order before it is placed.
+func WithRiskChecker(checker RiskChecker) Option {
+	return func(s *service) {
+		s.risk = checker
+	}
+}
+
+// WithOutbox stores each trade in the outbox before it is published, so it can be
+// published again if publishing fails.
+func WithOutbox(outbox Outbox) Option {
+	return func(s *service) {
+		s.outbox = outbox
+	}
+}
+
+// WithTradeHistory sets where previous trades are read from to work out the cost basis of the portfolio.
+func WithTradeHistory(history TradeHistory) Option {
+	return func(s *service) {
+		s.history = history
+	}
+}
@@ -2,30 +2,57 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/request"
-	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
 	"github.com/cshep4/kripto/services/trader/internal/model"
 	"github.com/cshep4/kripto/services/trader/internal/trader"
+	"github.com/cshep4/kripto/shared/go/events"
+	"github.com/cshep4/kripto/shared/go/log"
+	"github.com/cshep4/kripto/shared/go/publisher"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	allocationDecimals = 2
+
+	eventSource = "trader"
 )
 
 type (
 	Trader interface {
-		Trade(tradeType trader.TradeType, amount string) (*trader.TradeResponse, error)
-		GetAccounts() ([]trader.Account, error)
+		Trade(ctx context.Context, order trader.Order) (*trader.TradeResponse, error)
+		FindTrade(ctx context.Context, clientOrderId string) (*trader.TradeResponse, bool, error)
+		DryRun(ctx context.Context, order trader.Order) (*trader.TradeResponse, error)
+		GetAccounts(ctx context.Context) ([]trader.Account, error)
+		CancelOrder(ctx context.Context, id string) error
+		CancelAllOrders(ctx context.Context, productId string) ([]string, error)
+		ListOpenOrders(ctx context.Context, productId string) ([]trader.TradeResponse, error)
+		GetProducts(ctx context.Context) ([]exchange.Product, error)
+		GetPrice(ctx context.Context, productId string) (decimal.Decimal, error)
+	}
+	RiskChecker interface {
+		Check(ctx context.Context, order model.Order) error
+	}
+	TradeHistory interface {
+		GetTrades(ctx context.Context, productId string) ([]model.Trade, error)
 	}
 	Publisher interface {
-		PublishWithContext(ctx context.Context, input *sns.PublishInput, opts ...request.Option) (*sns.PublishOutput, error)
+		Publish(ctx context.Context, event publisher.Event) error
+	}
+	Outbox interface {
+		Add(ctx context.Context, event publisher.Event) error
+		MarkPublished(ctx context.Context, id string) error
 	}
 
 	service struct {
-		topic     string
 		publisher Publisher
 		trader    Trader
+		risk      RiskChecker
+		history   TradeHistory
+		outbox    Outbox
 	}
 
 	// InvalidParameterError is returned when a required parameter passed to New is invalid.
@@ -38,70 +65,370 @@ func (i InvalidParameterError) Error() string {
 	return fmt.Sprintf("invalid parameter %s", i.Parameter)
 }
 
-func New(topic string, publisher Publisher, trader Trader) (*service, error) {
+func New(publisher Publisher, trader Trader, opts ...Option) (*service, error) {
 	switch {
-	case topic == "":
-		return nil, InvalidParameterError{Parameter: "topic"}
 	case publisher == nil:
 		return nil, InvalidParameterError{Parameter: "publisher"}
 	case trader == nil:
 		return nil, InvalidParameterError{Parameter: "trader"}
 	}
 
-	return &service{
-		topic:     topic,
+	s := &service{
 		publisher: publisher,
 		trader:    trader,
-	}, nil
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
 }
 
-func (s *service) Trade(ctx context.Context, tradeType, amount string) error {
-	order, err := s.trader.Trade(trader.TradeType(tradeType), amount)
+// Trade places the order and publishes the trade. Dry-run orders are simulated
+// instead and published directly, as there is nothing to lose if publishing fails.
+// Orders which haven't filled, e.g. limit orders resting on the book, aren't
+// published as nothing has been traded.
+func (s *service) Trade(ctx context.Context, order model.Order) (*trader.TradeResponse, error) {
+	res, found, err := s.findTrade(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		if res, err = s.placeTrade(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+
+	if res.NetBase.IsZero() {
+		return res, nil
+	}
+
+	event, err := publisher.NewEvent(eventSource, events.TradeExecuted, events.TradeExecutedVersion, res.Id, tradeExecuted(res))
 	if err != nil {
-		return fmt.Errorf("trade: %w", err)
+		return nil, fmt.Errorf("new_event: %w", err)
+	}
+	event.Attributes = map[string]string{
+		"productId": res.ProductId,
+		"side":      res.Side,
+	}
+	if res.DryRun {
+		event.Attributes["dryRun"] = "true"
 	}
 
-	b, err := json.Marshal(order)
+	if s.outbox == nil || res.DryRun {
+		err = s.publish(ctx, event)
+	} else {
+		err = s.publishWithOutbox(ctx, event)
+	}
 	if err != nil {
-		return fmt.Errorf("json_marshal: %w", err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// findTrade returns the trade placed by a previous attempt at the order, e.g.
+// one which timed out after placing it, so it isn't placed again. The retry
+// must not be risk checked, as the order it already placed would count against
+// its own limits.
+func (s *service) findTrade(ctx context.Context, order model.Order) (*trader.TradeResponse, bool, error) {
+	if order.DryRun || order.IdempotencyKey == "" {
+		return nil, false, nil
+	}
+
+	res, found, err := s.trader.FindTrade(ctx, trader.ClientOrderId(order.IdempotencyKey))
+	if err != nil {
+		return nil, false, fmt.Errorf("find_trade: %w", err)
+	}
+
+	return res, found, nil
+}
+
+// placeTrade risk checks the order and places it, or simulates it if it is a dry run.
+func (s *service) placeTrade(ctx context.Context, order model.Order) (*trader.TradeResponse, error) {
+	if s.risk != nil {
+		if err := s.risk.Check(ctx, order); err != nil {
+			return nil, fmt.Errorf("risk_check: %w", err)
+		}
+	}
+
+	trade := s.trader.Trade
+	if order.DryRun {
+		trade = s.trader.DryRun
 	}
 
-	_, err = s.publisher.PublishWithContext(ctx, &sns.PublishInput{
-		Message:  aws.String(string(b)),
-		TopicArn: aws.String(s.topic),
+	res, err := trade(ctx, trader.Order{
+		ProductId:     order.ProductId,
+		Side:          trader.TradeType(order.Side),
+		Type:          trader.OrderType(order.Type),
+		Funds:         order.Funds,
+		Size:          order.Size,
+		Price:         order.Price,
+		TimeInForce:   trader.TimeInForce(order.TimeInForce),
+		PostOnly:      order.PostOnly,
+		ClientOrderId: trader.ClientOrderId(order.IdempotencyKey),
 	})
 	if err != nil {
-		return fmt.Errorf("send_message: %w", err)
+		return nil, fmt.Errorf("trade: %w", err)
+	}
+
+	return res, nil
+}
+
+// tradeExecuted returns the TradeExecuted event for the trade.
+func tradeExecuted(res *trader.TradeResponse) events.TradeExecutedV1 {
+	fills := make([]events.FillV1, len(res.Fills))
+	for i, f := range res.Fills {
+		fills[i] = events.FillV1{
+			Id:        f.Id,
+			Price:     f.Price,
+			Size:      f.Size,
+			Fee:       f.Fee,
+			Liquidity: f.Liquidity,
+			CreatedAt: f.CreatedAt,
+		}
+	}
+
+	return events.TradeExecutedV1{
+		Id:             res.Id,
+		Side:           res.Side,
+		Type:           res.Type,
+		ProductId:      res.ProductId,
+		Status:         res.Status,
+		Settled:        res.Settled,
+		Price:          res.Price,
+		Size:           res.Size,
+		StopPrice:      res.StopPrice,
+		TimeInForce:    res.TimeInForce,
+		PostOnly:       res.PostOnly,
+		DryRun:         res.DryRun,
+		CreatedAt:      res.CreatedAt,
+		Funds:          res.Funds,
+		FillFees:       res.FillFees,
+		FilledSize:     res.FilledSize,
+		ExecutedValue:  res.ExecutedValue,
+		EffectivePrice: res.EffectivePrice,
+		FeeRate:        res.FeeRate,
+		NetQuote:       res.NetQuote,
+		NetBase:        res.NetBase,
+		Fills:          fills,
+	}
+}
+
+// publishWithOutbox stores the trade in the outbox before publishing it. If
+// publishing fails the order has still been placed, so the error is logged
+// rather than returned and the trade is left for the relay to publish.
+func (s *service) publishWithOutbox(ctx context.Context, event publisher.Event) error {
+	if err := s.outbox.Add(ctx, event); err != nil {
+		log.Error(ctx, "error_adding_trade_to_outbox", log.ErrorParam(err), log.SafeParam("tradeId", event.Id))
+		return s.publish(ctx, event)
+	}
+
+	if err := s.publish(ctx, event); err != nil {
+		log.Error(ctx, "error_publishing_trade", log.ErrorParam(err), log.SafeParam("tradeId", event.Id))
+		return nil
+	}
+
+	if err := s.outbox.MarkPublished(ctx, event.Id); err != nil {
+		log.Warn(ctx, "error_marking_trade_published", log.ErrorParam(err), log.SafeParam("tradeId", event.Id))
+	}
+
+	return nil
+}
+
+func (s *service) publish(ctx context.Context, event publisher.Event) error {
+	if err := s.publisher.Publish(ctx, event); err != nil {
+		return fmt.Errorf("publish: %w", err)
 	}
 
 	return nil
 }
 
-func (s *service) GetWallet(context.Context) (*model.Wallet, error) {
-	accounts, err := s.trader.GetAccounts()
+func (s *service) GetWallet(ctx context.Context) (model.Wallet, error) {
+	accounts, err := s.trader.GetAccounts(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("get_account: %w", err)
 	}
 
-	var wallet model.Wallet
+	wallet := make(model.Wallet, len(accounts))
 	for _, a := range accounts {
-		if strings.ToLower(a.Currency) == "gbp" {
-			wallet.GBP = model.Account{
-				ID:        a.ID,
-				Balance:   a.Balance,
-				Hold:      a.Hold,
-				Available: a.Available,
-			}
+		wallet[strings.ToLower(a.Currency)] = model.Account{
+			ID:        a.ID,
+			Balance:   a.Balance,
+			Hold:      a.Hold,
+			Available: a.Available,
+		}
+	}
+
+	return wallet, nil
+}
+
+// GetPortfolio values each asset in the wallet in the given currency using the
+// current price. The cost basis and unrealised P&L are only calculated if a
+// trade history has been set, using the average cost of the previous trades.
+func (s *service) GetPortfolio(ctx context.Context, currency string) (model.Portfolio, error) {
+	accounts, err := s.trader.GetAccounts(ctx)
+	if err != nil {
+		return model.Portfolio{}, fmt.Errorf("get_accounts: %w", err)
+	}
+
+	products, err := s.trader.GetProducts(ctx)
+	if err != nil {
+		return model.Portfolio{}, fmt.Errorf("get_products: %w", err)
+	}
+	tradeable := make(map[string]bool, len(products))
+	for _, p := range products {
+		tradeable[strings.ToUpper(p.ID)] = true
+	}
+
+	portfolio := model.Portfolio{Currency: currency}
+	for _, a := range accounts {
+		if a.Balance.IsZero() {
+			continue
+		}
+
+		asset, err := s.valueAsset(ctx, a, currency, tradeable)
+		if err != nil {
+			return model.Portfolio{}, err
+		}
+
+		portfolio.Value = portfolio.Value.Add(asset.Value)
+		portfolio.CostBasis = portfolio.CostBasis.Add(asset.CostBasis)
+		portfolio.UnrealisedPnL = portfolio.UnrealisedPnL.Add(asset.UnrealisedPnL)
+		portfolio.Assets = append(portfolio.Assets, asset)
+	}
+
+	for i, a := range portfolio.Assets {
+		if portfolio.Value.IsPositive() {
+			portfolio.Assets[i].Allocation = a.Value.Div(portfolio.Value).Mul(decimal.New(100, 0)).Round(allocationDecimals)
+		}
+	}
+
+	sort.SliceStable(portfolio.Assets, func(i, j int) bool {
+		return portfolio.Assets[i].Value.GreaterThan(portfolio.Assets[j].Value)
+	})
+
+	return portfolio, nil
+}
+
+func (s *service) valueAsset(ctx context.Context, a trader.Account, currency string, tradeable map[string]bool) (model.Asset, error) {
+	asset := model.Asset{
+		Currency: a.Currency,
+		Balance:  a.Balance,
+	}
+
+	if strings.EqualFold(a.Currency, currency) {
+		asset.Price = decimal.New(1, 0)
+		asset.Value = a.Balance
+		if s.history != nil {
+			asset.CostBasis = a.Balance
 		}
-		if strings.ToLower(a.Currency) == "btc" {
-			wallet.BTC = model.Account{
-				ID:        a.ID,
-				Balance:   a.Balance,
-				Hold:      a.Hold,
-				Available: a.Available,
+		return asset, nil
+	}
+
+	productId := strings.ToUpper(a.Currency + "-" + currency)
+	if !tradeable[productId] {
+		return asset, nil
+	}
+
+	price, err := s.trader.GetPrice(ctx, productId)
+	if err != nil {
+		return model.Asset{}, fmt.Errorf("get_price (%s): %w", productId, err)
+	}
+	asset.Price = price
+	asset.Value = a.Balance.Mul(price)
+
+	if s.history == nil {
+		return asset, nil
+	}
+
+	trades, err := s.history.GetTrades(ctx, productId)
+	if err != nil {
+		return model.Asset{}, fmt.Errorf("get_trades (%s): %w", productId, err)
+	}
+
+	asset.CostBasis = averageCost(trades).Mul(a.Balance)
+	asset.UnrealisedPnL = asset.Value.Sub(asset.CostBasis)
+
+	return asset, nil
+}
+
+// averageCost returns the average cost per unit of the holding built up by the
+// trades. Selling reduces the holding and its cost in proportion, so only buys
+// change the average cost.
+func averageCost(trades []model.Trade) decimal.Decimal {
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].CreatedAt.Before(trades[j].CreatedAt)
+	})
+
+	size, cost := decimal.Zero, decimal.Zero
+	for _, t := range trades {
+		switch t.Side {
+		case string(trader.Buy):
+			size = size.Add(t.Size)
+			cost = cost.Add(t.Cost)
+		case string(trader.Sell):
+			if !size.IsPositive() {
+				continue
+			}
+			if t.Size.GreaterThanOrEqual(size) {
+				size, cost = decimal.Zero, decimal.Zero
+				continue
 			}
+			cost = cost.Sub(cost.Mul(t.Size).Div(size))
+			size = size.Sub(t.Size)
+		}
+	}
+
+	if !size.IsPositive() {
+		return decimal.Zero
+	}
+
+	return cost.Div(size)
+}
+
+func (s *service) CancelOrder(ctx context.Context, id string) error {
+	if err := s.trader.CancelOrder(ctx, id); err != nil {
+		return fmt.Errorf("cancel_order: %w", err)
+	}
+
+	return nil
+}
+
+func (s *service) CancelAllOrders(ctx context.Context, productId string) ([]string, error) {
+	ids, err := s.trader.CancelAllOrders(ctx, productId)
+	if err != nil {
+		return nil, fmt.Errorf("cancel_all_orders: %w", err)
+	}
+
+	return ids, nil
+}
+
+func (s *service) ListOpenOrders(ctx context.Context, productId string) ([]model.OpenOrder, error) {
+	res, err := s.trader.ListOpenOrders(ctx, productId)
+	if err != nil {
+		return nil, fmt.Errorf("list_open_orders: %w", err)
+	}
+
+	orders := make([]model.OpenOrder, len(res))
+	for i, o := range res {
+		orders[i] = model.OpenOrder{
+			ID:          o.Id,
+			ProductId:   o.ProductId,
+			Side:        o.Side,
+			Type:        o.Type,
+			Status:      o.Status,
+			Funds:       o.Funds,
+			Size:        o.Size,
+			Price:       o.Price,
+			StopPrice:   o.StopPrice,
+			TimeInForce: o.TimeInForce,
+			PostOnly:    o.PostOnly,
+			FilledSize:  o.FilledSize,
+			CreatedAt:   o.CreatedAt,
 		}
 	}
 
-	return &wallet, nil
+	return orders, nil
 }
@@ -4,33 +4,36 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/mocks/history"
+	"github.com/cshep4/kripto/services/trader/internal/mocks/outbox"
 	"github.com/cshep4/kripto/services/trader/internal/mocks/publish"
+	"github.com/cshep4/kripto/services/trader/internal/mocks/risk"
 	"github.com/cshep4/kripto/services/trader/internal/mocks/trader"
+	"github.com/cshep4/kripto/services/trader/internal/model"
+	"github.com/cshep4/kripto/services/trader/internal/risk"
 	"github.com/cshep4/kripto/services/trader/internal/service"
 	trade "github.com/cshep4/kripto/services/trader/internal/trader"
+	"github.com/cshep4/kripto/shared/go/events"
+	"github.com/cshep4/kripto/shared/go/events/eventstest"
+	"github.com/cshep4/kripto/shared/go/publisher"
 	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestNew(t *testing.T) {
-	t.Run("returns error if topic is empty", func(t *testing.T) {
-		s, err := service.New("", nil, nil)
-		require.Error(t, err)
-
-		assert.Nil(t, s)
-
-		ipErr, ok := err.(service.InvalidParameterError)
-		assert.True(t, ok)
-		assert.Equal(t, "topic", ipErr.Parameter)
-	})
+// filled is the base amount of a trade which has been filled, as trades with
+// nothing filled aren't published.
+var filled = decimal.RequireFromString("0.0025")
 
+func TestNew(t *testing.T) {
 	t.Run("returns error if publisher is empty", func(t *testing.T) {
-		s, err := service.New("url", nil, nil)
+		s, err := service.New(nil, nil)
 		require.Error(t, err)
 
 		assert.Nil(t, s)
@@ -46,7 +49,7 @@ func TestNew(t *testing.T) {
 
 		publisher := publish_mocks.NewMockPublisher(ctrl)
 
-		s, err := service.New("url", publisher, nil)
+		s, err := service.New(publisher, nil)
 		require.Error(t, err)
 
 		assert.Nil(t, s)
@@ -63,7 +66,7 @@ func TestNew(t *testing.T) {
 		publisher := publish_mocks.NewMockPublisher(ctrl)
 		trader := trader_mocks.NewMockTrader(ctrl)
 
-		s, err := service.New("url", publisher, trader)
+		s, err := service.New(publisher, trader)
 		require.NoError(t, err)
 
 		assert.NotNil(t, s)
@@ -71,6 +74,163 @@ func TestNew(t *testing.T) {
 }
 
 func TestService_Trade(t *testing.T) {
+	t.Run("returns error and does not trade if risk check fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+		checker := risk_mocks.NewMockRiskChecker(ctrl)
+
+		order := model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "100"}
+		rejectErr := risk.RejectedError{Check: risk.KillSwitch, Reason: "trading is disabled"}
+		ctx := context.Background()
+
+		checker.EXPECT().Check(ctx, order).Return(rejectErr)
+
+		s, err := service.New(publisher, trader, service.WithRiskChecker(checker))
+		require.NoError(t, err)
+
+		_, err = s.Trade(ctx, order)
+		require.Error(t, err)
+
+		var rErr risk.RejectedError
+		require.True(t, errors.As(err, &rErr))
+		assert.Equal(t, rejectErr, rErr)
+	})
+
+	t.Run("trades if risk check passes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+		checker := risk_mocks.NewMockRiskChecker(ctrl)
+
+		order := model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "100"}
+		ctx := context.Background()
+
+		gomock.InOrder(
+			checker.EXPECT().Check(ctx, order).Return(nil),
+			trader.EXPECT().Trade(gomock.Any(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "100"}).Return(&trade.TradeResponse{Id: "tradeId", NetBase: filled}, nil),
+			publisher.EXPECT().Publish(ctx, gomock.Any()).Return(nil),
+		)
+
+		s, err := service.New(publisher, trader, service.WithRiskChecker(checker))
+		require.NoError(t, err)
+
+		_, err = s.Trade(ctx, order)
+		require.NoError(t, err)
+	})
+
+	t.Run("returns order already placed with idempotency key without risk checking it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+		checker := risk_mocks.NewMockRiskChecker(ctrl)
+
+		const idempotencyKey = "aa368788-bb4f-40c0-b80f-afcfdaf18574"
+
+		order := model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "100", IdempotencyKey: idempotencyKey}
+		res := &trade.TradeResponse{Id: "tradeId", ProductId: "BTC-GBP", Side: "buy", NetBase: filled}
+		ctx := context.Background()
+
+		gomock.InOrder(
+			trader.EXPECT().FindTrade(ctx, idempotencyKey).Return(res, true, nil),
+			publisher.EXPECT().Publish(ctx, tradeEvent(res)).Return(nil),
+		)
+
+		s, err := service.New(publisher, trader, service.WithRiskChecker(checker))
+		require.NoError(t, err)
+
+		trade, err := s.Trade(ctx, order)
+		require.NoError(t, err)
+
+		assert.Equal(t, res, trade)
+	})
+
+	t.Run("returns order already placed with idempotency key without risk checker", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+
+		const idempotencyKey = "aa368788-bb4f-40c0-b80f-afcfdaf18574"
+
+		order := model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "100", IdempotencyKey: idempotencyKey}
+		res := &trade.TradeResponse{Id: "tradeId", ProductId: "BTC-GBP", Side: "buy", NetBase: filled}
+		ctx := context.Background()
+
+		gomock.InOrder(
+			trader.EXPECT().FindTrade(ctx, idempotencyKey).Return(res, true, nil),
+			publisher.EXPECT().Publish(ctx, tradeEvent(res)).Return(nil),
+		)
+
+		s, err := service.New(publisher, trader)
+		require.NoError(t, err)
+
+		trade, err := s.Trade(ctx, order)
+		require.NoError(t, err)
+
+		assert.Equal(t, res, trade)
+	})
+
+	t.Run("risk checks and trades if order not already placed with idempotency key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+		checker := risk_mocks.NewMockRiskChecker(ctrl)
+
+		const idempotencyKey = "aa368788-bb4f-40c0-b80f-afcfdaf18574"
+
+		order := model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "100", IdempotencyKey: idempotencyKey}
+		ctx := context.Background()
+
+		gomock.InOrder(
+			trader.EXPECT().FindTrade(ctx, idempotencyKey).Return(nil, false, nil),
+			checker.EXPECT().Check(ctx, order).Return(nil),
+			trader.EXPECT().Trade(gomock.Any(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "100", ClientOrderId: idempotencyKey}).Return(&trade.TradeResponse{Id: "tradeId", NetBase: filled}, nil),
+			publisher.EXPECT().Publish(ctx, gomock.Any()).Return(nil),
+		)
+
+		s, err := service.New(publisher, trader, service.WithRiskChecker(checker))
+		require.NoError(t, err)
+
+		_, err = s.Trade(ctx, order)
+		require.NoError(t, err)
+	})
+
+	t.Run("returns error and does not trade if error finding order placed with idempotency key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+		checker := risk_mocks.NewMockRiskChecker(ctrl)
+
+		const idempotencyKey = "aa368788-bb4f-40c0-b80f-afcfdaf18574"
+
+		order := model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "100", IdempotencyKey: idempotencyKey}
+		testErr := errors.New("error")
+		ctx := context.Background()
+
+		trader.EXPECT().FindTrade(ctx, idempotencyKey).Return(nil, false, testErr)
+
+		s, err := service.New(publisher, trader, service.WithRiskChecker(checker))
+		require.NoError(t, err)
+
+		res, err := s.Trade(ctx, order)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Nil(t, res)
+	})
+
 	t.Run("returns error if error trading", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -80,17 +240,16 @@ func TestService_Trade(t *testing.T) {
 
 		const (
 			amount    = "amount"
-			url       = "url"
 			tradeType = "type"
 		)
 		testErr := errors.New("error")
 
-		trader.EXPECT().Trade(trade.TradeType(tradeType), amount).Return(nil, testErr)
+		trader.EXPECT().Trade(gomock.Any(), trade.Order{Side: trade.TradeType(tradeType), Funds: amount}).Return(nil, testErr)
 
-		s, err := service.New(url, publisher, trader)
+		s, err := service.New(publisher, trader)
 		require.NoError(t, err)
 
-		err = s.Trade(context.Background(), tradeType, amount)
+		_, err = s.Trade(context.Background(), model.Order{Side: tradeType, Funds: amount})
 		require.Error(t, err)
 
 		assert.True(t, errors.Is(err, testErr))
@@ -105,31 +264,25 @@ func TestService_Trade(t *testing.T) {
 
 		const (
 			amount    = "amount"
-			topic     = "topic"
 			tradeType = "type"
 			id        = "tradeId"
 		)
 		testErr := errors.New("error")
 
 		order := &trade.TradeResponse{
-			Id: id,
-		}
-		b, err := json.Marshal(order)
-		require.NoError(t, err)
-
-		publishInput := &sns.PublishInput{
-			Message:  aws.String(string(b)),
-			TopicArn: aws.String(topic),
+			Id:      id,
+			NetBase: filled,
 		}
+		event := tradeEvent(order)
 		ctx := context.Background()
 
-		trader.EXPECT().Trade(trade.TradeType(tradeType), amount).Return(order, nil)
-		publisher.EXPECT().PublishWithContext(ctx, publishInput).Return(nil, testErr)
+		trader.EXPECT().Trade(gomock.Any(), trade.Order{Side: trade.TradeType(tradeType), Funds: amount}).Return(order, nil)
+		publisher.EXPECT().Publish(ctx, event).Return(testErr)
 
-		s, err := service.New(topic, publisher, trader)
+		s, err := service.New(publisher, trader)
 		require.NoError(t, err)
 
-		err = s.Trade(ctx, tradeType, amount)
+		_, err = s.Trade(ctx, model.Order{Side: tradeType, Funds: amount})
 		require.Error(t, err)
 
 		assert.True(t, errors.Is(err, testErr))
@@ -144,30 +297,706 @@ func TestService_Trade(t *testing.T) {
 
 		const (
 			amount    = "amount"
-			topic     = "topic"
 			tradeType = "type"
 			id        = "tradeId"
+
+			idempotencyKey = "aa368788-bb4f-40c0-b80f-afcfdaf18574"
 		)
 
 		order := &trade.TradeResponse{
-			Id: id,
+			Id:        id,
+			ProductId: "BTC-GBP",
+			Side:      "buy",
+			NetBase:   filled,
+		}
+		event := tradeEvent(order)
+		ctx := context.Background()
+
+		trader.EXPECT().FindTrade(ctx, idempotencyKey).Return(nil, false, nil)
+		trader.EXPECT().Trade(gomock.Any(), trade.Order{Side: trade.TradeType(tradeType), Funds: amount, ClientOrderId: idempotencyKey}).Return(order, nil)
+		publisher.EXPECT().Publish(ctx, event).Return(nil)
+
+		s, err := service.New(publisher, trader)
+		require.NoError(t, err)
+
+		_, err = s.Trade(ctx, model.Order{Side: tradeType, Funds: amount, IdempotencyKey: idempotencyKey})
+		require.NoError(t, err)
+	})
+
+	t.Run("returns trade without publishing it if nothing has been filled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+		outbox := outbox_mocks.NewMockOutbox(ctrl)
+
+		order := model.Order{ProductId: "BTC-GBP", Side: "buy", Type: "limit", Size: "0.01", Price: "30000"}
+		res := &trade.TradeResponse{Id: "tradeId", ProductId: "BTC-GBP", Side: "buy", Type: "limit", Status: exchange.StatusOpen}
+
+		trader.EXPECT().Trade(gomock.Any(), gomock.Any()).Return(res, nil)
+
+		s, err := service.New(publisher, trader, service.WithOutbox(outbox))
+		require.NoError(t, err)
+
+		trade, err := s.Trade(context.Background(), order)
+		require.NoError(t, err)
+
+		assert.Equal(t, res, trade)
+	})
+}
+
+func TestService_Trade_DryRun(t *testing.T) {
+	t.Run("simulates trade and publishes it as a dry run without using the outbox", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		trader := trader_mocks.NewMockTrader(ctrl)
+		checker := risk_mocks.NewMockRiskChecker(ctrl)
+		outbox := outbox_mocks.NewMockOutbox(ctrl)
+		publisher := publisher.NewMemory()
+
+		order := model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "100", DryRun: true}
+		res := &trade.TradeResponse{Id: "dry-run-tradeId", ProductId: "BTC-GBP", Side: "buy", DryRun: true, NetBase: filled}
+		ctx := context.Background()
+
+		gomock.InOrder(
+			checker.EXPECT().Check(ctx, order).Return(nil),
+			trader.EXPECT().DryRun(gomock.Any(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "100"}).Return(res, nil),
+		)
+
+		s, err := service.New(publisher, trader, service.WithRiskChecker(checker), service.WithOutbox(outbox))
+		require.NoError(t, err)
+
+		trade, err := s.Trade(ctx, order)
+		require.NoError(t, err)
+
+		assert.Equal(t, res, trade)
+
+		published := publisher.Events()
+		require.Len(t, published, 1)
+		assert.Equal(t, "true", published[0].Attributes["dryRun"])
+
+		e, err := events.Decode(published[0].Type, published[0].Version, published[0].Data)
+		require.NoError(t, err)
+		assert.True(t, e.(*events.TradeExecutedV1).DryRun)
+	})
+
+	t.Run("returns error and does not simulate trade if risk check fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+		checker := risk_mocks.NewMockRiskChecker(ctrl)
+
+		order := model.Order{ProductId: "BTC-GBP", Side: "buy", Funds: "100", DryRun: true}
+		rejectErr := risk.RejectedError{Check: risk.KillSwitch, Reason: "trading is disabled"}
+		ctx := context.Background()
+
+		checker.EXPECT().Check(ctx, order).Return(rejectErr)
+
+		s, err := service.New(publisher, trader, service.WithRiskChecker(checker))
+		require.NoError(t, err)
+
+		res, err := s.Trade(ctx, order)
+		require.Error(t, err)
+
+		assert.Nil(t, res)
+		assert.True(t, errors.As(err, &risk.RejectedError{}))
+	})
+}
+
+// TestService_Trade_Schema fails if the trade published no longer matches the
+// TradeExecuted schema read by the consumers, e.g. a field is renamed.
+func TestService_Trade_Schema(t *testing.T) {
+	t.Run("publishes trade as latest version of TradeExecuted event", func(t *testing.T) {
+		for _, e := range eventstest.TradeExecutedV1Examples() {
+			ctrl := gomock.NewController(t)
+
+			trader := trader_mocks.NewMockTrader(ctrl)
+			publisher := publisher.NewMemory()
+
+			var res trade.TradeResponse
+			err := json.Unmarshal([]byte(e.JSON), &res)
+			require.NoError(t, err, e.Name)
+
+			// trades with nothing filled aren't published
+			if res.NetBase.IsZero() {
+				ctrl.Finish()
+				continue
+			}
+
+			trader.EXPECT().Trade(gomock.Any(), gomock.Any()).Return(&res, nil)
+
+			s, err := service.New(publisher, trader)
+			require.NoError(t, err, e.Name)
+
+			_, err = s.Trade(context.Background(), model.Order{Side: "buy", Funds: "402.00"})
+			require.NoError(t, err, e.Name)
+
+			published := publisher.Events()
+			require.Len(t, published, 1, e.Name)
+
+			assert.Equal(t, events.TradeExecuted, published[0].Type, e.Name)
+			assert.Equal(t, events.TradeExecutedVersion, published[0].Version, e.Name)
+			assert.JSONEq(t, e.JSON, string(published[0].Data), e.Name)
+
+			_, err = events.Decode(published[0].Type, published[0].Version, published[0].Data)
+			require.NoError(t, err, e.Name)
+
+			ctrl.Finish()
 		}
-		b, err := json.Marshal(order)
+	})
+}
+
+func TestService_Trade_Outbox(t *testing.T) {
+	t.Run("adds trade to outbox and marks it published", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+		outbox := outbox_mocks.NewMockOutbox(ctrl)
+
+		const id = "tradeId"
+
+		res := &trade.TradeResponse{Id: id, NetBase: filled}
+		event := tradeEvent(res)
+		ctx := context.Background()
+
+		trader.EXPECT().Trade(gomock.Any(), gomock.Any()).Return(res, nil)
+		gomock.InOrder(
+			outbox.EXPECT().Add(ctx, event).Return(nil),
+			publisher.EXPECT().Publish(ctx, event).Return(nil),
+			outbox.EXPECT().MarkPublished(ctx, id).Return(nil),
+		)
+
+		s, err := service.New(publisher, trader, service.WithOutbox(outbox))
+		require.NoError(t, err)
+
+		_, err = s.Trade(ctx, model.Order{Side: "buy", Funds: "10"})
+		require.NoError(t, err)
+	})
+
+	t.Run("returns nil and leaves trade in outbox if error sending message", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+		outbox := outbox_mocks.NewMockOutbox(ctrl)
+
+		const id = "tradeId"
+		testErr := errors.New("error")
+
+		res := &trade.TradeResponse{Id: id, NetBase: filled}
+		event := tradeEvent(res)
+		ctx := context.Background()
+
+		trader.EXPECT().Trade(gomock.Any(), gomock.Any()).Return(res, nil)
+		gomock.InOrder(
+			outbox.EXPECT().Add(ctx, event).Return(nil),
+			publisher.EXPECT().Publish(ctx, event).Return(testErr),
+		)
+
+		s, err := service.New(publisher, trader, service.WithOutbox(outbox))
+		require.NoError(t, err)
+
+		_, err = s.Trade(ctx, model.Order{Side: "buy", Funds: "10"})
+		require.NoError(t, err)
+	})
+
+	t.Run("returns nil if error marking trade published", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+		outbox := outbox_mocks.NewMockOutbox(ctrl)
+
+		const id = "tradeId"
+		testErr := errors.New("error")
+
+		res := &trade.TradeResponse{Id: id, NetBase: filled}
+		event := tradeEvent(res)
+		ctx := context.Background()
+
+		trader.EXPECT().Trade(gomock.Any(), gomock.Any()).Return(res, nil)
+		gomock.InOrder(
+			outbox.EXPECT().Add(ctx, event).Return(nil),
+			publisher.EXPECT().Publish(ctx, event).Return(nil),
+			outbox.EXPECT().MarkPublished(ctx, id).Return(testErr),
+		)
+
+		s, err := service.New(publisher, trader, service.WithOutbox(outbox))
+		require.NoError(t, err)
+
+		_, err = s.Trade(ctx, model.Order{Side: "buy", Funds: "10"})
+		require.NoError(t, err)
+	})
+
+	t.Run("publishes trade if error adding trade to outbox", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+		outbox := outbox_mocks.NewMockOutbox(ctrl)
+
+		const id = "tradeId"
+		testErr := errors.New("error")
+
+		res := &trade.TradeResponse{Id: id, NetBase: filled}
+		event := tradeEvent(res)
+		ctx := context.Background()
+
+		trader.EXPECT().Trade(gomock.Any(), gomock.Any()).Return(res, nil)
+		gomock.InOrder(
+			outbox.EXPECT().Add(ctx, event).Return(testErr),
+			publisher.EXPECT().Publish(ctx, event).Return(nil),
+		)
+
+		s, err := service.New(publisher, trader, service.WithOutbox(outbox))
+		require.NoError(t, err)
+
+		_, err = s.Trade(ctx, model.Order{Side: "buy", Funds: "10"})
 		require.NoError(t, err)
+	})
+
+	t.Run("returns error if error adding trade to outbox and sending message", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+		outbox := outbox_mocks.NewMockOutbox(ctrl)
+
+		const id = "tradeId"
+		testErr := errors.New("error")
+
+		res := &trade.TradeResponse{Id: id, NetBase: filled}
+		event := tradeEvent(res)
+		ctx := context.Background()
+
+		trader.EXPECT().Trade(gomock.Any(), gomock.Any()).Return(res, nil)
+		gomock.InOrder(
+			outbox.EXPECT().Add(ctx, event).Return(testErr),
+			publisher.EXPECT().Publish(ctx, event).Return(testErr),
+		)
+
+		s, err := service.New(publisher, trader, service.WithOutbox(outbox))
+		require.NoError(t, err)
+
+		_, err = s.Trade(ctx, model.Order{Side: "buy", Funds: "10"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+}
+
+func TestService_GetWallet(t *testing.T) {
+	t.Run("returns error if error getting accounts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
 
-		publishInput := &sns.PublishInput{
-			Message:  aws.String(string(b)),
-			TopicArn: aws.String(topic),
+		testErr := errors.New("error")
+
+		trader.EXPECT().GetAccounts(gomock.Any()).Return(nil, testErr)
+
+		s, err := service.New(publisher, trader)
+		require.NoError(t, err)
+
+		wallet, err := s.GetWallet(context.Background())
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Nil(t, wallet)
+	})
+
+	t.Run("returns account for each currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+
+		var (
+			ten  = decimal.RequireFromString("10")
+			one  = decimal.RequireFromString("1")
+			half = decimal.RequireFromString("0.5")
+			two  = decimal.RequireFromString("2.00000001")
+		)
+
+		trader.EXPECT().GetAccounts(gomock.Any()).Return([]trade.Account{
+			{ID: "gbpId", Currency: "GBP", Balance: ten, Available: ten},
+			{ID: "btcId", Currency: "BTC", Balance: one, Hold: half, Available: half},
+			{ID: "ethId", Currency: "ETH", Balance: two, Available: two},
+		}, nil)
+
+		s, err := service.New(publisher, trader)
+		require.NoError(t, err)
+
+		wallet, err := s.GetWallet(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, model.Wallet{
+			"gbp": {ID: "gbpId", Balance: ten, Available: ten},
+			"btc": {ID: "btcId", Balance: one, Hold: half, Available: half},
+			"eth": {ID: "ethId", Balance: two, Available: two},
+		}, wallet)
+	})
+}
+
+func TestService_GetPortfolio(t *testing.T) {
+	var (
+		products = []exchange.Product{{ID: "BTC-GBP"}, {ID: "ETH-BTC"}}
+		gbp      = decimal.RequireFromString("100")
+		btc      = decimal.RequireFromString("0.5")
+		doge     = decimal.RequireFromString("10")
+		price    = decimal.RequireFromString("40000")
+		accounts = []trade.Account{
+			{ID: "gbpId", Currency: "GBP", Balance: gbp},
+			{ID: "btcId", Currency: "BTC", Balance: btc},
+			{ID: "ethId", Currency: "ETH", Balance: decimal.Zero},
+			{ID: "dogeId", Currency: "DOGE", Balance: doge},
 		}
+	)
+
+	t.Run("returns error if error getting accounts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+
+		testErr := errors.New("error")
+
+		trader.EXPECT().GetAccounts(gomock.Any()).Return(nil, testErr)
+
+		s, err := service.New(publisher, trader)
+		require.NoError(t, err)
+
+		portfolio, err := s.GetPortfolio(context.Background(), "GBP")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Empty(t, portfolio)
+	})
+
+	t.Run("returns error if error getting products", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+
+		testErr := errors.New("error")
+
+		trader.EXPECT().GetAccounts(gomock.Any()).Return(accounts, nil)
+		trader.EXPECT().GetProducts(gomock.Any()).Return(nil, testErr)
+
+		s, err := service.New(publisher, trader)
+		require.NoError(t, err)
+
+		portfolio, err := s.GetPortfolio(context.Background(), "GBP")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Empty(t, portfolio)
+	})
+
+	t.Run("returns error if error getting price", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+
+		testErr := errors.New("error")
+
+		trader.EXPECT().GetAccounts(gomock.Any()).Return(accounts, nil)
+		trader.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		trader.EXPECT().GetPrice(gomock.Any(), "BTC-GBP").Return(decimal.Zero, testErr)
+
+		s, err := service.New(publisher, trader)
+		require.NoError(t, err)
+
+		portfolio, err := s.GetPortfolio(context.Background(), "GBP")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Empty(t, portfolio)
+	})
+
+	t.Run("returns error if error getting trades", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+		history := history_mocks.NewMockTradeHistory(ctrl)
+
+		testErr := errors.New("error")
+
+		trader.EXPECT().GetAccounts(ctx).Return(accounts, nil)
+		trader.EXPECT().GetProducts(ctx).Return(products, nil)
+		trader.EXPECT().GetPrice(ctx, "BTC-GBP").Return(price, nil)
+		history.EXPECT().GetTrades(ctx, "BTC-GBP").Return(nil, testErr)
+
+		s, err := service.New(publisher, trader, service.WithTradeHistory(history))
+		require.NoError(t, err)
+
+		portfolio, err := s.GetPortfolio(ctx, "GBP")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Empty(t, portfolio)
+	})
+
+	t.Run("returns portfolio without cost basis if no trade history", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+
+		trader.EXPECT().GetAccounts(gomock.Any()).Return(accounts, nil)
+		trader.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		trader.EXPECT().GetPrice(gomock.Any(), "BTC-GBP").Return(price, nil)
+
+		s, err := service.New(publisher, trader)
+		require.NoError(t, err)
+
+		portfolio, err := s.GetPortfolio(context.Background(), "GBP")
+		require.NoError(t, err)
+
+		assert.Equal(t, "20100", portfolio.Value.String())
+		assert.True(t, portfolio.CostBasis.IsZero())
+		assert.True(t, portfolio.UnrealisedPnL.IsZero())
+	})
+
+	t.Run("returns valued portfolio with unrealised pnl", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
 		ctx := context.Background()
+		now := time.Now()
 
-		trader.EXPECT().Trade(trade.TradeType(tradeType), amount).Return(order, nil)
-		publisher.EXPECT().PublishWithContext(ctx, publishInput).Return(nil, nil)
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+		history := history_mocks.NewMockTradeHistory(ctrl)
+
+		trader.EXPECT().GetAccounts(ctx).Return(accounts, nil)
+		trader.EXPECT().GetProducts(ctx).Return(products, nil)
+		trader.EXPECT().GetPrice(ctx, "BTC-GBP").Return(price, nil)
+		history.EXPECT().GetTrades(ctx, "BTC-GBP").Return([]model.Trade{
+			{Side: "buy", CreatedAt: now, Size: decimal.RequireFromString("0.5"), Cost: decimal.RequireFromString("15000")},
+			{Side: "buy", CreatedAt: now.Add(-2 * time.Hour), Size: decimal.RequireFromString("1"), Cost: decimal.RequireFromString("30000")},
+			{Side: "sell", CreatedAt: now.Add(-time.Hour), Size: decimal.RequireFromString("0.5"), Cost: decimal.RequireFromString("20000")},
+		}, nil)
+
+		s, err := service.New(publisher, trader, service.WithTradeHistory(history))
+		require.NoError(t, err)
 
-		s, err := service.New(topic, publisher, trader)
+		portfolio, err := s.GetPortfolio(ctx, "GBP")
 		require.NoError(t, err)
 
-		err = s.Trade(ctx, tradeType, amount)
+		assert.Equal(t, "GBP", portfolio.Currency)
+		assert.Equal(t, "20100", portfolio.Value.String())
+		assert.Equal(t, "15100", portfolio.CostBasis.String())
+		assert.Equal(t, "5000", portfolio.UnrealisedPnL.String())
+
+		require.Len(t, portfolio.Assets, 3)
+
+		assert.Equal(t, "BTC", portfolio.Assets[0].Currency)
+		assert.Equal(t, "40000", portfolio.Assets[0].Price.String())
+		assert.Equal(t, "20000", portfolio.Assets[0].Value.String())
+		assert.Equal(t, "99.5", portfolio.Assets[0].Allocation.String())
+		assert.Equal(t, "15000", portfolio.Assets[0].CostBasis.String())
+		assert.Equal(t, "5000", portfolio.Assets[0].UnrealisedPnL.String())
+
+		assert.Equal(t, "GBP", portfolio.Assets[1].Currency)
+		assert.Equal(t, "100", portfolio.Assets[1].Value.String())
+		assert.Equal(t, "0.5", portfolio.Assets[1].Allocation.String())
+
+		assert.Equal(t, "DOGE", portfolio.Assets[2].Currency)
+		assert.True(t, portfolio.Assets[2].Value.IsZero())
+		assert.True(t, portfolio.Assets[2].Allocation.IsZero())
+	})
+}
+
+func TestService_CancelOrder(t *testing.T) {
+	t.Run("returns error if error cancelling order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+
+		testErr := errors.New("error")
+
+		trader.EXPECT().CancelOrder(gomock.Any(), "id").Return(testErr)
+
+		s, err := service.New(publisher, trader)
+		require.NoError(t, err)
+
+		err = s.CancelOrder(context.Background(), "id")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("returns nil if order cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+
+		trader.EXPECT().CancelOrder(gomock.Any(), "id").Return(nil)
+
+		s, err := service.New(publisher, trader)
+		require.NoError(t, err)
+
+		err = s.CancelOrder(context.Background(), "id")
 		require.NoError(t, err)
 	})
 }
+
+func TestService_CancelAllOrders(t *testing.T) {
+	t.Run("returns error if error cancelling orders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+
+		testErr := errors.New("error")
+
+		trader.EXPECT().CancelAllOrders(gomock.Any(), "BTC-GBP").Return(nil, testErr)
+
+		s, err := service.New(publisher, trader)
+		require.NoError(t, err)
+
+		ids, err := s.CancelAllOrders(context.Background(), "BTC-GBP")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Empty(t, ids)
+	})
+
+	t.Run("returns cancelled order ids", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+
+		trader.EXPECT().CancelAllOrders(gomock.Any(), "BTC-GBP").Return([]string{"id"}, nil)
+
+		s, err := service.New(publisher, trader)
+		require.NoError(t, err)
+
+		ids, err := s.CancelAllOrders(context.Background(), "BTC-GBP")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"id"}, ids)
+	})
+}
+
+func TestService_ListOpenOrders(t *testing.T) {
+	t.Run("returns error if error listing orders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+
+		testErr := errors.New("error")
+
+		trader.EXPECT().ListOpenOrders(gomock.Any(), "").Return(nil, testErr)
+
+		s, err := service.New(publisher, trader)
+		require.NoError(t, err)
+
+		orders, err := s.ListOpenOrders(context.Background(), "")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Empty(t, orders)
+	})
+
+	t.Run("returns open orders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		publisher := publish_mocks.NewMockPublisher(ctrl)
+		trader := trader_mocks.NewMockTrader(ctrl)
+
+		trader.EXPECT().ListOpenOrders(gomock.Any(), "BTC-GBP").Return([]trade.TradeResponse{{
+			Id:        "id",
+			ProductId: "BTC-GBP",
+			Side:      "buy",
+			Type:      "limit",
+			Status:    "open",
+			Price:     "8000.00",
+			Size:      "0.1",
+		}}, nil)
+
+		s, err := service.New(publisher, trader)
+		require.NoError(t, err)
+
+		orders, err := s.ListOpenOrders(context.Background(), "BTC-GBP")
+		require.NoError(t, err)
+
+		assert.Equal(t, []model.OpenOrder{{
+			ID:        "id",
+			ProductId: "BTC-GBP",
+			Side:      "buy",
+			Type:      "limit",
+			Status:    "open",
+			Price:     "8000.00",
+			Size:      "0.1",
+		}}, orders)
+	})
+}
+
+// eventMatcher matches the TradeExecuted event published for the trade,
+// ignoring the time the event was created.
+type eventMatcher struct {
+	trade *trade.TradeResponse
+}
+
+func tradeEvent(res *trade.TradeResponse) gomock.Matcher {
+	return eventMatcher{trade: res}
+}
+
+func (m eventMatcher) Matches(x interface{}) bool {
+	e, ok := x.(publisher.Event)
+	if !ok {
+		return false
+	}
+
+	b, err := json.Marshal(m.trade)
+	if err != nil {
+		return false
+	}
+
+	return e.Id == m.trade.Id &&
+		e.Type == events.TradeExecuted &&
+		e.Version == 1 &&
+		e.Source == "trader" &&
+		e.Attributes["productId"] == m.trade.ProductId &&
+		e.Attributes["side"] == m.trade.Side &&
+		string(e.Data) == string(b)
+}
+
+func (m eventMatcher) String() string {
+	return fmt.Sprintf("is TradeExecuted event for trade %s", m.trade.Id)
+}
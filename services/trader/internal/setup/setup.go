@@ -0,0 +1,159 @@
+// Package setup builds the exchange used by each of the trader's functions from
+// the configuration, so they all trade on the same venue.
+package setup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange/coinbase"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/fake"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/kraken"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/paper"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/retry"
+	"github.com/cshep4/kripto/services/trader/internal/secrets"
+	paperstore "github.com/cshep4/kripto/services/trader/internal/store/paper/mongo"
+	ratestore "github.com/cshep4/kripto/services/trader/internal/store/rate/mongo"
+	"github.com/cshep4/kripto/services/trader/internal/trader"
+	"github.com/cshep4/kripto/shared/go/mongodb"
+	"github.com/preichenberger/go-coinbasepro/v2"
+	"github.com/shopspring/decimal"
+)
+
+const coinbaseTimeout = 15 * time.Second
+
+// Exchange returns the exchange set by EXCHANGE, retrying calls which fail with
+// a transient error.
+func Exchange(ctx context.Context, s secrets.Secrets) (trader.Exchange, error) {
+	ex, err := newExchange(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("initialise_exchange: %w", err)
+	}
+
+	ex, err = retry.New(ex,
+		retry.WithMaxAttempts(s.Retry.MaxAttempts),
+		retry.WithBackoff(s.Retry.BaseDelay, s.Retry.MaxDelay),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("initialise_retrier: %w", err)
+	}
+
+	return ex, nil
+}
+
+func newExchange(ctx context.Context, s secrets.Secrets) (trader.Exchange, error) {
+	switch s.Exchange {
+	case secrets.Kraken:
+		return kraken.New(s.Kraken.Key, s.Kraken.Secret)
+	case secrets.Paper:
+		return newPaperExchange(ctx, s)
+	case secrets.Fake:
+		return newFakeExchange(s)
+	default:
+		return newCoinbaseExchange(s)
+	}
+}
+
+func newFakeExchange(s secrets.Secrets) (trader.Exchange, error) {
+	opts := []fake.Option{fake.WithLatency(s.Fake.Latency)}
+
+	prices, err := secrets.ParseAmounts(s.Fake.Prices)
+	if err != nil {
+		return nil, fmt.Errorf("FAKE_PRICES: %w", err)
+	}
+	for productId, price := range prices {
+		opts = append(opts, fake.WithPrice(productId, price))
+	}
+
+	balances, err := secrets.ParseAmounts(s.Fake.Balances)
+	if err != nil {
+		return nil, fmt.Errorf("FAKE_BALANCES: %w", err)
+	}
+	for currency, balance := range balances {
+		opts = append(opts, fake.WithBalance(currency, balance))
+	}
+
+	feeRate, err := parseDecimal(s.Fake.FeeRate)
+	if err != nil {
+		return nil, fmt.Errorf("FAKE_FEE_RATE: %w", err)
+	}
+	fillRatio, err := parseDecimal(s.Fake.FillRatio)
+	if err != nil {
+		return nil, fmt.Errorf("FAKE_FILL_RATIO: %w", err)
+	}
+
+	return fake.New(append(opts, fake.WithFeeRate(feeRate), fake.WithFillRatio(fillRatio))...), nil
+}
+
+func newPaperExchange(ctx context.Context, s secrets.Secrets) (trader.Exchange, error) {
+	mongoClient, err := mongodb.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("initialise_mongo_client: %w", err)
+	}
+
+	store, err := paperstore.New(ctx, mongoClient)
+	if err != nil {
+		return nil, fmt.Errorf("initialise_paper_store: %w", err)
+	}
+	rates, err := ratestore.New(ctx, mongoClient)
+	if err != nil {
+		return nil, fmt.Errorf("initialise_rate_store: %w", err)
+	}
+
+	opts := []paper.Option{paper.WithMaxRateAge(s.Paper.MaxRateAge)}
+
+	balances, err := secrets.ParseAmounts(s.Paper.Balances)
+	if err != nil {
+		return nil, fmt.Errorf("PAPER_BALANCES: %w", err)
+	}
+	for currency, balance := range balances {
+		opts = append(opts, paper.WithBalance(currency, balance))
+	}
+
+	if s.Paper.FeeRate != "" {
+		feeRate, err := decimal.NewFromString(s.Paper.FeeRate)
+		if err != nil {
+			return nil, fmt.Errorf("PAPER_FEE_RATE: %w", err)
+		}
+		opts = append(opts, paper.WithFeeRate(feeRate))
+	}
+
+	return paper.New(ctx, store, rates, opts...)
+}
+
+// newCoinbaseExchange returns the Coinbase Pro exchange, which reloads its
+// credentials from the secret provider so rotated credentials are picked up by
+// warm functions. They are reloaded within the context of the request.
+func newCoinbaseExchange(s secrets.Secrets) (trader.Exchange, error) {
+	client, err := coinbase.NewClient(newCoinbaseProClient(s), func(ctx context.Context, refresh bool) (coinbase.Credentials, error) {
+		if err := s.ReloadCredentials(ctx, refresh); err != nil {
+			return coinbase.Credentials{}, err
+		}
+		return coinbase.Credentials(s.CoinbaseProCredentials()), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("initialise_coinbase_client: %w", err)
+	}
+
+	return coinbase.New(client)
+}
+
+func newCoinbaseProClient(s secrets.Secrets) *coinbasepro.Client {
+	creds := s.CoinbaseProCredentials()
+	return &coinbasepro.Client{
+		BaseURL:    s.CoinbaseProURL(),
+		Key:        creds.Key,
+		Passphrase: creds.Passphrase,
+		Secret:     creds.Secret,
+		HTTPClient: &http.Client{Timeout: coinbaseTimeout},
+	}
+}
+
+func parseDecimal(v string) (decimal.Decimal, error) {
+	if v == "" {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(v)
+}
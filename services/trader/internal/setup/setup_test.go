@@ -0,0 +1,59 @@
+package setup_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/secrets"
+	"github.com/cshep4/kripto/services/trader/internal/setup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchange(t *testing.T) {
+	ctx := context.Background()
+
+	fake := func() secrets.Secrets {
+		var s secrets.Secrets
+		s.Exchange = secrets.Fake
+		s.Fake.Prices = "BTC-GBP:40000"
+		s.Retry.MaxAttempts = 1
+		return s
+	}
+
+	t.Run("returns error if fake prices are invalid", func(t *testing.T) {
+		s := fake()
+		s.Fake.Prices = "BTC-GBP"
+
+		ex, err := setup.Exchange(ctx, s)
+		require.Error(t, err)
+
+		assert.Contains(t, err.Error(), "FAKE_PRICES")
+		assert.Nil(t, ex)
+	})
+
+	t.Run("returns fake exchange with no open orders", func(t *testing.T) {
+		ex, err := setup.Exchange(ctx, fake())
+		require.NoError(t, err)
+
+		orders, err := ex.ListOpenOrders(ctx, "BTC-GBP")
+		require.NoError(t, err)
+		assert.Empty(t, orders)
+
+		ids, err := ex.CancelAllOrders(ctx, "BTC-GBP")
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("returns error if order to cancel isn't on fake exchange", func(t *testing.T) {
+		ex, err := setup.Exchange(ctx, fake())
+		require.NoError(t, err)
+
+		err = ex.CancelOrder(ctx, "order-placed-by-trade")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, exchange.ErrOrderNotFound))
+	})
+}
@@ -0,0 +1,133 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/paper"
+)
+
+type (
+	wallet struct {
+		Id        string                          `bson:"_id"`
+		Balances  map[string]primitive.Decimal128 `bson:"balances"`
+		CreatedAt time.Time                       `bson:"createdAt"`
+	}
+
+	// order is stored with amounts as strings, the same as the exchange returns them.
+	order struct {
+		Id            string    `bson:"_id"`
+		ClientOrderId string    `bson:"clientOrderId,omitempty"`
+		ProductId     string    `bson:"productId"`
+		Side          string    `bson:"side"`
+		Type          string    `bson:"type"`
+		Status        string    `bson:"status"`
+		Settled       bool      `bson:"settled"`
+		Price         string    `bson:"price,omitempty"`
+		Size          string    `bson:"size,omitempty"`
+		StopPrice     string    `bson:"stopPrice,omitempty"`
+		TimeInForce   string    `bson:"timeInForce,omitempty"`
+		PostOnly      bool      `bson:"postOnly"`
+		CreatedAt     time.Time `bson:"createdAt"`
+		Funds         string    `bson:"funds,omitempty"`
+		FillFees      string    `bson:"fillFees"`
+		FilledSize    string    `bson:"filledSize"`
+		ExecutedValue string    `bson:"executedValue"`
+		Fills         []fill    `bson:"fills"`
+	}
+
+	fill struct {
+		Id        string    `bson:"id"`
+		Price     string    `bson:"price"`
+		Size      string    `bson:"size"`
+		Fee       string    `bson:"fee"`
+		Liquidity string    `bson:"liquidity"`
+		CreatedAt time.Time `bson:"createdAt"`
+	}
+)
+
+func fromOrder(o paper.Order) order {
+	fills := make([]fill, len(o.Fills))
+	for i, f := range o.Fills {
+		fills[i] = fill{
+			Id:        f.ID,
+			Price:     f.Price,
+			Size:      f.Size,
+			Fee:       f.Fee,
+			Liquidity: f.Liquidity,
+			CreatedAt: f.CreatedAt,
+		}
+	}
+
+	return order{
+		Id:            o.ID,
+		ClientOrderId: o.ClientOrderId,
+		ProductId:     o.ProductId,
+		Side:          o.Side,
+		Type:          o.Type,
+		Status:        o.Status,
+		Settled:       o.Settled,
+		Price:         o.Price,
+		Size:          o.Size,
+		StopPrice:     o.StopPrice,
+		TimeInForce:   o.TimeInForce,
+		PostOnly:      o.PostOnly,
+		CreatedAt:     o.CreatedAt,
+		Funds:         o.Funds,
+		FillFees:      o.FillFees,
+		FilledSize:    o.FilledSize,
+		ExecutedValue: o.ExecutedValue,
+		Fills:         fills,
+	}
+}
+
+func toOrder(o order) paper.Order {
+	fills := make([]exchange.Fill, len(o.Fills))
+	for i, f := range o.Fills {
+		fills[i] = exchange.Fill{
+			ID:        f.Id,
+			OrderId:   o.Id,
+			ProductId: o.ProductId,
+			Side:      o.Side,
+			Price:     f.Price,
+			Size:      f.Size,
+			Fee:       f.Fee,
+			Liquidity: f.Liquidity,
+			CreatedAt: f.CreatedAt.UTC(),
+		}
+	}
+
+	return paper.Order{
+		Order: exchange.Order{
+			ID:            o.Id,
+			ProductId:     o.ProductId,
+			Side:          o.Side,
+			Type:          o.Type,
+			Status:        o.Status,
+			Settled:       o.Settled,
+			Price:         o.Price,
+			Size:          o.Size,
+			StopPrice:     o.StopPrice,
+			TimeInForce:   o.TimeInForce,
+			PostOnly:      o.PostOnly,
+			CreatedAt:     o.CreatedAt.UTC(),
+			Funds:         o.Funds,
+			FillFees:      o.FillFees,
+			FilledSize:    o.FilledSize,
+			ExecutedValue: o.ExecutedValue,
+		},
+		ClientOrderId: o.ClientOrderId,
+		Fills:         fills,
+	}
+}
+
+func toDecimal128(d decimal.Decimal) (primitive.Decimal128, error) {
+	return primitive.ParseDecimal128(d.String())
+}
+
+func fromDecimal128(d primitive.Decimal128) (decimal.Decimal, error) {
+	return decimal.NewFromString(d.String())
+}
@@ -0,0 +1,306 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/paper"
+	"github.com/cshep4/kripto/shared/go/apperror"
+)
+
+const (
+	db               = "paper"
+	walletCollection = "wallet"
+	orderCollection  = "order"
+
+	// There is a single paper wallet.
+	walletId = "paper"
+
+	duplicateKeyCode = 11000
+)
+
+var (
+	// ErrInsufficientFunds is returned when a balance can't cover a change.
+	ErrInsufficientFunds = apperror.New(apperror.InsufficientFunds, errors.New("insufficient funds"))
+	// ErrWalletNotFound is returned when the wallet hasn't been created.
+	ErrWalletNotFound = errors.New("wallet not found")
+	// ErrDuplicateOrder is returned when an order has already been saved with the client order ID.
+	ErrDuplicateOrder = apperror.New(apperror.ExchangeRejected, errors.New("duplicate client order id"))
+)
+
+type (
+	store struct {
+		client  *mongo.Client
+		wallets *mongo.Collection
+		orders  *mongo.Collection
+		now     func() time.Time
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func New(ctx context.Context, client *mongo.Client) (*store, error) {
+	if client == nil {
+		return nil, InvalidParameterError{Parameter: "client"}
+	}
+
+	s := &store{
+		client:  client,
+		wallets: client.Database(db).Collection(walletCollection),
+		orders:  client.Database(db).Collection(orderCollection),
+		now:     time.Now,
+	}
+
+	if err := s.ping(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureIndexes(ctx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// ensureIndexes makes client order IDs unique, and indexes orders by product
+// and age to list fills.
+func (s *store) ensureIndexes(ctx context.Context) error {
+	_, err := s.orders.
+		Indexes().
+		CreateMany(
+			ctx,
+			[]mongo.IndexModel{
+				{
+					Keys: bsonx.Doc{
+						{Key: "clientOrderId", Value: bsonx.Int64(1)},
+					},
+					Options: options.Index().
+						SetName("clientOrderIdIdx").
+						SetBackground(true).
+						SetUnique(true).
+						SetSparse(true),
+				},
+				{
+					Keys: bsonx.Doc{
+						{Key: "productId", Value: bsonx.Int64(1)},
+						{Key: "createdAt", Value: bsonx.Int64(-1)},
+					},
+					Options: options.Index().
+						SetName("productIdCreatedAtIdx").
+						SetBackground(true),
+				},
+			},
+		)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateWallet creates the wallet with the balances, a wallet which already
+// exists is left as it is so restarting doesn't reset it.
+func (s *store) CreateWallet(ctx context.Context, balances map[string]decimal.Decimal) error {
+	doc := bson.D{}
+	for currency, balance := range balances {
+		d, err := toDecimal128(balance)
+		if err != nil {
+			return fmt.Errorf("invalid_%s_balance: %w", currency, err)
+		}
+		doc = append(doc, bson.E{Key: currency, Value: d})
+	}
+
+	_, err := s.wallets.UpdateOne(
+		ctx,
+		bson.D{{Key: "_id", Value: walletId}},
+		bson.D{{
+			Key: "$setOnInsert",
+			Value: bson.D{
+				{Key: "balances", Value: doc},
+				{Key: "createdAt", Value: s.now()},
+			},
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("update_one: %w", err)
+	}
+
+	return nil
+}
+
+func (s *store) GetBalances(ctx context.Context) (map[string]decimal.Decimal, error) {
+	var w wallet
+	err := s.wallets.FindOne(ctx, bson.D{{Key: "_id", Value: walletId}}).Decode(&w)
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return nil, ErrWalletNotFound
+	case err != nil:
+		return nil, fmt.Errorf("find_one: %w", err)
+	}
+
+	balances := make(map[string]decimal.Decimal, len(w.Balances))
+	for currency, b := range w.Balances {
+		if balances[currency], err = fromDecimal128(b); err != nil {
+			return nil, fmt.Errorf("invalid_%s_balance: %w", currency, err)
+		}
+	}
+
+	return balances, nil
+}
+
+// UpdateBalances adds the changes to the balances in a single update, which
+// only matches if every balance being reduced can cover its change. So two
+// orders spending the same funds at the same time can't both succeed.
+func (s *store) UpdateBalances(ctx context.Context, changes map[string]decimal.Decimal) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var (
+		filter = bson.D{{Key: "_id", Value: walletId}}
+		inc    = bson.D{}
+	)
+	for currency, change := range changes {
+		key := "balances." + currency
+
+		d, err := toDecimal128(change)
+		if err != nil {
+			return fmt.Errorf("invalid_%s_change: %w", currency, err)
+		}
+		inc = append(inc, bson.E{Key: key, Value: d})
+
+		if change.IsNegative() {
+			min, err := toDecimal128(change.Neg())
+			if err != nil {
+				return fmt.Errorf("invalid_%s_change: %w", currency, err)
+			}
+			filter = append(filter, bson.E{Key: key, Value: bson.D{{Key: "$gte", Value: min}}})
+		}
+	}
+
+	res, err := s.wallets.UpdateOne(ctx, filter, bson.D{{Key: "$inc", Value: inc}})
+	if err != nil {
+		return fmt.Errorf("update_one: %w", err)
+	}
+	if res.MatchedCount > 0 {
+		return nil
+	}
+
+	count, err := s.wallets.CountDocuments(ctx, bson.D{{Key: "_id", Value: walletId}})
+	if err != nil {
+		return fmt.Errorf("count_documents: %w", err)
+	}
+	if count == 0 {
+		return ErrWalletNotFound
+	}
+
+	return ErrInsufficientFunds
+}
+
+// SaveOrder stores the order, ErrDuplicateOrder is returned if an order has
+// already been saved with its client order ID.
+func (s *store) SaveOrder(ctx context.Context, o paper.Order) error {
+	_, err := s.orders.InsertOne(ctx, fromOrder(o))
+	switch {
+	case isDuplicateKey(err):
+		return fmt.Errorf("%w: %s", ErrDuplicateOrder, o.ClientOrderId)
+	case err != nil:
+		return fmt.Errorf("insert_one: %w", err)
+	}
+
+	return nil
+}
+
+func isDuplicateKey(err error) bool {
+	var writeErr mongo.WriteException
+	if !errors.As(err, &writeErr) {
+		return false
+	}
+	for _, e := range writeErr.WriteErrors {
+		if e.Code == duplicateKeyCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *store) GetOrder(ctx context.Context, id string) (paper.Order, error) {
+	return s.findOrder(ctx, bson.D{{Key: "_id", Value: id}}, id)
+}
+
+func (s *store) GetOrderByClientId(ctx context.Context, clientOrderId string) (paper.Order, error) {
+	if clientOrderId == "" {
+		return paper.Order{}, fmt.Errorf("%w: %s", exchange.ErrOrderNotFound, clientOrderId)
+	}
+
+	return s.findOrder(ctx, bson.D{{Key: "clientOrderId", Value: clientOrderId}}, clientOrderId)
+}
+
+func (s *store) findOrder(ctx context.Context, filter bson.D, id string) (paper.Order, error) {
+	var o order
+	err := s.orders.FindOne(ctx, filter).Decode(&o)
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return paper.Order{}, fmt.Errorf("%w: %s", exchange.ErrOrderNotFound, id)
+	case err != nil:
+		return paper.Order{}, fmt.Errorf("find_one: %w", err)
+	}
+
+	return toOrder(o), nil
+}
+
+// ListOrders returns the orders of the product with fills since the time, newest first.
+func (s *store) ListOrders(ctx context.Context, productId string, since time.Time) ([]paper.Order, error) {
+	cur, err := s.orders.Find(
+		ctx,
+		bson.D{
+			{Key: "productId", Value: productId},
+			{Key: "fills.createdAt", Value: bson.D{{Key: "$gte", Value: since}}},
+		},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("find: %w", err)
+	}
+
+	var docs []order
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	orders := make([]paper.Order, len(docs))
+	for i, d := range docs {
+		orders[i] = toOrder(d)
+	}
+
+	return orders, nil
+}
+
+func (s *store) ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	return s.client.Ping(ctx, nil)
+}
+
+func (s *store) Close(ctx context.Context) error {
+	return s.client.Disconnect(ctx)
+}
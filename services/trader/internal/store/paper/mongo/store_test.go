@@ -0,0 +1,211 @@
+//+build integration
+
+package mongo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/exchange/paper"
+	store "github.com/cshep4/kripto/services/trader/internal/store/paper/mongo"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type paperStore interface {
+	paper.Store
+	Close(ctx context.Context) error
+}
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if mongo client is nil", func(t *testing.T) {
+		s, err := store.New(context.Background(), nil)
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+
+		ipErr, ok := err.(store.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "client", ipErr.Parameter)
+	})
+
+	t.Run("returns store", func(t *testing.T) {
+		ctx := context.Background()
+
+		s, err := store.New(ctx, newClient(t, ctx))
+		require.NoError(t, err)
+
+		assert.NotNil(t, s)
+
+		err = s.Close(ctx)
+		require.NoError(t, err)
+	})
+}
+
+func TestStore_Balances(t *testing.T) {
+	ctx := context.Background()
+	s := newStore(t, ctx)
+
+	t.Run("returns wallet not found error if wallet hasn't been created", func(t *testing.T) {
+		_, err := s.GetBalances(ctx)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, store.ErrWalletNotFound))
+	})
+
+	t.Run("creates wallet once", func(t *testing.T) {
+		err := s.CreateWallet(ctx, map[string]decimal.Decimal{"GBP": decimal.RequireFromString("1000")})
+		require.NoError(t, err)
+
+		err = s.CreateWallet(ctx, map[string]decimal.Decimal{"GBP": decimal.RequireFromString("5")})
+		require.NoError(t, err)
+
+		balances, err := s.GetBalances(ctx)
+		require.NoError(t, err)
+
+		require.Len(t, balances, 1)
+		assert.Equal(t, "1000", balances["GBP"].String())
+	})
+
+	t.Run("updates balances", func(t *testing.T) {
+		err := s.UpdateBalances(ctx, map[string]decimal.Decimal{
+			"GBP": decimal.RequireFromString("-99.999912"),
+			"BTC": decimal.RequireFromString("0.00248756"),
+		})
+		require.NoError(t, err)
+
+		balances, err := s.GetBalances(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, "900.000088", balances["GBP"].String())
+		assert.Equal(t, "0.00248756", balances["BTC"].String())
+	})
+
+	t.Run("returns insufficient funds error if a balance would be negative", func(t *testing.T) {
+		err := s.UpdateBalances(ctx, map[string]decimal.Decimal{
+			"GBP": decimal.RequireFromString("10"),
+			"BTC": decimal.RequireFromString("-1"),
+		})
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.InsufficientFunds, apperror.CodeOf(err))
+
+		balances, err := s.GetBalances(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, "900.000088", balances["GBP"].String())
+		assert.Equal(t, "0.00248756", balances["BTC"].String())
+	})
+}
+
+func TestStore_Orders(t *testing.T) {
+	ctx := context.Background()
+	s := newStore(t, ctx)
+
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	order := paper.Order{
+		Order: exchange.Order{
+			ID:            "order-id",
+			ProductId:     "BTC-GBP",
+			Side:          "buy",
+			Type:          "market",
+			Status:        exchange.StatusDone,
+			Settled:       true,
+			CreatedAt:     now,
+			Funds:         "100",
+			FillFees:      "0.497512",
+			FilledSize:    "0.00248756",
+			ExecutedValue: "99.5024",
+		},
+		ClientOrderId: "client-order-id",
+		Fills: []exchange.Fill{{
+			ID:        "fill-id",
+			OrderId:   "order-id",
+			ProductId: "BTC-GBP",
+			Side:      "buy",
+			Price:     "40000",
+			Size:      "0.00248756",
+			Fee:       "0.497512",
+			Liquidity: exchange.Taker,
+			CreatedAt: now,
+		}},
+	}
+
+	t.Run("returns order not found error if order doesn't exist", func(t *testing.T) {
+		_, err := s.GetOrder(ctx, order.ID)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, exchange.ErrOrderNotFound))
+	})
+
+	t.Run("saves order", func(t *testing.T) {
+		err := s.SaveOrder(ctx, order)
+		require.NoError(t, err)
+
+		o, err := s.GetOrder(ctx, order.ID)
+		require.NoError(t, err)
+		assert.Equal(t, order, o)
+
+		o, err = s.GetOrderByClientId(ctx, order.ClientOrderId)
+		require.NoError(t, err)
+		assert.Equal(t, order, o)
+	})
+
+	t.Run("returns duplicate order error if client order id is in use", func(t *testing.T) {
+		dupe := order
+		dupe.ID = "other-order-id"
+
+		err := s.SaveOrder(ctx, dupe)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, store.ErrDuplicateOrder))
+	})
+
+	t.Run("lists orders with fills since time", func(t *testing.T) {
+		orders, err := s.ListOrders(ctx, "BTC-GBP", now)
+		require.NoError(t, err)
+		assert.Equal(t, []paper.Order{order}, orders)
+
+		orders, err = s.ListOrders(ctx, "BTC-GBP", now.Add(time.Second))
+		require.NoError(t, err)
+		assert.Empty(t, orders)
+	})
+}
+
+func newStore(t *testing.T, ctx context.Context) paperStore {
+	t.Helper()
+
+	client := newClient(t, ctx)
+
+	s, err := store.New(ctx, client)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		err := client.Database("paper").Drop(ctx)
+		require.NoError(t, err)
+
+		err = s.Close(ctx)
+		require.NoError(t, err)
+	})
+
+	return s
+}
+
+func newClient(t *testing.T, ctx context.Context) *mongo.Client {
+	t.Helper()
+
+	client, err := mongo.NewClient(options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+
+	err = client.Connect(ctx)
+	require.NoError(t, err)
+
+	return client
+}
@@ -0,0 +1,90 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange/paper"
+)
+
+// The rates are stored by the data-storer rate-writer function.
+const (
+	db         = "rate"
+	collection = "rate"
+)
+
+type (
+	store struct {
+		client     *mongo.Client
+		collection *mongo.Collection
+	}
+
+	rate struct {
+		Rate     float64   `bson:"rate"`
+		DateTime time.Time `bson:"dateTime"`
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+func New(ctx context.Context, client *mongo.Client) (*store, error) {
+	if client == nil {
+		return nil, InvalidParameterError{Parameter: "client"}
+	}
+
+	s := &store{
+		client:     client,
+		collection: client.Database(db).Collection(collection),
+	}
+
+	if err := s.ping(ctx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// GetLatestRate returns the most recent rate, paper.ErrNoRate if there are none.
+func (s *store) GetLatestRate(ctx context.Context) (paper.Rate, error) {
+	var r rate
+	err := s.collection.FindOne(
+		ctx,
+		bson.D{},
+		options.FindOne().SetSort(bson.D{{Key: "dateTime", Value: -1}}),
+	).Decode(&r)
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return paper.Rate{}, fmt.Errorf("%w: no rates stored", paper.ErrNoRate)
+	case err != nil:
+		return paper.Rate{}, fmt.Errorf("find_one: %w", err)
+	}
+
+	return paper.Rate{
+		Rate:     r.Rate,
+		DateTime: r.DateTime.UTC(),
+	}, nil
+}
+
+func (s *store) ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	return s.client.Ping(ctx, nil)
+}
+
+func (s *store) Close(ctx context.Context) error {
+	return s.client.Disconnect(ctx)
+}
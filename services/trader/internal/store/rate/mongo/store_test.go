@@ -0,0 +1,95 @@
+//+build integration
+
+package mongo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange/paper"
+	store "github.com/cshep4/kripto/services/trader/internal/store/rate/mongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("returns error if mongo client is nil", func(t *testing.T) {
+		s, err := store.New(context.Background(), nil)
+		require.Error(t, err)
+
+		assert.Nil(t, s)
+
+		ipErr, ok := err.(store.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "client", ipErr.Parameter)
+	})
+
+	t.Run("returns store", func(t *testing.T) {
+		ctx := context.Background()
+
+		s, err := store.New(ctx, newClient(t, ctx))
+		require.NoError(t, err)
+
+		assert.NotNil(t, s)
+
+		err = s.Close(ctx)
+		require.NoError(t, err)
+	})
+}
+
+func TestStore_GetLatestRate(t *testing.T) {
+	ctx := context.Background()
+
+	client := newClient(t, ctx)
+	collection := client.Database("rate").Collection("rate")
+
+	s, err := store.New(ctx, client)
+	require.NoError(t, err)
+
+	defer func() {
+		err := collection.Drop(ctx)
+		require.NoError(t, err)
+
+		err = s.Close(ctx)
+		require.NoError(t, err)
+	}()
+
+	t.Run("returns no rate error if there are no rates", func(t *testing.T) {
+		_, err := s.GetLatestRate(ctx)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, paper.ErrNoRate))
+	})
+
+	t.Run("returns latest rate", func(t *testing.T) {
+		now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+		_, err := collection.InsertMany(ctx, []interface{}{
+			bson.D{{Key: "rate", Value: 40000.5}, {Key: "dateTime", Value: now}},
+			bson.D{{Key: "rate", Value: 39000.0}, {Key: "dateTime", Value: now.Add(-time.Minute)}},
+		})
+		require.NoError(t, err)
+
+		rate, err := s.GetLatestRate(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, paper.Rate{Rate: 40000.5, DateTime: now}, rate)
+	})
+}
+
+func newClient(t *testing.T, ctx context.Context) *mongo.Client {
+	t.Helper()
+
+	client, err := mongo.NewClient(options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+
+	err = client.Connect(ctx)
+	require.NoError(t, err)
+
+	return client
+}
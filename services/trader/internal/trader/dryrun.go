@@ -0,0 +1,79 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange/sim"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/shopspring/decimal"
+)
+
+const dryRunIdPrefix = "dry-run-"
+
+// DryRun validates the order and simulates placing it without calling the
+// exchange to create an order. The order is filled the same way as by the fake
+// and paper exchanges, at the current ticker price against the available
+// balances of the real accounts, so market orders are filled at the ticker
+// price, limit orders are only filled if they cross it and orders the balances
+// can't cover are rejected. The would-be trade is returned with a dry-run ID
+// and marked as a dry run.
+func (t *trader) DryRun(ctx context.Context, order Order) (*TradeResponse, error) {
+	product, err := t.getProduct(ctx, order.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err = normalise(order, product)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := toNewOrder(order)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := t.GetPrice(ctx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := t.GetAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string]decimal.Decimal, len(accounts))
+	for _, a := range accounts {
+		balances[a.Currency] = a.Available
+	}
+
+	res, err := sim.Execute(sim.Market{
+		Product:  product,
+		Price:    price,
+		Balances: balances,
+		FeeRate:  t.dryRunFeeRate,
+	}, o, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("simulate: %w", err)
+	}
+
+	res.Order.ID = dryRunIdPrefix + uuid.NewV4().String()
+	for i := range res.Fills {
+		res.Fills[i].ID = dryRunIdPrefix + uuid.NewV4().String()
+		res.Fills[i].OrderId = res.Order.ID
+	}
+
+	trade, err := toTradeResponse(res.Order)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Fills) > 0 {
+		trade.Fills = toFills(res.Fills)
+	}
+	trade.DryRun = true
+
+	return trade, nil
+}
@@ -0,0 +1,169 @@
+package trader_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/mocks/exchange"
+	trade "github.com/cshep4/kripto/services/trader/internal/trader"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrader_DryRun(t *testing.T) {
+	accounts := []exchange.Account{
+		{ID: "gbp", Currency: "GBP", Balance: "1000", Hold: "0", Available: "1000"},
+		{ID: "btc", Currency: "BTC", Balance: "0.5", Hold: "0.1", Available: "0.4"},
+	}
+
+	t.Run("returns error if product is not supported", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+
+		res, err := trader.DryRun(context.Background(), trade.Order{ProductId: "DOGE-GBP", Side: trade.Buy, Funds: "10"})
+		require.Error(t, err)
+
+		assert.Nil(t, res)
+
+		ioErr, ok := err.(trade.InvalidOrderError)
+		assert.True(t, ok)
+		assert.Equal(t, "productId", ioErr.Parameter)
+	})
+
+	t.Run("returns error if error getting price", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().GetTicker(gomock.Any(), "BTC-GBP").Return(exchange.Ticker{}, testErr)
+
+		res, err := trader.DryRun(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "10"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Nil(t, res)
+	})
+
+	t.Run("returns error if error getting accounts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().GetTicker(gomock.Any(), "BTC-GBP").Return(exchange.Ticker{Price: "40000"}, nil)
+		ex.EXPECT().GetAccounts(gomock.Any()).Return(nil, testErr)
+
+		res, err := trader.DryRun(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "10"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Nil(t, res)
+	})
+
+	t.Run("returns error if balance does not cover order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().GetTicker(gomock.Any(), "BTC-GBP").Return(exchange.Ticker{Price: "40000"}, nil)
+		ex.EXPECT().GetAccounts(gomock.Any()).Return(accounts, nil)
+
+		res, err := trader.DryRun(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Sell, Size: "0.5"})
+		require.Error(t, err)
+
+		assert.Equal(t, apperror.InsufficientFunds, apperror.CodeOf(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("simulates market order at ticker price without placing it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex, trade.WithDryRunFeeRate(decimal.RequireFromString("0.005")))
+		require.NoError(t, err)
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().GetTicker(gomock.Any(), "BTC-GBP").Return(exchange.Ticker{Price: "40000"}, nil)
+		ex.EXPECT().GetAccounts(gomock.Any()).Return(accounts, nil)
+
+		res, err := trader.DryRun(context.Background(), trade.Order{
+			ProductId:     "btc-gbp",
+			Side:          trade.Sell,
+			Size:          "0.01",
+			ClientOrderId: clientOrderId,
+		})
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(res.Id, "dry-run-"))
+		assert.True(t, res.DryRun)
+		assert.True(t, res.Settled)
+		assert.Equal(t, "BTC-GBP", res.ProductId)
+		assert.Equal(t, "sell", res.Side)
+		assert.Equal(t, "0.01", res.FilledSize)
+		assert.True(t, decimal.RequireFromString("398").Equal(res.NetQuote))
+		assert.True(t, decimal.RequireFromString("-0.01").Equal(res.NetBase))
+		assert.True(t, decimal.RequireFromString("0.005").Equal(res.FeeRate))
+		assert.Len(t, res.Fills, 1)
+	})
+
+	t.Run("does not fill limit order which does not cross ticker price", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().GetTicker(gomock.Any(), "BTC-GBP").Return(exchange.Ticker{Price: "40000"}, nil)
+		ex.EXPECT().GetAccounts(gomock.Any()).Return(accounts, nil)
+
+		res, err := trader.DryRun(context.Background(), trade.Order{
+			ProductId: "BTC-GBP",
+			Side:      trade.Buy,
+			Type:      trade.Limit,
+			Price:     "35000",
+			Size:      "0.01",
+		})
+		require.NoError(t, err)
+
+		assert.True(t, res.DryRun)
+		assert.Equal(t, exchange.StatusOpen, res.Status)
+		assert.True(t, res.NetBase.IsZero())
+		assert.Empty(t, res.Fills)
+	})
+}
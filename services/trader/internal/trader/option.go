@@ -0,0 +1,59 @@
+package trader
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultPollInterval    = 250 * time.Millisecond
+	defaultMaxPollInterval = 2 * time.Second
+	defaultSettleTimeout   = 10 * time.Second
+	defaultDeadlineMargin  = 2 * time.Second
+)
+
+var defaultDryRunFeeRate = decimal.New(5, -3)
+
+type Option func(*trader)
+
+// WithPollInterval sets the initial and maximum interval between checks for an
+// order being settled. The interval doubles after each check up to max.
+func WithPollInterval(initial, max time.Duration) Option {
+	return func(t *trader) {
+		if initial > 0 {
+			t.pollInterval = initial
+		}
+		if max > 0 {
+			t.maxPollInterval = max
+		}
+	}
+}
+
+// WithSettlementTimeout sets the maximum time to wait for an order to be settled.
+func WithSettlementTimeout(timeout time.Duration) Option {
+	return func(t *trader) {
+		if timeout > 0 {
+			t.settleTimeout = timeout
+		}
+	}
+}
+
+// WithDeadlineMargin sets how long before the context deadline polling is stopped,
+// leaving time to publish the trade before the lambda times out.
+func WithDeadlineMargin(margin time.Duration) Option {
+	return func(t *trader) {
+		if margin >= 0 {
+			t.deadlineMargin = margin
+		}
+	}
+}
+
+// WithDryRunFeeRate sets the fee charged on dry-run fills as a fraction of their value, e.g. 0.005 for 0.5%.
+func WithDryRunFeeRate(rate decimal.Decimal) Option {
+	return func(t *trader) {
+		if !rate.IsNegative() {
+			t.dryRunFeeRate = rate
+		}
+	}
+}
@@ -1,131 +1,652 @@
 package trader
 
 import (
+	"context"
+	"crypto/sha1"
+	"errors"
 	"fmt"
-	"strconv"
+	"regexp"
+	"strings"
 	"time"
 
-	"github.com/preichenberger/go-coinbasepro/v2"
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/shopspring/decimal"
 )
 
 const (
 	Buy  TradeType = "buy"
 	Sell TradeType = "sell"
+
+	Market OrderType = "market"
+	Limit  OrderType = "limit"
+	Stop   OrderType = "stop"
+
+	GoodTillCancelled TimeInForce = "GTC"
+	ImmediateOrCancel TimeInForce = "IOC"
+	FillOrKill        TimeInForce = "FOK"
+
+	sizeDecimals = 8
 )
 
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
 type (
-	TradeType string
+	TradeType   string
+	OrderType   string
+	TimeInForce string
+
+	// Order describes the order to be placed on the exchange.
+	// Market orders are placed using either Funds or Size, limit orders require
+	// both Price and Size, and stop orders are triggered as market orders once
+	// the market reaches Price. Funds and Price are in the quote currency of the
+	// product and Size is in the base currency. If ClientOrderId is set and an
+	// order has already been placed with it, that order is returned instead.
+	Order struct {
+		ProductId     string
+		Side          TradeType
+		Type          OrderType
+		Funds         string
+		Size          string
+		Price         string
+		TimeInForce   TimeInForce
+		PostOnly      bool
+		ClientOrderId string
+	}
 
 	TradeResponse struct {
 		Id            string    `json:"id"`
 		Side          string    `json:"side"`
+		Type          string    `json:"type"`
 		ProductId     string    `json:"productId"`
+		Status        string    `json:"status,omitempty"`
 		Settled       bool      `json:"settled"`
+		Price         string    `json:"price,omitempty"`     // Limit price in quote currency.
+		Size          string    `json:"size,omitempty"`      // Requested size in base currency.
+		StopPrice     string    `json:"stopPrice,omitempty"` // Trigger price in quote currency.
+		TimeInForce   string    `json:"timeInForce,omitempty"`
+		PostOnly      bool      `json:"postOnly,omitempty"`
+		DryRun        bool      `json:"dryRun,omitempty"` // Simulated by DryRun rather than placed.
 		CreatedAt     time.Time `json:"createdAt,string,omitempty"`
-		Funds         string    `json:"funds,omitempty"`         // Spent Funds in GBP.
-		FillFees      string    `json:"fillFees,omitempty"`      // Fees in GBP.
-		FilledSize    string    `json:"filledSize,omitempty"`    // Value in BTC.
-		ExecutedValue string    `json:"executedValue,omitempty"` // Value in GBP.
+		Funds         string    `json:"funds,omitempty"`         // Spent Funds in quote currency.
+		FillFees      string    `json:"fillFees,omitempty"`      // Fees in quote currency.
+		FilledSize    string    `json:"filledSize,omitempty"`    // Value in base currency.
+		ExecutedValue string    `json:"executedValue,omitempty"` // Value in quote currency.
+
+		// Derived from the fills, zero if nothing has been filled.
+		EffectivePrice decimal.Decimal `json:"effectivePrice"` // Price per unit of base currency including fees.
+		FeeRate        decimal.Decimal `json:"feeRate"`        // Fees as a fraction of the executed value.
+		NetQuote       decimal.Decimal `json:"netQuote"`       // Change in quote currency balance, e.g. GBP.
+		NetBase        decimal.Decimal `json:"netBase"`        // Change in base currency balance, e.g. BTC.
+
+		Fills []Fill `json:"fills,omitempty"`
+	}
+
+	// Fill is a single execution of part of an order.
+	Fill struct {
+		Id        string    `json:"id"`
+		Price     string    `json:"price"` // Price in quote currency.
+		Size      string    `json:"size"`  // Size in base currency.
+		Fee       string    `json:"fee"`   // Fee in quote currency.
+		Liquidity string    `json:"liquidity"`
+		CreatedAt time.Time `json:"createdAt"`
 	}
 
 	Account struct {
 		ID        string
-		Balance   float32
-		Hold      float32
-		Available float32
+		Balance   decimal.Decimal
+		Hold      decimal.Decimal
+		Available decimal.Decimal
 		Currency  string
 	}
 
-	Coinbase interface {
-		CreateOrder(order *coinbasepro.Order) (coinbasepro.Order, error)
-		GetOrder(id string) (coinbasepro.Order, error)
-		GetAccounts() ([]coinbasepro.Account, error)
+	Exchange interface {
+		CreateOrder(ctx context.Context, order exchange.NewOrder) (exchange.Order, error)
+		GetOrder(ctx context.Context, id string) (exchange.Order, error)
+		CancelOrder(ctx context.Context, id string) error
+		CancelAllOrders(ctx context.Context, productId string) ([]string, error)
+		ListOpenOrders(ctx context.Context, productId string) ([]exchange.Order, error)
+		ListFills(ctx context.Context, productId string, since time.Time) ([]exchange.Fill, error)
+		GetOrderFills(ctx context.Context, orderId string) ([]exchange.Fill, error)
+		GetAccounts(ctx context.Context) ([]exchange.Account, error)
+		GetProducts(ctx context.Context) ([]exchange.Product, error)
+		GetTicker(ctx context.Context, productId string) (exchange.Ticker, error)
+	}
+
+	// OrderFinder is implemented by exchanges which can look up an order by its client order ID.
+	OrderFinder interface {
+		GetOrderByClientId(ctx context.Context, clientOrderId string) (exchange.Order, error)
 	}
 
 	trader struct {
-		coinbase Coinbase
+		exchange        Exchange
+		pollInterval    time.Duration
+		maxPollInterval time.Duration
+		settleTimeout   time.Duration
+		deadlineMargin  time.Duration
+		dryRunFeeRate   decimal.Decimal
 	}
 
 	// InvalidParameterError is returned when a required parameter passed to New is invalid.
 	InvalidParameterError struct {
 		Parameter string
 	}
+
+	// InvalidOrderError is returned when an order passed to Trade cannot be placed.
+	InvalidOrderError struct {
+		Parameter string
+		Err       string
+	}
+
+	// SettlementTimeoutError is returned when an order has been placed but is not
+	// settled before the settlement timeout or context deadline is reached.
+	SettlementTimeoutError struct {
+		OrderId string
+		Status  string
+	}
 )
 
 func (i InvalidParameterError) Error() string {
 	return fmt.Sprintf("invalid parameter %s", i.Parameter)
 }
 
-func New(coinbase Coinbase) (*trader, error) {
-	if coinbase == nil {
-		return nil, InvalidParameterError{Parameter: "coinbase"}
+func (i InvalidOrderError) Error() string {
+	return fmt.Sprintf("invalid order - param: %s, error: %s", i.Parameter, i.Err)
+}
+
+func (s SettlementTimeoutError) Error() string {
+	return fmt.Sprintf("order %s not settled before timeout, status: %s", s.OrderId, s.Status)
+}
+
+func (InvalidOrderError) ErrorCode() apperror.Code {
+	return apperror.Validation
+}
+
+func (SettlementTimeoutError) ErrorCode() apperror.Code {
+	return apperror.Timeout
+}
+
+func New(exchange Exchange, opts ...Option) (*trader, error) {
+	if exchange == nil {
+		return nil, InvalidParameterError{Parameter: "exchange"}
 	}
 
-	return &trader{
-		coinbase: coinbase,
-	}, nil
+	t := &trader{
+		exchange:        exchange,
+		pollInterval:    defaultPollInterval,
+		maxPollInterval: defaultMaxPollInterval,
+		settleTimeout:   defaultSettleTimeout,
+		deadlineMargin:  defaultDeadlineMargin,
+		dryRunFeeRate:   defaultDryRunFeeRate,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t, nil
 }
 
-func (t *trader) Trade(tradeType TradeType, amount string) (*TradeResponse, error) {
-	o := &coinbasepro.Order{
-		Funds:     amount,
-		Side:      string(tradeType),
-		ProductID: "BTC-GBP",
-		Type:      "market",
+func (t *trader) Trade(ctx context.Context, order Order) (*TradeResponse, error) {
+	product, err := t.getProduct(ctx, order.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err = normalise(order, product)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := toNewOrder(order)
+	if err != nil {
+		return nil, err
 	}
-	order, err := t.coinbase.CreateOrder(o)
+
+	res, err := t.exchange.CreateOrder(ctx, o)
 	if err != nil {
 		return nil, fmt.Errorf("create_order: %w", err)
 	}
 
 	// Must get the order after creating as it will not be settled in previous response
-	order, err = t.coinbase.GetOrder(order.ID)
+	return t.settledTrade(ctx, res.ID)
+}
+
+// FindTrade returns the trade placed with the client order ID by a previous
+// attempt at the request once it is settled, e.g. one which timed out after
+// placing the order, or false if there isn't one.
+func (t *trader) FindTrade(ctx context.Context, clientOrderId string) (*TradeResponse, bool, error) {
+	finder, ok := t.exchange.(OrderFinder)
+	if !ok || clientOrderId == "" {
+		return nil, false, nil
+	}
+
+	o, err := finder.GetOrderByClientId(ctx, clientOrderId)
+	switch {
+	case errors.Is(err, exchange.ErrOrderNotFound), errors.Is(err, exchange.ErrNotSupported):
+		return nil, false, nil
+	case err != nil:
+		return nil, false, fmt.Errorf("get_order_by_client_id: %w", err)
+	}
+
+	trade, err := t.settledTrade(ctx, o.ID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return trade, true, nil
+}
+
+// settledTrade waits for the order to settle and returns it with its fills.
+func (t *trader) settledTrade(ctx context.Context, orderId string) (*TradeResponse, error) {
+	res, err := t.awaitSettlement(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	trade, err := toTradeResponse(res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !trade.NetBase.IsZero() {
+		if trade.Fills, err = t.getFills(ctx, res.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return trade, nil
+}
+
+// ClientOrderId returns the client order ID for the idempotency key. Exchanges
+// require a UUID, so keys which aren't UUIDs are hashed into one.
+func ClientOrderId(idempotencyKey string) string {
+	switch {
+	case idempotencyKey == "":
+		return ""
+	case uuidPattern.MatchString(idempotencyKey):
+		return strings.ToLower(idempotencyKey)
+	}
+
+	// Name based UUID (version 5), as described in RFC 4122.
+	h := sha1.Sum([]byte(idempotencyKey))
+	h[6] = (h[6] & 0x0f) | 0x50
+	h[8] = (h[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
+
+func (t *trader) getFills(ctx context.Context, orderId string) ([]Fill, error) {
+	res, err := t.exchange.GetOrderFills(ctx, orderId)
+	if err != nil {
+		return nil, fmt.Errorf("get_order_fills: %w", err)
+	}
+
+	return toFills(res), nil
+}
+
+func toFills(res []exchange.Fill) []Fill {
+	fills := make([]Fill, len(res))
+	for i, f := range res {
+		fills[i] = Fill{
+			Id:        f.ID,
+			Price:     f.Price,
+			Size:      f.Size,
+			Fee:       f.Fee,
+			Liquidity: f.Liquidity,
+			CreatedAt: f.CreatedAt,
+		}
+	}
+
+	return fills
+}
+
+func toTradeResponse(o exchange.Order) (*TradeResponse, error) {
+	res := &TradeResponse{
+		Side:          o.Side,
+		Type:          o.Type,
+		ProductId:     o.ProductId,
+		Status:        o.Status,
+		Funds:         o.Funds,
+		Id:            o.ID,
+		Settled:       o.Settled,
+		Price:         o.Price,
+		Size:          o.Size,
+		StopPrice:     o.StopPrice,
+		TimeInForce:   o.TimeInForce,
+		PostOnly:      o.PostOnly,
+		CreatedAt:     o.CreatedAt,
+		FillFees:      o.FillFees,
+		FilledSize:    o.FilledSize,
+		ExecutedValue: o.ExecutedValue,
+	}
+
+	size, err := parseAmount(o.FilledSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid_filled_size (%s): %w", o.FilledSize, err)
+	}
+	value, err := parseAmount(o.ExecutedValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid_executed_value (%s): %w", o.ExecutedValue, err)
+	}
+	fees, err := parseAmount(o.FillFees)
+	if err != nil {
+		return nil, fmt.Errorf("invalid_fill_fees (%s): %w", o.FillFees, err)
+	}
+
+	if value.IsPositive() {
+		res.FeeRate = fees.Div(value)
+	}
+
+	if size.IsPositive() {
+		// buying spends the value plus fees, selling receives the value less fees
+		res.NetQuote, res.NetBase = value.Add(fees).Neg(), size
+		if o.Side == string(exchange.Sell) {
+			res.NetQuote, res.NetBase = value.Sub(fees), size.Neg()
+		}
+		res.EffectivePrice = res.NetQuote.Abs().Div(size)
+	}
+
+	return res, nil
+}
+
+// parseAmount parses an amount returned by the exchange, which is empty if nothing has been filled.
+func parseAmount(v string) (decimal.Decimal, error) {
+	if v == "" {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(v)
+}
+
+func (t *trader) CancelOrder(ctx context.Context, id string) error {
+	if err := t.exchange.CancelOrder(ctx, id); err != nil {
+		return fmt.Errorf("cancel_order: %w", err)
+	}
+
+	return nil
+}
+
+// CancelAllOrders cancels all open orders, or only those for the product if productId is set.
+// The IDs of the cancelled orders are returned.
+func (t *trader) CancelAllOrders(ctx context.Context, productId string) ([]string, error) {
+	ids, err := t.exchange.CancelAllOrders(ctx, productId)
 	if err != nil {
-		return nil, fmt.Errorf("get_order: %w", err)
+		return nil, fmt.Errorf("cancel_all_orders: %w", err)
 	}
 
-	return &TradeResponse{
-		Side:          order.Side,
-		ProductId:     order.ProductID,
+	return ids, nil
+}
+
+// ListOpenOrders returns all orders which are open, pending or active, optionally filtered by product.
+func (t *trader) ListOpenOrders(ctx context.Context, productId string) ([]TradeResponse, error) {
+	res, err := t.exchange.ListOpenOrders(ctx, productId)
+	if err != nil {
+		return nil, fmt.Errorf("list_open_orders: %w", err)
+	}
+
+	orders := make([]TradeResponse, len(res))
+	for i, o := range res {
+		order, err := toTradeResponse(o)
+		if err != nil {
+			return nil, err
+		}
+		orders[i] = *order
+	}
+
+	return orders, nil
+}
+
+// awaitSettlement polls the order with exponential backoff until it is settled or
+// resting on the order book. Polling stops at the settlement timeout, or earlier
+// if the context deadline less the deadline margin is reached first.
+func (t *trader) awaitSettlement(ctx context.Context, id string) (exchange.Order, error) {
+	timeout := t.settleTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline) - t.deadlineMargin; remaining < timeout {
+			timeout = remaining
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	interval := t.pollInterval
+	for {
+		order, err := t.exchange.GetOrder(ctx, id)
+		if err != nil {
+			return exchange.Order{}, fmt.Errorf("get_order: %w", err)
+		}
+
+		// Orders resting on the book will not settle until they are filled.
+		if order.Settled || order.Status == exchange.StatusOpen || order.Status == exchange.StatusActive {
+			return order, nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return exchange.Order{}, SettlementTimeoutError{OrderId: id, Status: order.Status}
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > t.maxPollInterval {
+			interval = t.maxPollInterval
+		}
+	}
+}
+
+// toNewOrder checks the order has the fields required by its type.
+func toNewOrder(order Order) (exchange.NewOrder, error) {
+	o := exchange.NewOrder{
+		ProductId:     order.ProductId,
+		Side:          exchange.Side(order.Side),
+		Type:          exchange.OrderType(order.Type),
 		Funds:         order.Funds,
-		Id:            order.ID,
-		Settled:       order.Settled,
-		CreatedAt:     time.Time(order.CreatedAt),
-		FillFees:      order.FillFees,
-		FilledSize:    order.FilledSize,
-		ExecutedValue: order.ExecutedValue,
-	}, nil
+		Size:          order.Size,
+		ClientOrderId: order.ClientOrderId,
+	}
+
+	switch order.Type {
+	case Market, "":
+		if order.Funds == "" && order.Size == "" {
+			return exchange.NewOrder{}, InvalidOrderError{Parameter: "funds", Err: "either funds or size must be specified"}
+		}
+		o.Type = exchange.Market
+	case Limit:
+		switch {
+		case order.Price == "":
+			return exchange.NewOrder{}, InvalidOrderError{Parameter: "price", Err: "empty"}
+		case order.Size == "":
+			return exchange.NewOrder{}, InvalidOrderError{Parameter: "size", Err: "empty"}
+		case order.PostOnly && (order.TimeInForce == ImmediateOrCancel || order.TimeInForce == FillOrKill):
+			return exchange.NewOrder{}, InvalidOrderError{Parameter: "postOnly", Err: "invalid with IOC/FOK time in force"}
+		}
+		o.Funds = ""
+		o.Price = order.Price
+		o.TimeInForce = exchange.TimeInForce(order.TimeInForce)
+		o.PostOnly = order.PostOnly
+	case Stop:
+		switch {
+		case order.Price == "":
+			return exchange.NewOrder{}, InvalidOrderError{Parameter: "price", Err: "empty"}
+		case order.Funds == "" && order.Size == "":
+			return exchange.NewOrder{}, InvalidOrderError{Parameter: "funds", Err: "either funds or size must be specified"}
+		}
+		o.Price = order.Price
+	default:
+		return exchange.NewOrder{}, InvalidOrderError{Parameter: "type", Err: fmt.Sprintf("unsupported order type %s", order.Type)}
+	}
+
+	return o, nil
+}
+
+func (t *trader) getProduct(ctx context.Context, id string) (exchange.Product, error) {
+	products, err := t.GetProducts(ctx)
+	if err != nil {
+		return exchange.Product{}, err
+	}
+
+	for _, p := range products {
+		if strings.EqualFold(p.ID, id) {
+			return p, nil
+		}
+	}
+
+	return exchange.Product{}, InvalidOrderError{Parameter: "productId", Err: fmt.Sprintf("unsupported product %s", id)}
+}
+
+// normalise truncates the order amounts to the increments accepted by the
+// product and checks the size is within the product limits.
+func normalise(order Order, product exchange.Product) (Order, error) {
+	order.ProductId = product.ID
+
+	if order.Funds != "" {
+		funds, err := decimal.NewFromString(order.Funds)
+		if err != nil {
+			return Order{}, InvalidOrderError{Parameter: "funds", Err: err.Error()}
+		}
+		order.Funds = truncate(funds, product.QuoteIncrement, 0)
+	}
+
+	if order.Price != "" {
+		price, err := decimal.NewFromString(order.Price)
+		if err != nil {
+			return Order{}, InvalidOrderError{Parameter: "price", Err: err.Error()}
+		}
+		order.Price = truncate(price, product.QuoteIncrement, 0)
+	}
+
+	if order.Size != "" {
+		size, err := decimal.NewFromString(order.Size)
+		if err != nil {
+			return Order{}, InvalidOrderError{Parameter: "size", Err: err.Error()}
+		}
+		order.Size = truncate(size, product.BaseIncrement, sizeDecimals)
+
+		size = decimal.RequireFromString(order.Size)
+		if min, err := decimal.NewFromString(product.BaseMinSize); err == nil && size.LessThan(min) {
+			return Order{}, InvalidOrderError{Parameter: "size", Err: fmt.Sprintf("below minimum size %s", product.BaseMinSize)}
+		}
+		if max, err := decimal.NewFromString(product.BaseMaxSize); err == nil && max.IsPositive() && size.GreaterThan(max) {
+			return Order{}, InvalidOrderError{Parameter: "size", Err: fmt.Sprintf("above maximum size %s", product.BaseMaxSize)}
+		}
+	}
+
+	return order, nil
+}
+
+// truncate rounds the amount down to a multiple of the increment, as the
+// exchange rejects amounts which aren't, e.g. 0.0019 with an increment of
+// 0.001 => 0.001. If the product has no increment it is rounded down to places.
+func truncate(amount decimal.Decimal, increment string, places int32) string {
+	inc, err := decimal.NewFromString(increment)
+	if err != nil || !inc.IsPositive() {
+		return amount.Truncate(places).StringFixed(places)
+	}
+
+	return amount.Div(inc).Floor().Mul(inc).StringFixed(decimals(increment))
+}
+
+// decimals returns the number of decimal places in an increment, e.g. 0.01 => 2.
+func decimals(increment string) int32 {
+	i := strings.IndexByte(increment, '.')
+	if i < 0 {
+		return 0
+	}
+
+	return int32(len(strings.TrimRight(increment[i+1:], "0")))
+}
+
+func (t *trader) GetProducts(ctx context.Context) ([]exchange.Product, error) {
+	products, err := t.exchange.GetProducts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get_products: %w", err)
+	}
+
+	return products, nil
 }
 
-func (t *trader) GetAccounts() ([]Account, error) {
-	res, err := t.coinbase.GetAccounts()
+// SelfCheck makes an authenticated call to the exchange so bad credentials
+// are found when the function starts rather than on the first trade.
+func (t *trader) SelfCheck(ctx context.Context) error {
+	if _, err := t.exchange.GetAccounts(ctx); err != nil {
+		return fmt.Errorf("get_accounts: %w", err)
+	}
+
+	return nil
+}
+
+func (t *trader) GetAccounts(ctx context.Context) ([]Account, error) {
+	res, err := t.exchange.GetAccounts(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("get_accounts: %w", err)
 	}
 
 	accounts := make([]Account, len(res))
 	for i, a := range res {
-		balance, err := strconv.ParseFloat(a.Balance, 32)
+		balance, err := decimal.NewFromString(a.Balance)
 		if err != nil {
 			return nil, fmt.Errorf("invalid_%s_balance (%s): %w", a.Currency, a.Balance, err)
 		}
-		hold, err := strconv.ParseFloat(a.Hold, 32)
+		hold, err := decimal.NewFromString(a.Hold)
 		if err != nil {
 			return nil, fmt.Errorf("invalid_%s_hold (%s): %w", a.Currency, a.Hold, err)
 		}
-		available, err := strconv.ParseFloat(a.Available, 32)
+		available, err := decimal.NewFromString(a.Available)
 		if err != nil {
 			return nil, fmt.Errorf("invalid_%s_available (%s): %w", a.Currency, a.Available, err)
 		}
 
 		accounts[i] = Account{
 			ID:        a.ID,
-			Balance:   float32(balance),
-			Hold:      float32(hold),
-			Available: float32(available),
+			Balance:   balance,
+			Hold:      hold,
+			Available: available,
 			Currency:  a.Currency,
 		}
 	}
 
 	return accounts, nil
 }
+
+// GetPrice returns the last traded price of the product.
+func (t *trader) GetPrice(ctx context.Context, productId string) (decimal.Decimal, error) {
+	ticker, err := t.exchange.GetTicker(ctx, productId)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("get_ticker: %w", err)
+	}
+
+	price, err := decimal.NewFromString(ticker.Price)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid_%s_price (%s): %w", productId, ticker.Price, err)
+	}
+
+	return price, nil
+}
+
+// GetExecutedNotional returns the total value, in the quote currency, of the
+// fills for the product since the given time.
+func (t *trader) GetExecutedNotional(ctx context.Context, productId string, since time.Time) (decimal.Decimal, error) {
+	fills, err := t.exchange.ListFills(ctx, productId, since)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("list_fills: %w", err)
+	}
+
+	notional := decimal.Zero
+	for _, f := range fills {
+		price, err := decimal.NewFromString(f.Price)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("invalid_fill_price (%s): %w", f.Price, err)
+		}
+		size, err := decimal.NewFromString(f.Size)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("invalid_fill_size (%s): %w", f.Size, err)
+		}
+
+		notional = notional.Add(price.Mul(size))
+	}
+
+	return notional, nil
+}
@@ -1,19 +1,22 @@
 package trader_test
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
-	"github.com/cshep4/kripto/services/trader/internal/mocks/coinbase"
+	"github.com/cshep4/kripto/services/trader/internal/exchange"
+	"github.com/cshep4/kripto/services/trader/internal/mocks/exchange"
 	trade "github.com/cshep4/kripto/services/trader/internal/trader"
 	"github.com/golang/mock/gomock"
-	"github.com/preichenberger/go-coinbasepro/v2"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestNew(t *testing.T) {
-	t.Run("returns error if coinbase is empty", func(t *testing.T) {
+	t.Run("returns error if ex is empty", func(t *testing.T) {
 		trader, err := trade.New(nil)
 		require.Error(t, err)
 
@@ -21,50 +24,160 @@ func TestNew(t *testing.T) {
 
 		ipErr, ok := err.(trade.InvalidParameterError)
 		assert.True(t, ok)
-		assert.Equal(t, "coinbase", ipErr.Parameter)
+		assert.Equal(t, "exchange", ipErr.Parameter)
 	})
 
 	t.Run("returns service", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
-		coinbase := coinbase_mocks.NewMockCoinbase(ctrl)
+		ex := exchange_mocks.NewMockExchange(ctrl)
 
-		trader, err := trade.New(coinbase)
+		trader, err := trade.New(ex)
 		require.NoError(t, err)
 
 		assert.NotNil(t, trader)
 	})
 }
 
+var products = []exchange.Product{
+	{
+		ID:             "BTC-GBP",
+		BaseCurrency:   "BTC",
+		QuoteCurrency:  "GBP",
+		BaseMinSize:    "0.001",
+		BaseMaxSize:    "80",
+		BaseIncrement:  "0.00000001",
+		QuoteIncrement: "0.01",
+	},
+	{
+		ID:             "ETH-BTC",
+		BaseCurrency:   "ETH",
+		QuoteCurrency:  "BTC",
+		BaseMinSize:    "0.01",
+		BaseMaxSize:    "1000",
+		BaseIncrement:  "0.001",
+		QuoteIncrement: "0.00001",
+	},
+}
+
+const clientOrderId = "aa368788-bb4f-40c0-b80f-afcfdaf18574"
+
+// finderExchange is an exchange which can look orders up by client order ID.
+type finderExchange struct {
+	*exchange_mocks.MockExchange
+	*exchange_mocks.MockOrderFinder
+}
+
+func TestClientOrderId(t *testing.T) {
+	t.Run("returns empty id if idempotency key is empty", func(t *testing.T) {
+		assert.Empty(t, trade.ClientOrderId(""))
+	})
+
+	t.Run("returns idempotency key if it is a uuid", func(t *testing.T) {
+		assert.Equal(t, clientOrderId, trade.ClientOrderId("AA368788-BB4F-40C0-B80F-AFCFDAF18574"))
+	})
+
+	t.Run("returns the same uuid for each idempotency key", func(t *testing.T) {
+		id := trade.ClientOrderId("request-1")
+
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+		assert.Equal(t, id, trade.ClientOrderId("request-1"))
+		assert.NotEqual(t, id, trade.ClientOrderId("request-2"))
+	})
+}
+
 func TestTrader_Trade(t *testing.T) {
+	t.Run("returns error if error getting products", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(nil, testErr)
+
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "10"})
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Empty(t, res)
+	})
+
+	t.Run("returns error if product is not supported", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: "DOGE-GBP", Side: trade.Buy, Funds: "10"})
+		require.Error(t, err)
+
+		assert.Empty(t, res)
+
+		ioErr, ok := err.(trade.InvalidOrderError)
+		assert.True(t, ok)
+		assert.Equal(t, "productId", ioErr.Parameter)
+	})
+
+	t.Run("returns error if size is below product minimum", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Size: "0.0001"})
+		require.Error(t, err)
+
+		assert.Empty(t, res)
+
+		ioErr, ok := err.(trade.InvalidOrderError)
+		assert.True(t, ok)
+		assert.Equal(t, "size", ioErr.Parameter)
+	})
+
 	t.Run("returns error if error creating order", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		const (
-			amount                    = "amount"
+			amount                    = "10"
 			tradeType trade.TradeType = "tradeType"
 			productId                 = "BTC-GBP"
-			orderType                 = "market"
+			orderType                 = exchange.Market
 		)
 
-		coinbase := coinbase_mocks.NewMockCoinbase(ctrl)
+		ex := exchange_mocks.NewMockExchange(ctrl)
 
-		trader, err := trade.New(coinbase)
+		trader, err := trade.New(ex)
 		require.NoError(t, err)
 
-		order := &coinbasepro.Order{
-			Funds:     amount,
-			Side:      string(tradeType),
-			ProductID: productId,
+		order := exchange.NewOrder{
+			Funds:     "10.00",
+			Side:      exchange.Side(tradeType),
+			ProductId: productId,
 			Type:      orderType,
 		}
 		testErr := errors.New("error")
 
-		coinbase.EXPECT().CreateOrder(order).Return(coinbasepro.Order{}, testErr)
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), order).Return(exchange.Order{}, testErr)
 
-		res, err := trader.Trade(tradeType, amount)
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: productId, Side: tradeType, Funds: amount})
 		require.Error(t, err)
 
 		assert.Empty(t, res)
@@ -75,33 +188,35 @@ func TestTrader_Trade(t *testing.T) {
 		defer ctrl.Finish()
 
 		const (
-			amount                    = "amount"
+			amount                    = "10"
 			tradeType trade.TradeType = "tradeType"
 			productId                 = "BTC-GBP"
-			orderType                 = "market"
+			orderType                 = exchange.Market
 			orderId                   = "id"
 		)
 
-		coinbase := coinbase_mocks.NewMockCoinbase(ctrl)
+		ex := exchange_mocks.NewMockExchange(ctrl)
 
-		trader, err := trade.New(coinbase)
+		trader, err := trade.New(ex)
 		require.NoError(t, err)
 
-		order := &coinbasepro.Order{
-			Funds:     amount,
-			Side:      string(tradeType),
-			ProductID: productId,
+		order := exchange.NewOrder{
+			Funds:     "10.00",
+			Side:      exchange.Side(tradeType),
+			ProductId: productId,
 			Type:      orderType,
 		}
-		orderRes := coinbasepro.Order{
-			ID: orderId,
+		orderRes := exchange.Order{
+			ID:      orderId,
+			Settled: true,
 		}
 		testErr := errors.New("error")
 
-		coinbase.EXPECT().CreateOrder(order).Return(orderRes, nil)
-		coinbase.EXPECT().GetOrder(orderId).Return(coinbasepro.Order{}, testErr)
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), order).Return(orderRes, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(exchange.Order{}, testErr)
 
-		res, err := trader.Trade(tradeType, amount)
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: productId, Side: tradeType, Funds: amount})
 		require.Error(t, err)
 
 		assert.Empty(t, res)
@@ -112,34 +227,962 @@ func TestTrader_Trade(t *testing.T) {
 		defer ctrl.Finish()
 
 		const (
-			amount                    = "amount"
+			amount                    = "10"
 			tradeType trade.TradeType = "tradeType"
 			productId                 = "BTC-GBP"
-			orderType                 = "market"
+			orderType                 = exchange.Market
 			orderId                   = "id"
 		)
 
-		coinbase := coinbase_mocks.NewMockCoinbase(ctrl)
+		ex := exchange_mocks.NewMockExchange(ctrl)
 
-		trader, err := trade.New(coinbase)
+		trader, err := trade.New(ex)
 		require.NoError(t, err)
 
-		order := &coinbasepro.Order{
-			Funds:     amount,
-			Side:      string(tradeType),
-			ProductID: productId,
+		order := exchange.NewOrder{
+			Funds:     "10.00",
+			Side:      exchange.Side(tradeType),
+			ProductId: productId,
 			Type:      orderType,
 		}
-		orderRes := coinbasepro.Order{
-			ID: orderId,
+		orderRes := exchange.Order{
+			ID:      orderId,
+			Settled: true,
 		}
 
-		coinbase.EXPECT().CreateOrder(order).Return(orderRes, nil)
-		coinbase.EXPECT().GetOrder(orderId).Return(orderRes, nil)
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), order).Return(orderRes, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(orderRes, nil)
 
-		res, err := trader.Trade(tradeType, amount)
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: productId, Side: tradeType, Funds: amount})
 		require.NoError(t, err)
 
 		assert.Equal(t, orderRes.ID, res.Id)
 	})
+
+	t.Run("places order with client order id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := finderExchange{exchange_mocks.NewMockExchange(ctrl), exchange_mocks.NewMockOrderFinder(ctrl)}
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		order := exchange.NewOrder{
+			Funds:         "10.00",
+			Side:          exchange.Buy,
+			ProductId:     "BTC-GBP",
+			Type:          exchange.Market,
+			ClientOrderId: clientOrderId,
+		}
+		orderRes := exchange.Order{ID: "id", Settled: true}
+
+		ex.MockExchange.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.MockExchange.EXPECT().CreateOrder(gomock.Any(), order).Return(orderRes, nil)
+		ex.MockExchange.EXPECT().GetOrder(gomock.Any(), "id").Return(orderRes, nil)
+
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "10", ClientOrderId: clientOrderId})
+		require.NoError(t, err)
+
+		assert.Equal(t, "id", res.Id)
+	})
+
+	t.Run("truncates amounts to the product increments", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const orderId = "id"
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		order := exchange.NewOrder{
+			Side:      exchange.Buy,
+			ProductId: "ETH-BTC",
+			Type:      exchange.Limit,
+			Price:     "0.02512",
+			Size:      "1.500",
+		}
+		orderRes := exchange.Order{
+			ID:        orderId,
+			Settled:   true,
+			ProductId: "ETH-BTC",
+		}
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), order).Return(orderRes, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(orderRes, nil)
+
+		res, err := trader.Trade(context.Background(), trade.Order{
+			ProductId: "eth-btc",
+			Side:      trade.Buy,
+			Type:      trade.Limit,
+			Price:     "0.025127",
+			Size:      "1.5009",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "ETH-BTC", res.ProductId)
+	})
+
+	t.Run("truncates funds to the product quote increment", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const orderId = "id"
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		order := exchange.NewOrder{
+			Side:      exchange.Buy,
+			ProductId: "BTC-GBP",
+			Type:      exchange.Market,
+			Funds:     "10.01",
+		}
+		orderRes := exchange.Order{
+			ID:        orderId,
+			Settled:   true,
+			ProductId: "BTC-GBP",
+		}
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), order).Return(orderRes, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(orderRes, nil)
+
+		_, err = trader.Trade(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "10.019"})
+		require.NoError(t, err)
+	})
+
+	t.Run("returns error if order is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Type: trade.Limit, Size: "0.1"})
+		require.Error(t, err)
+
+		assert.Empty(t, res)
+
+		ioErr, ok := err.(trade.InvalidOrderError)
+		assert.True(t, ok)
+		assert.Equal(t, "price", ioErr.Parameter)
+	})
+
+	t.Run("places limit order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const (
+			price   = "8000.00"
+			size    = "0.10000000"
+			orderId = "id"
+		)
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		order := exchange.NewOrder{
+			Side:        exchange.Sell,
+			ProductId:   "BTC-GBP",
+			Type:        exchange.Limit,
+			Price:       price,
+			Size:        size,
+			TimeInForce: exchange.GoodTillCancelled,
+			PostOnly:    true,
+		}
+		orderRes := exchange.Order{
+			ID:          orderId,
+			Settled:     true,
+			Type:        "limit",
+			Price:       price,
+			Size:        size,
+			TimeInForce: string(trade.GoodTillCancelled),
+			PostOnly:    true,
+		}
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), order).Return(orderRes, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(orderRes, nil)
+
+		res, err := trader.Trade(context.Background(), trade.Order{
+			ProductId:   "BTC-GBP",
+			Side:        trade.Sell,
+			Type:        trade.Limit,
+			Price:       "8000",
+			Size:        "0.1",
+			TimeInForce: trade.GoodTillCancelled,
+			PostOnly:    true,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, orderId, res.Id)
+		assert.Equal(t, "limit", res.Type)
+		assert.Equal(t, price, res.Price)
+		assert.Equal(t, size, res.Size)
+		assert.Equal(t, "GTC", res.TimeInForce)
+		assert.True(t, res.PostOnly)
+	})
+
+	t.Run("places stop order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const (
+			price   = "7000.00"
+			size    = "0.10000000"
+			orderId = "id"
+		)
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		order := exchange.NewOrder{
+			Side:      exchange.Sell,
+			ProductId: "BTC-GBP",
+			Type:      exchange.Stop,
+			Size:      size,
+			Price:     price,
+		}
+		orderRes := exchange.Order{
+			ID:        orderId,
+			Settled:   true,
+			Type:      "stop",
+			StopPrice: price,
+		}
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), order).Return(orderRes, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(orderRes, nil)
+
+		res, err := trader.Trade(context.Background(), trade.Order{
+			ProductId: "BTC-GBP",
+			Side:      trade.Sell,
+			Type:      trade.Stop,
+			Price:     "7000",
+			Size:      "0.1",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, orderId, res.Id)
+		assert.Equal(t, "stop", res.Type)
+		assert.Equal(t, price, res.StopPrice)
+	})
+
+	t.Run("polls order until settled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const orderId = "id"
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex, trade.WithPollInterval(time.Millisecond, time.Millisecond))
+		require.NoError(t, err)
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(exchange.Order{ID: orderId}, nil)
+		gomock.InOrder(
+			ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(exchange.Order{ID: orderId, Status: "pending"}, nil),
+			ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(exchange.Order{ID: orderId, Status: "done"}, nil),
+			ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(exchange.Order{
+				ID:            orderId,
+				Status:        "done",
+				Settled:       true,
+				FilledSize:    "0.001",
+				ExecutedValue: "10.00",
+			}, nil),
+		)
+		ex.EXPECT().GetOrderFills(gomock.Any(), orderId).Return(nil, nil)
+
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "10"})
+		require.NoError(t, err)
+
+		assert.True(t, res.Settled)
+		assert.Equal(t, "0.001", res.FilledSize)
+		assert.Equal(t, "10.00", res.ExecutedValue)
+	})
+
+	t.Run("does not wait for resting order to settle", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const orderId = "id"
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(exchange.Order{ID: orderId}, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(exchange.Order{ID: orderId, Status: "open"}, nil)
+
+		res, err := trader.Trade(context.Background(), trade.Order{
+			ProductId: "BTC-GBP",
+			Side:      trade.Buy,
+			Type:      trade.Limit,
+			Price:     "8000",
+			Size:      "0.1",
+		})
+		require.NoError(t, err)
+
+		assert.False(t, res.Settled)
+		assert.Equal(t, "open", res.Status)
+	})
+
+	t.Run("returns timeout error if order not settled before timeout", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const orderId = "id"
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex,
+			trade.WithPollInterval(time.Millisecond, 5*time.Millisecond),
+			trade.WithSettlementTimeout(20*time.Millisecond),
+		)
+		require.NoError(t, err)
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(exchange.Order{ID: orderId}, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(exchange.Order{ID: orderId, Status: "pending"}, nil).MinTimes(1)
+
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "10"})
+		require.Error(t, err)
+
+		assert.Empty(t, res)
+
+		stErr, ok := err.(trade.SettlementTimeoutError)
+		assert.True(t, ok)
+		assert.Equal(t, orderId, stErr.OrderId)
+		assert.Equal(t, "pending", stErr.Status)
+	})
+
+	t.Run("stops polling before context deadline", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const orderId = "id"
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex,
+			trade.WithPollInterval(time.Millisecond, time.Millisecond),
+			trade.WithDeadlineMargin(50*time.Millisecond),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(exchange.Order{ID: orderId}, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(exchange.Order{ID: orderId, Status: "pending"}, nil).MinTimes(1)
+
+		_, err = trader.Trade(ctx, trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "10"})
+		require.Error(t, err)
+
+		_, ok := err.(trade.SettlementTimeoutError)
+		assert.True(t, ok)
+
+		deadline, _ := ctx.Deadline()
+		assert.True(t, time.Until(deadline) > 0)
+	})
+
+	t.Run("returns error if filled amounts are invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const orderId = "id"
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		orderRes := exchange.Order{ID: orderId, Settled: true, FilledSize: "invalid"}
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(orderRes, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(orderRes, nil)
+
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "10"})
+		require.Error(t, err)
+
+		assert.Nil(t, res)
+	})
+
+	t.Run("returns fee-aware amounts for buy", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const orderId = "id"
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		orderRes := exchange.Order{
+			ID:            orderId,
+			Side:          "buy",
+			Settled:       true,
+			FilledSize:    "0.002",
+			ExecutedValue: "80",
+			FillFees:      "0.4",
+		}
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(orderRes, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(orderRes, nil)
+		ex.EXPECT().GetOrderFills(gomock.Any(), orderId).Return(nil, nil)
+
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "80.4"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "40200", res.EffectivePrice.String())
+		assert.Equal(t, "0.005", res.FeeRate.String())
+		assert.Equal(t, "-80.4", res.NetQuote.String())
+		assert.Equal(t, "0.002", res.NetBase.String())
+	})
+
+	t.Run("returns fee-aware amounts for sell", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const orderId = "id"
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		orderRes := exchange.Order{
+			ID:            orderId,
+			Side:          "sell",
+			Settled:       true,
+			FilledSize:    "0.002",
+			ExecutedValue: "80",
+			FillFees:      "0.4",
+		}
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(orderRes, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(orderRes, nil)
+		ex.EXPECT().GetOrderFills(gomock.Any(), orderId).Return(nil, nil)
+
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Sell, Size: "0.002"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "39800", res.EffectivePrice.String())
+		assert.Equal(t, "0.005", res.FeeRate.String())
+		assert.Equal(t, "79.6", res.NetQuote.String())
+		assert.Equal(t, "-0.002", res.NetBase.String())
+	})
+
+	t.Run("returns error if error getting fills", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const orderId = "id"
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		orderRes := exchange.Order{ID: orderId, Side: "buy", Settled: true, FilledSize: "0.002", ExecutedValue: "80"}
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(orderRes, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(orderRes, nil)
+		ex.EXPECT().GetOrderFills(gomock.Any(), orderId).Return(nil, errors.New("error"))
+
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "80"})
+		require.Error(t, err)
+
+		assert.Nil(t, res)
+	})
+
+	t.Run("returns fills of order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const orderId = "id"
+		createdAt := time.Now()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		orderRes := exchange.Order{ID: orderId, Side: "buy", Settled: true, FilledSize: "0.002", ExecutedValue: "80"}
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(orderRes, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(orderRes, nil)
+		ex.EXPECT().GetOrderFills(gomock.Any(), orderId).Return([]exchange.Fill{
+			{ID: "2", OrderId: orderId, Price: "40100", Size: "0.001", Fee: "0.2005", Liquidity: exchange.Taker, CreatedAt: createdAt},
+			{ID: "1", OrderId: orderId, Price: "39900", Size: "0.001", Fee: "0", Liquidity: exchange.Maker, CreatedAt: createdAt},
+		}, nil)
+
+		res, err := trader.Trade(context.Background(), trade.Order{ProductId: "BTC-GBP", Side: trade.Buy, Funds: "80"})
+		require.NoError(t, err)
+
+		assert.Equal(t, []trade.Fill{
+			{Id: "2", Price: "40100", Size: "0.001", Fee: "0.2005", Liquidity: exchange.Taker, CreatedAt: createdAt},
+			{Id: "1", Price: "39900", Size: "0.001", Fee: "0", Liquidity: exchange.Maker, CreatedAt: createdAt},
+		}, res.Fills)
+	})
+
+	t.Run("returns zero amounts if nothing filled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const orderId = "id"
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		orderRes := exchange.Order{ID: orderId, Side: "buy", Status: "open"}
+
+		ex.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+		ex.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(orderRes, nil)
+		ex.EXPECT().GetOrder(gomock.Any(), orderId).Return(orderRes, nil)
+
+		res, err := trader.Trade(context.Background(), trade.Order{
+			ProductId: "BTC-GBP",
+			Side:      trade.Buy,
+			Type:      trade.Limit,
+			Price:     "8000",
+			Size:      "0.1",
+		})
+		require.NoError(t, err)
+
+		assert.True(t, res.EffectivePrice.IsZero())
+		assert.True(t, res.FeeRate.IsZero())
+		assert.True(t, res.NetQuote.IsZero())
+		assert.True(t, res.NetBase.IsZero())
+	})
+}
+
+func TestTrader_FindTrade(t *testing.T) {
+	t.Run("returns false if exchange can't look up orders by client order id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		res, found, err := trader.FindTrade(context.Background(), clientOrderId)
+		require.NoError(t, err)
+
+		assert.False(t, found)
+		assert.Nil(t, res)
+	})
+
+	t.Run("returns false if order not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := finderExchange{exchange_mocks.NewMockExchange(ctrl), exchange_mocks.NewMockOrderFinder(ctrl)}
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.MockOrderFinder.EXPECT().GetOrderByClientId(gomock.Any(), clientOrderId).Return(exchange.Order{}, exchange.ErrOrderNotFound)
+
+		res, found, err := trader.FindTrade(context.Background(), clientOrderId)
+		require.NoError(t, err)
+
+		assert.False(t, found)
+		assert.Nil(t, res)
+	})
+
+	t.Run("returns error if error looking up order by client order id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := finderExchange{exchange_mocks.NewMockExchange(ctrl), exchange_mocks.NewMockOrderFinder(ctrl)}
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+
+		ex.MockOrderFinder.EXPECT().GetOrderByClientId(gomock.Any(), clientOrderId).Return(exchange.Order{}, testErr)
+
+		res, found, err := trader.FindTrade(context.Background(), clientOrderId)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.False(t, found)
+		assert.Nil(t, res)
+	})
+
+	t.Run("returns settled trade placed with client order id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := finderExchange{exchange_mocks.NewMockExchange(ctrl), exchange_mocks.NewMockOrderFinder(ctrl)}
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		gomock.InOrder(
+			ex.MockOrderFinder.EXPECT().GetOrderByClientId(gomock.Any(), clientOrderId).Return(exchange.Order{ID: "id"}, nil),
+			ex.MockExchange.EXPECT().GetOrder(gomock.Any(), "id").Return(exchange.Order{ID: "id", Settled: true}, nil),
+		)
+
+		res, found, err := trader.FindTrade(context.Background(), clientOrderId)
+		require.NoError(t, err)
+
+		assert.True(t, found)
+		assert.Equal(t, "id", res.Id)
+		assert.True(t, res.Settled)
+	})
+}
+
+func TestTrader_SelfCheck(t *testing.T) {
+	t.Run("returns error if error getting accounts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+
+		ex.EXPECT().GetAccounts(gomock.Any()).Return(nil, testErr)
+
+		err = trader.SelfCheck(context.Background())
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("returns nil if accounts are returned", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().GetAccounts(gomock.Any()).Return([]exchange.Account{{Currency: "BTC", Balance: "1"}}, nil)
+
+		err = trader.SelfCheck(context.Background())
+		require.NoError(t, err)
+	})
+}
+
+func TestTrader_GetAccounts(t *testing.T) {
+	t.Run("returns error if error getting accounts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+
+		ex.EXPECT().GetAccounts(gomock.Any()).Return(nil, testErr)
+
+		accounts, err := trader.GetAccounts(context.Background())
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Empty(t, accounts)
+	})
+
+	t.Run("returns error if balance is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().GetAccounts(gomock.Any()).Return([]exchange.Account{{Currency: "BTC", Balance: "invalid"}}, nil)
+
+		accounts, err := trader.GetAccounts(context.Background())
+		require.Error(t, err)
+
+		assert.Empty(t, accounts)
+	})
+
+	t.Run("returns accounts without losing precision", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().GetAccounts(gomock.Any()).Return([]exchange.Account{{
+			ID:        "id",
+			Currency:  "BTC",
+			Balance:   "21.1234567890123456",
+			Hold:      "0.0000000100000000",
+			Available: "21.1234567790123456",
+		}}, nil)
+
+		accounts, err := trader.GetAccounts(context.Background())
+		require.NoError(t, err)
+
+		require.Len(t, accounts, 1)
+		assert.Equal(t, "21.1234567890123456", accounts[0].Balance.String())
+		assert.Equal(t, "0.00000001", accounts[0].Hold.String())
+		assert.Equal(t, "21.1234567790123456", accounts[0].Available.String())
+		assert.True(t, accounts[0].Balance.Sub(accounts[0].Hold).Equal(accounts[0].Available))
+		assert.True(t, decimal.RequireFromString("0.00000001").Equal(accounts[0].Hold))
+	})
+}
+
+func TestTrader_CancelOrder(t *testing.T) {
+	t.Run("returns error if error cancelling order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+
+		ex.EXPECT().CancelOrder(gomock.Any(), "id").Return(testErr)
+
+		err = trader.CancelOrder(context.Background(), "id")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("returns nil if order cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().CancelOrder(gomock.Any(), "id").Return(nil)
+
+		err = trader.CancelOrder(context.Background(), "id")
+		require.NoError(t, err)
+	})
+}
+
+func TestTrader_CancelAllOrders(t *testing.T) {
+	t.Run("returns error if error cancelling orders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+
+		ex.EXPECT().CancelAllOrders(gomock.Any(), "BTC-GBP").Return(nil, testErr)
+
+		ids, err := trader.CancelAllOrders(context.Background(), "BTC-GBP")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Empty(t, ids)
+	})
+
+	t.Run("returns cancelled order ids", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().CancelAllOrders(gomock.Any(), "").Return([]string{"id1", "id2"}, nil)
+
+		ids, err := trader.CancelAllOrders(context.Background(), "")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"id1", "id2"}, ids)
+	})
+}
+
+func TestTrader_GetPrice(t *testing.T) {
+	t.Run("returns error if error getting ticker", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+		ex.EXPECT().GetTicker(gomock.Any(), "BTC-GBP").Return(exchange.Ticker{}, testErr)
+
+		price, err := trader.GetPrice(context.Background(), "BTC-GBP")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.True(t, price.IsZero())
+	})
+
+	t.Run("returns error if price is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().GetTicker(gomock.Any(), "BTC-GBP").Return(exchange.Ticker{Price: "invalid"}, nil)
+
+		price, err := trader.GetPrice(context.Background(), "BTC-GBP")
+		require.Error(t, err)
+
+		assert.True(t, price.IsZero())
+	})
+
+	t.Run("returns price", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().GetTicker(gomock.Any(), "BTC-GBP").Return(exchange.Ticker{Price: "31234.56"}, nil)
+
+		price, err := trader.GetPrice(context.Background(), "BTC-GBP")
+		require.NoError(t, err)
+
+		assert.Equal(t, "31234.56", price.String())
+	})
+}
+
+func TestTrader_ListOpenOrders(t *testing.T) {
+	t.Run("returns error if error listing orders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+
+		ex.EXPECT().ListOpenOrders(gomock.Any(), "").Return(nil, testErr)
+
+		orders, err := trader.ListOpenOrders(context.Background(), "")
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Empty(t, orders)
+	})
+
+	t.Run("returns orders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().ListOpenOrders(gomock.Any(), "BTC-GBP").Return([]exchange.Order{
+			{ID: "id1", Status: "open"},
+			{ID: "id2", Status: "pending"},
+		}, nil)
+
+		orders, err := trader.ListOpenOrders(context.Background(), "BTC-GBP")
+		require.NoError(t, err)
+
+		require.Len(t, orders, 2)
+		assert.Equal(t, "id1", orders[0].Id)
+		assert.Equal(t, "open", orders[0].Status)
+		assert.Equal(t, "id2", orders[1].Id)
+		assert.Equal(t, "pending", orders[1].Status)
+	})
+}
+
+func TestTrader_GetExecutedNotional(t *testing.T) {
+	since := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("returns error if error listing fills", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		testErr := errors.New("error")
+
+		ex.EXPECT().ListFills(gomock.Any(), "BTC-GBP", since).Return(nil, testErr)
+
+		notional, err := trader.GetExecutedNotional(context.Background(), "BTC-GBP", since)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.True(t, notional.IsZero())
+	})
+
+	t.Run("returns value of fills", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ex := exchange_mocks.NewMockExchange(ctrl)
+
+		trader, err := trade.New(ex)
+		require.NoError(t, err)
+
+		ex.EXPECT().ListFills(gomock.Any(), "BTC-GBP", since).Return([]exchange.Fill{
+			{Price: "30000.00", Size: "0.001"},
+			{Price: "30000.10", Size: "0.002"},
+		}, nil)
+
+		notional, err := trader.GetExecutedNotional(context.Background(), "BTC-GBP", since)
+		require.NoError(t, err)
+
+		assert.Equal(t, "90.0002", notional.String())
+	})
 }
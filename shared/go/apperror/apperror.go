@@ -0,0 +1,150 @@
+// Package apperror categorises errors so callers can tell whether a failed
+// request is worth retrying.
+package apperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// Validation is returned when the request is invalid, e.g. a missing parameter or a failed risk check.
+	Validation Code = "validation"
+	// InsufficientFunds is returned when the account can't cover the order.
+	InsufficientFunds Code = "insufficient_funds"
+	// ExchangeRejected is returned when the exchange refuses the request, e.g. invalid credentials or order size.
+	ExchangeRejected Code = "exchange_rejected"
+	// ExchangeUnavailable is returned when the exchange can't be reached or fails to handle the request.
+	ExchangeUnavailable Code = "exchange_unavailable"
+	// RateLimited is returned when the exchange rejects the request because too many have been made.
+	RateLimited Code = "rate_limited"
+	// Timeout is returned when the request doesn't complete in time.
+	Timeout Code = "timeout"
+	// Internal is returned for any other error.
+	Internal Code = "internal"
+)
+
+type (
+	// Code is the machine-readable category of an error.
+	Code string
+
+	// Error is returned as a Lambda error response. Its message is prefixed
+	// with the code, e.g. "rate_limited: trade: Private rate limit exceeded",
+	// so callers can read the code from the response.
+	Error struct {
+		Code Code
+		Err  error
+	}
+
+	// codedError adds a code to an error without changing its message.
+	codedError struct {
+		code Code
+		err  error
+	}
+
+	// coder is implemented by typed errors which belong to a category.
+	coder interface {
+		ErrorCode() Code
+	}
+)
+
+var codes = []Code{Validation, InsufficientFunds, ExchangeRejected, ExchangeUnavailable, RateLimited, Timeout, Internal}
+
+// Retryable returns true if a request failing with the code may succeed if it is retried.
+func (c Code) Retryable() bool {
+	switch c {
+	case ExchangeUnavailable, RateLimited, Timeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return string(e.Code)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) ErrorCode() Code {
+	return e.Code
+}
+
+func (c *codedError) Error() string {
+	return c.err.Error()
+}
+
+func (c *codedError) Unwrap() error {
+	return c.err
+}
+
+func (c *codedError) ErrorCode() Code {
+	return c.code
+}
+
+// New adds the code to err, keeping its message.
+func New(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{code: code, err: err}
+}
+
+// Errorf returns an error with the code and a formatted message.
+func Errorf(code Code, format string, a ...interface{}) error {
+	return &codedError{code: code, err: fmt.Errorf(format, a...)}
+}
+
+// CodeOf returns the code of the first error in the chain with one. Context
+// deadlines are timeouts and any other errors are internal.
+func CodeOf(err error) Code {
+	var c coder
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &c):
+		return c.ErrorCode()
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	default:
+		return Internal
+	}
+}
+
+// IsRetryable returns true if a request failing with err may succeed if it is retried.
+func IsRetryable(err error) bool {
+	return CodeOf(err).Retryable()
+}
+
+// Wrap prefixes the message of err with its code, so it can be returned as a
+// Lambda error response. Errors which are already wrapped are returned as is.
+func Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*Error); ok {
+		return err
+	}
+	return &Error{Code: CodeOf(err), Err: err}
+}
+
+// Parse reads the code from an error message prefixed by Wrap, e.g. from a
+// Lambda error response. Messages without a known code are internal errors.
+func Parse(msg string) *Error {
+	for _, c := range codes {
+		if strings.HasPrefix(msg, string(c)+": ") {
+			return &Error{Code: c, Err: errors.New(strings.TrimPrefix(msg, string(c)+": "))}
+		}
+		if msg == string(c) {
+			return &Error{Code: c}
+		}
+	}
+	return &Error{Code: Internal, Err: errors.New(msg)}
+}
@@ -0,0 +1,115 @@
+package apperror_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cshep4/kripto/shared/go/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedError struct{}
+
+func (typedError) Error() string            { return "typed" }
+func (typedError) ErrorCode() apperror.Code { return apperror.Validation }
+
+func TestCode_Retryable(t *testing.T) {
+	t.Run("returns true for transient errors", func(t *testing.T) {
+		assert.True(t, apperror.ExchangeUnavailable.Retryable())
+		assert.True(t, apperror.RateLimited.Retryable())
+		assert.True(t, apperror.Timeout.Retryable())
+	})
+
+	t.Run("returns false for permanent errors", func(t *testing.T) {
+		assert.False(t, apperror.Validation.Retryable())
+		assert.False(t, apperror.InsufficientFunds.Retryable())
+		assert.False(t, apperror.ExchangeRejected.Retryable())
+		assert.False(t, apperror.Internal.Retryable())
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Run("returns nil if error is nil", func(t *testing.T) {
+		assert.NoError(t, apperror.New(apperror.Timeout, nil))
+	})
+
+	t.Run("keeps message and wrapped error", func(t *testing.T) {
+		testErr := errors.New("error")
+
+		err := apperror.New(apperror.RateLimited, testErr)
+
+		assert.Equal(t, "error", err.Error())
+		assert.True(t, errors.Is(err, testErr))
+	})
+}
+
+func TestCodeOf(t *testing.T) {
+	t.Run("returns empty code if error is nil", func(t *testing.T) {
+		assert.Equal(t, apperror.Code(""), apperror.CodeOf(nil))
+	})
+
+	t.Run("returns code of wrapped error", func(t *testing.T) {
+		err := fmt.Errorf("trade: %w", apperror.Errorf(apperror.InsufficientFunds, "create_order: %s", "Insufficient funds"))
+
+		assert.Equal(t, apperror.InsufficientFunds, apperror.CodeOf(err))
+	})
+
+	t.Run("returns code of typed error", func(t *testing.T) {
+		err := fmt.Errorf("trade: %w", typedError{})
+
+		assert.Equal(t, apperror.Validation, apperror.CodeOf(err))
+	})
+
+	t.Run("returns timeout if context deadline exceeded", func(t *testing.T) {
+		err := fmt.Errorf("trade: %w", context.DeadlineExceeded)
+
+		assert.Equal(t, apperror.Timeout, apperror.CodeOf(err))
+		assert.True(t, apperror.IsRetryable(err))
+	})
+
+	t.Run("returns internal for any other error", func(t *testing.T) {
+		assert.Equal(t, apperror.Internal, apperror.CodeOf(errors.New("error")))
+	})
+}
+
+func TestWrap(t *testing.T) {
+	t.Run("returns nil if error is nil", func(t *testing.T) {
+		assert.NoError(t, apperror.Wrap(nil))
+	})
+
+	t.Run("prefixes message with code", func(t *testing.T) {
+		err := apperror.Wrap(fmt.Errorf("trade: %w", apperror.New(apperror.RateLimited, errors.New("Private rate limit exceeded"))))
+
+		var aErr *apperror.Error
+		require.True(t, errors.As(err, &aErr))
+
+		assert.Equal(t, apperror.RateLimited, aErr.Code)
+		assert.Equal(t, "rate_limited: trade: Private rate limit exceeded", err.Error())
+	})
+
+	t.Run("doesn't wrap error twice", func(t *testing.T) {
+		err := apperror.Wrap(errors.New("error"))
+
+		assert.Equal(t, err, apperror.Wrap(err))
+	})
+}
+
+func TestParse(t *testing.T) {
+	t.Run("returns code and message", func(t *testing.T) {
+		err := apperror.Parse("exchange_unavailable: trade: service unavailable")
+
+		assert.Equal(t, apperror.ExchangeUnavailable, err.Code)
+		assert.Equal(t, "exchange_unavailable: trade: service unavailable", err.Error())
+		assert.True(t, apperror.IsRetryable(err))
+	})
+
+	t.Run("returns internal if message has no code", func(t *testing.T) {
+		err := apperror.Parse("trade: error")
+
+		assert.Equal(t, apperror.Internal, err.Code)
+		assert.Equal(t, "internal: trade: error", err.Error())
+	})
+}
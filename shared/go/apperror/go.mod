@@ -0,0 +1,9 @@
+module github.com/cshep4/kripto/shared/go/apperror
+
+go 1.14
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/stretchr/testify v1.4.0
+	gopkg.in/yaml.v2 v2.2.5 // indirect
+)
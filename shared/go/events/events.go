@@ -0,0 +1,90 @@
+// Package events defines the events published between services. Producers and
+// consumers both use these types so they can't disagree on the field names.
+//
+// The shape of a version of an event never changes once it has been
+// published. Adding an optional field is compatible, anything else, e.g.
+// renaming or removing a field, needs a new version of the event.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// RateUpdated is published by the rate-retriever with the latest BTC-GBP rate.
+	RateUpdated = "RateUpdated"
+	// TradeExecuted is published by the trader once an order has been placed.
+	TradeExecuted = "TradeExecuted"
+
+	// Latest version of each event, published by the producers.
+	RateUpdatedVersion   = 1
+	TradeExecutedVersion = 1
+)
+
+type (
+	// Payload is the data of an event, which can check it matches its schema.
+	Payload interface {
+		Validate() error
+	}
+
+	schema struct {
+		eventType string
+		version   int
+	}
+
+	// ValidationError is returned when an event doesn't match its schema.
+	ValidationError struct {
+		Field  string
+		Reason string
+	}
+
+	// UnknownSchemaError is returned when there is no schema for the event type and version.
+	UnknownSchemaError struct {
+		Type    string
+		Version int
+	}
+)
+
+var schemas = map[schema]func() Payload{
+	{eventType: RateUpdated, version: 1}:   func() Payload { return &RateUpdatedV1{} },
+	{eventType: TradeExecuted, version: 1}: func() Payload { return &TradeExecutedV1{} },
+}
+
+func (v ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", v.Field, v.Reason)
+}
+
+func (u UnknownSchemaError) Error() string {
+	return fmt.Sprintf("unknown schema %s v%d", u.Type, u.Version)
+}
+
+// Decode unmarshals the data into the payload for the event type and version
+// and validates it, e.g. for an event whose type and version were read from
+// its message attributes.
+func Decode(eventType string, version int, data []byte) (Payload, error) {
+	newPayload, ok := schemas[schema{eventType: eventType, version: version}]
+	if !ok {
+		return nil, UnknownSchemaError{Type: eventType, Version: version}
+	}
+
+	p := newPayload()
+	if err := Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Unmarshal unmarshals the data into the payload and validates it.
+func Unmarshal(data []byte, p Payload) error {
+	if err := json.Unmarshal(data, p); err != nil {
+		return fmt.Errorf("json_unmarshal: %w", err)
+	}
+
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
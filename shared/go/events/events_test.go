@@ -0,0 +1,65 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cshep4/kripto/shared/go/events"
+	"github.com/cshep4/kripto/shared/go/events/eventstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	t.Run("returns error if schema is unknown", func(t *testing.T) {
+		p, err := events.Decode(events.TradeExecuted, 2, []byte(eventstest.TradeExecutedV1JSON))
+		require.Error(t, err)
+
+		assert.Nil(t, p)
+
+		usErr, ok := err.(events.UnknownSchemaError)
+		assert.True(t, ok)
+		assert.Equal(t, events.TradeExecuted, usErr.Type)
+		assert.Equal(t, 2, usErr.Version)
+	})
+
+	t.Run("returns error if data is not json", func(t *testing.T) {
+		p, err := events.Decode(events.TradeExecuted, 1, []byte("trade"))
+		require.Error(t, err)
+
+		assert.Nil(t, p)
+	})
+
+	t.Run("returns error if data does not match schema", func(t *testing.T) {
+		p, err := events.Decode(events.RateUpdated, 1, []byte(`{"rate":-1}`))
+		require.Error(t, err)
+
+		assert.Nil(t, p)
+
+		var vErr events.ValidationError
+		require.True(t, errors.As(err, &vErr))
+		assert.Equal(t, "rate", vErr.Field)
+	})
+
+	t.Run("returns payload of event type and version", func(t *testing.T) {
+		p, err := events.Decode(events.RateUpdated, 1, []byte(eventstest.RateUpdatedV1JSON))
+		require.NoError(t, err)
+
+		rate := eventstest.RateUpdatedV1()
+		assert.Equal(t, &rate, p)
+
+		p, err = events.Decode(events.TradeExecuted, 1, []byte(eventstest.TradeExecutedV1JSON))
+		require.NoError(t, err)
+
+		trade := eventstest.TradeExecutedV1()
+		assert.Equal(t, &trade, p)
+	})
+
+	t.Run("has schema for latest version of each event", func(t *testing.T) {
+		_, err := events.Decode(events.RateUpdated, events.RateUpdatedVersion, []byte(eventstest.RateUpdatedV1JSON))
+		assert.NoError(t, err)
+
+		_, err = events.Decode(events.TradeExecuted, events.TradeExecutedVersion, []byte(eventstest.TradeExecutedV1JSON))
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,270 @@
+// Package eventstest provides an example of each version of each event, with
+// every field set, for producers and consumers to check they are compatible
+// with the schema. TradeExecuted also has an example of each shape of order the
+// trader publishes, as most of its fields are optional.
+package eventstest
+
+import (
+	"time"
+
+	"github.com/cshep4/kripto/shared/go/events"
+	"github.com/shopspring/decimal"
+)
+
+// RateUpdatedV1JSON is the wire format of RateUpdatedV1.
+const RateUpdatedV1JSON = `{
+	"rate": 40123.45,
+	"dateTime": "2021-05-01T12:00:00Z"
+}`
+
+// TradeExecutedV1JSON is the wire format of TradeExecutedV1.
+const TradeExecutedV1JSON = `{
+	"id": "d0c5340b-6d6c-49d9-b567-48c4bfca13d2",
+	"side": "buy",
+	"type": "limit",
+	"productId": "BTC-GBP",
+	"status": "done",
+	"settled": true,
+	"price": "40000.00",
+	"size": "0.01000000",
+	"stopPrice": "39000.00",
+	"timeInForce": "GTC",
+	"postOnly": true,
+	"dryRun": true,
+	"createdAt": "2021-05-01T12:00:00Z",
+	"funds": "402.00",
+	"fillFees": "2.00",
+	"filledSize": "0.01000000",
+	"executedValue": "400.00",
+	"effectivePrice": "40200",
+	"feeRate": "0.005",
+	"netQuote": "-402",
+	"netBase": "0.01",
+	"fills": [{
+		"id": "1",
+		"price": "40000.00",
+		"size": "0.01000000",
+		"fee": "2.00",
+		"liquidity": "M",
+		"createdAt": "2021-05-01T12:00:01Z"
+	}]
+}`
+
+// TradeExecutedV1LimitJSON is the wire format of TradeExecutedV1Limit.
+const TradeExecutedV1LimitJSON = `{
+	"id": "8a3c4a39-0f0e-4bde-9d34-2f1a8e0d9a61",
+	"side": "sell",
+	"type": "limit",
+	"productId": "BTC-GBP",
+	"status": "done",
+	"settled": true,
+	"price": "40000.00",
+	"size": "0.01000000",
+	"timeInForce": "IOC",
+	"createdAt": "2021-05-01T12:00:00Z",
+	"fillFees": "2.00",
+	"filledSize": "0.01000000",
+	"executedValue": "400.00",
+	"effectivePrice": "39800",
+	"feeRate": "0.005",
+	"netQuote": "398",
+	"netBase": "-0.01",
+	"fills": [{
+		"id": "2",
+		"price": "40000.00",
+		"size": "0.01000000",
+		"fee": "2.00",
+		"liquidity": "T",
+		"createdAt": "2021-05-01T12:00:01Z"
+	}]
+}`
+
+// TradeExecutedV1SizeJSON is the wire format of TradeExecutedV1Size.
+const TradeExecutedV1SizeJSON = `{
+	"id": "5b7e2f4c-1d2a-4c3b-8e9f-6a7b8c9d0e1f",
+	"side": "buy",
+	"type": "market",
+	"productId": "BTC-GBP",
+	"status": "done",
+	"settled": true,
+	"size": "0.01000000",
+	"createdAt": "2021-05-01T12:00:00Z",
+	"fillFees": "2.00",
+	"filledSize": "0.01000000",
+	"executedValue": "400.00",
+	"effectivePrice": "40200",
+	"feeRate": "0.005",
+	"netQuote": "-402",
+	"netBase": "0.01",
+	"fills": [{
+		"id": "3",
+		"price": "40000.00",
+		"size": "0.01000000",
+		"fee": "2.00",
+		"liquidity": "T",
+		"createdAt": "2021-05-01T12:00:01Z"
+	}]
+}`
+
+// TradeExecutedV1OpenJSON is the wire format of TradeExecutedV1Open.
+const TradeExecutedV1OpenJSON = `{
+	"id": "e4f5a6b7-c8d9-4e0f-a1b2-c3d4e5f6a7b8",
+	"side": "buy",
+	"type": "limit",
+	"productId": "BTC-GBP",
+	"status": "open",
+	"settled": false,
+	"price": "30000.00",
+	"size": "0.01000000",
+	"timeInForce": "GTC",
+	"createdAt": "2021-05-01T12:00:00Z",
+	"effectivePrice": "0",
+	"feeRate": "0",
+	"netQuote": "0",
+	"netBase": "0"
+}`
+
+var createdAt = time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// RateUpdatedV1 returns the event in RateUpdatedV1JSON.
+func RateUpdatedV1() events.RateUpdatedV1 {
+	return events.RateUpdatedV1{
+		Rate:     40123.45,
+		DateTime: createdAt,
+	}
+}
+
+// TradeExecutedV1 returns the event in TradeExecutedV1JSON.
+func TradeExecutedV1() events.TradeExecutedV1 {
+	return events.TradeExecutedV1{
+		Id:             "d0c5340b-6d6c-49d9-b567-48c4bfca13d2",
+		Side:           "buy",
+		Type:           "limit",
+		ProductId:      "BTC-GBP",
+		Status:         "done",
+		Settled:        true,
+		Price:          "40000.00",
+		Size:           "0.01000000",
+		StopPrice:      "39000.00",
+		TimeInForce:    "GTC",
+		PostOnly:       true,
+		DryRun:         true,
+		CreatedAt:      createdAt,
+		Funds:          "402.00",
+		FillFees:       "2.00",
+		FilledSize:     "0.01000000",
+		ExecutedValue:  "400.00",
+		EffectivePrice: decimal.RequireFromString("40200"),
+		FeeRate:        decimal.RequireFromString("0.005"),
+		NetQuote:       decimal.RequireFromString("-402"),
+		NetBase:        decimal.RequireFromString("0.01"),
+		Fills: []events.FillV1{{
+			Id:        "1",
+			Price:     "40000.00",
+			Size:      "0.01000000",
+			Fee:       "2.00",
+			Liquidity: "M",
+			CreatedAt: createdAt.Add(time.Second),
+		}},
+	}
+}
+
+// TradeExecutedV1Limit returns the event in TradeExecutedV1LimitJSON, a limit
+// order placed by size which filled immediately, so has no funds.
+func TradeExecutedV1Limit() events.TradeExecutedV1 {
+	return events.TradeExecutedV1{
+		Id:             "8a3c4a39-0f0e-4bde-9d34-2f1a8e0d9a61",
+		Side:           "sell",
+		Type:           "limit",
+		ProductId:      "BTC-GBP",
+		Status:         "done",
+		Settled:        true,
+		Price:          "40000.00",
+		Size:           "0.01000000",
+		TimeInForce:    "IOC",
+		CreatedAt:      createdAt,
+		FillFees:       "2.00",
+		FilledSize:     "0.01000000",
+		ExecutedValue:  "400.00",
+		EffectivePrice: decimal.RequireFromString("39800"),
+		FeeRate:        decimal.RequireFromString("0.005"),
+		NetQuote:       decimal.RequireFromString("398"),
+		NetBase:        decimal.RequireFromString("-0.01"),
+		Fills: []events.FillV1{{
+			Id:        "2",
+			Price:     "40000.00",
+			Size:      "0.01000000",
+			Fee:       "2.00",
+			Liquidity: "T",
+			CreatedAt: createdAt.Add(time.Second),
+		}},
+	}
+}
+
+// TradeExecutedV1Size returns the event in TradeExecutedV1SizeJSON, a market
+// order placed by size, so has no price or funds.
+func TradeExecutedV1Size() events.TradeExecutedV1 {
+	return events.TradeExecutedV1{
+		Id:             "5b7e2f4c-1d2a-4c3b-8e9f-6a7b8c9d0e1f",
+		Side:           "buy",
+		Type:           "market",
+		ProductId:      "BTC-GBP",
+		Status:         "done",
+		Settled:        true,
+		Size:           "0.01000000",
+		CreatedAt:      createdAt,
+		FillFees:       "2.00",
+		FilledSize:     "0.01000000",
+		ExecutedValue:  "400.00",
+		EffectivePrice: decimal.RequireFromString("40200"),
+		FeeRate:        decimal.RequireFromString("0.005"),
+		NetQuote:       decimal.RequireFromString("-402"),
+		NetBase:        decimal.RequireFromString("0.01"),
+		Fills: []events.FillV1{{
+			Id:        "3",
+			Price:     "40000.00",
+			Size:      "0.01000000",
+			Fee:       "2.00",
+			Liquidity: "T",
+			CreatedAt: createdAt.Add(time.Second),
+		}},
+	}
+}
+
+// TradeExecutedV1Open returns the event in TradeExecutedV1OpenJSON, a limit
+// order resting on the book which is unsettled and has no fills.
+func TradeExecutedV1Open() events.TradeExecutedV1 {
+	return events.TradeExecutedV1{
+		Id:             "e4f5a6b7-c8d9-4e0f-a1b2-c3d4e5f6a7b8",
+		Side:           "buy",
+		Type:           "limit",
+		ProductId:      "BTC-GBP",
+		Status:         "open",
+		Price:          "30000.00",
+		Size:           "0.01000000",
+		TimeInForce:    "GTC",
+		CreatedAt:      createdAt,
+		EffectivePrice: decimal.RequireFromString("0"),
+		FeeRate:        decimal.RequireFromString("0"),
+		NetQuote:       decimal.RequireFromString("0"),
+		NetBase:        decimal.RequireFromString("0"),
+	}
+}
+
+// TradeExecutedV1Example is an example of TradeExecutedV1 in its wire format.
+type TradeExecutedV1Example struct {
+	Name  string
+	JSON  string
+	Event events.TradeExecutedV1
+}
+
+// TradeExecutedV1Examples returns an example of each shape of order the trader
+// publishes, for consumers to check they can read every one.
+func TradeExecutedV1Examples() []TradeExecutedV1Example {
+	return []TradeExecutedV1Example{
+		{Name: "every field", JSON: TradeExecutedV1JSON, Event: TradeExecutedV1()},
+		{Name: "limit order", JSON: TradeExecutedV1LimitJSON, Event: TradeExecutedV1Limit()},
+		{Name: "size-based market order", JSON: TradeExecutedV1SizeJSON, Event: TradeExecutedV1Size()},
+		{Name: "open order", JSON: TradeExecutedV1OpenJSON, Event: TradeExecutedV1Open()},
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/cshep4/kripto/shared/go/events
+
+go 1.14
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/shopspring/decimal v1.3.1
+	github.com/stretchr/testify v1.5.1
+	gopkg.in/yaml.v2 v2.2.5 // indirect
+)
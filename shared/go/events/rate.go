@@ -0,0 +1,20 @@
+package events
+
+import "time"
+
+// RateUpdatedV1 is the BTC-GBP rate at a point in time.
+type RateUpdatedV1 struct {
+	Rate     float64   `json:"rate"`
+	DateTime time.Time `json:"dateTime"`
+}
+
+func (r RateUpdatedV1) Validate() error {
+	switch {
+	case r.Rate <= 0:
+		return ValidationError{Field: "rate", Reason: "must be positive"}
+	case r.DateTime.IsZero():
+		return ValidationError{Field: "dateTime", Reason: "value is empty"}
+	}
+
+	return nil
+}
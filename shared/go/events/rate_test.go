@@ -0,0 +1,55 @@
+package events_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cshep4/kripto/shared/go/events"
+	"github.com/cshep4/kripto/shared/go/events/eventstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateUpdatedV1_Validate(t *testing.T) {
+	t.Run("returns error if rate is not positive", func(t *testing.T) {
+		err := events.RateUpdatedV1{DateTime: time.Now()}.Validate()
+		require.Error(t, err)
+
+		vErr, ok := err.(events.ValidationError)
+		assert.True(t, ok)
+		assert.Equal(t, "rate", vErr.Field)
+	})
+
+	t.Run("returns error if dateTime is empty", func(t *testing.T) {
+		err := events.RateUpdatedV1{Rate: 1}.Validate()
+		require.Error(t, err)
+
+		vErr, ok := err.(events.ValidationError)
+		assert.True(t, ok)
+		assert.Equal(t, "dateTime", vErr.Field)
+	})
+
+	t.Run("returns nil if valid", func(t *testing.T) {
+		assert.NoError(t, eventstest.RateUpdatedV1().Validate())
+	})
+}
+
+// TestRateUpdatedV1_Schema fails if the wire format of the event changes, which
+// would break consumers of the published version.
+func TestRateUpdatedV1_Schema(t *testing.T) {
+	t.Run("marshals to example", func(t *testing.T) {
+		b, err := json.Marshal(eventstest.RateUpdatedV1())
+		require.NoError(t, err)
+
+		assert.JSONEq(t, eventstest.RateUpdatedV1JSON, string(b))
+	})
+
+	t.Run("unmarshals every field of example", func(t *testing.T) {
+		var rate events.RateUpdatedV1
+		err := json.Unmarshal([]byte(eventstest.RateUpdatedV1JSON), &rate)
+		require.NoError(t, err)
+
+		assert.Equal(t, eventstest.RateUpdatedV1(), rate)
+	})
+}
@@ -0,0 +1,121 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type (
+	// TradeExecutedV1 is an order placed on the exchange. Amounts are decimal
+	// strings as returned by the exchange. Dry-run trades were only simulated
+	// and never placed, so must not be treated as real trades.
+	TradeExecutedV1 struct {
+		Id            string    `json:"id"`
+		Side          string    `json:"side"` // buy or sell.
+		Type          string    `json:"type"`
+		ProductId     string    `json:"productId"`
+		Status        string    `json:"status,omitempty"`
+		Settled       bool      `json:"settled"`
+		Price         string    `json:"price,omitempty"`     // Limit price in quote currency.
+		Size          string    `json:"size,omitempty"`      // Requested size in base currency.
+		StopPrice     string    `json:"stopPrice,omitempty"` // Trigger price in quote currency.
+		TimeInForce   string    `json:"timeInForce,omitempty"`
+		PostOnly      bool      `json:"postOnly,omitempty"`
+		DryRun        bool      `json:"dryRun,omitempty"`
+		CreatedAt     time.Time `json:"createdAt"`
+		Funds         string    `json:"funds,omitempty"`         // Spent Funds in quote currency.
+		FillFees      string    `json:"fillFees,omitempty"`      // Fees in quote currency.
+		FilledSize    string    `json:"filledSize,omitempty"`    // Value in base currency.
+		ExecutedValue string    `json:"executedValue,omitempty"` // Value in quote currency.
+
+		// Derived from the fills, zero if nothing has been filled.
+		EffectivePrice decimal.Decimal `json:"effectivePrice"` // Price per unit of base currency including fees.
+		FeeRate        decimal.Decimal `json:"feeRate"`        // Fees as a fraction of the executed value.
+		NetQuote       decimal.Decimal `json:"netQuote"`       // Change in quote currency balance, e.g. GBP.
+		NetBase        decimal.Decimal `json:"netBase"`        // Change in base currency balance, e.g. BTC.
+
+		Fills []FillV1 `json:"fills,omitempty"`
+	}
+
+	// FillV1 is a single execution of part of an order.
+	FillV1 struct {
+		Id        string    `json:"id"`
+		Price     string    `json:"price"` // Price in quote currency.
+		Size      string    `json:"size"`  // Size in base currency.
+		Fee       string    `json:"fee"`   // Fee in quote currency.
+		Liquidity string    `json:"liquidity"`
+		CreatedAt time.Time `json:"createdAt"`
+	}
+)
+
+func (t TradeExecutedV1) Validate() error {
+	switch {
+	case t.Id == "":
+		return ValidationError{Field: "id", Reason: "value is empty"}
+	case t.Side != "buy" && t.Side != "sell":
+		return ValidationError{Field: "side", Reason: fmt.Sprintf("must be buy or sell, got %q", t.Side)}
+	case t.ProductId == "":
+		return ValidationError{Field: "productId", Reason: "value is empty"}
+	}
+
+	err := validateAmounts(false, []amount{
+		{field: "price", value: t.Price},
+		{field: "size", value: t.Size},
+		{field: "stopPrice", value: t.StopPrice},
+		{field: "funds", value: t.Funds},
+		{field: "fillFees", value: t.FillFees},
+		{field: "filledSize", value: t.FilledSize},
+		{field: "executedValue", value: t.ExecutedValue},
+	})
+	if err != nil {
+		return *err
+	}
+
+	for i, f := range t.Fills {
+		if err := f.validate(); err != nil {
+			err.Field = fmt.Sprintf("fills[%d].%s", i, err.Field)
+			return *err
+		}
+	}
+
+	return nil
+}
+
+func (f FillV1) validate() *ValidationError {
+	if f.Id == "" {
+		return &ValidationError{Field: "id", Reason: "value is empty"}
+	}
+
+	return validateAmounts(true, []amount{
+		{field: "price", value: f.Price},
+		{field: "size", value: f.Size},
+		{field: "fee", value: f.Fee},
+	})
+}
+
+type amount struct {
+	field string
+	value string
+}
+
+// validateAmounts checks each amount is a decimal, allowing them to be empty if they aren't required.
+func validateAmounts(required bool, amounts []amount) *ValidationError {
+	for _, a := range amounts {
+		value := strings.TrimSpace(a.value)
+		if value == "" {
+			if required {
+				return &ValidationError{Field: a.field, Reason: "value is empty"}
+			}
+			continue
+		}
+
+		if _, err := decimal.NewFromString(value); err != nil {
+			return &ValidationError{Field: a.field, Reason: err.Error()}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package events_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cshep4/kripto/shared/go/events"
+	"github.com/cshep4/kripto/shared/go/events/eventstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTradeExecutedV1_Validate(t *testing.T) {
+	t.Run("returns error if required field is invalid", func(t *testing.T) {
+		for field, update := range map[string]func(*events.TradeExecutedV1){
+			"id":             func(e *events.TradeExecutedV1) { e.Id = "" },
+			"side":           func(e *events.TradeExecutedV1) { e.Side = "hold" },
+			"productId":      func(e *events.TradeExecutedV1) { e.ProductId = "" },
+			"funds":          func(e *events.TradeExecutedV1) { e.Funds = "invalid" },
+			"executedValue":  func(e *events.TradeExecutedV1) { e.ExecutedValue = "invalid" },
+			"fills[0].id":    func(e *events.TradeExecutedV1) { e.Fills[0].Id = "" },
+			"fills[0].price": func(e *events.TradeExecutedV1) { e.Fills[0].Price = "invalid" },
+			"fills[0].fee":   func(e *events.TradeExecutedV1) { e.Fills[0].Fee = "" },
+			"fills[0].size":  func(e *events.TradeExecutedV1) { e.Fills[0].Size = " " },
+			"stopPrice":      func(e *events.TradeExecutedV1) { e.StopPrice = "invalid" },
+		} {
+			trade := eventstest.TradeExecutedV1()
+			update(&trade)
+
+			err := trade.Validate()
+			require.Error(t, err, field)
+
+			vErr, ok := err.(events.ValidationError)
+			assert.True(t, ok, field)
+			assert.Equal(t, field, vErr.Field)
+		}
+	})
+
+	t.Run("returns nil if optional amounts are empty", func(t *testing.T) {
+		err := events.TradeExecutedV1{Id: "id", Side: "sell", ProductId: "BTC-GBP"}.Validate()
+		require.NoError(t, err)
+	})
+
+	t.Run("returns nil if valid", func(t *testing.T) {
+		for _, e := range eventstest.TradeExecutedV1Examples() {
+			assert.NoError(t, e.Event.Validate(), e.Name)
+		}
+	})
+}
+
+// TestTradeExecutedV1_Schema fails if the wire format of the event changes,
+// which would break consumers of the published version.
+func TestTradeExecutedV1_Schema(t *testing.T) {
+	t.Run("marshals to example", func(t *testing.T) {
+		for _, e := range eventstest.TradeExecutedV1Examples() {
+			b, err := json.Marshal(e.Event)
+			require.NoError(t, err, e.Name)
+
+			assert.JSONEq(t, e.JSON, string(b), e.Name)
+		}
+	})
+
+	t.Run("unmarshals every field of example", func(t *testing.T) {
+		for _, e := range eventstest.TradeExecutedV1Examples() {
+			var trade events.TradeExecutedV1
+			err := json.Unmarshal([]byte(e.JSON), &trade)
+			require.NoError(t, err, e.Name)
+
+			assert.Equal(t, e.Event, trade, e.Name)
+		}
+	})
+}
@@ -4,6 +4,7 @@ go 1.14
 
 require (
 	github.com/aws/aws-lambda-go v1.17.0
+	github.com/cshep4/kripto/shared/go/apperror v0.0.0-00010101000000-000000000000
 	github.com/cshep4/kripto/shared/go/log v0.0.0-00010101000000-000000000000
 	github.com/golang/mock v1.4.3
 	github.com/stretchr/testify v1.4.0
@@ -11,3 +12,5 @@ require (
 )
 
 replace github.com/cshep4/kripto/shared/go/log => ../log
+
+replace github.com/cshep4/kripto/shared/go/apperror => ../apperror
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/cshep4/kripto/shared/go/apperror"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -31,6 +32,7 @@ type (
 		State     State     `bson:"state"`
 		Response  []byte    `bson:"response,omitempty"`
 		Err       string    `bson:"error,omitempty"`
+		Code      string    `bson:"code,omitempty"`
 		CreatedAt time.Time `bson:"createdAt"`
 		UpdatedAt time.Time `bson:"updatedAt"`
 	}
@@ -45,6 +47,7 @@ type (
 		Check(ctx context.Context, key string) (*Response, error)
 		MarkComplete(ctx context.Context, key string, response []byte) error
 		MarkError(ctx context.Context, key string, err error) error
+		Release(ctx context.Context, key string) error
 	}
 
 	mongoIdempotencer struct {
@@ -132,9 +135,13 @@ func (m *mongoIdempotencer) Check(ctx context.Context, key string) (*Response, e
 	}
 
 	if doc.State == Error {
+		err := errors.New(doc.Err)
+		if doc.Code != "" {
+			err = apperror.New(apperror.Code(doc.Code), err)
+		}
 		return &Response{
 			Exists: true,
-			Err:    errors.New(doc.Err),
+			Err:    err,
 		}, nil
 	}
 
@@ -208,17 +215,37 @@ func (m *mongoIdempotencer) MarkComplete(ctx context.Context, key string, respon
 	}})
 }
 
+// MarkError stores the error, along with its code if it has one so the
+// category of error is kept when it is returned again.
 func (m *mongoIdempotencer) MarkError(ctx context.Context, key string, err error) error {
+	var code apperror.Code
+	if c := apperror.CodeOf(err); c != apperror.Internal {
+		code = c
+	}
+
 	return m.update(ctx, key, bson.D{{
 		Key: "$set",
 		Value: bson.D{
 			{Key: "error", Value: err.Error()},
+			{Key: "code", Value: code},
 			{Key: "state", Value: Error},
 			{Key: "updatedAt", Value: time.Now()},
 		},
 	}})
 }
 
+// Release removes the key so the request can be processed again, used when
+// it failed with an error which may succeed if the request is retried.
+func (m *mongoIdempotencer) Release(ctx context.Context, key string) error {
+	_, err := m.collection.
+		DeleteOne(ctx, bson.D{{Key: "_id", Value: key}})
+	if err != nil {
+		return fmt.Errorf("delete_one: %w", err)
+	}
+
+	return nil
+}
+
 func (m *mongoIdempotencer) update(ctx context.Context, key string, update bson.D) error {
 	res, err := m.collection.
 		UpdateOne(ctx, bson.D{{Key: "_id", Value: key}}, update)
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cshep4/kripto/shared/go/apperror"
 	"github.com/cshep4/kripto/shared/go/idempotency"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -277,6 +278,74 @@ func TestMongoIdempotencer_MarkError(t *testing.T) {
 		assert.Nil(t, res.Response)
 		assert.Equal(t, testErr, res.Err)
 	})
+
+	t.Run("keeps error code", func(t *testing.T) {
+		ctx := context.Background()
+
+		client := newClient(t, ctx)
+		idempotencer, err := idempotency.New(ctx, "database", client)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_, err := client.
+				Database("database").
+				Collection("idempotency").
+				DeleteMany(ctx, bson.M{})
+			require.NoError(t, err)
+
+			err = idempotencer.Close(ctx)
+			require.NoError(t, err)
+		})
+
+		const key = "key"
+		testErr := apperror.New(apperror.InsufficientFunds, errors.New("Insufficient funds"))
+
+		_, err = idempotencer.Check(ctx, key)
+		require.NoError(t, err)
+
+		err = idempotencer.MarkError(ctx, key, testErr)
+		require.NoError(t, err)
+
+		res, err := idempotencer.Check(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, res.Exists)
+		assert.Equal(t, "Insufficient funds", res.Err.Error())
+		assert.Equal(t, apperror.InsufficientFunds, apperror.CodeOf(res.Err))
+	})
+}
+
+func TestMongoIdempotencer_Release(t *testing.T) {
+	t.Run("allows key to be used again", func(t *testing.T) {
+		ctx := context.Background()
+
+		client := newClient(t, ctx)
+		idempotencer, err := idempotency.New(ctx, "database", client)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_, err := client.
+				Database("database").
+				Collection("idempotency").
+				DeleteMany(ctx, bson.M{})
+			require.NoError(t, err)
+
+			err = idempotencer.Close(ctx)
+			require.NoError(t, err)
+		})
+
+		const key = "key"
+
+		res, err := idempotencer.Check(ctx, key)
+		require.NoError(t, err)
+		assert.False(t, res.Exists)
+
+		err = idempotencer.Release(ctx, key)
+		require.NoError(t, err)
+
+		res, err = idempotencer.Check(ctx, key)
+		require.NoError(t, err)
+		assert.False(t, res.Exists)
+	})
 }
 
 func newClient(t *testing.T, ctx context.Context) *mongo.Client {
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/cshep4/kripto/shared/go/apperror"
 	"github.com/cshep4/kripto/shared/go/idempotency"
 	"github.com/cshep4/kripto/shared/go/log"
 )
@@ -124,6 +125,17 @@ func (m *middleware) HandleError(ctx context.Context, payload []byte, err error)
 		return
 	}
 
+	if apperror.IsRetryable(err) {
+		if releaseErr := m.idempotencer.Release(ctx, idempotencyKey); releaseErr != nil {
+			log.Error(ctx, "error_releasing_idempotency_key",
+				log.SafeParam("idempotencyKey", idempotencyKey),
+				log.ErrorParam(releaseErr),
+				log.ErrorParam(err),
+			)
+		}
+		return
+	}
+
 	idempotencyErr := m.idempotencer.MarkError(ctx, idempotencyKey, err)
 	if idempotencyErr != nil {
 		log.Error(ctx, "error_marking_error",
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"github.com/cshep4/kripto/shared/go/apperror"
 	"github.com/cshep4/kripto/shared/go/idempotency"
 	"github.com/cshep4/kripto/shared/go/idempotency/internal/mocks/idempotency"
 	"github.com/cshep4/kripto/shared/go/idempotency/middleware/invoke"
@@ -486,4 +487,31 @@ func TestMiddleware_HandleError(t *testing.T) {
 
 		s.HandleError(ctx, b, testErr)
 	})
+
+	t.Run("releases idempotency key if error is retryable", func(t *testing.T) {
+		ctrl, ctx := gomock.WithContext(context.Background(), t)
+		defer ctrl.Finish()
+
+		const idempotencyKey = "🔑"
+		var (
+			idempotencer = idempotency_mocks.NewMockIdempotencer(ctrl)
+			testErr      = apperror.New(apperror.RateLimited, errors.New("rate limit exceeded"))
+
+			req = map[string]interface{}{
+				"idempotencyKey": idempotencyKey,
+			}
+			b, err = json.Marshal(req)
+		)
+		require.NoError(t, err)
+
+		ctx = log.WithServiceName(ctx, log.New("debug"), "invokeIdempotencer")
+
+		s, err := invoke.NewMiddleware(idempotencer)
+		require.NoError(t, err)
+
+		idempotencer.EXPECT().Release(ctx, idempotencyKey).Return(nil)
+		idempotencer.EXPECT().MarkError(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		s.HandleError(ctx, b, testErr)
+	})
 }
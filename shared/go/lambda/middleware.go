@@ -6,9 +6,10 @@ import (
 )
 
 type (
-	preExecutorFunc  func(ctx context.Context, payload []byte) (bool, context.Context, []byte, error)
-	postExecutorFunc func(ctx context.Context, payload, response []byte) error
-	errorHandlerFunc func(ctx context.Context, payload []byte, err error)
+	preExecutorFunc   func(ctx context.Context, payload []byte) (bool, context.Context, []byte, error)
+	postExecutorFunc  func(ctx context.Context, payload, response []byte) error
+	errorHandlerFunc  func(ctx context.Context, payload []byte, err error)
+	errorResponseFunc func(err error) error
 
 	preExecutor struct {
 		handler lambda.Handler
@@ -27,6 +28,11 @@ type (
 		runner       *runner
 		errorHandler errorHandlerFunc
 	}
+
+	errorResponder struct {
+		handler lambda.Handler
+		respond errorResponseFunc
+	}
 )
 
 func (pe *preExecutor) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
@@ -74,3 +80,12 @@ func (eh *errorHandler) Invoke(ctx context.Context, payload []byte) ([]byte, err
 
 	return nil, err
 }
+
+func (er *errorResponder) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	res, err := er.handler.Invoke(ctx, payload)
+	if err != nil {
+		return nil, er.respond(err)
+	}
+
+	return res, nil
+}
@@ -4,6 +4,7 @@ const (
 	preExecute optionType = iota + 1
 	postExecute
 	errorHandle
+	errorResponse
 )
 
 type (
@@ -53,3 +54,17 @@ func WithErrorHandler(e errorHandlerFunc) option {
 		},
 	}
 }
+
+// WithErrorResponse converts every error returned by the function, including
+// errors from the other options, before it is returned in the Lambda error response.
+func WithErrorResponse(e errorResponseFunc) option {
+	return option{
+		optionType: errorResponse,
+		apply: func(r *runner) {
+			r.Handler = &errorResponder{
+				respond: e,
+				handler: r.Handler,
+			}
+		},
+	}
+}
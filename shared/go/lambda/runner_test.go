@@ -3,6 +3,7 @@ package lambda_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -563,6 +564,55 @@ func TestRunner_Invoke(t *testing.T) {
 		_, err := runner.Invoke(ctx, payload)
 		require.NoError(t, err)
 	})
+
+	t.Run("error response converts error from function", func(t *testing.T) {
+		var (
+			ctx     = context.Background()
+			payload = []byte{1}
+			testErr = errors.New("error")
+
+			function = func() ([]byte, error) { return nil, testErr }
+		)
+
+		runner := lambda.New(
+			function,
+			lambda.WithErrorResponse(func(err error) error {
+				return fmt.Errorf("converted: %w", err)
+			}),
+		)
+
+		_, err := runner.Invoke(ctx, payload)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+		assert.Equal(t, "converted: error", err.Error())
+	})
+
+	t.Run("error response converts error returned by done pre executor", func(t *testing.T) {
+		var (
+			ctx     = context.Background()
+			payload = []byte{1}
+			testErr = errors.New("error")
+
+			function    = func() ([]byte, error) { return nil, nil }
+			preExecutor = func(ctx context.Context, payload []byte) (bool, context.Context, []byte, error) {
+				return true, ctx, nil, testErr
+			}
+		)
+
+		runner := lambda.New(
+			function,
+			lambda.WithErrorResponse(func(err error) error {
+				return fmt.Errorf("converted: %w", err)
+			}),
+			lambda.WithPreExecute(preExecutor),
+		)
+
+		_, err := runner.Invoke(ctx, payload)
+		require.Error(t, err)
+
+		assert.Equal(t, "converted: error", err.Error())
+	})
 }
 
 func errorHandlerFunc(h aws.Handler) func(ctx context.Context, payload []byte, err error) {
@@ -0,0 +1,77 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+)
+
+type (
+	EventBridgeClient interface {
+		PutEventsWithContext(ctx context.Context, input *eventbridge.PutEventsInput, opts ...request.Option) (*eventbridge.PutEventsOutput, error)
+	}
+
+	eventBridgePublisher struct {
+		client   EventBridgeClient
+		eventBus string
+	}
+
+	// FailedEntryError is returned when EventBridge accepts the request but fails to put the event.
+	FailedEntryError struct {
+		Code    string
+		Message string
+	}
+)
+
+func (f FailedEntryError) Error() string {
+	return fmt.Sprintf("%s: %s", f.Code, f.Message)
+}
+
+// NewEventBridge returns a publisher which puts events on the EventBridge event
+// bus. The event type is the detail type, so rules can match on it.
+func NewEventBridge(client EventBridgeClient, eventBus string) (*eventBridgePublisher, error) {
+	switch {
+	case client == nil:
+		return nil, InvalidParameterError{Parameter: "client"}
+	case eventBus == "":
+		return nil, InvalidParameterError{Parameter: "eventBus"}
+	}
+
+	return &eventBridgePublisher{
+		client:   client,
+		eventBus: eventBus,
+	}, nil
+}
+
+func (p *eventBridgePublisher) Publish(ctx context.Context, event Event) error {
+	detail, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("json_marshal: %w", err)
+	}
+
+	out, err := p.client.PutEventsWithContext(ctx, &eventbridge.PutEventsInput{
+		Entries: []*eventbridge.PutEventsRequestEntry{{
+			Detail:       aws.String(string(detail)),
+			DetailType:   aws.String(event.Type),
+			EventBusName: aws.String(p.eventBus),
+			Source:       aws.String(event.Source),
+			Time:         aws.Time(event.Timestamp),
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("put_events: %w", err)
+	}
+
+	if aws.Int64Value(out.FailedEntryCount) > 0 && len(out.Entries) > 0 {
+		return fmt.Errorf("put_events: %w", FailedEntryError{
+			Code:    aws.StringValue(out.Entries[0].ErrorCode),
+			Message: aws.StringValue(out.Entries[0].ErrorMessage),
+		})
+	}
+
+	return nil
+}
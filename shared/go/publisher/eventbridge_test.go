@@ -0,0 +1,123 @@
+package publisher_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/cshep4/kripto/shared/go/publisher"
+	"github.com/cshep4/kripto/shared/go/publisher/internal/mocks/aws"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEventBridge(t *testing.T) {
+	t.Run("returns error if client is empty", func(t *testing.T) {
+		p, err := publisher.NewEventBridge(nil, "bus")
+		require.Error(t, err)
+
+		assert.Nil(t, p)
+
+		ipErr, ok := err.(publisher.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "client", ipErr.Parameter)
+	})
+
+	t.Run("returns error if event bus is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		p, err := publisher.NewEventBridge(aws_mocks.NewMockEventBridgeClient(ctrl), "")
+		require.Error(t, err)
+
+		assert.Nil(t, p)
+
+		ipErr, ok := err.(publisher.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "eventBus", ipErr.Parameter)
+	})
+}
+
+func TestEventBridgePublisher_Publish(t *testing.T) {
+	t.Run("returns error if error putting events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := aws_mocks.NewMockEventBridgeClient(ctrl)
+		testErr := errors.New("error")
+		ctx := context.Background()
+
+		client.EXPECT().PutEventsWithContext(ctx, gomock.Any()).Return(nil, testErr)
+
+		p, err := publisher.NewEventBridge(client, "bus")
+		require.NoError(t, err)
+
+		err = p.Publish(ctx, testEvent(t))
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("returns error if entry failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := aws_mocks.NewMockEventBridgeClient(ctrl)
+		ctx := context.Background()
+
+		client.EXPECT().PutEventsWithContext(ctx, gomock.Any()).Return(&eventbridge.PutEventsOutput{
+			FailedEntryCount: aws.Int64(1),
+			Entries: []*eventbridge.PutEventsResultEntry{{
+				ErrorCode:    aws.String("InternalFailure"),
+				ErrorMessage: aws.String("failed"),
+			}},
+		}, nil)
+
+		p, err := publisher.NewEventBridge(client, "bus")
+		require.NoError(t, err)
+
+		err = p.Publish(ctx, testEvent(t))
+		require.Error(t, err)
+
+		var fErr publisher.FailedEntryError
+		require.True(t, errors.As(err, &fErr))
+		assert.Equal(t, "InternalFailure", fErr.Code)
+		assert.Equal(t, "failed", fErr.Message)
+	})
+
+	t.Run("puts event with envelope as detail", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := aws_mocks.NewMockEventBridgeClient(ctrl)
+		event := testEvent(t)
+		ctx := context.Background()
+
+		client.EXPECT().PutEventsWithContext(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, input *eventbridge.PutEventsInput, _ ...interface{}) (*eventbridge.PutEventsOutput, error) {
+			require.Len(t, input.Entries, 1)
+
+			entry := input.Entries[0]
+			assert.Equal(t, "bus", aws.StringValue(entry.EventBusName))
+			assert.Equal(t, "trader", aws.StringValue(entry.Source))
+			assert.Equal(t, "TradeExecuted", aws.StringValue(entry.DetailType))
+			assert.True(t, event.Timestamp.Equal(aws.TimeValue(entry.Time)))
+
+			detail := envelope(t, aws.StringValue(entry.Detail))
+			assert.Equal(t, event.Id, detail.Id)
+			assert.Equal(t, event.Version, detail.Version)
+			assert.JSONEq(t, string(event.Data), string(detail.Data))
+			assert.Equal(t, event.Attributes, detail.Attributes)
+
+			return &eventbridge.PutEventsOutput{FailedEntryCount: aws.Int64(0)}, nil
+		})
+
+		p, err := publisher.NewEventBridge(client, "bus")
+		require.NoError(t, err)
+
+		err = p.Publish(ctx, event)
+		require.NoError(t, err)
+	})
+}
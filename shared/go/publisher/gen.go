@@ -0,0 +1,3 @@
+package publisher
+
+//go:generate mockgen -destination=internal/mocks/aws/client.gen.go -package=aws_mocks github.com/cshep4/kripto/shared/go/publisher SNSClient,SQSClient,EventBridgeClient
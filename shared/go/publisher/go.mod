@@ -0,0 +1,12 @@
+module github.com/cshep4/kripto/shared/go/publisher
+
+go 1.14
+
+require (
+	github.com/aws/aws-sdk-go v1.31.0
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/golang/mock v1.4.3
+	github.com/kevinburke/go.uuid v1.2.0
+	github.com/stretchr/testify v1.5.1
+	gopkg.in/yaml.v2 v2.2.5 // indirect
+)
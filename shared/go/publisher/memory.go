@@ -0,0 +1,41 @@
+package publisher
+
+import (
+	"context"
+	"sync"
+)
+
+// memoryPublisher records the events published, for tests and running locally.
+type memoryPublisher struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func NewMemory() *memoryPublisher {
+	return &memoryPublisher{}
+}
+
+func (p *memoryPublisher) Publish(_ context.Context, event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, event)
+
+	return nil
+}
+
+// Events returns the events published so far, oldest first.
+func (p *memoryPublisher) Events() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Event(nil), p.events...)
+}
+
+// Reset forgets the events published so far.
+func (p *memoryPublisher) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = nil
+}
@@ -0,0 +1,36 @@
+package publisher_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cshep4/kripto/shared/go/publisher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryPublisher(t *testing.T) {
+	t.Run("records events in order published", func(t *testing.T) {
+		p := publisher.NewMemory()
+		ctx := context.Background()
+
+		first, second := testEvent(t), testEvent(t)
+		second.Id = "second"
+
+		require.NoError(t, p.Publish(ctx, first))
+		require.NoError(t, p.Publish(ctx, second))
+
+		assert.Equal(t, []publisher.Event{first, second}, p.Events())
+	})
+
+	t.Run("forgets events when reset", func(t *testing.T) {
+		p := publisher.NewMemory()
+		ctx := context.Background()
+
+		require.NoError(t, p.Publish(ctx, testEvent(t)))
+
+		p.Reset()
+
+		assert.Empty(t, p.Events())
+	})
+}
@@ -0,0 +1,145 @@
+// Package publisher publishes events to SNS, SQS, EventBridge or memory.
+//
+// Every event is published in a versioned envelope. On SNS and SQS the
+// message body is the event data and the envelope is sent as message
+// attributes, so existing subscribers which read the body are unaffected and
+// subscriptions can filter on the event type. EventBridge has no message
+// attributes, so the whole envelope is sent as the event detail.
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	uuid "github.com/kevinburke/go.uuid"
+)
+
+const (
+	SNS         = "sns"
+	SQS         = "sqs"
+	EventBridge = "eventbridge"
+	Memory      = "memory"
+
+	// Message attribute names holding the envelope.
+	IdAttribute        = "id"
+	TypeAttribute      = "type"
+	VersionAttribute   = "version"
+	SourceAttribute    = "source"
+	TimestampAttribute = "timestamp"
+)
+
+type (
+	// Event is the envelope every event is published in. Version is bumped
+	// whenever the shape of Data changes so consumers can tell which they received.
+	Event struct {
+		Id        string          `json:"id"`
+		Type      string          `json:"type"`
+		Version   int             `json:"version"`
+		Source    string          `json:"source"`
+		Timestamp time.Time       `json:"timestamp"`
+		Data      json.RawMessage `json:"data"`
+		// Attributes are published alongside the envelope, e.g. to filter subscriptions.
+		Attributes map[string]string `json:"attributes,omitempty"`
+	}
+
+	Publisher interface {
+		Publish(ctx context.Context, event Event) error
+	}
+
+	// Config selects where events are published.
+	Config struct {
+		// Type is either sns (default), sqs, eventbridge or memory.
+		Type     string `env:"PUBLISHER"`
+		Topic    string `env:"TOPIC"`
+		QueueURL string `env:"QUEUE_URL"`
+		EventBus string `env:"EVENT_BUS"`
+	}
+
+	// InvalidParameterError is returned when a required parameter passed to New is invalid.
+	InvalidParameterError struct {
+		Parameter string
+	}
+)
+
+func (i InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s", i.Parameter)
+}
+
+// New returns the publisher selected by the config, using the session to create the AWS client.
+func New(p client.ConfigProvider, cfg Config) (Publisher, error) {
+	switch cfg.Type {
+	case "", SNS:
+		return NewSNS(sns.New(p), cfg.Topic)
+	case SQS:
+		return NewSQS(sqs.New(p), cfg.QueueURL)
+	case EventBridge:
+		return NewEventBridge(eventbridge.New(p), cfg.EventBus)
+	case Memory:
+		return NewMemory(), nil
+	default:
+		return nil, InvalidParameterError{Parameter: "type"}
+	}
+}
+
+// NewEvent marshals the data into an event of the given type and version. If
+// id is empty a random one is generated.
+func NewEvent(source, eventType string, version int, id string, data interface{}) (Event, error) {
+	switch {
+	case source == "":
+		return Event{}, InvalidParameterError{Parameter: "source"}
+	case eventType == "":
+		return Event{}, InvalidParameterError{Parameter: "eventType"}
+	case version < 1:
+		return Event{}, InvalidParameterError{Parameter: "version"}
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("json_marshal: %w", err)
+	}
+
+	if id == "" {
+		id = uuid.NewV4().String()
+	}
+
+	return Event{
+		Id:        id,
+		Type:      eventType,
+		Version:   version,
+		Source:    source,
+		Timestamp: time.Now().UTC(),
+		Data:      b,
+	}, nil
+}
+
+// attributes returns the envelope and the event's own attributes as message attributes.
+func (e Event) attributes() map[string]string {
+	attrs := make(map[string]string, len(e.Attributes)+5)
+	for k, v := range e.Attributes {
+		attrs[k] = v
+	}
+
+	attrs[IdAttribute] = e.Id
+	attrs[TypeAttribute] = e.Type
+	attrs[VersionAttribute] = strconv.Itoa(e.Version)
+	attrs[SourceAttribute] = e.Source
+	attrs[TimestampAttribute] = e.Timestamp.Format(time.RFC3339Nano)
+
+	return attrs
+}
+
+// dataType is the message attribute data type of the attribute, so numeric
+// subscription filters can be used on the version.
+func dataType(name string) string {
+	if name == VersionAttribute {
+		return "Number"
+	}
+	return "String"
+}
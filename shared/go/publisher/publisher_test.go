@@ -0,0 +1,135 @@
+package publisher_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/cshep4/kripto/shared/go/publisher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String("eu-west-2")})
+	require.NoError(t, err)
+
+	t.Run("returns error if type is unknown", func(t *testing.T) {
+		p, err := publisher.New(sess, publisher.Config{Type: "kafka"})
+		require.Error(t, err)
+
+		assert.Nil(t, p)
+
+		ipErr, ok := err.(publisher.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "type", ipErr.Parameter)
+	})
+
+	t.Run("returns sns publisher by default", func(t *testing.T) {
+		p, err := publisher.New(sess, publisher.Config{Topic: "topic"})
+		require.NoError(t, err)
+
+		assert.NotNil(t, p)
+	})
+
+	t.Run("returns error if destination of type is empty", func(t *testing.T) {
+		for typ, parameter := range map[string]string{
+			publisher.SNS:         "topic",
+			publisher.SQS:         "queueURL",
+			publisher.EventBridge: "eventBus",
+		} {
+			_, err := publisher.New(sess, publisher.Config{Type: typ})
+			require.Error(t, err)
+
+			ipErr, ok := err.(publisher.InvalidParameterError)
+			assert.True(t, ok)
+			assert.Equal(t, parameter, ipErr.Parameter)
+		}
+	})
+
+	t.Run("returns publisher of type", func(t *testing.T) {
+		for _, cfg := range []publisher.Config{
+			{Type: publisher.SQS, QueueURL: "url"},
+			{Type: publisher.EventBridge, EventBus: "bus"},
+			{Type: publisher.Memory},
+		} {
+			p, err := publisher.New(sess, cfg)
+			require.NoError(t, err)
+
+			assert.NotNil(t, p)
+		}
+	})
+}
+
+func TestNewEvent(t *testing.T) {
+	t.Run("returns error if source is empty", func(t *testing.T) {
+		_, err := publisher.NewEvent("", "TradeExecuted", 1, "id", nil)
+		require.Error(t, err)
+
+		ipErr, ok := err.(publisher.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "source", ipErr.Parameter)
+	})
+
+	t.Run("returns error if event type is empty", func(t *testing.T) {
+		_, err := publisher.NewEvent("trader", "", 1, "id", nil)
+		require.Error(t, err)
+
+		ipErr, ok := err.(publisher.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "eventType", ipErr.Parameter)
+	})
+
+	t.Run("returns error if version is not positive", func(t *testing.T) {
+		_, err := publisher.NewEvent("trader", "TradeExecuted", 0, "id", nil)
+		require.Error(t, err)
+
+		ipErr, ok := err.(publisher.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "version", ipErr.Parameter)
+	})
+
+	t.Run("returns error if data can't be marshalled", func(t *testing.T) {
+		_, err := publisher.NewEvent("trader", "TradeExecuted", 1, "id", make(chan int))
+		require.Error(t, err)
+	})
+
+	t.Run("returns event with data", func(t *testing.T) {
+		e, err := publisher.NewEvent("trader", "TradeExecuted", 2, "id", map[string]string{"key": "value"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "id", e.Id)
+		assert.Equal(t, "TradeExecuted", e.Type)
+		assert.Equal(t, 2, e.Version)
+		assert.Equal(t, "trader", e.Source)
+		assert.False(t, e.Timestamp.IsZero())
+		assert.JSONEq(t, `{"key":"value"}`, string(e.Data))
+	})
+
+	t.Run("generates id if empty", func(t *testing.T) {
+		e, err := publisher.NewEvent("trader", "TradeExecuted", 1, "", nil)
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, e.Id)
+	})
+}
+
+func testEvent(t *testing.T) publisher.Event {
+	t.Helper()
+
+	e, err := publisher.NewEvent("trader", "TradeExecuted", 1, "id", map[string]string{"key": "value"})
+	require.NoError(t, err)
+	e.Attributes = map[string]string{"productId": "BTC-GBP"}
+
+	return e
+}
+
+func envelope(t *testing.T, detail string) publisher.Event {
+	t.Helper()
+
+	var e publisher.Event
+	require.NoError(t, json.Unmarshal([]byte(detail), &e))
+
+	return e
+}
@@ -0,0 +1,57 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sns"
+)
+
+type (
+	SNSClient interface {
+		PublishWithContext(ctx context.Context, input *sns.PublishInput, opts ...request.Option) (*sns.PublishOutput, error)
+	}
+
+	snsPublisher struct {
+		client SNSClient
+		topic  string
+	}
+)
+
+// NewSNS returns a publisher which publishes events to the SNS topic.
+func NewSNS(client SNSClient, topic string) (*snsPublisher, error) {
+	switch {
+	case client == nil:
+		return nil, InvalidParameterError{Parameter: "client"}
+	case topic == "":
+		return nil, InvalidParameterError{Parameter: "topic"}
+	}
+
+	return &snsPublisher{
+		client: client,
+		topic:  topic,
+	}, nil
+}
+
+func (p *snsPublisher) Publish(ctx context.Context, event Event) error {
+	attrs := make(map[string]*sns.MessageAttributeValue)
+	for k, v := range event.attributes() {
+		attrs[k] = &sns.MessageAttributeValue{
+			DataType:    aws.String(dataType(k)),
+			StringValue: aws.String(v),
+		}
+	}
+
+	_, err := p.client.PublishWithContext(ctx, &sns.PublishInput{
+		Message:           aws.String(string(event.Data)),
+		MessageAttributes: attrs,
+		TopicArn:          aws.String(p.topic),
+	})
+	if err != nil {
+		return fmt.Errorf("publish: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package publisher_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/cshep4/kripto/shared/go/publisher"
+	"github.com/cshep4/kripto/shared/go/publisher/internal/mocks/aws"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSNS(t *testing.T) {
+	t.Run("returns error if client is empty", func(t *testing.T) {
+		p, err := publisher.NewSNS(nil, "topic")
+		require.Error(t, err)
+
+		assert.Nil(t, p)
+
+		ipErr, ok := err.(publisher.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "client", ipErr.Parameter)
+	})
+
+	t.Run("returns error if topic is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		p, err := publisher.NewSNS(aws_mocks.NewMockSNSClient(ctrl), "")
+		require.Error(t, err)
+
+		assert.Nil(t, p)
+
+		ipErr, ok := err.(publisher.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "topic", ipErr.Parameter)
+	})
+}
+
+func TestSNSPublisher_Publish(t *testing.T) {
+	t.Run("returns error if error publishing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := aws_mocks.NewMockSNSClient(ctrl)
+		testErr := errors.New("error")
+		ctx := context.Background()
+
+		client.EXPECT().PublishWithContext(ctx, gomock.Any()).Return(nil, testErr)
+
+		p, err := publisher.NewSNS(client, "topic")
+		require.NoError(t, err)
+
+		err = p.Publish(ctx, testEvent(t))
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("publishes data with envelope as message attributes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := aws_mocks.NewMockSNSClient(ctrl)
+		event := testEvent(t)
+		ctx := context.Background()
+
+		client.EXPECT().PublishWithContext(ctx, &sns.PublishInput{
+			Message: aws.String(`{"key":"value"}`),
+			MessageAttributes: map[string]*sns.MessageAttributeValue{
+				"id":        {DataType: aws.String("String"), StringValue: aws.String("id")},
+				"type":      {DataType: aws.String("String"), StringValue: aws.String("TradeExecuted")},
+				"version":   {DataType: aws.String("Number"), StringValue: aws.String("1")},
+				"source":    {DataType: aws.String("String"), StringValue: aws.String("trader")},
+				"timestamp": {DataType: aws.String("String"), StringValue: aws.String(event.Timestamp.Format(time.RFC3339Nano))},
+				"productId": {DataType: aws.String("String"), StringValue: aws.String("BTC-GBP")},
+			},
+			TopicArn: aws.String("topic"),
+		}).Return(&sns.PublishOutput{}, nil)
+
+		p, err := publisher.NewSNS(client, "topic")
+		require.NoError(t, err)
+
+		err = p.Publish(ctx, event)
+		require.NoError(t, err)
+	})
+}
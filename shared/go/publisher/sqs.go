@@ -0,0 +1,66 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+type (
+	SQSClient interface {
+		SendMessageWithContext(ctx context.Context, input *sqs.SendMessageInput, opts ...request.Option) (*sqs.SendMessageOutput, error)
+	}
+
+	sqsPublisher struct {
+		client   SQSClient
+		queueURL string
+		fifo     bool
+	}
+)
+
+// NewSQS returns a publisher which sends events to the SQS queue. If the queue
+// is a FIFO queue, events are deduplicated by id and ordered by type.
+func NewSQS(client SQSClient, queueURL string) (*sqsPublisher, error) {
+	switch {
+	case client == nil:
+		return nil, InvalidParameterError{Parameter: "client"}
+	case queueURL == "":
+		return nil, InvalidParameterError{Parameter: "queueURL"}
+	}
+
+	return &sqsPublisher{
+		client:   client,
+		queueURL: queueURL,
+		fifo:     strings.HasSuffix(queueURL, ".fifo"),
+	}, nil
+}
+
+func (p *sqsPublisher) Publish(ctx context.Context, event Event) error {
+	attrs := make(map[string]*sqs.MessageAttributeValue)
+	for k, v := range event.attributes() {
+		attrs[k] = &sqs.MessageAttributeValue{
+			DataType:    aws.String(dataType(k)),
+			StringValue: aws.String(v),
+		}
+	}
+
+	input := &sqs.SendMessageInput{
+		MessageBody:       aws.String(string(event.Data)),
+		MessageAttributes: attrs,
+		QueueUrl:          aws.String(p.queueURL),
+	}
+	if p.fifo {
+		input.MessageDeduplicationId = aws.String(event.Id)
+		input.MessageGroupId = aws.String(event.Type)
+	}
+
+	if _, err := p.client.SendMessageWithContext(ctx, input); err != nil {
+		return fmt.Errorf("send_message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package publisher_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/cshep4/kripto/shared/go/publisher"
+	"github.com/cshep4/kripto/shared/go/publisher/internal/mocks/aws"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSQS(t *testing.T) {
+	t.Run("returns error if client is empty", func(t *testing.T) {
+		p, err := publisher.NewSQS(nil, "url")
+		require.Error(t, err)
+
+		assert.Nil(t, p)
+
+		ipErr, ok := err.(publisher.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "client", ipErr.Parameter)
+	})
+
+	t.Run("returns error if queue url is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		p, err := publisher.NewSQS(aws_mocks.NewMockSQSClient(ctrl), "")
+		require.Error(t, err)
+
+		assert.Nil(t, p)
+
+		ipErr, ok := err.(publisher.InvalidParameterError)
+		assert.True(t, ok)
+		assert.Equal(t, "queueURL", ipErr.Parameter)
+	})
+}
+
+func TestSQSPublisher_Publish(t *testing.T) {
+	t.Run("returns error if error sending message", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := aws_mocks.NewMockSQSClient(ctrl)
+		testErr := errors.New("error")
+		ctx := context.Background()
+
+		client.EXPECT().SendMessageWithContext(ctx, gomock.Any()).Return(nil, testErr)
+
+		p, err := publisher.NewSQS(client, "url")
+		require.NoError(t, err)
+
+		err = p.Publish(ctx, testEvent(t))
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("sends data with envelope as message attributes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := aws_mocks.NewMockSQSClient(ctrl)
+		ctx := context.Background()
+
+		client.EXPECT().SendMessageWithContext(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, input *sqs.SendMessageInput, _ ...interface{}) (*sqs.SendMessageOutput, error) {
+			assert.Equal(t, "url", aws.StringValue(input.QueueUrl))
+			assert.Equal(t, `{"key":"value"}`, aws.StringValue(input.MessageBody))
+			assert.Equal(t, "TradeExecuted", aws.StringValue(input.MessageAttributes["type"].StringValue))
+			assert.Equal(t, "Number", aws.StringValue(input.MessageAttributes["version"].DataType))
+			assert.Equal(t, "BTC-GBP", aws.StringValue(input.MessageAttributes["productId"].StringValue))
+			assert.Nil(t, input.MessageGroupId)
+			assert.Nil(t, input.MessageDeduplicationId)
+			return &sqs.SendMessageOutput{}, nil
+		})
+
+		p, err := publisher.NewSQS(client, "url")
+		require.NoError(t, err)
+
+		err = p.Publish(ctx, testEvent(t))
+		require.NoError(t, err)
+	})
+
+	t.Run("sets group and deduplication id for fifo queue", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := aws_mocks.NewMockSQSClient(ctrl)
+		ctx := context.Background()
+
+		client.EXPECT().SendMessageWithContext(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, input *sqs.SendMessageInput, _ ...interface{}) (*sqs.SendMessageOutput, error) {
+			assert.Equal(t, "TradeExecuted", aws.StringValue(input.MessageGroupId))
+			assert.Equal(t, "id", aws.StringValue(input.MessageDeduplicationId))
+			return &sqs.SendMessageOutput{}, nil
+		})
+
+		p, err := publisher.NewSQS(client, "url.fifo")
+		require.NoError(t, err)
+
+		err = p.Publish(ctx, testEvent(t))
+		require.NoError(t, err)
+	})
+}
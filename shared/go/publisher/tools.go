@@ -0,0 +1,5 @@
+// +build tools
+
+package tools
+
+import _ "github.com/golang/mock/mockgen"